@@ -0,0 +1,130 @@
+/*
+app.go contains the App struct, which holds the configuration, database, logger, HTTP
+client, messengers and Discord session used by the bot. It is built by the cli package and
+passed to the packages that run the bot, so they do not read package globals and can be
+run with a different configuration or a fake Discord session.
+*/
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/config"
+	"gamestreams/db"
+	"gamestreams/jobs"
+	"gamestreams/logs"
+	"gamestreams/messenger"
+	"gamestreams/providers"
+	"gamestreams/utils"
+)
+
+// App holds the dependencies of the bot.
+type App struct {
+	// The configuration values of the bot, replaced when config.toml is reloaded.
+	config atomic.Pointer[config.Config]
+	// The database the bot stores its data in.
+	DB *db.Database
+	// The loggers of the bot.
+	Log *logs.Logger
+	// The client that HTTP requests are made with.
+	HTTP *utils.Client
+	// Resolves the direct URLs, metadata and live status of stream URLs.
+	Providers *providers.Resolver
+	// The messengers that streams are announced with. The first is the default, which is
+	// used to send DMs.
+	Messengers []messenger.Messenger
+	// The Discord session, or nil if the bot is not connected to Discord.
+	Session *discordgo.Session
+	// The jobs the bot runs on a schedule, which are registered when the bot is run. It is
+	// set with the database.
+	Jobs *jobs.Registry
+	// The time the bot started.
+	StartTime time.Time
+
+	// Cancelled when the bot starts shutting down.
+	ctx context.Context
+	// Cancels ctx.
+	cancel context.CancelFunc
+	// Guards tasks, keys, nextTask and drained.
+	tasksMu sync.Mutex
+	// The names of the running tasks, by their ID.
+	tasks map[int]string
+	// The keys of the tasks started with GoOnce that have not finished.
+	keys map[string]bool
+	// The ID of the next task to start.
+	nextTask int
+	// Closed once no tasks are running while Shutdown is waiting for them, or nil.
+	drained chan struct{}
+}
+
+// New returns an App with the given configuration and loggers. The database is set with
+// OpenDB or SetDB, and messengers and the Discord session are added when the bot is run.
+func New(cfg *config.Config, log *logs.Logger) *App {
+	a := &App{
+		Log:   log,
+		tasks: make(map[int]string),
+		keys:  make(map[string]bool),
+	}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	a.SetConfig(cfg)
+	a.HTTP = utils.NewClient(a.ctx, a.Config, log)
+	return a
+}
+
+// OpenDB opens the database file set in config.toml, creating it and its tables if they
+// do not exist, and sets it as the database of the App.
+func (a *App) OpenDB() error {
+	database, openErr := db.Open(a.Config().Files.Database, a.Config, a.Log, a.HTTP)
+	if openErr != nil {
+		return openErr
+	}
+	a.SetDB(database)
+	return nil
+}
+
+// SetDB sets the database of the App, and the jobs and providers that use it.
+func (a *App) SetDB(database *db.Database) {
+	a.DB = database
+	a.Jobs = jobs.NewRegistry(a.ctx, database, a.Log, a.Track)
+	a.Providers = providers.NewResolver(a.HTTP, db.MetadataCache{DB: database}, a.Log)
+}
+
+// Config returns the configuration values of the bot. They are shared and must not be
+// modified.
+func (a *App) Config() *config.Config {
+	return a.config.Load()
+}
+
+// SetConfig atomically replaces the configuration values of the bot. Functions already
+// running keep the values they read, and read the new values the next time they call
+// Config.
+func (a *App) SetConfig(cfg *config.Config) {
+	a.config.Store(cfg)
+}
+
+// AddMessenger adds a messenger that streams are announced with. The first messenger
+// added is the default, and is used to DM the bot owner.
+func (a *App) AddMessenger(m messenger.Messenger) {
+	a.Messengers = append(a.Messengers, m)
+	if len(a.Messengers) == 1 {
+		a.Log.RegisterMessenger(m)
+	}
+}
+
+// Messenger returns the default messenger, or nil if none have been added.
+func (a *App) Messenger() messenger.Messenger {
+	if len(a.Messengers) == 0 {
+		return nil
+	}
+	return a.Messengers[0]
+}
+
+// Uptime returns how long the bot has been running.
+func (a *App) Uptime() time.Duration {
+	return time.Since(a.StartTime)
+}
@@ -0,0 +1,124 @@
+/*
+tasks.go contains the functions that track the background work of the bot, such as
+scheduled jobs and announcements, so it can be cancelled and waited for when the bot
+shuts down.
+*/
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Context returns a context that is cancelled when the bot starts shutting down.
+// Long-running work should stop once it is done.
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// ShuttingDown reports whether the bot has started shutting down.
+func (a *App) ShuttingDown() bool {
+	return a.ctx.Err() != nil
+}
+
+// Sleep pauses for the duration, or until the bot starts shutting down. It returns
+// false if the bot started shutting down first.
+func (a *App) Sleep(d time.Duration) bool {
+	if d <= 0 {
+		return !a.ShuttingDown()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-a.ctx.Done():
+		return false
+	}
+}
+
+// Track records that the task with the name has started, and returns the function to
+// call when it has finished. Shutdown waits for tracked tasks to finish.
+func (a *App) Track(name string) (done func()) {
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+
+	id := a.nextTask
+	a.nextTask++
+	a.tasks[id] = name
+	return func() {
+		a.tasksMu.Lock()
+		defer a.tasksMu.Unlock()
+
+		delete(a.tasks, id)
+		if len(a.tasks) == 0 && a.drained != nil {
+			close(a.drained)
+			a.drained = nil
+		}
+	}
+}
+
+// Go runs f in a new goroutine as a task with the name, which Shutdown waits for.
+func (a *App) Go(name string, f func()) {
+	done := a.Track(name)
+	go func() {
+		defer done()
+		f()
+	}()
+}
+
+// GoOnce runs f in a new goroutine as a task with the name, like Go, unless a task
+// started with the same key has not finished yet. It returns false if f was not run.
+func (a *App) GoOnce(key string, name string, f func()) bool {
+	a.tasksMu.Lock()
+	if a.keys[key] {
+		a.tasksMu.Unlock()
+		return false
+	}
+	a.keys[key] = true
+	a.tasksMu.Unlock()
+
+	a.Go(name, func() {
+		defer func() {
+			a.tasksMu.Lock()
+			delete(a.keys, key)
+			a.tasksMu.Unlock()
+		}()
+		f()
+	})
+	return true
+}
+
+// Shutdown cancels the context of the App and waits up to the timeout for the tracked
+// tasks to finish. It returns the names of the tasks that were still running when the
+// timeout was reached, sorted, or nil if they all finished.
+func (a *App) Shutdown(timeout time.Duration) []string {
+	a.cancel()
+
+	a.tasksMu.Lock()
+	if len(a.tasks) == 0 {
+		a.tasksMu.Unlock()
+		return nil
+	}
+	drained := make(chan struct{})
+	a.drained = drained
+	a.tasksMu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+	}
+
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+	var running []string
+	for _, name := range a.tasks {
+		running = append(running, name)
+	}
+	slices.Sort(running)
+	return running
+}
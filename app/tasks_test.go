@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"gamestreams/config"
+	"gamestreams/logs"
+)
+
+func TestGoOnce(t *testing.T) {
+	cfg := &config.Config{}
+	a := New(cfg, logs.New(cfg.Logs))
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	if !a.GoOnce("key", "first", func() {
+		<-release
+		close(finished)
+	}) {
+		t.Fatal("GoOnce() = false, want the first task to run")
+	}
+	if a.GoOnce("key", "second", func() {}) {
+		t.Error("GoOnce() = true while a task with the key is running, want false")
+	}
+	if !a.GoOnce("other", "other", func() {}) {
+		t.Error("GoOnce() = false for another key, want true")
+	}
+
+	close(release)
+	<-finished
+	// The key is released once the task has returned.
+	deadline := time.Now().Add(5 * time.Second)
+	for !a.GoOnce("key", "third", func() {}) {
+		if time.Now().After(deadline) {
+			t.Fatal("GoOnce() = false after the task finished, want true")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if running := a.Shutdown(5 * time.Second); running != nil {
+		t.Errorf("Shutdown() = %v, want no running tasks", running)
+	}
+}
@@ -5,6 +5,7 @@ package backup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -16,7 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/logs"
 )
 
@@ -27,94 +28,102 @@ type Bucket struct {
 	KeyID     string
 	KeySecret string
 	Client    *s3.Client
+	Log       *logs.Logger
 }
 
 // UploadFile uploads a file to the bucket. The current date is appended to the file name.
-func (bucket Bucket) UploadFile(filePath string) {
+func (bucket Bucket) UploadFile(filePath string) error {
 	file, err := os.Open(filePath)
 	currentDate := time.Now().UTC().Format("2006-01-02")
 	fileName := strings.Split(filePath, "/")[len(strings.Split(filePath, "/"))-1]
 	objectKey := fmt.Sprintf("%s_%s", fileName, currentDate)
 
 	if err != nil {
-		logs.LogError("BCKUP", "could not open database file", "err", err)
-	} else {
-		defer file.Close()
-		_, err = bucket.Client.PutObject(context.TODO(), &s3.PutObjectInput{
-			Bucket: aws.String(bucket.Name),
-			Key:    aws.String(objectKey),
-			Body:   file,
-		})
-		if err != nil {
-			logs.LogError("BCKUP", "could not upload database file", "err", err)
-		} else {
-			logs.LogInfo("BCKUP", "database file uploaded successfully", false)
-		}
+		bucket.Log.LogError("BCKUP", "could not open database file", "err", err)
+		return err
+	}
+	defer file.Close()
+	_, err = bucket.Client.PutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket: aws.String(bucket.Name),
+		Key:    aws.String(objectKey),
+		Body:   file,
+	})
+	if err != nil {
+		bucket.Log.LogError("BCKUP", "could not upload database file", "err", err)
+		return err
 	}
+	bucket.Log.LogInfo("BCKUP", "database file uploaded successfully", false,
+		"key", objectKey)
+	return nil
 }
 
-// CleanUp removes backup files older than the number of days specified in config.toml.
-func (bucket Bucket) CleanUp() {
+// CleanUp removes backup files older than the given number of days.
+func (bucket Bucket) CleanUp(daysToKeep int) {
 	objects, err := bucket.Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucket.Name),
 	})
 	if err != nil {
-		logs.LogError("BCKUP", "could not list objects in bucket", "err", err)
+		bucket.Log.LogError("BCKUP", "could not list objects in bucket", "err", err)
 		return
 	}
 	for _, object := range objects.Contents {
-		if object.LastModified.Before(time.Now().AddDate(0, 0, -config.Values.Backup.DaysToKeep)) {
+		if object.LastModified.Before(time.Now().AddDate(0, 0, -daysToKeep)) {
 			_, err := bucket.Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
 				Bucket: aws.String(bucket.Name),
 				Key:    object.Key,
 			})
 			if err != nil {
-				logs.LogError("BCKUP", "could not delete object",
+				bucket.Log.LogError("BCKUP", "could not delete object",
 					"obj", *object.Key,
 					"err", err)
 			} else {
-				logs.LogInfo("BCKUP", "deleted object", false, "key", *object.Key)
+				bucket.Log.LogInfo("BCKUP", "deleted object", false, "key", *object.Key)
 			}
 		}
 	}
 }
 
 // BackupDB wraps the other functions in this package to create a backup of the database.
-func BackupDB() {
+// Errors are logged and returned.
+func BackupDB(a *app.App) error {
 	if runtime.GOOS == "windows" {
-		logs.LogInfo("BCKUP", "backup not supported on windows", false)
-		return
+		a.Log.LogInfo("BCKUP", "backup not supported on windows", false)
+		return errors.New("backup not supported on windows")
 	}
 
-	err := Encrypt()
+	err := Encrypt(a)
 	if err != nil {
-		logs.LogError("BCKUP", "backup failed: could not encrypt database", "err", err)
-		return
+		a.Log.LogError("BCKUP", "backup failed: could not encrypt database", "err", err)
+		return err
 	}
 	// Create a new endpoint resolver that resolves to the R2 endpoint.
 	r2Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 		return aws.Endpoint{
-			URL: fmt.Sprintf("https://%s.%s", config.Values.Cloudflare.AccountID,
-				config.Values.Cloudflare.Endpoint),
+			URL: fmt.Sprintf("https://%s.%s", a.Config().Cloudflare.AccountID,
+				a.Config().Cloudflare.Endpoint),
 		}, nil
 	})
 	// Load the default configuration with the R2 endpoint resolver and Cloudflare credentials.
 	cfg, err := awsconf.LoadDefaultConfig(context.TODO(),
 		awsconf.WithEndpointResolverWithOptions(r2Resolver),
-		awsconf.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(config.Values.Cloudflare.AccessKeyID, config.Values.Cloudflare.AccessKeySecret, "")),
+		awsconf.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(a.Config().Cloudflare.AccessKeyID, a.Config().Cloudflare.AccessKeySecret, "")),
 		awsconf.WithRegion("auto"),
 	)
 	if err != nil {
-		logs.LogError("BCKUP", "backup failed: could not load default config", "err", err)
-		return
+		a.Log.LogError("BCKUP", "backup failed: could not load default config", "err", err)
+		return err
 	}
 	bucket := Bucket{
-		Name:      config.Values.Cloudflare.BucketName,
-		AccountID: config.Values.Cloudflare.AccountID,
-		KeyID:     config.Values.Cloudflare.AccessKeyID,
-		KeySecret: config.Values.Cloudflare.AccessKeySecret,
+		Name:      a.Config().Cloudflare.BucketName,
+		AccountID: a.Config().Cloudflare.AccountID,
+		KeyID:     a.Config().Cloudflare.AccessKeyID,
+		KeySecret: a.Config().Cloudflare.AccessKeySecret,
 		Client:    s3.NewFromConfig(cfg),
+		Log:       a.Log,
+	}
+	if uploadErr := bucket.UploadFile(a.Config().Files.EncryptedDatabase); uploadErr != nil {
+		return uploadErr
 	}
-	bucket.UploadFile(config.Values.Files.EncryptedDatabase)
-	bucket.CleanUp()
+	bucket.CleanUp(a.Config().Backup.DaysToKeep)
+	return nil
 }
@@ -11,18 +11,17 @@ import (
 	"io"
 	"os"
 
-	"gamestreams/config"
-	"gamestreams/logs"
+	"gamestreams/app"
 )
 
 // Encrypt encrypts the database using AES256 encryption.
-func Encrypt() error {
-	logs.LogInfo("BCKUP", "encrypting database...", false)
-	dbFile, err := os.ReadFile(config.Values.Files.Database)
+func Encrypt(a *app.App) error {
+	a.Log.LogInfo("BCKUP", "encrypting database...", false)
+	dbFile, err := os.ReadFile(a.DB.Path)
 	if err != nil {
 		return err
 	}
-	key, err := os.ReadFile(config.Values.Files.EncryptionKey)
+	key, err := os.ReadFile(a.Config().Files.EncryptionKey)
 	if err != nil {
 		return err
 	}
@@ -41,22 +40,22 @@ func Encrypt() error {
 	}
 
 	dbEncrypted := gcm.Seal(nonce, nonce, dbFile, nil)
-	err = os.WriteFile(config.Values.Files.EncryptedDatabase, dbEncrypted, 0777)
+	err = os.WriteFile(a.Config().Files.EncryptedDatabase, dbEncrypted, 0777)
 	if err != nil {
 		return err
 	}
-	logs.LogInfo("BCKUP", "database encrypted", false)
+	a.Log.LogInfo("BCKUP", "database encrypted", false)
 	return nil
 }
 
 // Decrypt decrypts the database and writes it to the database file location.
-func Decrypt() error {
-	logs.LogInfo("RESTO", "decrypting database...", false)
-	dbEncrypted, err := os.ReadFile(config.Values.Files.EncryptedDatabase)
+func Decrypt(a *app.App) error {
+	a.Log.LogInfo("RESTO", "decrypting database...", false)
+	dbEncrypted, err := os.ReadFile(a.Config().Files.EncryptedDatabase)
 	if err != nil {
 		return err
 	}
-	key, err := os.ReadFile(config.Values.Files.EncryptionKey)
+	key, err := os.ReadFile(a.Config().Files.EncryptionKey)
 	if err != nil {
 		return err
 	}
@@ -76,10 +75,10 @@ func Decrypt() error {
 		return err
 	}
 
-	err = os.WriteFile(config.Values.Files.Database, dbFile, 0777)
+	err = os.WriteFile(a.DB.Path, dbFile, 0777)
 	if err != nil {
 		return err
 	}
-	logs.LogInfo("RESTO", "database decrypted", false)
+	a.Log.LogInfo("RESTO", "database decrypted", false)
 	return nil
 }
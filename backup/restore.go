@@ -1,11 +1,13 @@
 /*
-restore.go contains the functions to restore the database from the most recent backup
-in the bucket.
+restore.go contains the functions to restore the database from a backup in the bucket.
+By default the most recent backup is restored, or a backup can be chosen by its key or
+by the time it was made.
 */
 package backup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -15,97 +17,121 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
-	"gamestreams/config"
-	"gamestreams/logs"
+	"gamestreams/app"
 )
 
-// DownloadFile downloads the most recent file from the bucket.
-func (bucket Bucket) DownloadFile() {
-	fmt.Println("Downloading file...")
+// Selection chooses which backup is restored. If both fields are empty, the most recent
+// backup is restored.
+type Selection struct {
+	// The key of the backup to restore, e.g. game-streams.db.enc_2024-05-01.
+	Key string
+	// If set, the most recent backup made at or before this time is restored.
+	At time.Time
+}
 
-	// Get the list of objects in the bucket.
+// findObject returns the key of the backup in the bucket chosen by the selection.
+func (bucket Bucket) findObject(selection Selection) (string, error) {
+	if selection.Key != "" {
+		return selection.Key, nil
+	}
 	listObjectsOutput, err := bucket.Client.ListObjects(context.TODO(), &s3.ListObjectsInput{
 		Bucket: &bucket.Name,
 	})
 	if err != nil {
-		logs.LogError("RESTO", "could not list objects in bucket", "err", err)
-		return
+		return "", fmt.Errorf("could not list objects in bucket: %w", err)
 	}
 
-	// Find the most recent object.
 	var mostRecent time.Time
 	var mostRecentKey string
 	for _, object := range listObjectsOutput.Contents {
+		if !selection.At.IsZero() && object.LastModified.After(selection.At) {
+			continue
+		}
 		if object.LastModified.After(mostRecent) {
 			mostRecent = *object.LastModified
 			mostRecentKey = *object.Key
 		}
 	}
+	if mostRecentKey == "" {
+		return "", errors.New("no backup found")
+	}
+	return mostRecentKey, nil
+}
+
+// DownloadFile downloads the backup chosen by the selection from the bucket to the file
+// path.
+func (bucket Bucket) DownloadFile(filePath string, selection Selection) error {
+	key, findErr := bucket.findObject(selection)
+	if findErr != nil {
+		return findErr
+	}
+	bucket.Log.LogInfo("RESTO", "downloading backup...", false,
+		"key", key)
 
-	// Download the most recent object.
 	getObjectOutput, err := bucket.Client.GetObject(context.TODO(), &s3.GetObjectInput{
 		Bucket: &bucket.Name,
-		Key:    &mostRecentKey,
+		Key:    &key,
 	})
 	if err != nil {
-		logs.LogError("RESTO", "could not download object from bucket", "err", err)
-		return
+		return fmt.Errorf("could not download object from bucket: %w", err)
 	}
+	defer getObjectOutput.Body.Close()
 
-	// Write the object to file.
-	file, err := os.Create(config.Values.Files.EncryptedDatabase)
+	file, err := os.Create(filePath)
 	if err != nil {
-		logs.LogError("RESTO", "could not create file", "err", err)
-		return
+		return fmt.Errorf("could not create file: %w", err)
 	}
 	defer file.Close()
 
-	_, err = file.ReadFrom(getObjectOutput.Body)
-	if err != nil {
-		logs.LogError("RESTO", "could not write to file", "err", err)
-		return
+	if _, err = file.ReadFrom(getObjectOutput.Body); err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
 	}
-	logs.LogInfo("RESTO", "file downloaded successfully", false)
-
+	bucket.Log.LogInfo("RESTO", "file downloaded successfully", false)
+	return nil
 }
 
-// RestoreDB wraps the download and decrypt functions to restore the database.
-func RestoreDB() {
+// RestoreDB wraps the download and decrypt functions to restore the database from the
+// backup chosen by the selection.
+func RestoreDB(a *app.App, selection Selection) error {
 	r2Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
 		return aws.Endpoint{
-			URL: fmt.Sprintf("https://%s.eu.r2.cloudflarestorage.com", config.Values.Cloudflare.AccountID),
+			URL: fmt.Sprintf("https://%s.eu.r2.cloudflarestorage.com", a.Config().Cloudflare.AccountID),
 		}, nil
 	})
 
 	// Load the default config with the custom resolver and credentials.
 	cfg, err := awsconf.LoadDefaultConfig(context.TODO(),
 		awsconf.WithEndpointResolverWithOptions(r2Resolver),
-		awsconf.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(config.Values.Cloudflare.AccessKeyID, config.Values.Cloudflare.AccessKeySecret, "")),
+		awsconf.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(a.Config().Cloudflare.AccessKeyID, a.Config().Cloudflare.AccessKeySecret, "")),
 		awsconf.WithRegion("auto"),
 	)
 	if err != nil {
-		logs.LogError("RESTO", "restore failed: could not load default config", "err", err)
-		return
+		a.Log.LogError("RESTO", "restore failed: could not load default config", "err", err)
+		return err
 	}
 
 	bucket := Bucket{
-		Name:      config.Values.Cloudflare.BucketName,
-		AccountID: config.Values.Cloudflare.AccountID,
-		KeyID:     config.Values.Cloudflare.AccessKeyID,
-		KeySecret: config.Values.Cloudflare.AccessKeySecret,
+		Name:      a.Config().Cloudflare.BucketName,
+		AccountID: a.Config().Cloudflare.AccountID,
+		KeyID:     a.Config().Cloudflare.AccessKeyID,
+		KeySecret: a.Config().Cloudflare.AccessKeySecret,
 		Client:    s3.NewFromConfig(cfg),
+		Log:       a.Log,
 	}
 
-	bucket.Client = s3.NewFromConfig(cfg)
-	bucket.DownloadFile()
-	decryptErr := Decrypt()
+	if downloadErr := bucket.DownloadFile(a.Config().Files.EncryptedDatabase, selection); downloadErr != nil {
+		a.Log.LogError("RESTO", "restore failed: could not download backup", "err", downloadErr)
+		return downloadErr
+	}
+	decryptErr := Decrypt(a)
 	if decryptErr != nil {
-		logs.LogError("RESTO", "restore failed: could not decrypt database", "err", decryptErr)
-		return
+		a.Log.LogError("RESTO", "restore failed: could not decrypt database", "err", decryptErr)
+		return decryptErr
 	}
-	err = os.Remove(config.Values.Files.EncryptedDatabase)
+	err = os.Remove(a.Config().Files.EncryptedDatabase)
 	if err != nil {
-		logs.LogError("RESTO", "could not delete encrypted database file", "err", err)
+		a.Log.LogError("RESTO", "could not delete encrypted database file", "err", err)
 	}
-	logs.LogInfo("RESTO", "database restored", false)
+	a.Log.LogInfo("RESTO", "database restored", false)
+	return nil
 }
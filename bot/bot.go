@@ -1,69 +1,165 @@
 /*
 bot.go contains the main function that runs the bot.
-It is the first file called by main.go when the bot is started.
+It is the first file called by the run subcommand of the cli package.
 */
 package bot
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
+	"gamestreams/app"
 	"gamestreams/backup"
 	"gamestreams/commands"
-	"gamestreams/config"
-	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/feeds"
+	"gamestreams/jobs"
+	"gamestreams/messenger"
 	"gamestreams/servers"
-	"gamestreams/utils"
 )
 
 // Run is the main function that runs the bot. It creates a new Discord session,
-// registers the commands, and registers the scheduled functions.
+// registers the commands, the messengers and the scheduled functions. If the Discord
+// token is not set but a Telegram token is, the bot runs without Discord and only
+// announces streams with Telegram and webhooks.
 // If the restore flag is set, it restores the database from the most recent backup
-// then exits. The bot runs until it receives a termination signal (ctrl + c).
-func Run(botToken, appID string) {
-	if config.Values.Bot.RestoreDatabase {
-		backup.BackupDB()
-		logs.LogInfo(" MAIN", "RESTORE FLAG SET: RESTORING DATABASE", false)
-		backup.RestoreDB()
-		os.Exit(0)
+// then exits. config.toml is reloaded on SIGHUP or when it is modified. The bot runs
+// until it receives SIGINT (ctrl + c) or SIGTERM, then shuts down gracefully. It returns
+// an error if the bot could not be started, so the process exits with an error and can
+// be restarted.
+func Run(a *app.App) error {
+	if a.Config().Bot.RestoreDatabase {
+		backup.BackupDB(a)
+		a.Log.LogInfo(" MAIN", "RESTORE FLAG SET: RESTORING DATABASE", false)
+		if restoreErr := backup.RestoreDB(a, backup.Selection{}); restoreErr != nil {
+			return fmt.Errorf("restoring database: %w", restoreErr)
+		}
+		return nil
 	}
 
-	session, sessionErr := discordgo.New("Bot " + botToken)
-	if sessionErr != nil {
-		logs.LogError(" MAIN", "error creating Discord session",
-			"err", sessionErr)
-		return
+	// Signals received while the bot is starting are handled once it has started.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	if a.Config().Discord.Token != "" {
+		session, sessionErr := discordgo.New("Bot " + a.Config().Discord.Token)
+		if sessionErr != nil {
+			return fmt.Errorf("creating Discord session: %w", sessionErr)
+		}
+		if openErr := session.Open(); openErr != nil {
+			return fmt.Errorf("connecting to Discord: %w", openErr)
+		}
+		a.Session = session
+	} else if a.Config().Telegram.Token == "" {
+		return errors.New("no Discord or Telegram token set")
+	}
+
+	registerJobs(a)
+	if scheduleErr := ScheduleFunctions(a); scheduleErr != nil {
+		if a.Session != nil {
+			a.Session.Close()
+		}
+		return fmt.Errorf("scheduling jobs: %w", scheduleErr)
+	}
+
+	registerMessengers(a)
+	if a.Session != nil {
+		//commands.RemoveAllCommands(a)
+		commands.RegisterCommands(a)
+		commands.RegisterHandler(a)
+		commands.RegisterOwnerCommands(a)
+	}
+
+	// Run some of the jobs immediately, then any others that missed a scheduled run
+	// while the bot was not running.
+	for _, name := range []string{"stream_update", "maintenance", "stream_notifications",
+		"timeless_streams"} {
+		a.Jobs.Run(name, jobs.TriggerStartup)
 	}
-	if openErr := session.Open(); openErr != nil {
-		logs.LogError(" MAIN", "error connecting to Discord",
-			"err", openErr)
-		return
+	a.Go("job catch-up", a.Jobs.CatchUp)
+
+	if a.Session != nil {
+		servers.MonitorGuilds(a)
+	}
+	feedServer := feeds.Serve(a)
+	a.Go("config watcher", func() {
+		watchConfig(a)
+	})
+	a.StartTime = time.Now().UTC()
+	a.Log.LogInfo(" MAIN", "bot started", true,
+		"messengers", messengerNames(a))
+	received := <-stop
+	a.Log.LogInfo(" MAIN", "shutting down...", false,
+		"signal", received.String())
+	shutdown(a, feedServer)
+	return nil
+}
+
+// shutdown stops the bot. It disconnects from the Discord gateway so no more commands
+// are received, stops the scheduler and cancels pending announcements and live checks,
+// then waits for running jobs and announcements to finish for up to the shutdown
+// timeout set in config.toml. It then stops serving feeds, closes the database and
+// logs any tasks that were interrupted.
+func shutdown(a *app.App, feedServer *http.Server) {
+	if a.Session != nil {
+		if closeErr := a.Session.Close(); closeErr != nil {
+			a.Log.LogError(" MAIN", "error closing Discord session",
+				"err", closeErr)
+		}
+	}
+
+	timeout := time.Duration(a.Config().Bot.ShutdownTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	a.Jobs.Stop()
+	interrupted := a.Shutdown(timeout)
+
+	if feedServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if closeErr := feedServer.Shutdown(ctx); closeErr != nil {
+			a.Log.LogError(" MAIN", "error stopping feeds server",
+				"err", closeErr)
+		}
+	}
+	if closeErr := a.DB.Close(); closeErr != nil {
+		a.Log.LogError(" MAIN", "error closing database",
+			"err", closeErr)
+	}
+
+	if len(interrupted) > 0 {
+		a.Log.LogInfo(" MAIN", "shutdown timed out, interrupted tasks", false,
+			"timeout", timeout.String(),
+			"tasks", interrupted)
+	}
+	a.Log.LogInfo(" MAIN", "bot stopped", false)
+}
+
+// registerMessengers adds the messengers used to send announcements and DMs to the App.
+// Discord is the default messenger if the bot is connected to it, and Telegram is added
+// if its token is set in config.toml. The owner is sent DMs with the default messenger.
+func registerMessengers(a *app.App) {
+	if a.Session != nil {
+		a.AddMessenger(messenger.NewDiscord(a.Session, a.Config))
+	}
+	if a.Config().Telegram.Token != "" {
+		a.AddMessenger(messenger.NewTelegram(a.Config().Telegram.Token, a.HTTP, a.Config))
+	}
+}
+
+// messengerNames returns the names of the messengers of the App.
+func messengerNames(a *app.App) []string {
+	var names []string
+	for _, m := range a.Messengers {
+		names = append(names, m.Name())
 	}
-	defer session.Close()
-
-	ScheduleFunctions(session)
-
-	logs.RegisterSession(session)
-	discord.RegisterSession(session)
-	//commands.RemoveAllCommands(appID, session)
-	commands.RegisterCommands(appID, session)
-	commands.RegisterHandler(session, &discordgo.InteractionCreate{})
-	commands.RegisterOwnerCommands(session)
-
-	// Run some of the scheduled functions immediately
-	streamUpdater()
-	performMaintenance(session)
-	streamNotifications(session)
-	checkTimelessStreams()
-
-	servers.MonitorGuilds(session)
-	utils.StartTime = time.Now().UTC()
-	logs.LogInfo(" MAIN", "bot started", true)
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	<-c
+	return names
 }
@@ -0,0 +1,122 @@
+/*
+reload.go contains functions to reload config.toml while the bot is running. The config
+is reloaded when the bot receives SIGHUP or when the file is modified.
+*/
+package bot
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gamestreams/app"
+	"gamestreams/config"
+)
+
+// configPollInterval is how often config.toml is checked for modifications.
+const configPollInterval = 10 * time.Second
+
+// restartKeys are the keys, or prefixes of keys, of values that are only read when the
+// bot starts. Changes to them are reported but do not take effect until a restart.
+var restartKeys = []string{
+	"discord.token",
+	"discord.application_id",
+	"telegram.token",
+	"files.",
+	"feeds.address",
+	"logs.",
+}
+
+// watchConfig reloads config.toml each time the bot receives SIGHUP or the file is
+// modified, and reschedules the jobs with the new schedules. When the bot starts
+// shutting down, it stops the scheduler so no more jobs are started, and returns.
+func watchConfig(a *app.App) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	modified := modTime(a.Config().Files.Config)
+	for {
+		select {
+		case <-a.Context().Done():
+			signal.Stop(hangup)
+			a.Jobs.Stop()
+			return
+		case <-hangup:
+			a.Log.LogInfo("CONFG", "received SIGHUP, reloading config...", false)
+		case <-ticker.C:
+			current := modTime(a.Config().Files.Config)
+			if current.Equal(modified) {
+				continue
+			}
+			modified = current
+			a.Log.LogInfo("CONFG", "config file modified, reloading config...", false)
+		}
+		reloadConfig(a)
+	}
+}
+
+// reloadConfig loads and validates config.toml. If it is valid, the configuration is
+// swapped, the jobs are rescheduled with it, and the owner is sent the values that
+// changed. Jobs already running finish with the values they read. If it is invalid, the
+// current configuration is kept and the owner is sent the problems.
+func reloadConfig(a *app.App) {
+	old := a.Config()
+	next := &config.Config{}
+	if loadErr := next.Load(old.Files.Config); loadErr != nil {
+		a.Log.LogError("CONFG", "config not reloaded",
+			"err", loadErr)
+		return
+	}
+	changes := config.Diff(old, next)
+	if len(changes) == 0 {
+		a.Log.LogInfo("CONFG", "config reloaded with no changes", false)
+		return
+	}
+
+	a.SetConfig(next)
+	if scheduleErr := ScheduleFunctions(a); scheduleErr != nil {
+		a.Log.LogError("CONFG", "error rescheduling jobs",
+			"err", scheduleErr)
+	}
+
+	var changed, restart []string
+	for _, change := range changes {
+		if needsRestart(change.Key) {
+			restart = append(restart, change.Key)
+		}
+		changed = append(changed, change.String())
+	}
+	if len(restart) > 0 {
+		a.Log.LogInfo("CONFG", "config reloaded", true,
+			"changes", changed,
+			"restart", restart)
+	} else {
+		a.Log.LogInfo("CONFG", "config reloaded", true,
+			"changes", changed)
+	}
+}
+
+// needsRestart reports whether a change to the value with the key only takes effect
+// after a restart.
+func needsRestart(key string) bool {
+	for _, k := range restartKeys {
+		if key == k || (strings.HasSuffix(k, ".") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// modTime returns the time the file was last modified, or the zero time if it cannot
+// be read.
+func modTime(path string) time.Time {
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
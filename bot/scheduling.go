@@ -4,44 +4,55 @@ scheduling.go contains logic to schedule functions to run at specified intervals
 package bot
 
 import (
-	"time"
-
-	"github.com/bwmarrin/discordgo"
-	"github.com/robfig/cron/v3"
-
+	"gamestreams/app"
 	"gamestreams/config"
 )
 
-// ScheduleFunctions schedules the functions that need to be run on a schedule.
-// It uses the cron package to schedule the functions at the intervals specified
-// in the config.toml file.
-func ScheduleFunctions(session *discordgo.Session) {
-	c := cron.New(cron.WithLocation(time.UTC))
-
-	if config.Values.Schedule.StreamUpdate.Enabled {
-		c.AddFunc(config.Values.Schedule.StreamUpdate.Cron, func() {
-			streamUpdater()
-		})
+// registerJobs adds the functions that are run on a schedule to the jobs of the App,
+// named after their schedule in config.toml. If the App has no Discord session, as the
+// bot is not connected to Discord, the functions that only apply to Discord servers are
+// not registered.
+func registerJobs(a *app.App) {
+	a.Jobs.Register("stream_update", "import streams.toml and update announcements",
+		func() error { return streamUpdater(a) })
+	a.Jobs.Register("stream_notifications", "schedule today's stream announcements",
+		func() error { return streamNotifications(a) })
+	if a.Session != nil {
+		a.Jobs.Register("announcement_retry", "retry failed announcements",
+			func() error { return retryAnnouncements(a) })
 	}
-	if config.Values.Schedule.StreamNotifications.Enabled {
-		c.AddFunc(config.Values.Schedule.StreamNotifications.Cron, func() {
-			streamNotifications(session)
-		})
+	a.Jobs.Register("timeless_streams", "report upcoming streams with no time",
+		func() error { return checkTimelessStreams(a) })
+	a.Jobs.Register("maintenance", "clean up logs, servers, streams and suggestions",
+		func() error { return performMaintenance(a) })
+	a.Jobs.Register("backup", "back up the database",
+		func() error { return backupDatabase(a) })
+	if a.Session != nil {
+		a.Jobs.Register("channel_health", "check the announce channels of servers",
+			func() error { return checkChannelHealth(a) })
 	}
-	if config.Values.Schedule.CheckTimelessStreams.Enabled {
-		c.AddFunc(config.Values.Schedule.CheckTimelessStreams.Cron, func() {
-			checkTimelessStreams()
-		})
-	}
-	if config.Values.Schedule.Maintenance.Enabled {
-		c.AddFunc(config.Values.Schedule.Maintenance.Cron, func() {
-			performMaintenance(session)
-		})
+}
+
+// ScheduleFunctions schedules the jobs of the App with the cron strings of the
+// schedules enabled in its configuration, replacing any earlier schedule. It is called
+// again when config.toml is reloaded. Jobs whose schedule is disabled can still be run
+// by the owner.
+func ScheduleFunctions(a *app.App) error {
+	schedule := a.Config().Schedule
+	schedules := map[string]config.Schedule{
+		"stream_update":        schedule.StreamUpdate,
+		"stream_notifications": schedule.StreamNotifications,
+		"announcement_retry":   schedule.AnnouncementRetry,
+		"timeless_streams":     schedule.CheckTimelessStreams,
+		"maintenance":          schedule.Maintenance,
+		"backup":               schedule.Backup,
+		"channel_health":       schedule.ChannelHealth,
 	}
-	if config.Values.Schedule.Backup.Enabled {
-		c.AddFunc(config.Values.Schedule.Backup.Cron, func() {
-			backupDatabase()
-		})
+	specs := make(map[string]string)
+	for name, s := range schedules {
+		if s.Enabled {
+			specs[name] = s.Cron
+		}
 	}
-	c.Start()
+	return a.Jobs.Schedule(specs)
 }
@@ -1,77 +1,169 @@
 /*
 threads.go contains functions that are run on a schedule in their own threads.
 These functions perform maintenance tasks, update streams, schedule notifications,
-and backup the database.
+and backup the database. Each is run as a job of the App, and returns the errors it
+logged so the run is recorded as failed.
 */
 package bot
 
 import (
-	"github.com/bwmarrin/discordgo"
+	"errors"
 
+	"gamestreams/app"
 	"gamestreams/backup"
 	"gamestreams/db"
-	"gamestreams/logs"
+	"gamestreams/feeds"
 	"gamestreams/servers"
 	"gamestreams/streams"
 )
 
-// streamUpdater updates the streams in the database from a web-hosted toml file.
-func streamUpdater() {
+// streamUpdater updates the streams in the database from a web-hosted toml file,
+// creates upcoming streams from recurring templates and writes the feeds of streams if
+// streams.toml was imported. It then tells servers about announced streams that have
+// been cancelled or postponed, edits announcements of streams whose URL has changed,
+// and syncs the Discord scheduled events of servers that have enabled them. Servers are
+// only updated if the App has a Discord session and the bot is not shutting down.
+func streamUpdater(a *app.App) error {
 	var s db.Streams
-	logs.LogInfo("UPDAT", "checking for stream updates...", false)
+	var errs []error
+	a.Log.LogInfo("UPDAT", "checking for stream updates...", false)
 
-	if updateErr := s.Update(); updateErr != nil {
-		logs.LogError("UPDAT", "error updating streams",
+	var before, after db.StreamTOML
+	if getErr := before.Get(a.DB); getErr != nil {
+		a.Log.LogError("UPDAT", "error getting streams.toml update time",
+			"err", getErr)
+		errs = append(errs, getErr)
+	}
+	if updateErr := s.Update(a.DB); updateErr != nil {
+		a.Log.LogError("UPDAT", "error updating streams",
 			"err", updateErr)
+		errs = append(errs, updateErr)
+	}
+	if expandErr := a.DB.ExpandTemplates(); expandErr != nil {
+		a.Log.LogError("UPDAT", "error expanding stream templates",
+			"err", expandErr)
+		errs = append(errs, expandErr)
+	}
+	if getErr := after.Get(a.DB); getErr == nil && after.LastUpdate != before.LastUpdate {
+		if writeErr := feeds.WriteFiles(a); writeErr != nil {
+			a.Log.LogError("UPDAT", "error writing feeds",
+				"err", writeErr)
+			errs = append(errs, writeErr)
+		}
+	}
+	if a.Session == nil || a.ShuttingDown() {
+		return errors.Join(errs...)
 	}
+	streams.SendStatusFollowups(a)
+	streams.SyncAnnouncements(a)
+	streams.SyncScheduledEvents(a)
+	return errors.Join(errs...)
 }
 
 // streamNotifications schedules stream notifications for the day. The day is the
 // 24-hour period between cron jobs.
-func streamNotifications(session *discordgo.Session) {
-	logs.LogInfo("NOTIF", "scheduling stream notifications...", false)
+func streamNotifications(a *app.App) error {
+	a.Log.LogInfo("NOTIF", "scheduling stream notifications...", false)
 
-	if scheduleErr := streams.ScheduleNotifications(session); scheduleErr != nil {
-		logs.LogError("NOTIF", "error scheduling today's streams",
+	if scheduleErr := streams.ScheduleNotifications(a); scheduleErr != nil {
+		a.Log.LogError("NOTIF", "error scheduling today's streams",
 			"err", scheduleErr)
+		return scheduleErr
 	}
+	return nil
+}
+
+// retryAnnouncements reposts announcements that failed to post and crossposts those
+// that failed to publish.
+func retryAnnouncements(a *app.App) error {
+	a.Log.LogInfo("NOTIF", "retrying failed announcements...", false)
+	streams.RetryFailedAnnouncements(a)
+	streams.RetryFailedPublishes(a)
+	return nil
 }
 
 // checkTimelessStreams checks for streams that have no time set and logs them.
 // a DM is also sent to the owner as a reminder to set times for the streams.
-func checkTimelessStreams() {
+func checkTimelessStreams(a *app.App) error {
 	var s db.Streams
-	if tomorrowErr := s.CheckTimeless(); tomorrowErr != nil {
-		logs.LogError("TMRW ", "error checking timeless streams",
+	if tomorrowErr := s.CheckTimeless(a.DB); tomorrowErr != nil {
+		a.Log.LogError("TMRW ", "error checking timeless streams",
 			"err", tomorrowErr)
+		return tomorrowErr
 	}
 	if len(s.Streams) > 0 {
 		cleanStreams := make(map[int]string)
 		for _, stream := range s.Streams {
 			cleanStreams[stream.ID] = stream.Name
 		}
-		logs.LogInfo("TMRW ", "upcoming streams with no time", true,
+		a.Log.LogInfo("TMRW ", "upcoming streams with no time", true,
 			"streams", cleanStreams)
 	}
+	return nil
 }
 
 // performMaintenance performs database maintenance, clean up of logs
-// blacklisted items and suggestions.
-func performMaintenance(session *discordgo.Session) {
-	logs.LogInfo("MNTNC", "truncating logs...", false)
-	logs.TruncateLogs()
-	logs.LogInfo("MNTNC", "performing server maintenance...", false)
-	servers.ServerMaintenance(session)
-	logs.LogInfo("MNTNC", "performing stream maintenance...", false)
-	streams.StreamMaintenance()
-	logs.LogInfo("MNTNC", "performing suggestion maintenance...", false)
-	db.ArchiveSuggestions()
-	db.RemoveOldSuggestions()
-	db.PerformCommandMaintenance()
+// blacklisted items and suggestions. Servers are only maintained if the App has a
+// Discord session. The history of job runs older than the logs is removed. If the bot
+// starts shutting down, the remaining steps are skipped.
+func performMaintenance(a *app.App) error {
+	var errs []error
+	a.Log.LogInfo("MNTNC", "truncating logs...", false)
+	a.Log.TruncateLogs(a.Config().Logs.DaysToKeep)
+	if a.ShuttingDown() {
+		return nil
+	}
+	if a.Session != nil {
+		a.Log.LogInfo("MNTNC", "performing server maintenance...", false)
+		servers.ServerMaintenance(a)
+		servers.RemindUnconfigured(a)
+	}
+	if a.ShuttingDown() {
+		return nil
+	}
+	a.Log.LogInfo("MNTNC", "performing stream maintenance...", false)
+	streams.StreamMaintenance(a)
+	a.Log.LogInfo("MNTNC", "performing suggestion maintenance...", false)
+	if archiveErr := a.DB.ArchiveSuggestions(); archiveErr != nil {
+		a.Log.LogError("MNTNC", "error archiving suggestions",
+			"err", archiveErr)
+		errs = append(errs, archiveErr)
+	}
+	if removeErr := a.DB.RemoveOldSuggestions(); removeErr != nil {
+		a.Log.LogError("MNTNC", "error removing old suggestions",
+			"err", removeErr)
+		errs = append(errs, removeErr)
+	}
+	if commandErr := a.DB.PerformCommandMaintenance(); commandErr != nil {
+		a.Log.LogError("MNTNC", "error performing command maintenance",
+			"err", commandErr)
+		errs = append(errs, commandErr)
+	}
+	a.Log.LogInfo("MNTNC", "performing follow maintenance...", false)
+	if followErr := a.DB.PerformFollowMaintenance(); followErr != nil {
+		a.Log.LogError("MNTNC", "error performing follow maintenance",
+			"err", followErr)
+		errs = append(errs, followErr)
+	}
+	a.Log.LogInfo("MNTNC", "removing old job runs...", false)
+	if removeErr := a.DB.RemoveOldJobRuns(a.Config().Logs.DaysToKeep); removeErr != nil {
+		a.Log.LogError("MNTNC", "error removing old job runs",
+			"err", removeErr)
+		errs = append(errs, removeErr)
+	}
+	return errors.Join(errs...)
+}
+
+// checkChannelHealth checks that the bot can still post in the announce channel of each
+// server and DMs the server owner if it cannot.
+func checkChannelHealth(a *app.App) error {
+	a.Log.LogInfo("HLTH ", "checking announce channels...", false)
+	servers.CheckAnnounceChannels(a)
+	return nil
 }
 
 // backupDatabase backs up the database to a cloudflare R2 storage bucket.
-func backupDatabase() {
-	logs.LogInfo("BCKUP", "backing up database...", false)
-	backup.BackupDB()
+func backupDatabase(a *app.App) error {
+	a.Log.LogInfo("BCKUP", "backing up database...", false)
+	return backup.BackupDB(a)
 }
@@ -0,0 +1,92 @@
+/*
+backup.go contains the subcommands that back up and restore the database.
+*/
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gamestreams/backup"
+	"gamestreams/db"
+)
+
+// backupDB encrypts the database and uploads it to the backup bucket.
+func backupDB(args []string) int {
+	fs, configPath := newFlagSet("backup")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("backup", appErr)
+	}
+	if backupErr := backup.BackupDB(a); backupErr != nil {
+		return fail("backup", backupErr)
+	}
+	fmt.Println("database backed up")
+	return exitOK
+}
+
+// restoreDB replaces the database with a backup from the bucket: the most recent one,
+// the one with the key given with --key, or the most recent one made at or before the
+// time given with --at. Unless --skip-backup is given, the current database is backed
+// up first.
+func restoreDB(args []string) int {
+	fs, configPath := newFlagSet("restore")
+	key := fs.String("key", "", "key of the backup to restore")
+	at := fs.String("at", "", "restore the most recent backup made at or before this "+
+		"date (YYYY-MM-DD) or time (RFC 3339)")
+	skipBackup := fs.Bool("skip-backup", false, "do not back up the current database first")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+	if *key != "" && *at != "" {
+		fmt.Fprintln(os.Stderr, "restore: --key and --at cannot be used together")
+		return exitUsage
+	}
+
+	selection := backup.Selection{Key: *key}
+	if *at != "" {
+		t, timeErr := parseTime(*at)
+		if timeErr != nil {
+			fmt.Fprintf(os.Stderr, "restore: %s\n", timeErr)
+			return exitUsage
+		}
+		selection.At = t
+	}
+
+	a, loadErr := loadConfig(*configPath)
+	if loadErr != nil {
+		return fail("restore", loadErr)
+	}
+	// The database is not opened, as it may be missing or about to be replaced.
+	a.SetDB(db.New(a.Config().Files.Database, a.Config, a.Log, a.HTTP))
+
+	if _, statErr := os.Stat(a.DB.Path); statErr == nil && !*skipBackup {
+		if backupErr := backup.BackupDB(a); backupErr != nil {
+			return fail("restore", fmt.Errorf("could not back up the current database "+
+				"(use --skip-backup to restore anyway): %w", backupErr))
+		}
+	}
+	if restoreErr := backup.RestoreDB(a, selection); restoreErr != nil {
+		return fail("restore", restoreErr)
+	}
+	fmt.Printf("database %s restored\n", a.DB.Path)
+	return exitOK
+}
+
+// parseTime parses a date in the format YYYY-MM-DD, as the end of that day in local
+// time, or a time in RFC 3339 format.
+func parseTime(value string) (time.Time, error) {
+	if t, parseErr := time.ParseInLocation(time.DateOnly, value, time.Local); parseErr == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	if t, parseErr := time.Parse(time.RFC3339, value); parseErr == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("--at must be a date (YYYY-MM-DD) or an RFC 3339 time")
+}
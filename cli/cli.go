@@ -0,0 +1,154 @@
+/*
+cli.go contains the command-line interface of the bot. The first argument names the
+subcommand to run, so operators can script maintenance tasks without editing
+config.toml. If no subcommand is given, the bot is run.
+*/
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gamestreams/app"
+	"gamestreams/config"
+	"gamestreams/logs"
+)
+
+// The exit codes returned by Run.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// command is a subcommand of the command-line interface.
+type command struct {
+	// The name of the subcommand, e.g. import-streams.
+	name string
+	// The arguments of the subcommand, shown in the usage.
+	args string
+	// A description of the subcommand, shown in the usage.
+	description string
+	// The function that runs the subcommand with the arguments after its name. It
+	// returns the exit code.
+	run func(args []string) int
+}
+
+// commands returns the subcommands of the command-line interface in the order they are
+// shown in the usage.
+func commands() []command {
+	return []command{
+		{"run", "", "run the bot (the default)", runBot},
+		{"migrate", "", "create the database or add any missing tables and columns", migrate},
+		{"backup", "", "encrypt the database and upload it to the backup bucket", backupDB},
+		{"restore", "[--key <key> | --at <date>] [--skip-backup]",
+			"restore the database from the most recent backup, or the one chosen", restoreDB},
+		{"import-streams", "<file> [--dry-run]",
+			"import streams and templates from a streams.toml file", importStreams},
+		{"export-streams", "[--upcoming] [--out <file>]",
+			"export streams and templates in the format of streams.toml", exportStreams},
+		{"validate-config", "", "check config.toml and list any problems", validateConfig},
+		{"db", "stats", "show the number of rows in each table of the database", dbCommand},
+	}
+}
+
+// Run runs the subcommand named by the first argument with the rest of the arguments,
+// and returns the exit code of the program. If the first argument is missing or is a
+// flag, the bot is run.
+func Run(args []string) int {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		usage(os.Stdout)
+		return exitOK
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runBot(args)
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return exitUsage
+}
+
+// usage writes the subcommands and their arguments to w.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: game-streams [command] [--config <file>] [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, c := range commands() {
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("  %-16s %s", c.name, c.args), " "))
+		fmt.Fprintf(w, "  %-16s %s\n", "", c.description)
+	}
+	fmt.Fprintf(w, "\nconfig.toml is read from %s unless --config is given.\n", config.DefaultPath())
+}
+
+// newFlagSet returns a flag set for the subcommand with the --config flag, and the
+// value of that flag.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String("config", config.DefaultPath(), "path to config.toml")
+	return fs, configPath
+}
+
+// parseArgs parses the flags of the flag set, which may come before or after the
+// positional arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if parseErr := fs.Parse(args); parseErr != nil {
+			return nil, parseErr
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// loadConfig loads and validates the config file at the path and returns an App with
+// it and the loggers it sets. The database of the App is not set.
+func loadConfig(path string) (*app.App, error) {
+	cfg := &config.Config{}
+	if loadErr := cfg.Load(path); loadErr != nil {
+		return nil, loadErr
+	}
+	return app.New(cfg, logs.New(cfg.Logs)), nil
+}
+
+// newApp loads the config file at the path, opens the database, creating any missing
+// tables, and returns an App holding them. The App has no Discord session or
+// messengers.
+func newApp(path string) (*app.App, error) {
+	a, loadErr := loadConfig(path)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	if openErr := a.OpenDB(); openErr != nil {
+		return nil, fmt.Errorf("could not open database: %w", openErr)
+	}
+	return a, nil
+}
+
+// usageError returns the exit code for an error parsing the arguments of a subcommand,
+// which the flag set has already written to stderr. Asking for help is not an error.
+func usageError(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// fail writes the error to stderr prefixed with the name of the subcommand, and
+// returns the exit code for it.
+func fail(name string, err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+	return exitError
+}
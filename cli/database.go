@@ -0,0 +1,65 @@
+/*
+database.go contains the subcommands that create the database and report its size.
+*/
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// migrate creates the database, or adds any tables and columns it is missing.
+func migrate(args []string) int {
+	fs, configPath := newFlagSet("migrate")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("migrate", appErr)
+	}
+	fmt.Printf("database %s is up to date\n", a.DB.Path)
+	return exitOK
+}
+
+// dbCommand runs the db subcommand named by the first argument. The only one is stats,
+// which prints the number of rows in each table and the size of the database file.
+func dbCommand(args []string) int {
+	fs, configPath := newFlagSet("db")
+	positional, parseErr := parseArgs(fs, args)
+	if parseErr != nil {
+		return usageError(parseErr)
+	}
+	if len(positional) != 1 || positional[0] != "stats" {
+		fmt.Fprintln(os.Stderr, "usage: game-streams db stats [--config <file>]")
+		return exitUsage
+	}
+
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("db stats", appErr)
+	}
+	tables, statsErr := a.DB.Stats()
+	if statsErr != nil {
+		return fail("db stats", statsErr)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "table\trows\t")
+	total := 0
+	for _, t := range tables {
+		fmt.Fprintf(w, "%s\t%d\t\n", t.Name, t.Rows)
+		total += t.Rows
+	}
+	fmt.Fprintf(w, "total\t%d\t\n", total)
+	if flushErr := w.Flush(); flushErr != nil {
+		return fail("db stats", flushErr)
+	}
+
+	if info, statErr := os.Stat(a.DB.Path); statErr == nil {
+		fmt.Printf("\n%s: %.1f KiB\n", a.DB.Path, float64(info.Size())/1024)
+	}
+	return exitOK
+}
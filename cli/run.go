@@ -0,0 +1,53 @@
+/*
+run.go contains the subcommands that run the bot and check its configuration.
+*/
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gamestreams/bot"
+	"gamestreams/config"
+)
+
+// runBot loads the configuration, opens the database and runs the bot until it
+// receives a termination signal.
+func runBot(args []string) int {
+	fs, configPath := newFlagSet("run")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("run", appErr)
+	}
+	a.Log.Info.WithPrefix(" MAIN").Info("starting bot")
+	if runErr := bot.Run(a); runErr != nil {
+		return fail("run", runErr)
+	}
+	return exitOK
+}
+
+// validateConfig loads config.toml and lists every problem with it. It returns an
+// error exit code if there are any.
+func validateConfig(args []string) int {
+	fs, configPath := newFlagSet("validate-config")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+
+	cfg := &config.Config{}
+	if loadErr := cfg.Load(*configPath); loadErr != nil {
+		var validationErr *config.ValidationError
+		if errors.As(loadErr, &validationErr) {
+			fmt.Fprintln(os.Stderr, validationErr)
+			return exitError
+		}
+		return fail("validate-config", loadErr)
+	}
+	fmt.Printf("%s is valid\n", *configPath)
+	return exitOK
+}
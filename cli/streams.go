@@ -0,0 +1,114 @@
+/*
+streams.go contains the subcommands that import and export streams in the format of
+streams.toml.
+*/
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gamestreams/db"
+)
+
+// importStreams imports the streams and templates of a file in the format of
+// streams.toml into the database, then expands the templates. With --dry-run, it
+// prints what would change without writing to the database.
+func importStreams(args []string) int {
+	fs, configPath := newFlagSet("import-streams")
+	dryRun := fs.Bool("dry-run", false, "show what would change without writing to the database")
+	positional, parseErr := parseArgs(fs, args)
+	if parseErr != nil {
+		return usageError(parseErr)
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: game-streams import-streams <file> [--dry-run] [--config <file>]")
+		return exitUsage
+	}
+
+	data, readErr := os.ReadFile(positional[0])
+	if readErr != nil {
+		return fail("import-streams", readErr)
+	}
+	s, parseStreamsErr := db.ParseStreams(string(data))
+	if parseStreamsErr != nil {
+		return fail("import-streams", fmt.Errorf("could not parse %s: %w", positional[0], parseStreamsErr))
+	}
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("import-streams", appErr)
+	}
+
+	if *dryRun {
+		summary, dryRunErr := s.DryRun(a.DB)
+		if dryRunErr != nil {
+			return fail("import-streams", dryRunErr)
+		}
+		printSummary(os.Stdout, summary)
+		return exitOK
+	}
+
+	if importErr := s.Import(a.DB); importErr != nil {
+		return fail("import-streams", importErr)
+	}
+	if expandErr := a.DB.ExpandTemplates(); expandErr != nil {
+		return fail("import-streams", fmt.Errorf("could not expand templates: %w", expandErr))
+	}
+	fmt.Printf("imported %s\n", positional[0])
+	return exitOK
+}
+
+// printSummary writes what a dry run of an import would change to w.
+func printSummary(w io.Writer, summary db.ImportSummary) {
+	fmt.Fprintf(w, "templates added or replaced: %d\n", summary.Templates)
+	fmt.Fprintf(w, "templates deleted:           %d\n", summary.DeletedTemplates)
+	fmt.Fprintf(w, "streams updated:             %d\n", summary.Updated)
+	fmt.Fprintf(w, "streams deleted:             %d\n", summary.Deleted)
+	fmt.Fprintf(w, "duplicate streams skipped:   %d\n", summary.Duplicates)
+	fmt.Fprintf(w, "new streams:                 %d\n", len(summary.New))
+	for _, name := range summary.New {
+		fmt.Fprintf(w, "  + %s\n", name)
+	}
+	if len(summary.InvalidTemplates) > 0 {
+		fmt.Fprintf(w, "invalid templates skipped:   %d\n", len(summary.InvalidTemplates))
+		for _, problem := range summary.InvalidTemplates {
+			fmt.Fprintf(w, "  ! %s\n", problem)
+		}
+	}
+}
+
+// exportStreams writes the streams and templates of the database in the format of
+// streams.toml to stdout, or to the file given with --out. With --upcoming, only
+// streams that have not started are exported.
+func exportStreams(args []string) int {
+	fs, configPath := newFlagSet("export-streams")
+	upcoming := fs.Bool("upcoming", false, "only export streams that have not started")
+	out := fs.String("out", "", "file to write to instead of stdout")
+	if _, parseErr := parseArgs(fs, args); parseErr != nil {
+		return usageError(parseErr)
+	}
+
+	a, appErr := newApp(*configPath)
+	if appErr != nil {
+		return fail("export-streams", appErr)
+	}
+	s, exportErr := a.DB.Export(*upcoming)
+	if exportErr != nil {
+		return fail("export-streams", exportErr)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, createErr := os.Create(*out)
+		if createErr != nil {
+			return fail("export-streams", createErr)
+		}
+		defer file.Close()
+		w = file
+	}
+	if writeErr := s.WriteTOML(w); writeErr != nil {
+		return fail("export-streams", writeErr)
+	}
+	return exitOK
+}
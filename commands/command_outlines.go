@@ -4,7 +4,13 @@ with Discord.
 */
 package commands
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/locales"
+)
 
 // admin is the permission level for an administrator. This is used to set the
 // permissions for the help and settings commands.
@@ -15,9 +21,35 @@ var admin int64 = discordgo.PermissionAdministrator
 // value.
 var boolFalse bool = false
 
+// leadTimes are the number of minutes before a stream starts that a server can choose
+// to have streams announced.
+var leadTimes = []int{5, 10, 15, 30, 60}
+
+// leadTimeChoices are the choices for the lead_time option of the settings command.
+var leadTimeChoices = func() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, minutes := range leadTimes {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%d minutes", minutes),
+			Value: minutes,
+		})
+	}
+	return choices
+}()
+
+// platformChoices are the choices for options that take a platform.
+var platformChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "PlayStation", Value: "playstation"},
+	{Name: "Xbox", Value: "xbox"},
+	{Name: "Nintendo", Value: "nintendo"},
+	{Name: "PC", Value: "pc"},
+	{Name: "VR", Value: "vr"},
+}
+
 // commands is a slice of all the commands that the bot can register with Discord. Each
 // command has a name and description, and some commands have options and permissions.
-var commands = []*discordgo.ApplicationCommand{
+// The names and descriptions are translated using the message catalogue.
+var commands = localiseCommands([]*discordgo.ApplicationCommand{
 	{
 		Name:         "streams",
 		Description:  "List upcoming streams for all platforms",
@@ -63,6 +95,18 @@ var commands = []*discordgo.ApplicationCommand{
 						Name:  "settings",
 						Value: "settings",
 					},
+					{
+						Name:  "setup",
+						Value: "setup",
+					},
+					{
+						Name:  "follow",
+						Value: "follow",
+					},
+					{
+						Name:  "webhooks",
+						Value: "webhooks",
+					},
 				},
 			},
 		},
@@ -141,6 +185,85 @@ var commands = []*discordgo.ApplicationCommand{
 				Description: "Enable or disable VR stream announcements",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "events",
+				Description: "Enable or disable creating Discord events for upcoming streams",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "crosspost",
+				Description: "Publish announcements to servers following the channel, if it is an announcement channel",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "lead_time",
+				Description: "Set how many minutes before a stream starts to announce it",
+				Required:    false,
+				Choices:     leadTimeChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "follow_publisher",
+				Description: "Announce all streams from a publisher, whatever the platform",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "unfollow_publisher",
+				Description: "Stop announcing streams from a publisher",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "message",
+				Description: "Template for the announcement message text, or \"default\"",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "embed_title",
+				Description: "Template for the announcement embed title, or \"default\"",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "embed_description",
+				Description: "Template for the announcement embed description, or \"default\"",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "embed_footer",
+				Description: "Template for the announcement embed footer, or \"default\"",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "thumbnail",
+				Description: "Show or hide the stream thumbnail in announcements",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "show_platforms",
+				Description: "Show or hide the platforms field in announcements",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "reset_template",
+				Description: "Reset the announcement templates to default",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "preview",
+				Description: "Preview an announcement using the current templates",
+				Required:    false,
+			},
 			{
 				Type:        discordgo.ApplicationCommandOptionBoolean,
 				Name:        "reset",
@@ -149,4 +272,108 @@ var commands = []*discordgo.ApplicationCommand{
 			},
 		},
 	},
+	{
+		Name:                     "setup",
+		Description:              "Set up stream announcements step by step",
+		DefaultMemberPermissions: &admin,
+		DMPermission:             &boolFalse,
+	},
+	{
+		Name:         "follow",
+		Description:  "Follow a stream, platform or publisher to be reminded by DM when streams start",
+		DMPermission: &boolFalse,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "stream",
+				Description: "The name or ID of the stream to follow",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "platform",
+				Description: "The platform to follow",
+				Required:    false,
+				Choices:     platformChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "publisher",
+				Description: "The publisher to follow (e.g. Nintendo, Ubisoft)",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:         "following",
+		Description:  "List and remove the streams, platforms and publishers you follow",
+		DMPermission: &boolFalse,
+	},
+	{
+		Name:                     "webhooks",
+		Description:              "Send stream announcements to Discord, Slack or JSON webhooks",
+		DefaultMemberPermissions: &admin,
+		DMPermission:             &boolFalse,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "add",
+				Description: "The URL of a webhook to add",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "type",
+				Description: "The type of webhook to add, worked out from the URL if not set",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Discord", Value: "discord"},
+					{Name: "Slack", Value: "slack"},
+					{Name: "JSON", Value: "json"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "platform",
+				Description: "Only send streams for this platform to the webhook being added",
+				Required:    false,
+				Choices:     platformChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "lead_time",
+				Description: "How many minutes before a stream starts to send it to the webhook being added",
+				Required:    false,
+				Choices:     leadTimeChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "remove",
+				Description: "The ID of a webhook to remove",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "enable",
+				Description: "The ID of a disabled webhook to enable again",
+				Required:    false,
+			},
+		},
+	},
+})
+
+// localiseCommands sets the name and description localizations of the given commands
+// and their options from the message catalogue, and returns the commands. The keys are
+// the command name followed by the option name, if any, and name or description.
+func localiseCommands(commands []*discordgo.ApplicationCommand) []*discordgo.ApplicationCommand {
+	for _, c := range commands {
+		c.NameLocalizations = locales.Localizations("command." + c.Name + ".name")
+		c.DescriptionLocalizations = locales.Localizations("command." + c.Name + ".description")
+		for _, o := range c.Options {
+			if localizations := locales.Localizations("command." + c.Name + "." + o.Name + ".description"); localizations != nil {
+				o.DescriptionLocalizations = *localizations
+			}
+		}
+	}
+	return commands
 }
@@ -5,61 +5,85 @@ from the Discord application.
 package commands
 
 import (
+	"strings"
+
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/logs"
+	"gamestreams/app"
 )
 
 // commandHandlers is a map of command names to their respective handler functions.
-var commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+var commandHandlers = map[string]func(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate){
 	"streams":    listStreams,
 	"streaminfo": streamInfo,
 	"suggest":    suggest,
 	"help":       help,
 	"settings":   settings,
+	"setup":      setup,
+	"follow":     follow,
+	"following":  following,
+	"webhooks":   webhooks,
+}
+
+// componentHandlers is a map of component custom ID prefixes to their respective
+// handler functions. The prefix is the part of the custom ID before the first colon.
+var componentHandlers = map[string]func(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate){
+	"setup":     setupComponent,
+	"following": followingComponent,
 }
 
 // RegisterCommands registers all commands in the commands slice, which is defined in
 // command_outlines.go
-func RegisterCommands(appID string, s *discordgo.Session) {
+func RegisterCommands(a *app.App) {
 	for _, c := range commands {
-		_, err := s.ApplicationCommandCreate(appID, "", c)
+		_, err := a.Session.ApplicationCommandCreate(a.Config().Discord.ApplicationID, "", c)
 		if err != nil {
-			logs.LogError(" CMND", "error creating command",
+			a.Log.LogError(" CMND", "error creating command",
 				"cmd", c.Name,
 				"err", err)
 		}
-		logs.LogInfo(" CMND", "registered command", false,
+		a.Log.LogInfo(" CMND", "registered command", false,
 			"cmd", c.Name)
 	}
 }
 
 // RemoveAllCommands retieves all registered commands and removes them from the
 // application.
-func RemoveAllCommands(appID string, s *discordgo.Session) {
-	commands, err := s.ApplicationCommands(appID, "")
+func RemoveAllCommands(a *app.App) {
+	appID := a.Config().Discord.ApplicationID
+	commands, err := a.Session.ApplicationCommands(appID, "")
 	if err != nil {
-		logs.LogError(" CMND", "error removing commands",
+		a.Log.LogError(" CMND", "error removing commands",
 			"err", err)
 	}
 	for _, command := range commands {
-		if delErr := s.ApplicationCommandDelete(appID, "", command.ID); delErr != nil {
-			logs.LogError(" MAIN", "error removing command",
+		if delErr := a.Session.ApplicationCommandDelete(appID, "", command.ID); delErr != nil {
+			a.Log.LogError(" MAIN", "error removing command",
 				"cmd", command.Name,
 				"err", delErr)
 			continue
 		}
-		logs.LogInfo(" CMND", "removed command", false,
+		a.Log.LogInfo(" CMND", "removed command", false,
 			"cmd", command.Name)
 	}
 }
 
-// RegisterHandler registers the functions that handle each commands. The functions
-// and the command names are mapped to each other in the commandHandlers map.
-func RegisterHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-			h(s, i)
+// RegisterHandler registers the functions that handle each commands and components.
+// The functions and the command names are mapped to each other in the commandHandlers
+// map, and the functions and component custom ID prefixes are mapped to each other in
+// the componentHandlers map. Each function is passed the App along with the interaction.
+func RegisterHandler(a *app.App) {
+	a.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		switch i.Type {
+		case discordgo.InteractionApplicationCommand:
+			if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+				h(a, s, i)
+			}
+		case discordgo.InteractionMessageComponent:
+			prefix := strings.SplitN(i.MessageComponentData().CustomID, ":", 2)[0]
+			if h, ok := componentHandlers[prefix]; ok {
+				h(a, s, i)
+			}
 		}
 	})
 }
@@ -0,0 +1,353 @@
+/*
+follow.go provides the /follow and /following commands. These allow users to follow
+individual streams, whole platforms or publishers and be reminded by DM when they are
+starting, list their follows, remove follows and opt out of the reminders.
+*/
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/discord"
+	"gamestreams/locales"
+	"gamestreams/utils"
+)
+
+// follow adds a stream, platform or publisher to the user's follows. A stream can be
+// given by its ID or by name, partial matches are allowed. If the user has reached the follow limit
+// set in config.toml, the follow is not added.
+func follow(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
+		return
+	}
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
+
+	userID := discord.GetUserID(i)
+	locale := responseLocale(i, true)
+	a.Log.LogInfo(" CMND", "follow command", false,
+		"user", userID,
+		"server", i.GuildID)
+	saveUserLocale(a, i, userID)
+
+	embed := &discordgo.MessageEmbed{
+		Title: locales.T(locale, "follow.title"),
+		Color: a.Config().Discord.EmbedColour,
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		embed.Description = locales.T(locale, "follow.choose")
+		respond(a, s, i, embed)
+		return
+	}
+	maxFollows := a.Config().Follows.MaxFollows
+	var added []string
+	for _, option := range options {
+		f := db.Follow{UserID: userID}
+		var name string
+		switch option.Name {
+		case "stream":
+			stream, found := findStream(a, option.StringValue())
+			if !found {
+				embed.Description = locales.T(locale, "follow.stream_not_found")
+				respond(a, s, i, embed)
+				return
+			}
+			f.Type = "stream"
+			f.Value = strconv.Itoa(stream.ID)
+			name = fmt.Sprintf("**%s**", stream.Name)
+		case "publisher":
+			publisher, found, findErr := a.DB.FindPublisher(option.StringValue())
+			if findErr != nil {
+				a.Log.LogError(" CMND", "error finding publisher",
+					"publisher", option.StringValue(),
+					"err", findErr)
+			}
+			if !found {
+				embed.Description = locales.T(locale, "follow.publisher_not_found")
+				respond(a, s, i, embed)
+				return
+			}
+			f.Type = "publisher"
+			f.Value = strconv.Itoa(publisher.ID)
+			name = locales.T(locale, "follow.all_streams", publisher.Name)
+		case "platform":
+			f.Type = "platform"
+			f.Value = option.StringValue()
+			name = locales.T(locale, "follow.all_streams", displayPlatform(f.Value))
+		default:
+			continue
+		}
+		insertErr := f.Insert(a.DB, maxFollows)
+		if errors.Is(insertErr, db.ErrFollowLimit) {
+			embed.Description = locales.T(locale, "follow.limit", maxFollows)
+			if len(added) > 0 {
+				embed.Description = locales.T(locale, "follow.added",
+					strings.Join(added, locales.T(locale, "follow.and"))) + "\n\n" + embed.Description
+			}
+			respond(a, s, i, embed)
+			return
+		}
+		if insertErr != nil {
+			a.Log.LogError(" CMND", "error adding follow",
+				"user", userID,
+				"err", insertErr)
+			embed.Description = locales.T(locale, "follow.error")
+			respond(a, s, i, embed)
+			return
+		}
+		added = append(added, name)
+	}
+	embed.Description = locales.T(locale, "follow.added",
+		strings.Join(added, locales.T(locale, "follow.and"))) + " " +
+		locales.T(locale, "follow.reminder", a.Config().Schedule.NotificationTMinus)
+	if enabled, _ := a.DB.DMNotificationsEnabled(userID); !enabled {
+		embed.Description += "\n\n" + locales.T(locale, "follow.dms_off")
+	}
+	respond(a, s, i, embed)
+}
+
+// following lists the streams, platforms and publishers the user follows. The response
+// contains a select menu for removing follows and a button for turning DM reminders on or off.
+func following(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
+		return
+	}
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
+
+	userID := discord.GetUserID(i)
+	a.Log.LogInfo(" CMND", "following command", false,
+		"user", userID,
+		"server", i.GuildID)
+	saveUserLocale(a, i, userID)
+
+	embed, components := followingMessage(a, userID, "", responseLocale(i, true))
+	respondErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if respondErr != nil {
+		a.Log.LogError(" CMND", "error responding to interaction",
+			"cmd", i.ApplicationCommandData().Name,
+			"err", respondErr)
+	}
+}
+
+// followingComponent handles the select menu and button of the /following response.
+// The custom ID of each component is in the format following:<action>.
+func followingComponent(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	userID := discord.GetUserID(i)
+	if blacklisted, _ := a.DB.IsBlacklisted(userID); blacklisted {
+		return
+	}
+	locale := responseLocale(i, true)
+	var status string
+	switch strings.TrimPrefix(data.CustomID, "following:") {
+	case "remove":
+		for _, value := range data.Values {
+			followID, convErr := strconv.Atoi(value)
+			if convErr != nil {
+				continue
+			}
+			if rmErr := a.DB.RemoveFollow(userID, followID); rmErr != nil {
+				a.Log.LogError(" CMND", "error removing follow",
+					"user", userID,
+					"err", rmErr)
+				status = locales.T(locale, "following.remove_error")
+			}
+		}
+		if status == "" {
+			status = locales.T(locale, "following.removed")
+		}
+	case "dms":
+		enabled, getErr := a.DB.DMNotificationsEnabled(userID)
+		if getErr != nil {
+			a.Log.LogError(" CMND", "error getting DM notifications",
+				"user", userID,
+				"err", getErr)
+		}
+		if setErr := a.DB.SetDMNotifications(userID, !enabled); setErr != nil {
+			a.Log.LogError(" CMND", "error setting DM notifications",
+				"user", userID,
+				"err", setErr)
+			status = locales.T(locale, "following.dms_error")
+		} else if enabled {
+			status = locales.T(locale, "following.turned_off")
+		} else {
+			status = locales.T(locale, "following.turned_on")
+		}
+	default:
+		return
+	}
+	embed, components := followingMessage(a, userID, status, locale)
+	respondComponent(a, s, i, discordgo.InteractionResponseUpdateMessage,
+		&discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		})
+}
+
+// followingMessage returns the embed and components listing the user's follows in the
+// given locale. The status is shown at the top of the embed if it is not empty.
+func followingMessage(a *app.App, userID string, status string, locale string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title: locales.T(locale, "following.title"),
+		Color: a.Config().Discord.EmbedColour,
+	}
+	follows, getErr := a.DB.GetFollows(userID)
+	if getErr != nil {
+		a.Log.LogError(" CMND", "error getting follows",
+			"user", userID,
+			"err", getErr)
+		embed.Description = locales.T(locale, "common.error_occurred")
+		return embed, []discordgo.MessageComponent{}
+	}
+	enabled, _ := a.DB.DMNotificationsEnabled(userID)
+
+	var lines []string
+	var options []discordgo.SelectMenuOption
+	for _, f := range follows {
+		label := followLabel(a, f, locale)
+		lines = append(lines, "- "+label)
+		if len(options) < 25 {
+			options = append(options, discordgo.SelectMenuOption{
+				Label: utils.Truncate(label, 100),
+				Value: strconv.Itoa(f.ID),
+			})
+		}
+	}
+	if len(lines) == 0 {
+		embed.Description = locales.T(locale, "following.none")
+	} else {
+		embed.Description = strings.Join(lines, "\n")
+	}
+	if status != "" {
+		embed.Description = fmt.Sprintf("**%s**\n\n%s", status, embed.Description)
+	}
+	dmStatus := locales.T(locale, "following.on")
+	dmLabel := locales.T(locale, "following.turn_off")
+	if !enabled {
+		dmStatus = locales.T(locale, "following.off")
+		dmLabel = locales.T(locale, "following.turn_on")
+	}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   locales.T(locale, "following.dms"),
+			Value:  dmStatus,
+			Inline: false,
+		},
+	}
+
+	var components []discordgo.MessageComponent
+	if len(options) > 0 {
+		zero := 0
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "following:remove",
+					Placeholder: locales.T(locale, "following.remove"),
+					MinValues:   &zero,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		})
+	}
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    dmLabel,
+				Style:    discordgo.SecondaryButton,
+				CustomID: "following:dms",
+			},
+		},
+	})
+	return embed, components
+}
+
+// followLabel returns a readable description of a follow in the given locale. Stream
+// follows show the name and date of the stream, publisher follows show the name of the
+// publisher.
+func followLabel(a *app.App, f db.Follow, locale string) string {
+	if f.Type == "platform" {
+		return locales.T(locale, "following.all_streams", displayPlatform(f.Value))
+	}
+	id, convErr := strconv.Atoi(f.Value)
+	if convErr != nil {
+		return f.Value
+	}
+	if f.Type == "publisher" {
+		publisher, getErr := a.DB.GetPublisher(id)
+		if getErr != nil {
+			return locales.T(locale, "following.publisher", id)
+		}
+		return locales.T(locale, "following.all_streams", publisher.Name)
+	}
+	var streams db.Streams
+	if getErr := streams.GetByID(a.DB, id); getErr != nil || len(streams.Streams) == 0 {
+		return locales.T(locale, "following.stream", id)
+	}
+	return fmt.Sprintf("%s (%s)", streams.Streams[0].Name, streams.Streams[0].Date)
+}
+
+// saveUserLocale records the locale of the user's Discord client, so the reminders sent
+// to them by DM are in the same language as their responses.
+func saveUserLocale(a *app.App, i *discordgo.InteractionCreate, userID string) {
+	if i.Locale == "" {
+		return
+	}
+	if setErr := a.DB.SetUserLocale(userID, string(i.Locale)); setErr != nil {
+		a.Log.LogError(" CMND", "error setting user locale",
+			"user", userID,
+			"err", setErr)
+	}
+}
+
+// findStream returns the upcoming stream with the given ID, or the first upcoming
+// stream whose name matches the given value.
+func findStream(a *app.App, value string) (db.Stream, bool) {
+	var streams db.Streams
+	if id, convErr := strconv.Atoi(strings.TrimSpace(value)); convErr == nil {
+		if getErr := streams.GetByID(a.DB, id); getErr == nil && len(streams.Streams) > 0 {
+			return streams.Streams[0], true
+		}
+		streams = db.Streams{}
+	}
+	if getErr := streams.GetInfo(a.DB, value); getErr != nil {
+		a.Log.LogError(" CMND", "error getting stream",
+			"stream", value,
+			"err", getErr)
+		return db.Stream{}, false
+	}
+	if len(streams.Streams) == 0 {
+		return db.Stream{}, false
+	}
+	return streams.Streams[0], true
+}
+
+// displayPlatform returns the platform name with the capitalisation used when
+// displaying streams.
+func displayPlatform(platform string) string {
+	for _, p := range platformOptions {
+		if strings.EqualFold(p, platform) {
+			return p
+		}
+	}
+	return platform
+}
@@ -9,43 +9,51 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
+	"gamestreams/streams"
 )
 
 // help responds with a help message for the bot.
 // Help messages are specific to the command requested. If no command is requested,
-// a general help message is sent.
-func help(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if userIsBlacklisted(i) {
+// a general help message is sent. Help messages are sent in the user's locale.
+func help(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
 		return
 	}
-	a := db.CommandData{}
-	a.Start(i)
-	defer a.End()
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
 
 	userID := discord.GetUserID(i)
-	logs.LogInfo(" CMND", "help command", false,
+	a.Log.LogInfo(" CMND", "help command", false,
 		"user", userID,
 		"server", i.GuildID)
 
+	locale := responseLocale(i, true)
 	var content []*discordgo.MessageEmbed
 	if len(i.ApplicationCommandData().Options) == 0 {
-		content = helpGeneral()
+		content = helpGeneral(a, locale)
 	} else {
 		switch i.ApplicationCommandData().Options[0].Value {
 		case "streams":
-			content = helpStreams()
+			content = helpStreams(a, locale)
 		case "streaminfo":
-			content = helpStreamInfo()
+			content = helpStreamInfo(a, locale)
 		case "suggest":
-			content = helpSuggest()
+			content = helpSuggest(a, locale)
 		case "settings":
-			content = helpSettings()
+			content = helpSettings(a, locale)
+		case "setup":
+			content = helpSetup(a, locale)
+		case "follow":
+			content = helpFollow(a, locale)
+		case "webhooks":
+			content = helpWebhooks(a, locale)
 		default:
-			content = helpGeneral()
+			content = helpGeneral(a, locale)
 		}
 	}
 
@@ -57,73 +65,78 @@ func help(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if respondErr != nil {
-		logs.LogError(" CMND", "error responding to interaction",
+		a.Log.LogError(" CMND", "error responding to interaction",
 			"cmd", i.ApplicationCommandData().Name,
 			"err", respondErr)
 	}
 }
 
-// helpGeneral returns a general help message for the bot.
-func helpGeneral() []*discordgo.MessageEmbed {
-	return []*discordgo.MessageEmbed{
+// helpGeneral returns a general help message in the given locale for the bot. If the
+// bot has a public announcement channel, an invite to follow it is included.
+func helpGeneral(a *app.App, locale string) []*discordgo.MessageEmbed {
+	embeds := []*discordgo.MessageEmbed{
 		{
-			Title: "Game Streams",
-			Description: "Game Streams is a bot that keeps track of game announcement streams " +
-				"and can announce when streams are beginning. \n\nUse the `/settings` command in your server " +
-				"to configure stream announcements.",
-			Color: config.Values.Discord.EmbedColour,
+			Title:       "Game Streams",
+			Description: locales.T(locale, "help.general.description"),
+			Color:       a.Config().Discord.EmbedColour,
 			Fields: []*discordgo.MessageEmbedField{
 				{
-					Name: "Commands",
-					Value: "`/streams` - List upcoming streams" +
-						"\n`/streaminfo` - Get information on a specific stream by title" +
-						"\n`/suggest` - Suggest a stream to be added to the database" +
-						"\n`/help` - Get help with the bot and commands" +
-						"\n`/settings` [admin] - Configure stream announcements",
+					Name:   locales.T(locale, "help.general.commands"),
+					Value:  locales.T(locale, "help.general.commands_list"),
 					Inline: false,
 				},
 				{
-					Name: "Documents",
-					Value: fmt.Sprintf("Privacy Policy: %s\n", config.Values.Documents.PrivacyPolicy) +
-						fmt.Sprintf("Terms of Service: %s\n", config.Values.Documents.TermsOfService) +
-						fmt.Sprintf("Changelog: %s\n", config.Values.Documents.Changelog),
+					Name: locales.T(locale, "help.general.documents"),
+					Value: fmt.Sprintf("%s: %s\n", locales.T(locale, "help.general.privacy"),
+						a.Config().Documents.PrivacyPolicy) +
+						fmt.Sprintf("%s: %s\n", locales.T(locale, "help.general.terms"),
+							a.Config().Documents.TermsOfService) +
+						fmt.Sprintf("%s: %s\n", locales.T(locale, "help.general.changelog"),
+							a.Config().Documents.Changelog),
 					Inline: false,
 				},
 				{
-					Name: "Version",
-					Value: fmt.Sprintf("Version: `%s`\n", config.Values.Bot.Version) +
-						fmt.Sprintf("Release Date: `%s`\n", config.Values.Bot.ReleaseDate),
+					Name: locales.T(locale, "help.general.version"),
+					Value: fmt.Sprintf("%s: `%s`\n", locales.T(locale, "help.general.version"),
+						a.Config().Bot.Version) +
+						fmt.Sprintf("%s: `%s`\n", locales.T(locale, "help.general.release_date"),
+							locales.FormatDateString(locale, a.Config().Bot.ReleaseDate)),
 				},
 			},
 		},
 	}
+	if invite := a.Config().Announcements.PublicInvite; invite != "" {
+		embeds[0].Fields = append(embeds[0].Fields, &discordgo.MessageEmbedField{
+			Name:   locales.T(locale, "help.general.public"),
+			Value:  locales.T(locale, "help.general.public_follow", invite),
+			Inline: false,
+		})
+	}
+	return embeds
 }
 
-// helpStreams returns a help message for the /streams command.
-func helpStreams() []*discordgo.MessageEmbed {
+// helpStreams returns a help message in the given locale for the /streams command.
+func helpStreams(a *app.App, locale string) []*discordgo.MessageEmbed {
 	return []*discordgo.MessageEmbed{
 		{
-			Title: "/streams",
-			Description: "List upcoming streams. Streams are sorted by date and time." +
-				"\n\nStreams that have already started will not be listed. " +
-				fmt.Sprintf("\n\nList is limited to %d streams.", config.Values.Streams.Limit),
-			Color: config.Values.Discord.EmbedColour,
+			Title:       "/streams",
+			Description: locales.T(locale, "help.streams.description", a.Config().Streams.Limit),
+			Color:       a.Config().Discord.EmbedColour,
 		},
 	}
 }
 
-// helpStreamInfo returns a help message for the /streaminfo command.
-func helpStreamInfo() []*discordgo.MessageEmbed {
+// helpStreamInfo returns a help message in the given locale for the /streaminfo command.
+func helpStreamInfo(a *app.App, locale string) []*discordgo.MessageEmbed {
 	return []*discordgo.MessageEmbed{
 		{
-			Title: "/streaminfo",
-			Description: "Get information on a specific stream by title." +
-				"\n\nThe name field is required.",
-			Color: config.Values.Discord.EmbedColour,
+			Title:       "/streaminfo",
+			Description: locales.T(locale, "help.streaminfo.description"),
+			Color:       a.Config().Discord.EmbedColour,
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "name",
-					Value:  "The name of the stream to search for, partial matches are allowed.",
+					Value:  locales.T(locale, "help.streaminfo.name"),
 					Inline: false,
 				},
 			},
@@ -131,28 +144,27 @@ func helpStreamInfo() []*discordgo.MessageEmbed {
 	}
 }
 
-// helpSuggest returns a help message for the /suggest command.
-func helpSuggest() []*discordgo.MessageEmbed {
+// helpSuggest returns a help message in the given locale for the /suggest command.
+func helpSuggest(a *app.App, locale string) []*discordgo.MessageEmbed {
 	return []*discordgo.MessageEmbed{
 		{
-			Title: "/suggest",
-			Description: "Suggest a stream to be added to the database. Suggestions will be reviewed by " +
-				"the bot owner and added to the database if they are valid.\n\nAll fields are required.",
-			Color: config.Values.Discord.EmbedColour,
+			Title:       "/suggest",
+			Description: locales.T(locale, "help.suggest.description"),
+			Color:       a.Config().Discord.EmbedColour,
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "name",
-					Value:  "The name of the stream to suggest",
+					Value:  locales.T(locale, "help.suggest.name"),
 					Inline: false,
 				},
 				{
 					Name:   "date",
-					Value:  "The date of the stream in the format `YYYY-MM-DD`",
+					Value:  locales.T(locale, "help.suggest.date"),
 					Inline: false,
 				},
 				{
 					Name:   "url",
-					Value:  "The URL of the stream",
+					Value:  locales.T(locale, "help.suggest.url"),
 					Inline: false,
 				},
 			},
@@ -160,37 +172,155 @@ func helpSuggest() []*discordgo.MessageEmbed {
 	}
 }
 
-// helpSettings returns a help message for the /settings command.
-func helpSettings() []*discordgo.MessageEmbed {
+// helpSettings returns a help message in the given locale for the /settings command.
+func helpSettings(a *app.App, locale string) []*discordgo.MessageEmbed {
 	return []*discordgo.MessageEmbed{
 		{
-			Title: "/settings",
-			Description: "Settings for the Game Streams bot. These need to be set in your server to " +
-				"enable the bot to announce streams.\n\nOnly server administrators can use this command." +
-				"\n\nAll fields are optional.",
-			Color: config.Values.Discord.EmbedColour,
+			Title:       "/settings",
+			Description: locales.T(locale, "help.settings.description"),
+			Color:       a.Config().Discord.EmbedColour,
 			Fields: []*discordgo.MessageEmbedField{
 				{
-					Name: "channel",
-					Value: "The channel for announcing when a stream starts. " +
-						"**If not set, the bot will not announce streams.**",
+					Name:   "channel",
+					Value:  locales.T(locale, "help.settings.channel"),
+					Inline: false,
+				},
+				{
+					Name:   "role",
+					Value:  locales.T(locale, "help.settings.role"),
+					Inline: false,
+				},
+				{
+					Name:   "events",
+					Value:  locales.T(locale, "help.settings.events"),
+					Inline: false,
+				},
+				{
+					Name:   "crosspost",
+					Value:  locales.T(locale, "help.settings.crosspost"),
+					Inline: false,
+				},
+				{
+					Name:   "lead_time",
+					Value:  locales.T(locale, "help.settings.lead_time"),
+					Inline: false,
+				},
+				{
+					Name:   "follow_publisher",
+					Value:  locales.T(locale, "help.settings.follow_publisher"),
+					Inline: false,
+				},
+				{
+					Name:   "message, embed_title, embed_description, embed_footer",
+					Value:  locales.T(locale, "help.settings.templates"),
+					Inline: false,
+				},
+				{
+					Name:   "thumbnail, show_platforms",
+					Value:  locales.T(locale, "help.settings.display"),
+					Inline: false,
+				},
+				{
+					Name:   "preview",
+					Value:  locales.T(locale, "help.settings.preview"),
 					Inline: false,
 				},
 				{
-					Name: "role",
-					Value: "The role to ping when a stream starts. " +
-						"**If not set, the bot will still announce streams but will not ping anyone.**",
+					Name:   "reset_template",
+					Value:  locales.T(locale, "help.settings.reset_template"),
 					Inline: false,
 				},
 				{
 					Name:   "reset",
-					Value:  "Reset all settings to default. Use `True` to reset.",
+					Value:  locales.T(locale, "help.settings.reset"),
+					Inline: false,
+				},
+				{
+					Name:   "platforms",
+					Value:  locales.T(locale, "help.settings.platforms"),
+					Inline: false,
+				},
+			},
+		},
+	}
+}
+
+// helpSetup returns a help message in the given locale for the /setup command.
+func helpSetup(a *app.App, locale string) []*discordgo.MessageEmbed {
+	return []*discordgo.MessageEmbed{
+		{
+			Title:       "/setup",
+			Description: locales.T(locale, "help.setup.description"),
+			Color:       a.Config().Discord.EmbedColour,
+		},
+	}
+}
+
+// helpFollow returns a help message in the given locale for the /follow and
+// /following commands.
+func helpFollow(a *app.App, locale string) []*discordgo.MessageEmbed {
+	return []*discordgo.MessageEmbed{
+		{
+			Title:       "/follow",
+			Description: locales.T(locale, "help.follow.description", a.Config().Schedule.NotificationTMinus),
+			Color:       a.Config().Discord.EmbedColour,
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "stream",
+					Value:  locales.T(locale, "help.follow.stream"),
+					Inline: false,
+				},
+				{
+					Name:   "platform",
+					Value:  locales.T(locale, "help.follow.platform"),
+					Inline: false,
+				},
+				{
+					Name:   "publisher",
+					Value:  locales.T(locale, "help.follow.publisher"),
+					Inline: false,
+				},
+			},
+		},
+	}
+}
+
+// helpWebhooks returns a help message in the given locale for the /webhooks command.
+func helpWebhooks(a *app.App, locale string) []*discordgo.MessageEmbed {
+	return []*discordgo.MessageEmbed{
+		{
+			Title:       "/webhooks",
+			Description: locales.T(locale, "help.webhooks.description", streams.WebhookServerLimit(a)),
+			Color:       a.Config().Discord.EmbedColour,
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "add",
+					Value:  locales.T(locale, "help.webhooks.add"),
+					Inline: false,
+				},
+				{
+					Name:   "type",
+					Value:  locales.T(locale, "help.webhooks.type"),
+					Inline: false,
+				},
+				{
+					Name:   "platform",
+					Value:  locales.T(locale, "help.webhooks.platform"),
+					Inline: false,
+				},
+				{
+					Name:   "lead_time",
+					Value:  locales.T(locale, "help.webhooks.lead_time"),
+					Inline: false,
+				},
+				{
+					Name:   "remove",
+					Value:  locales.T(locale, "help.webhooks.remove"),
 					Inline: false,
 				},
 				{
-					Name: "platforms",
-					Value: "Enable or disable announcements by platform. " +
-						"Use `True` to enable and `False` to disable annoucements.",
+					Name:   "enable",
+					Value:  locales.T(locale, "help.webhooks.enable"),
 					Inline: false,
 				},
 			},
@@ -0,0 +1,23 @@
+/*
+locale.go contains functions for choosing the locale to respond to an interaction in.
+*/
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/locales"
+)
+
+// responseLocale returns the locale to respond to the interaction in. Ephemeral
+// responses are only seen by the user, so they use the user's locale. Public responses
+// use the locale of the server, falling back to the user's locale outside of a server.
+func responseLocale(i *discordgo.InteractionCreate, ephemeral bool) string {
+	if !ephemeral && i.GuildLocale != nil && *i.GuildLocale != "" {
+		return string(*i.GuildLocale)
+	}
+	if i.Locale != "" {
+		return string(i.Locale)
+	}
+	return locales.Default
+}
@@ -5,7 +5,7 @@ of the bot. These commands are used to manage the bot and the data it uses.
 package commands
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -13,32 +13,47 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
-	"gamestreams/logs"
+	"gamestreams/jobs"
+	"gamestreams/locales"
 	"gamestreams/servers"
+	gsstreams "gamestreams/streams"
 	"gamestreams/utils"
 )
 
-// RegisterOwnerCommands registers the owner commands with the Discord session.
-func RegisterOwnerCommands(s *discordgo.Session) {
-	s.AddHandler(uptime)
-	s.AddHandler(serverCount)
-	s.AddHandler(listCommands)
-	s.AddHandler(update)
-	s.AddHandler(removeOldServers)
-	s.AddHandler(sqlExecute)
-	s.AddHandler(ownerListStreams)
-	s.AddHandler(blacklistEdit)
-	s.AddHandler(blacklistGet)
-	s.AddHandler(suggestions)
+// ownerHandlers is a list of the functions that handle the owner commands.
+var ownerHandlers = []func(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate){
+	uptime,
+	serverCount,
+	listCommands,
+	update,
+	jobsCommand,
+	removeOldServers,
+	sqlExecute,
+	ownerListStreams,
+	announcements,
+	ownerWebhooks,
+	blacklistEdit,
+	blacklistGet,
+	suggestions,
+}
+
+// RegisterOwnerCommands registers the owner commands with the Discord session of the
+// App.
+func RegisterOwnerCommands(a *app.App) {
+	for _, h := range ownerHandlers {
+		a.Session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+			h(a, s, m)
+		})
+	}
 }
 
 // listCommands lists the available owner commands
-func listCommands(s *discordgo.Session, m *discordgo.MessageCreate) {
+func listCommands(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	// ensure the message is from the bot owner and not the bot itself
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!commands" {
 		return
 	}
@@ -46,9 +61,12 @@ func listCommands(s *discordgo.Session, m *discordgo.MessageCreate) {
 		s.ChannelMessageSend(m.ChannelID, "```!uptime\n"+
 			"!servercount\n"+
 			"!update\n"+
+			"!jobs [run <name>]\n"+
 			"!removeoldservers\n"+
 			"!sqlx <command>\n"+
 			"!streams\n"+
+			"!announcements <stream id> [edit|delete]\n"+
+			"!webhooks [add <type> <url> [platforms]|rm <id>|enable <id>]\n"+
 			"!log\n"+
 			"!blacklist add <type> <id> <reason>\n"+
 			"!blacklist rm <id>\n"+
@@ -58,20 +76,20 @@ func listCommands(s *discordgo.Session, m *discordgo.MessageCreate) {
 }
 
 // uptime displays the uptime of the bot
-func uptime(s *discordgo.Session, m *discordgo.MessageCreate) {
+func uptime(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!uptime" {
 		return
 	}
 	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("uptime: %s",
-		time.Since(utils.StartTime).Round(time.Second).String()))
+		a.Uptime().Round(time.Second).String()))
 }
 
 // serverCount is a command that returns the number of servers the bot is in
-func serverCount(s *discordgo.Session, m *discordgo.MessageCreate) {
+func serverCount(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!servercount" {
 		return
 	}
@@ -79,32 +97,97 @@ func serverCount(s *discordgo.Session, m *discordgo.MessageCreate) {
 		len(s.State.Guilds)))
 }
 
-// update forces an update of the streams from the streams.toml file
-func update(s *discordgo.Session, m *discordgo.MessageCreate) {
+// update forces an update of the streams from the streams.toml file by running the
+// stream_update job, unless it is already running
+func update(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!update" {
 		return
 	}
-	var streams db.Streams
-	if updateErr := streams.Update(); updateErr != nil {
-		logs.LogError("OWNER", "error updating streams",
-			"err", updateErr)
-	} else {
-		s.ChannelMessageSend(m.ChannelID, "streams updated")
+	run, runErr := a.Jobs.Run("stream_update", jobs.TriggerOwner)
+	switch {
+	case errors.Is(runErr, jobs.ErrRunning):
+		s.ChannelMessageSend(m.ChannelID, "streams are already being updated")
+	case runErr != nil:
+		s.ChannelMessageSend(m.ChannelID, "error updating streams: "+runErr.Error())
+	default:
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("streams updated in %s",
+			run.Duration.Round(time.Millisecond)))
 	}
 }
 
+// jobsCommand lists the scheduled jobs with their next and most recent runs, or starts
+// a run of a job now
+func jobsCommand(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author.ID == s.State.User.ID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
+		strings.Split(m.Content, " ")[0] != "!jobs" {
+		return
+	}
+	splitString := strings.Fields(m.Content)
+	switch {
+	case len(splitString) == 1:
+		statuses, listErr := a.Jobs.List()
+		if listErr != nil {
+			a.Log.LogError("OWNER", "error listing jobs",
+				"err", listErr)
+			return
+		}
+		var lines []string
+		for _, status := range statuses {
+			lines = append(lines, describeJob(status))
+		}
+		s.ChannelMessageSend(m.ChannelID,
+			"```"+utils.Truncate(strings.Join(lines, "\n\n"), 1994)+"```")
+	case len(splitString) == 3 && splitString[1] == "run":
+		triggerErr := a.Jobs.Trigger(splitString[2], jobs.TriggerOwner)
+		switch {
+		case errors.Is(triggerErr, jobs.ErrUnknownJob):
+			s.ChannelMessageSend(m.ChannelID, "unknown job. use `!jobs` to list them")
+		case triggerErr != nil:
+			s.ChannelMessageSend(m.ChannelID, splitString[2]+": "+triggerErr.Error())
+		default:
+			s.ChannelMessageSend(m.ChannelID, "started "+splitString[2])
+		}
+	default:
+		s.ChannelMessageSend(m.ChannelID, "invalid command. use `!jobs [run [name]]`")
+	}
+}
+
+// describeJob returns the name, schedule, next run and most recent run of a job
+func describeJob(status jobs.Status) string {
+	schedule, next := "not scheduled", "-"
+	if status.Schedule != "" {
+		schedule = status.Schedule
+		next = status.Next.Format("2006-01-02 15:04 UTC")
+	}
+	last := "never"
+	if status.Last.ID != 0 {
+		last = fmt.Sprintf("%s %s in %s (%s)",
+			status.Last.StartedAt.Format("2006-01-02 15:04 UTC"), status.Last.Outcome,
+			status.Last.Duration.Round(time.Millisecond), status.Last.Trigger)
+		if status.Last.Error != "" {
+			last += ": " + utils.Truncate(status.Last.Error, 100)
+		}
+	}
+	if status.Running {
+		last = "running now, last: " + last
+	}
+	return fmt.Sprintf("%s - %s\n  schedule: %s\n  next: %s\n  last: %s",
+		status.Name, status.Description, schedule, next, last)
+}
+
 // removeOldServers removes servers from the servers table that are no longer in the
 // servers list
-func removeOldServers(s *discordgo.Session, m *discordgo.MessageCreate) {
+func removeOldServers(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!removeoldservers" {
 		return
 	}
-	if removeErr := servers.RemoveOldServerIDs(s); removeErr != nil {
-		logs.LogError("OWNER", "error removing old servers",
+	if removeErr := servers.RemoveOldServerIDs(a, s); removeErr != nil {
+		a.Log.LogError("OWNER", "error removing old servers",
 			"err", removeErr)
 	} else {
 		s.ChannelMessageSend(m.ChannelID, "old servers removed")
@@ -114,55 +197,202 @@ func removeOldServers(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 // sqlExecute allows for the execution of SQL commands on the database via Discord
 // DM
-func sqlExecute(s *discordgo.Session, m *discordgo.MessageCreate) {
+func sqlExecute(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!sqlx" {
 		return
 	}
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := a.DB.Conn()
 	if openErr != nil {
-		logs.LogError("OWNER", "error opening database",
+		a.Log.LogError("OWNER", "error opening database",
 			"err", openErr)
+		return
 	}
 	defer db.Close()
 	query := m.Content[6:]
 	_, execErr := db.Exec(query)
 	if execErr != nil {
-		logs.LogError("OWNER", "error executing database command",
+		a.Log.LogError("OWNER", "error executing database command",
 			"err", execErr)
 	}
 	s.ChannelMessageSend(m.ChannelID, "sql executed")
 }
 
 // ownerListStreams lists all upcoming streams in the streams table including their id
-func ownerListStreams(s *discordgo.Session, m *discordgo.MessageCreate) {
+func ownerListStreams(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!streams" {
 		return
 	}
 	var streams db.Streams
-	if getErr := streams.GetUpcoming(50); getErr != nil {
-		logs.LogError("OWNER", "error getting streams",
+	if getErr := streams.GetUpcoming(a.DB, 50); getErr != nil {
+		a.Log.LogError("OWNER", "error getting streams",
 			"err", getErr)
 	}
 	for _, stream := range streams.Streams {
 		stream.ProvideUnsetValues()
 		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("id: `%d`\nname: `%s`\n"+
-			"platform: `%s`\ndate: `%s`\ntime: `%s`\ndescription: `%s`\nurl: `%s`",
+			"platform: `%s`\ndate: `%s`\ntime: `%s`\nstatus: `%s`\ndescription: `%s`\nurl: `%s`",
 			stream.ID, stream.Name, stream.Platform, stream.Date, stream.Time,
-			stream.Description, stream.URL))
+			stream.Status, stream.Description, stream.URL))
 
 		s.ChannelMessageSend(m.ChannelID, "----------------")
 		time.Sleep(time.Second / 2)
 	}
 }
 
+// announcements shows the delivery status of the announcements of a stream, or edits
+// or deletes every message posted for it
+func announcements(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author.ID == s.State.User.ID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
+		strings.Split(m.Content, " ")[0] != "!announcements" {
+		return
+	}
+	splitString := strings.Split(m.Content, " ")
+	if len(splitString) < 2 || len(splitString) > 3 {
+		s.ChannelMessageSend(m.ChannelID, "invalid command. use `!announcements"+
+			" [stream id] [edit|delete]`")
+		return
+	}
+	streamID, convErr := strconv.Atoi(splitString[1])
+	if convErr != nil {
+		s.ChannelMessageSend(m.ChannelID, "invalid stream id")
+		return
+	}
+	if len(splitString) == 2 {
+		summary, summaryErr := gsstreams.AnnouncementSummary(a, streamID)
+		if summaryErr != nil {
+			a.Log.LogError("OWNER", "error getting announcements",
+				"stream", streamID,
+				"err", summaryErr)
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, summary)
+		return
+	}
+	switch splitString[2] {
+	case "edit":
+		var streams db.Streams
+		if getErr := streams.GetByID(a.DB, streamID); getErr != nil || len(streams.Streams) == 0 {
+			s.ChannelMessageSend(m.ChannelID, "stream not found")
+			return
+		}
+		edited, editErr := gsstreams.EditStreamAnnouncements(a, streams.Streams[0])
+		if editErr != nil {
+			a.Log.LogError("OWNER", "error editing announcements",
+				"stream", streamID,
+				"err", editErr)
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("edited %d announcements", edited))
+	case "delete":
+		deleted, deleteErr := gsstreams.DeleteStreamAnnouncements(a, streamID)
+		if deleteErr != nil {
+			a.Log.LogError("OWNER", "error deleting announcements",
+				"stream", streamID,
+				"err", deleteErr)
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("deleted %d announcements", deleted))
+	default:
+		s.ChannelMessageSend(m.ChannelID, "invalid command. use `!announcements"+
+			" [stream id] [edit|delete]`")
+	}
+}
+
+// ownerWebhooks lists the webhooks registered by the owner for external consumers, or
+// adds, removes or enables one. The platforms of a new webhook are separated by commas
+func ownerWebhooks(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author.ID == s.State.User.ID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
+		strings.Split(m.Content, " ")[0] != "!webhooks" {
+		return
+	}
+	splitString := strings.Fields(m.Content)
+	usage := "invalid command. use `!webhooks [add [type] [url] [platforms]|rm [id]|enable [id]]`"
+	if len(splitString) == 1 {
+		webhooks, getErr := a.DB.GetWebhooks("")
+		if getErr != nil {
+			a.Log.LogError("OWNER", "error getting webhooks",
+				"err", getErr)
+			return
+		}
+		if len(webhooks) == 0 {
+			s.ChannelMessageSend(m.ChannelID, "no webhooks")
+			return
+		}
+		var descriptions []string
+		for _, w := range webhooks {
+			descriptions = append(descriptions, gsstreams.DescribeWebhook(a, w, locales.Default))
+		}
+		s.ChannelMessageSend(m.ChannelID, utils.Truncate(strings.Join(descriptions, "\n\n"), 2000))
+		return
+	}
+	switch splitString[1] {
+	case "add":
+		if len(splitString) < 4 || len(splitString) > 5 {
+			s.ChannelMessageSend(m.ChannelID, usage)
+			return
+		}
+		var platforms string
+		if len(splitString) == 5 {
+			platforms = strings.ToLower(splitString[4])
+		}
+		w, newErr := gsstreams.NewWebhook("", splitString[2], splitString[3], platforms, 0)
+		if newErr != nil {
+			s.ChannelMessageSend(m.ChannelID, "invalid webhook: "+newErr.Error())
+			return
+		}
+		if insertErr := w.Insert(a.DB); insertErr != nil {
+			a.Log.LogError("OWNER", "error adding webhook",
+				"err", insertErr)
+			return
+		}
+		response := fmt.Sprintf("added webhook %d", w.ID)
+		if w.Secret != "" {
+			response += fmt.Sprintf("\nsecret: `%s`", w.Secret)
+		}
+		s.ChannelMessageSend(m.ChannelID, response)
+	case "rm", "remove", "enable":
+		if len(splitString) != 3 {
+			s.ChannelMessageSend(m.ChannelID, usage)
+			return
+		}
+		id, convErr := strconv.Atoi(splitString[2])
+		if convErr != nil {
+			s.ChannelMessageSend(m.ChannelID, "invalid webhook id")
+			return
+		}
+		var updated bool
+		var updateErr error
+		if splitString[1] == "enable" {
+			updated, updateErr = a.DB.EnableWebhook(id, "")
+		} else {
+			updated, updateErr = a.DB.DeleteWebhook(id, "")
+		}
+		if updateErr != nil {
+			a.Log.LogError("OWNER", "error updating webhook",
+				"webhook", id,
+				"err", updateErr)
+			return
+		}
+		if !updated {
+			s.ChannelMessageSend(m.ChannelID, "webhook not found")
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, "webhook updated")
+	default:
+		s.ChannelMessageSend(m.ChannelID, usage)
+	}
+}
+
 // blacklistEdit allows the owner to add or remove users or servers from the blacklist
-func blacklistEdit(s *discordgo.Session, m *discordgo.MessageCreate) {
+func blacklistEdit(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!blacklist" {
 		return
 	}
@@ -177,9 +407,9 @@ func blacklistEdit(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 	subCommand := splitString[1]
 	if subCommand == "add" {
-		blacklistAdd(s, m, splitString)
+		blacklistAdd(a, s, m, splitString)
 	} else if subCommand == "rm" || subCommand == "remove" {
-		blacklistRemove(s, m, splitString)
+		blacklistRemove(a, s, m, splitString)
 	} else {
 		s.ChannelMessageSend(m.ChannelID, "invalid command. use `!blacklist add"+
 			" [type] [id] [reason]` or `!blacklist rm [id]` or `!blacklist get`")
@@ -187,9 +417,9 @@ func blacklistEdit(s *discordgo.Session, m *discordgo.MessageCreate) {
 }
 
 // blacklistAdd adds a user or server to the blacklist
-func blacklistAdd(s *discordgo.Session, m *discordgo.MessageCreate, splitString []string) {
+func blacklistAdd(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate, splitString []string) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID {
+		m.Author.ID != a.Config().Discord.OwnerID {
 		return
 	}
 	if len(splitString) < 6 || splitString[2] == "" || splitString[3] == "" ||
@@ -212,8 +442,8 @@ func blacklistAdd(s *discordgo.Session, m *discordgo.MessageCreate, splitString
 	}
 	reason := strings.Join(splitString[5:], " ")
 
-	if dbErr := db.AddToBlacklist(id, idType, reason, duration); dbErr != nil {
-		logs.LogError("OWNER", "error adding to blacklist",
+	if dbErr := a.DB.AddToBlacklist(id, idType, reason, duration); dbErr != nil {
+		a.Log.LogError("OWNER", "error adding to blacklist",
 			"id", id,
 			"id_type", idType,
 			"reason", reason,
@@ -225,9 +455,9 @@ func blacklistAdd(s *discordgo.Session, m *discordgo.MessageCreate, splitString
 }
 
 // blacklistRemove removes a user or server from the blacklist
-func blacklistRemove(s *discordgo.Session, m *discordgo.MessageCreate, splitString []string) {
+func blacklistRemove(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate, splitString []string) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID {
+		m.Author.ID != a.Config().Discord.OwnerID {
 		return
 	}
 	if len(splitString) < 3 || splitString[2] == "" || len(splitString) > 3 {
@@ -236,13 +466,13 @@ func blacklistRemove(s *discordgo.Session, m *discordgo.MessageCreate, splitStri
 	}
 
 	id := splitString[2]
-	exists, _ := db.IsBlacklisted(id)
+	exists, _ := a.DB.IsBlacklisted(id)
 	if !exists {
 		s.ChannelMessageSend(m.ChannelID, "id not in blacklist")
 		return
 	}
-	if dbErr := db.RemoveFromBlacklist(id); dbErr != nil {
-		logs.LogError("OWNER", "error removing from blacklist",
+	if dbErr := a.DB.RemoveFromBlacklist(id); dbErr != nil {
+		a.Log.LogError("OWNER", "error removing from blacklist",
 			"id", id,
 			"err", dbErr)
 	} else {
@@ -251,16 +481,16 @@ func blacklistRemove(s *discordgo.Session, m *discordgo.MessageCreate, splitStri
 }
 
 // blacklistGet lists all blacklisted users and servers
-func blacklistGet(s *discordgo.Session, m *discordgo.MessageCreate) {
+func blacklistGet(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		len(m.Content) < 14 {
 		return
 	}
 	if m.Content == "!blacklist get" {
-		blacklist, err := db.GetBlacklist()
+		blacklist, err := a.DB.GetBlacklist()
 		if err != nil {
-			logs.LogError("OWNER", "error getting blacklist",
+			a.Log.LogError("OWNER", "error getting blacklist",
 				"err", err)
 		}
 		if len(blacklist) == 0 {
@@ -278,9 +508,9 @@ func blacklistGet(s *discordgo.Session, m *discordgo.MessageCreate) {
 }
 
 // suggestions lists the 5 most recent suggestions
-func suggestions(s *discordgo.Session, m *discordgo.MessageCreate) {
+func suggestions(a *app.App, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Author.ID == s.State.User.ID ||
-		m.Author.ID != config.Values.Discord.OwnerID ||
+		m.Author.ID != a.Config().Discord.OwnerID ||
 		strings.Split(m.Content, " ")[0] != "!suggestions" {
 		return
 	}
@@ -293,9 +523,9 @@ func suggestions(s *discordgo.Session, m *discordgo.MessageCreate) {
 		s.ChannelMessageSend(m.ChannelID, "invalid command. `limit` shoult be an int")
 		return
 	}
-	suggestions, err := db.GetSuggestions(limit)
+	suggestions, err := a.DB.GetSuggestions(limit)
 	if err != nil {
-		logs.LogError("OWNER", "error getting suggestions",
+		a.Log.LogError("OWNER", "error getting suggestions",
 			"err", err)
 	}
 	if len(suggestions) == 0 {
@@ -7,80 +7,120 @@ package commands
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
+	"gamestreams/streams"
 	"gamestreams/utils"
 )
 
 // settings updates the bot settings for the server. It parses the options from the
 // interaction into an options struct. If the options are empty, it responds with the
 // current settings. If the reset option is true, it resets the settings to default.
+// If the preview option is true, an example announcement rendered with the server's
+// templates is shown after the settings.
 // If the options are not empty, it first gets the current settings from the database,
 // then merges the new settings with the current settings into a single struct. It then
 // writes the updated settings to the database and responds with the updated settings.
 // If an error occurs, it responds with an error message.
-func settings(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if userIsBlacklisted(i) {
+func settings(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
 		return
 	}
-	a := db.CommandData{}
-	a.Start(i)
-	defer a.End()
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
 
 	userID := discord.GetUserID(i)
-	logs.LogInfo(" CMND", "settings command", false,
+	a.Log.LogInfo(" CMND", "settings command", false,
 		"user", userID,
 		"server", i.GuildID)
 
+	locale := responseLocale(i, true)
 	options := parseOptions(i.ApplicationCommandData().Options)
+	publishersChanged, publisherStatus := updatePublishers(a, i.GuildID, i.ApplicationCommandData().Options, locale)
+	templateChanged, templateStatus := updateTemplate(a, i.GuildID, i.ApplicationCommandData().Options,
+		options.Reset, locale)
 
 	var status string
-	if *options == (db.Settings{}) || options.Reset {
-		status = "Current settings:"
+	if (*options == (db.Settings{}) && !publishersChanged && !templateChanged) || options.Reset {
+		status = locales.T(locale, "settings.current")
 	} else {
-		status = "Settings successfully updated.\n\n**Current settings:**"
+		status = locales.T(locale, "settings.updated")
 	}
 	if options.Reset {
 		*options = db.NewSettings(i.GuildID)
-		if optErr := options.Set(); optErr != nil {
-			logs.LogError(" CMND", "error resetting options",
+		if optErr := options.Set(a.DB); optErr != nil {
+			a.Log.LogError(" CMND", "error resetting options",
 				"server", i.GuildID,
 				"err", optErr)
 
-			status = "An error occurred. Settings may not have been reset."
+			status = locales.T(locale, "settings.reset_error")
 		}
 	}
 	var currentOptions = db.NewSettings(i.GuildID)
 
-	if getOptErr := currentOptions.Get(i.GuildID); getOptErr != nil {
-		logs.LogError(" CMND", "error getting options",
+	if getOptErr := currentOptions.Get(a.DB, i.GuildID); getOptErr != nil {
+		a.Log.LogError(" CMND", "error getting options",
 			"server", i.GuildID,
 			"err", getOptErr)
 
-		status = "An error occurred. Settings may have not been updated."
+		status = locales.T(locale, "settings.get_error")
+	}
+	// Refuse a new announce channel that the bot cannot post in, keeping the
+	// previous value so announcements are not silently lost.
+	if options.AnnounceChannel.Set {
+		check := discord.CheckChannel(s, i.GuildID, options.AnnounceChannel.Value)
+		if !check.Usable() {
+			options.AnnounceChannel = db.StringSet{}
+			status = locales.T(locale, "settings.channel_refused")
+		}
+	}
+	if publisherStatus != "" {
+		status = publisherStatus + "\n\n" + status
+	}
+	if templateStatus != "" {
+		status = templateStatus + "\n\n" + status
 	}
 	currentOptions.Merge(*options)
+	warnings := settingsWarnings(s, i.GuildID, currentOptions, locale)
+	publishers, pubErr := a.DB.GetServerPublishers(i.GuildID)
+	if pubErr != nil {
+		a.Log.LogError(" CMND", "error getting publishers",
+			"server", i.GuildID,
+			"err", pubErr)
+	}
+	messageTemplate, templateErr := a.DB.GetMessageTemplate(i.GuildID)
+	if templateErr != nil {
+		a.Log.LogError(" CMND", "error getting announcement template",
+			"server", i.GuildID,
+			"err", templateErr)
+	}
+	templateName := locales.T(locale, "common.default")
+	if messageTemplate.Custom() {
+		templateName = locales.T(locale, "common.custom")
+	}
 
 	content := []*discordgo.MessageEmbed{
 		{
-			Title:       "Settings",
+			Title:       locales.T(locale, "settings.title"),
 			Description: status,
-			Color:       config.Values.Discord.EmbedColour,
+			Color:       a.Config().Discord.EmbedColour,
 			Fields: []*discordgo.MessageEmbedField{
 				{},
 				{
-					Name:   "Announce Channel",
-					Value:  utils.PlaceholderText(fmt.Sprintf("<#%s>", currentOptions.AnnounceChannel.Value)),
+					Name:   locales.T(locale, "settings.channel"),
+					Value:  utils.PlaceholderText(fmt.Sprintf("<#%s>", currentOptions.AnnounceChannel.Value), locale),
 					Inline: false,
 				},
 				{
-					Name:   "Announce Role",
-					Value:  utils.PlaceholderText(discord.DisplayRole(s, i.GuildID, currentOptions.AnnounceRole.Value)),
+					Name:   locales.T(locale, "settings.role"),
+					Value:  utils.PlaceholderText(discord.DisplayRole(s, i.GuildID, currentOptions.AnnounceRole.Value), locale),
 					Inline: false,
 				},
 				{
@@ -108,31 +148,92 @@ func settings(s *discordgo.Session, i *discordgo.InteractionCreate) {
 					Value:  strconv.FormatBool(currentOptions.VR.Value),
 					Inline: false,
 				},
+				{
+					Name:   locales.T(locale, "settings.lead_time"),
+					Value:  locales.T(locale, "common.minutes", currentOptions.NotificationLead(a.Config().Schedule.NotificationTMinus)),
+					Inline: false,
+				},
+				{
+					Name:   locales.T(locale, "settings.events"),
+					Value:  strconv.FormatBool(currentOptions.ScheduledEvents.Value),
+					Inline: false,
+				},
+				{
+					Name:   locales.T(locale, "settings.crosspost"),
+					Value:  strconv.FormatBool(currentOptions.Crosspost.Value),
+					Inline: false,
+				},
+				{
+					Name:   locales.T(locale, "settings.publishers"),
+					Value:  utils.PlaceholderText(strings.Join(publishers, ", "), locale),
+					Inline: false,
+				},
+				{
+					Name:   locales.T(locale, "settings.template"),
+					Value:  templateName,
+					Inline: false,
+				},
 			},
 		},
 	}
-	settingsErr := currentOptions.Set()
+	if len(warnings) > 0 {
+		content[0].Fields = append(content[0].Fields, &discordgo.MessageEmbedField{
+			Name:   "\u200b\n⚠️ " + locales.T(locale, "settings.warnings"),
+			Value:  "- " + strings.Join(warnings, "\n- "),
+			Inline: false,
+		})
+	}
+	settingsErr := currentOptions.Set(a.DB)
 	if settingsErr != nil {
-		logs.LogError(" CMND", "error setting options",
+		a.Log.LogError(" CMND", "error setting options",
 			"server", i.GuildID,
 			"err", settingsErr)
 
 		content = []*discordgo.MessageEmbed{
 			{
-				Title:       "Settings",
-				Description: "An error occurred. Settings have not been updated.",
+				Title:       locales.T(locale, "settings.title"),
+				Description: locales.T(locale, "settings.set_error"),
 			},
 		}
+	} else {
+		// A new announce channel has been checked above, so the owner should be told
+		// again if it becomes unusable.
+		if options.AnnounceChannel.Set {
+			if healthErr := a.DB.SetChannelUsable(i.GuildID); healthErr != nil {
+				a.Log.LogError(" CMND", "error recording channel health",
+					"server", i.GuildID,
+					"err", healthErr)
+			}
+		}
+		if options.ScheduledEvents.Set || currentOptions.ScheduledEvents.Value {
+			a.Go("sync scheduled events", func() {
+				streams.SyncServerEvents(a, currentOptions)
+			})
+		}
+	}
+	var previewContent string
+	if optionSet(i.ApplicationCommandData().Options, "preview") {
+		// The preview is shown in the server's locale, as the announcements are.
+		preview, embeds, previewErr := streams.PreviewMessage(a, messageTemplate,
+			currentOptions.AnnounceRole.Value, responseLocale(i, false))
+		if previewErr != nil {
+			previewContent = locales.T(locale, "settings.preview_error", previewErr)
+		} else {
+			previewContent = locales.T(locale, "settings.preview") + "\n\n" + preview
+			content = append(content, embeds...)
+		}
 	}
 	respondErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Flags:  discordgo.MessageFlagsEphemeral,
-			Embeds: content,
+			Flags:           discordgo.MessageFlagsEphemeral,
+			Content:         previewContent,
+			Embeds:          content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
 	})
 	if respondErr != nil {
-		logs.LogError(" CMND", "error responding to interaction",
+		a.Log.LogError(" CMND", "error responding to interaction",
 			"cmd", i.ApplicationCommandData().Name,
 			"err", respondErr)
 	}
@@ -164,9 +265,152 @@ func parseOptions(options []*discordgo.ApplicationCommandInteractionDataOption)
 		case "vr":
 			s.VR.Value = option.BoolValue()
 			s.VR.Set = true
+		case "events":
+			s.ScheduledEvents.Value = option.BoolValue()
+			s.ScheduledEvents.Set = true
+		case "crosspost":
+			s.Crosspost.Value = option.BoolValue()
+			s.Crosspost.Set = true
+		case "lead_time":
+			s.LeadTime.Value = int(option.IntValue())
+			s.LeadTime.Set = true
 		case "reset":
 			s.Reset = option.BoolValue()
 		}
 	}
 	return &s
 }
+
+// updatePublishers follows or unfollows the publishers given in the follow_publisher
+// and unfollow_publisher options for the server. It returns true if either option was
+// given, and a status message in the given locale if a publisher could not be found or
+// updated.
+func updatePublishers(a *app.App, serverID string, options []*discordgo.ApplicationCommandInteractionDataOption, locale string) (bool, string) {
+	var changed bool
+	var problems []string
+	for _, option := range options {
+		if option.Name != "follow_publisher" && option.Name != "unfollow_publisher" {
+			continue
+		}
+		changed = true
+		publisher, found, findErr := a.DB.FindPublisher(option.StringValue())
+		if findErr != nil {
+			a.Log.LogError(" CMND", "error finding publisher",
+				"publisher", option.StringValue(),
+				"err", findErr)
+		}
+		if !found {
+			problems = append(problems, locales.T(locale, "settings.publisher_not_found",
+				option.StringValue()))
+			continue
+		}
+		var updateErr error
+		if option.Name == "follow_publisher" {
+			updateErr = a.DB.FollowPublisher(serverID, publisher.ID)
+		} else {
+			updateErr = a.DB.UnfollowPublisher(serverID, publisher.ID)
+		}
+		if updateErr != nil {
+			a.Log.LogError(" CMND", "error updating publisher",
+				"server", serverID,
+				"publisher", publisher.Name,
+				"err", updateErr)
+			problems = append(problems, locales.T(locale, "settings.publisher_error",
+				publisher.Name))
+		}
+	}
+	return changed, strings.Join(problems, "\n")
+}
+
+// updateTemplate updates the announcement templates of the server from the message,
+// embed_title, embed_description, embed_footer, thumbnail, show_platforms and
+// reset_template options. A template option of "default" resets that template. The
+// templates are checked before they are saved. It returns true if any option was given,
+// and a status message in the given locale if the templates were not updated.
+func updateTemplate(a *app.App, serverID string, options []*discordgo.ApplicationCommandInteractionDataOption, reset bool, locale string) (bool, string) {
+	t, getErr := a.DB.GetMessageTemplate(serverID)
+	if getErr != nil {
+		a.Log.LogError(" CMND", "error getting announcement template",
+			"server", serverID,
+			"err", getErr)
+		return true, locales.T(locale, "settings.template_error")
+	}
+	var changed bool
+	for _, option := range options {
+		value := ""
+		if option.Type == discordgo.ApplicationCommandOptionString &&
+			!strings.EqualFold(strings.TrimSpace(option.StringValue()), "default") {
+			value = option.StringValue()
+		}
+		switch option.Name {
+		case "message":
+			t.Content = value
+		case "embed_title":
+			t.Title = value
+		case "embed_description":
+			t.Description = value
+		case "embed_footer":
+			t.Footer = value
+		case "thumbnail":
+			t.HideThumbnail = !option.BoolValue()
+		case "show_platforms":
+			t.HidePlatforms = !option.BoolValue()
+		case "reset_template":
+			if option.BoolValue() {
+				reset = true
+			}
+		default:
+			continue
+		}
+		changed = true
+	}
+	if reset {
+		t = db.MessageTemplate{ServerID: serverID}
+		changed = true
+	}
+	if !changed {
+		return false, ""
+	}
+	if checkErr := streams.CheckMessageTemplate(a, t); checkErr != nil {
+		return true, locales.T(locale, "settings.template_invalid", checkErr)
+	}
+	if setErr := t.Set(a.DB); setErr != nil {
+		a.Log.LogError(" CMND", "error setting announcement template",
+			"server", serverID,
+			"err", setErr)
+		return true, locales.T(locale, "settings.template_error")
+	}
+	return true, ""
+}
+
+// optionSet returns true if the boolean option with the given name is given and true.
+func optionSet(options []*discordgo.ApplicationCommandInteractionDataOption, name string) bool {
+	for _, option := range options {
+		if option.Name == name && option.Type == discordgo.ApplicationCommandOptionBoolean {
+			return option.BoolValue()
+		}
+	}
+	return false
+}
+
+// settingsWarnings checks that the bot is able to post in the announce channel and
+// mention the announce role of the given settings, and that the announce channel can
+// be crossposted from if crossposting is enabled. It returns a slice of warnings in the
+// given locale describing any problems found.
+func settingsWarnings(s *discordgo.Session, guildID string, settings db.Settings, locale string) []string {
+	var warnings []string
+	if settings.AnnounceChannel.Value != "" {
+		check := discord.CheckChannel(s, guildID, settings.AnnounceChannel.Value)
+		warnings = append(warnings, check.Warnings(locale)...)
+		if settings.Crosspost.Value && check.Exists && !check.News {
+			warnings = append(warnings, locales.T(locale, "warning.crosspost"))
+		}
+	}
+	if settings.AnnounceRole.Value != "" {
+		if roleWarning := discord.CheckRole(s, guildID, settings.AnnounceChannel.Value,
+			settings.AnnounceRole.Value, locale); roleWarning != "" {
+			warnings = append(warnings, roleWarning)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/config"
+	"gamestreams/db"
+	"gamestreams/discordtest"
+	"gamestreams/locales"
+	"gamestreams/logs"
+)
+
+const (
+	testGuildID       = "200000000000000001"
+	testOwnerID       = "200000000000000002"
+	testUserID        = "200000000000000003"
+	testTextChannel   = "200000000000000010"
+	testNewsChannel   = "200000000000000011"
+	testLockedChannel = "200000000000000012"
+)
+
+// newTestApp returns an App with a new database that is connected to a fake Discord
+// server, with the command handlers registered and a server added to it.
+func newTestApp(t *testing.T) (*app.App, *discordtest.Server) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Discord.OwnerID = testOwnerID
+	cfg.Files.Database = filepath.Join(t.TempDir(), "test.db")
+
+	server := discordtest.New()
+	t.Cleanup(server.Close)
+	session, sessionErr := server.Session()
+	if sessionErr != nil {
+		t.Fatalf("Session() error = %v", sessionErr)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	a := app.New(cfg, logs.New(cfg.Logs))
+	if openErr := a.OpenDB(); openErr != nil {
+		t.Fatalf("OpenDB() error = %v", openErr)
+	}
+	a.Session = session
+	RegisterHandler(a)
+
+	if serverErr := a.DB.NewServer(testGuildID, "Test Server", testOwnerID, time.Now().UTC(),
+		3, "en-US"); serverErr != nil {
+		t.Fatalf("a.DB.NewServer() error = %v", serverErr)
+	}
+	usable := int64(discordgo.PermissionViewChannel | discordgo.PermissionSendMessages |
+		discordgo.PermissionEmbedLinks)
+	guild := &discordgo.Guild{
+		ID:      testGuildID,
+		Name:    "Test Server",
+		OwnerID: testOwnerID,
+		Roles: []*discordgo.Role{
+			{ID: testGuildID, Name: "@everyone", Permissions: usable},
+		},
+		Members: []*discordgo.Member{
+			{GuildID: testGuildID, User: server.User},
+		},
+		Channels: []*discordgo.Channel{
+			{ID: testTextChannel, Name: "streams", Type: discordgo.ChannelTypeGuildText},
+			{ID: testNewsChannel, Name: "news", Type: discordgo.ChannelTypeGuildNews},
+			{ID: testLockedChannel, Name: "locked", Type: discordgo.ChannelTypeGuildText,
+				PermissionOverwrites: []*discordgo.PermissionOverwrite{{
+					ID:   testGuildID,
+					Type: discordgo.PermissionOverwriteTypeRole,
+					Deny: discordgo.PermissionSendMessages,
+				}}},
+		},
+	}
+	if addErr := server.AddGuild(guild); addErr != nil {
+		t.Fatalf("AddGuild() error = %v", addErr)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, stateErr := session.State.Guild(testGuildID); stateErr == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the server to be added to the session state")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return a, server
+}
+
+// settingsResponse runs /settings with the options and returns the embed of the
+// response.
+func settingsResponse(t *testing.T, server *discordtest.Server,
+	options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	t.Helper()
+	i, commandErr := server.Command(testGuildID, testTextChannel, testUserID, "settings", options...)
+	if commandErr != nil {
+		t.Fatalf("Command() error = %v", commandErr)
+	}
+	responses, waitErr := server.WaitForResponses(i, 1, 5*time.Second)
+	if waitErr != nil {
+		t.Fatal(waitErr)
+	}
+	if len(responses[0].Embeds) == 0 {
+		t.Fatal("settings response has no embeds")
+	}
+	return responses[0].Embeds[0]
+}
+
+// field returns the value of the field of the embed with the name, and whether it was
+// found.
+func field(embed *discordgo.MessageEmbed, name string) (string, bool) {
+	for _, f := range embed.Fields {
+		if strings.Contains(f.Name, name) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestSettingsSaved(t *testing.T) {
+	a, server := newTestApp(t)
+	locale := string(discordgo.EnglishUS)
+
+	embed := settingsResponse(t, server,
+		discordtest.Option("channel", testNewsChannel),
+		discordtest.Option("xbox", true),
+		discordtest.Option("crosspost", true),
+		discordtest.Option("lead_time", 15))
+
+	if !strings.Contains(embed.Description, locales.T(locale, "settings.updated")) {
+		t.Errorf("description = %q, want it to say the settings were updated", embed.Description)
+	}
+	if channel, _ := field(embed, locales.T(locale, "settings.channel")); channel != "<#"+testNewsChannel+">" {
+		t.Errorf("channel field = %q, want <#%s>", channel, testNewsChannel)
+	}
+	if xbox, _ := field(embed, "Xbox"); xbox != "true" {
+		t.Errorf("Xbox field = %q, want true", xbox)
+	}
+	if _, hasWarnings := field(embed, locales.T(locale, "settings.warnings")); hasWarnings {
+		t.Error("response has warnings for a usable announcement channel")
+	}
+
+	saved := db.NewSettings(testGuildID)
+	if getErr := saved.Get(a.DB, testGuildID); getErr != nil {
+		t.Fatalf("Get() error = %v", getErr)
+	}
+	if saved.AnnounceChannel.Value != testNewsChannel || !saved.Xbox.Value ||
+		!saved.Crosspost.Value || saved.LeadTime.Value != 15 || saved.Playstation.Value {
+		t.Errorf("saved settings = %+v", saved)
+	}
+}
+
+func TestSettingsCrosspostWarning(t *testing.T) {
+	a, server := newTestApp(t)
+	locale := string(discordgo.EnglishUS)
+
+	embed := settingsResponse(t, server,
+		discordtest.Option("channel", testTextChannel),
+		discordtest.Option("crosspost", true))
+
+	warnings, hasWarnings := field(embed, locales.T(locale, "settings.warnings"))
+	if !hasWarnings || !strings.Contains(warnings, locales.T(locale, "warning.crosspost")) {
+		t.Errorf("warnings = %q, want a warning that crossposting needs an announcement channel",
+			warnings)
+	}
+	saved := db.NewSettings(testGuildID)
+	if getErr := saved.Get(a.DB, testGuildID); getErr != nil {
+		t.Fatalf("Get() error = %v", getErr)
+	}
+	if saved.AnnounceChannel.Value != testTextChannel || !saved.Crosspost.Value {
+		t.Errorf("saved settings = %+v", saved)
+	}
+}
+
+func TestSettingsRefusesUnusableChannel(t *testing.T) {
+	a, server := newTestApp(t)
+	locale := string(discordgo.EnglishUS)
+
+	settingsResponse(t, server, discordtest.Option("channel", testTextChannel))
+	embed := settingsResponse(t, server, discordtest.Option("channel", testLockedChannel))
+
+	if !strings.Contains(embed.Description, locales.T(locale, "settings.channel_refused")) {
+		t.Errorf("description = %q, want the channel to be refused", embed.Description)
+	}
+	saved := db.NewSettings(testGuildID)
+	if getErr := saved.Get(a.DB, testGuildID); getErr != nil {
+		t.Fatalf("Get() error = %v", getErr)
+	}
+	if saved.AnnounceChannel.Value != testTextChannel {
+		t.Errorf("announce channel = %q, want the previous channel %s to be kept",
+			saved.AnnounceChannel.Value, testTextChannel)
+	}
+}
+
+func TestSettingsResetsChannelHealth(t *testing.T) {
+	a, server := newTestApp(t)
+
+	settingsResponse(t, server, discordtest.Option("channel", testTextChannel))
+	if changed, setErr := a.DB.SetChannelUnusable(testGuildID); setErr != nil || !changed {
+		t.Fatalf("SetChannelUnusable() = %t, %v, want true", changed, setErr)
+	}
+
+	// Refusing a channel leaves the recorded health unchanged.
+	settingsResponse(t, server, discordtest.Option("channel", testLockedChannel))
+	if changed, _ := a.DB.SetChannelUnusable(testGuildID); changed {
+		t.Error("refused channel reset the channel health")
+	}
+
+	settingsResponse(t, server, discordtest.Option("channel", testNewsChannel))
+	if changed, _ := a.DB.SetChannelUnusable(testGuildID); !changed {
+		t.Error("saving a usable channel did not reset the channel health")
+	}
+}
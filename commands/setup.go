@@ -0,0 +1,396 @@
+/*
+setup.go provides the /setup command and the setup wizard. The wizard guides server
+owners through choosing an announce channel, role, platforms and lead time using select
+menus and buttons. It can be launched from the /setup command or from the button in the
+introductory DM sent when the bot joins a server.
+*/
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/discord"
+	"gamestreams/locales"
+	"gamestreams/servers"
+	"gamestreams/utils"
+)
+
+// platformOptions are the platforms that can be chosen in the setup wizard.
+var platformOptions = []string{"PlayStation", "Xbox", "Nintendo", "PC", "VR"}
+
+// setup responds to the /setup command with the setup wizard for the server.
+func setup(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
+		return
+	}
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
+
+	userID := discord.GetUserID(i)
+	locale := responseLocale(i, true)
+	a.Log.LogInfo(" CMND", "setup command", false,
+		"user", userID,
+		"server", i.GuildID)
+
+	settings := db.NewSettings(i.GuildID)
+	if getErr := settings.Get(a.DB, i.GuildID); getErr != nil {
+		a.Log.LogError(" CMND", "error getting settings",
+			"server", i.GuildID,
+			"err", getErr)
+	}
+	respondErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Embeds:     []*discordgo.MessageEmbed{setupEmbed(a, s, settings, "", locale)},
+			Components: setupComponents(a, s, settings, true, locale),
+		},
+	})
+	if respondErr != nil {
+		a.Log.LogError(" CMND", "error responding to interaction",
+			"cmd", i.ApplicationCommandData().Name,
+			"err", respondErr)
+	}
+}
+
+// setupComponent handles the select menus and buttons of the setup wizard. The custom
+// ID of each component is in the format setup:<step>:<server ID>. When the wizard is
+// used from a DM only the owner of the server is able to change its settings. Each
+// selection is saved to the database immediately so progress is not lost.
+func setupComponent(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	parts := strings.Split(data.CustomID, ":")
+	if len(parts) != 3 {
+		return
+	}
+	step, serverID := parts[1], parts[2]
+	userID := discord.GetUserID(i)
+	inGuild := i.GuildID != ""
+	locale := responseLocale(i, true)
+
+	if blacklisted, _ := a.DB.IsBlacklisted(userID); blacklisted {
+		return
+	}
+	if !inGuild && servers.GetServerOwner(a, s, serverID) != userID {
+		respondComponent(a, s, i, discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: locales.T(locale, "setup.owner_only"),
+			})
+		return
+	}
+	a.Log.LogInfo(" CMND", "setup wizard", false,
+		"step", step,
+		"user", userID,
+		"server", serverID)
+
+	settings := db.NewSettings(serverID)
+	if getErr := settings.Get(a.DB, serverID); getErr != nil {
+		a.Log.LogError(" CMND", "error getting settings",
+			"server", serverID,
+			"err", getErr)
+		return
+	}
+
+	var status string
+	var update db.Settings
+	switch step {
+	case "start":
+		respondComponent(a, s, i, discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{setupEmbed(a, s, settings, "", locale)},
+				Components: setupComponents(a, s, settings, inGuild, locale),
+			})
+		return
+	case "channel":
+		if len(data.Values) > 0 {
+			if discord.CheckChannel(s, serverID, data.Values[0]).Usable() {
+				update.AnnounceChannel = db.StringSet{Value: data.Values[0], Set: true}
+			} else {
+				status = locales.T(locale, "setup.channel_refused")
+			}
+		}
+	case "role":
+		update.AnnounceRole = db.StringSet{Set: true}
+		if len(data.Values) > 0 {
+			update.AnnounceRole.Value = data.Values[0]
+		}
+	case "platforms":
+		selected := utils.RemoveSliceDuplicates(data.Values)
+		update.Playstation = db.BoolSet{Value: selected["PlayStation"], Set: true}
+		update.Xbox = db.BoolSet{Value: selected["Xbox"], Set: true}
+		update.Nintendo = db.BoolSet{Value: selected["Nintendo"], Set: true}
+		update.PC = db.BoolSet{Value: selected["PC"], Set: true}
+		update.VR = db.BoolSet{Value: selected["VR"], Set: true}
+	case "lead":
+		if len(data.Values) > 0 {
+			minutes, convErr := strconv.Atoi(data.Values[0])
+			if convErr == nil {
+				update.LeadTime = db.IntSet{Value: minutes, Set: true}
+			}
+		}
+	case "done":
+		status = locales.T(locale, "setup.complete")
+		if !settings.Configured() {
+			status = locales.T(locale, "setup.incomplete")
+		}
+		respondComponent(a, s, i, discordgo.InteractionResponseUpdateMessage,
+			&discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{setupEmbed(a, s, settings, status, locale)},
+				Components: []discordgo.MessageComponent{},
+			})
+		return
+	default:
+		return
+	}
+
+	settings.Merge(update)
+	if setErr := settings.Set(a.DB); setErr != nil {
+		a.Log.LogError(" CMND", "error setting options",
+			"server", serverID,
+			"err", setErr)
+		status = locales.T(locale, "setup.error")
+	}
+	respondComponent(a, s, i, discordgo.InteractionResponseUpdateMessage,
+		&discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{setupEmbed(a, s, settings, status, locale)},
+			Components: setupComponents(a, s, settings, inGuild, locale),
+		})
+}
+
+// respondComponent responds to a component interaction with the given response type
+// and data. If an error occurs, it logs the error.
+func respondComponent(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate, t discordgo.InteractionResponseType, data *discordgo.InteractionResponseData) {
+	respondErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: t,
+		Data: data,
+	})
+	if respondErr != nil {
+		a.Log.LogError(" CMND", "error responding to interaction",
+			"component", i.MessageComponentData().CustomID,
+			"err", respondErr)
+	}
+}
+
+// setupEmbed returns an embed in the given locale showing the current settings of the
+// server and any warnings about the announce channel or role. The status is shown at the
+// top of the embed if it is not empty.
+func setupEmbed(a *app.App, s *discordgo.Session, settings db.Settings, status string, locale string) *discordgo.MessageEmbed {
+	description := locales.T(locale, "setup.description")
+	if status != "" {
+		description = fmt.Sprintf("**%s**\n\n%s", status, description)
+	}
+	var platforms []string
+	for _, platform := range platformOptions {
+		if platformSelected(settings, platform) {
+			platforms = append(platforms, platform)
+		}
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       locales.T(locale, "setup.title", servers.GetServerName(a, s, settings.ServerID)),
+		Description: description,
+		Color:       a.Config().Discord.EmbedColour,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   locales.T(locale, "settings.channel"),
+				Value:  utils.PlaceholderText(fmt.Sprintf("<#%s>", settings.AnnounceChannel.Value), locale),
+				Inline: true,
+			},
+			{
+				Name:   locales.T(locale, "settings.role"),
+				Value:  utils.PlaceholderText(discord.DisplayRole(s, settings.ServerID, settings.AnnounceRole.Value), locale),
+				Inline: true,
+			},
+			{
+				Name:   locales.T(locale, "field.platforms"),
+				Value:  utils.PlaceholderText(strings.Join(platforms, ", "), locale),
+				Inline: false,
+			},
+			{
+				Name:   locales.T(locale, "settings.lead_time"),
+				Value:  locales.T(locale, "common.minutes", settings.NotificationLead(a.Config().Schedule.NotificationTMinus)),
+				Inline: false,
+			},
+		},
+	}
+	if warnings := settingsWarnings(s, settings.ServerID, settings, locale); len(warnings) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "\u200b\n⚠️ " + locales.T(locale, "settings.warnings"),
+			Value:  "- " + strings.Join(warnings, "\n- "),
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// setupComponents returns the select menus and buttons of the setup wizard, labelled in
+// the given locale. In a server the channel and role menus are populated by Discord. In a
+// DM they are populated from the server's channels and roles as Discord cannot populate
+// them outside the server.
+func setupComponents(a *app.App, s *discordgo.Session, settings db.Settings, inGuild bool, locale string) []discordgo.MessageComponent {
+	serverID := settings.ServerID
+	zero := 0
+
+	var channelMenu, roleMenu discordgo.SelectMenu
+	if inGuild {
+		channelMenu = discordgo.SelectMenu{
+			MenuType:     discordgo.ChannelSelectMenu,
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+		}
+		roleMenu = discordgo.SelectMenu{
+			MenuType: discordgo.RoleSelectMenu,
+		}
+		if settings.AnnounceChannel.Value != "" {
+			channelMenu.DefaultValues = []discordgo.SelectMenuDefaultValue{
+				{ID: settings.AnnounceChannel.Value, Type: discordgo.SelectMenuDefaultValueChannel},
+			}
+		}
+		if settings.AnnounceRole.Value != "" {
+			roleMenu.DefaultValues = []discordgo.SelectMenuDefaultValue{
+				{ID: settings.AnnounceRole.Value, Type: discordgo.SelectMenuDefaultValueRole},
+			}
+		}
+	} else {
+		channelMenu = discordgo.SelectMenu{
+			MenuType: discordgo.StringSelectMenu,
+			Options:  channelOptions(s, settings),
+		}
+		roleMenu = discordgo.SelectMenu{
+			MenuType: discordgo.StringSelectMenu,
+			Options:  roleOptions(s, settings),
+		}
+	}
+	channelMenu.CustomID = "setup:channel:" + serverID
+	channelMenu.Placeholder = locales.T(locale, "setup.channel_placeholder")
+	roleMenu.CustomID = "setup:role:" + serverID
+	roleMenu.Placeholder = locales.T(locale, "setup.role_placeholder")
+	roleMenu.MinValues = &zero
+	roleMenu.MaxValues = 1
+	// Discord rejects string select menus without any options.
+	channelMenu.Disabled = !inGuild && len(channelMenu.Options) == 0
+	if channelMenu.Disabled {
+		channelMenu.Options = []discordgo.SelectMenuOption{{Label: locales.T(locale, "setup.no_channels"), Value: "none"}}
+	}
+	roleMenu.Disabled = !inGuild && len(roleMenu.Options) == 0
+	if roleMenu.Disabled {
+		roleMenu.Options = []discordgo.SelectMenuOption{{Label: locales.T(locale, "setup.no_roles"), Value: "none"}}
+	}
+
+	var platformMenuOptions []discordgo.SelectMenuOption
+	for _, platform := range platformOptions {
+		platformMenuOptions = append(platformMenuOptions, discordgo.SelectMenuOption{
+			Label:   platform,
+			Value:   platform,
+			Default: platformSelected(settings, platform),
+		})
+	}
+	var leadOptions []discordgo.SelectMenuOption
+	for _, minutes := range leadTimes {
+		leadOptions = append(leadOptions, discordgo.SelectMenuOption{
+			Label:   locales.T(locale, "setup.lead_option", minutes),
+			Value:   strconv.Itoa(minutes),
+			Default: settings.NotificationLead(a.Config().Schedule.NotificationTMinus) == minutes,
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{channelMenu}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{roleMenu}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    "setup:platforms:" + serverID,
+				Placeholder: locales.T(locale, "setup.platforms_placeholder"),
+				MinValues:   &zero,
+				MaxValues:   len(platformOptions),
+				Options:     platformMenuOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    "setup:lead:" + serverID,
+				Placeholder: locales.T(locale, "setup.lead_placeholder"),
+				Options:     leadOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    locales.T(locale, "setup.done"),
+				Style:    discordgo.SuccessButton,
+				CustomID: "setup:done:" + serverID,
+			},
+		}},
+	}
+}
+
+// channelOptions returns up to 25 select menu options for the text channels in the
+// server that the bot is able to post in.
+func channelOptions(s *discordgo.Session, settings db.Settings) []discordgo.SelectMenuOption {
+	var options []discordgo.SelectMenuOption
+	guild, err := s.State.Guild(settings.ServerID)
+	if err != nil {
+		return options
+	}
+	for _, channel := range guild.Channels {
+		if len(options) == 25 {
+			break
+		}
+		if !discord.CheckChannel(s, settings.ServerID, channel.ID).Usable() {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   "#" + channel.Name,
+			Value:   channel.ID,
+			Default: channel.ID == settings.AnnounceChannel.Value,
+		})
+	}
+	return options
+}
+
+// roleOptions returns up to 25 select menu options for the roles in the server that
+// are not managed by an integration.
+func roleOptions(s *discordgo.Session, settings db.Settings) []discordgo.SelectMenuOption {
+	var options []discordgo.SelectMenuOption
+	guild, err := s.State.Guild(settings.ServerID)
+	if err != nil {
+		return options
+	}
+	for _, role := range guild.Roles {
+		if len(options) == 25 {
+			break
+		}
+		if role.Managed {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   role.Name,
+			Value:   role.ID,
+			Default: role.ID == settings.AnnounceRole.Value,
+		})
+	}
+	return options
+}
+
+// platformSelected returns true if the given platform is followed in the settings.
+func platformSelected(settings db.Settings, platform string) bool {
+	switch platform {
+	case "PlayStation":
+		return settings.Playstation.Value
+	case "Xbox":
+		return settings.Xbox.Value
+	case "Nintendo":
+		return settings.Nintendo.Value
+	case "PC":
+		return settings.PC.Value
+	case "VR":
+		return settings.VR.Value
+	}
+	return false
+}
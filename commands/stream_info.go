@@ -6,10 +6,10 @@ package commands
 import (
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
 	"gamestreams/streams"
 )
 
@@ -18,36 +18,37 @@ import (
 // If the stream is found, it creates an embed with the stream information and responds
 // to the interaction with the embed. If the stream is not found or an error occurs,
 // it responds with an error message.
-func streamInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if userIsBlacklisted(i) {
+func streamInfo(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
 		return
 	}
-	a := db.CommandData{}
-	a.Start(i)
-	defer a.End()
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
 
 	streamName := i.ApplicationCommandData().Options[0].StringValue()
 	userID := discord.GetUserID(i)
-	logs.LogInfo(" CMND", "stream info command", false,
+	a.Log.LogInfo(" CMND", "stream info command", false,
 		"stream", streamName,
 		"user", userID,
 		"server", i.GuildID)
 
-	embed, infoErr := streams.StreamInfo(streamName)
+	locale := responseLocale(i, false)
+	embed, infoErr := streams.StreamInfo(a, streamName, locale)
 	if infoErr != nil {
 		if infoErr.Error() == "no streams found" {
 			embed = &discordgo.MessageEmbed{
-				Title:       "Stream Info",
-				Description: "No streams found with that name",
-				Color:       config.Values.Discord.EmbedColour,
+				Title:       locales.T(locale, "streaminfo.title"),
+				Description: locales.T(locale, "streaminfo.none"),
+				Color:       a.Config().Discord.EmbedColour,
 			}
 		} else {
-			logs.LogError(" CMND", "error creating embeds",
+			a.Log.LogError(" CMND", "error creating embeds",
 				"err", infoErr)
 			embed = &discordgo.MessageEmbed{
-				Title:       "Stream Info",
-				Description: "An error occurred",
-				Color:       config.Values.Discord.EmbedColour,
+				Title:       locales.T(locale, "streaminfo.title"),
+				Description: locales.T(locale, "common.error_occurred"),
+				Color:       a.Config().Discord.EmbedColour,
 			}
 		}
 	}
@@ -58,7 +59,7 @@ func streamInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if respondErr != nil {
-		logs.LogError(" CMND", "error responding to interaction",
+		a.Log.LogError(" CMND", "error responding to interaction",
 			"cmd", i.ApplicationCommandData().Name,
 			"err", respondErr)
 	}
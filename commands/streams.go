@@ -6,10 +6,10 @@ package commands
 import (
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
 	"gamestreams/streams"
 )
 
@@ -17,34 +17,35 @@ import (
 // successfully created, it responds to the interaction with the embed. If an error
 // occurs, indicating no upcoming streams or an error creating the embed, it responds
 // with an error message.
-func listStreams(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if userIsBlacklisted(i) {
+func listStreams(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
 		return
 	}
-	a := db.CommandData{}
-	a.Start(i)
-	defer a.End()
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
 
 	userID := discord.GetUserID(i)
-	logs.LogInfo(" CMND", "list streams command", false,
+	a.Log.LogInfo(" CMND", "list streams command", false,
 		"user", userID,
 		"server", i.GuildID)
 
-	embed, listErr := streams.StreamList()
+	locale := responseLocale(i, false)
+	embed, listErr := streams.StreamList(a, locale)
 	if listErr != nil {
 		if listErr.Error() == "no streams found" {
 			embed = &discordgo.MessageEmbed{
-				Title:       "Upcoming Streams",
-				Description: "No streams found",
-				Color:       config.Values.Discord.EmbedColour,
+				Title:       locales.T(locale, "streams.title"),
+				Description: locales.T(locale, "streams.none"),
+				Color:       a.Config().Discord.EmbedColour,
 			}
 		} else {
-			logs.LogError(" CMND", "error creating embeds",
+			a.Log.LogError(" CMND", "error creating embeds",
 				"err", listErr)
 			embed = &discordgo.MessageEmbed{
-				Title:       "Upcoming Streams",
-				Description: "An error occurred",
-				Color:       config.Values.Discord.EmbedColour,
+				Title:       locales.T(locale, "streams.title"),
+				Description: locales.T(locale, "common.error_occurred"),
+				Color:       a.Config().Discord.EmbedColour,
 			}
 		}
 	}
@@ -55,7 +56,7 @@ func listStreams(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if respondErr != nil {
-		logs.LogError(" CMND", "error responding to interaction",
+		a.Log.LogError(" CMND", "error responding to interaction",
 			"cmd", i.ApplicationCommandData().Name,
 			"err", respondErr)
 	}
@@ -6,10 +6,10 @@ package commands
 import (
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
 )
 
 // suggest allows users to suggest a stream to be added to the database. It extracts the
@@ -17,39 +17,40 @@ import (
 // suggestion is successfully created, it inserts the suggestion into the database and
 // responds to the interaction with a success message. If an error occurs, it responds
 // with an error message. A suggestion limit is defined in config.toml.
-func suggest(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if userIsBlacklisted(i) {
+func suggest(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
 		return
 	}
-	a := db.CommandData{}
-	a.Start(i)
-	defer a.End()
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
 	userID := discord.GetUserID(i)
-	logs.LogInfo(" CMND", "suggest command", false,
+	a.Log.LogInfo(" CMND", "suggest command", false,
 		"user", userID,
 		"server", i.GuildID)
 
+	locale := responseLocale(i, true)
 	// Check if the user has reached the daily limit for suggestions
-	suggestionsToday, countErr := db.CountSuggestions(userID, 1)
+	suggestionsToday, countErr := a.DB.CountSuggestions(userID, 1)
 	if countErr != nil {
-		logs.LogError(" CMND", "error counting suggestions",
+		a.Log.LogError(" CMND", "error counting suggestions",
 			"user", userID,
 			"err", countErr)
 	}
-	if suggestionsToday >= config.Values.Suggestions.DailyLimit {
+	if suggestionsToday >= a.Config().Suggestions.DailyLimit {
 		embed := &discordgo.MessageEmbed{
-			Title:       "Error",
-			Description: "You have reached the daily suggestion limit. Try again tomorrow.",
-			Color:       config.Values.Discord.EmbedColour,
+			Title:       locales.T(locale, "common.error"),
+			Description: locales.T(locale, "suggest.limit"),
+			Color:       a.Config().Discord.EmbedColour,
 		}
-		respond(s, i, embed)
+		respond(a, s, i, embed)
 		return
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "Thank you",
-		Description: "Your suggestion has been received",
-		Color:       config.Values.Discord.EmbedColour,
+		Title:       locales.T(locale, "suggest.thanks_title"),
+		Description: locales.T(locale, "suggest.thanks"),
+		Color:       a.Config().Discord.EmbedColour,
 	}
 	streamName := i.ApplicationCommandData().Options[0].StringValue()
 	streamDate := i.ApplicationCommandData().Options[1].StringValue()
@@ -58,30 +59,30 @@ func suggest(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	suggestion, suggestErr := db.NewSuggestion(streamName, streamDate, streamURL)
 	if suggestErr != nil {
 		embed = &discordgo.MessageEmbed{
-			Title:       "Error",
+			Title:       locales.T(locale, "common.error"),
 			Description: suggestErr.Error(),
-			Color:       config.Values.Discord.EmbedColour,
+			Color:       a.Config().Discord.EmbedColour,
 		}
-		respond(s, i, embed)
+		respond(a, s, i, embed)
 		return
 	}
-	suggestion.CommandID = a.CommandID
-	insertErr := suggestion.Insert()
+	suggestion.CommandID = usage.CommandID
+	insertErr := suggestion.Insert(a.DB)
 	if insertErr != nil {
-		logs.LogError(" CMND", "error inserting suggestion",
+		a.Log.LogError(" CMND", "error inserting suggestion",
 			"err", insertErr)
 		embed = &discordgo.MessageEmbed{
-			Title:       "Error",
-			Description: "**An error occurred.** Your suggestion may not have been recieved.",
-			Color:       config.Values.Discord.EmbedColour,
+			Title:       locales.T(locale, "common.error"),
+			Description: locales.T(locale, "suggest.error"),
+			Color:       a.Config().Discord.EmbedColour,
 		}
 	}
-	respond(s, i, embed)
+	respond(a, s, i, embed)
 }
 
 // respond sends a response to the interaction with the provided embed. If an error
 // occurs, it logs the error.
-func respond(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+func respond(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
 	respondErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -90,7 +91,7 @@ func respond(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discor
 		},
 	})
 	if respondErr != nil {
-		logs.LogError(" CMND", "error responding to interaction",
+		a.Log.LogError(" CMND", "error responding to interaction",
 			"cmd", i.ApplicationCommandData().Name,
 			"err", respondErr)
 	}
@@ -0,0 +1,146 @@
+/*
+webhooks.go provides the /webhooks command. The webhooks command allows server owners to
+register webhooks that stream announcements are delivered to, list them, remove them and
+enable them again after they have been disabled for failing.
+*/
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/discord"
+	"gamestreams/locales"
+	"gamestreams/streams"
+)
+
+// webhooks adds, removes or enables a webhook for the server, depending on the options
+// given, then responds with the webhooks registered by the server. The secret of a new
+// JSON webhook is only shown in the response to the command that added it.
+func webhooks(a *app.App, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if userIsBlacklisted(a, s, i) {
+		return
+	}
+	usage := db.CommandData{}
+	usage.Start(a.DB, i)
+	defer usage.End(a.DB)
+
+	userID := discord.GetUserID(i)
+	a.Log.LogInfo(" CMND", "webhooks command", false,
+		"user", userID,
+		"server", i.GuildID)
+
+	locale := responseLocale(i, true)
+	var addURL, kind, platform string
+	var leadTime, remove, enable int
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "add":
+			addURL = option.StringValue()
+		case "type":
+			kind = option.StringValue()
+		case "platform":
+			platform = option.StringValue()
+		case "lead_time":
+			leadTime = int(option.IntValue())
+		case "remove":
+			remove = int(option.IntValue())
+		case "enable":
+			enable = int(option.IntValue())
+		}
+	}
+
+	var status string
+	switch {
+	case addURL != "":
+		status = addWebhook(a, i.GuildID, kind, addURL, platform, leadTime, locale)
+	case remove != 0:
+		removed, deleteErr := a.DB.DeleteWebhook(remove, i.GuildID)
+		status = webhookUpdateStatus(a, removed, deleteErr, "webhooks.removed", remove, locale)
+	case enable != 0:
+		enabled, enableErr := a.DB.EnableWebhook(enable, i.GuildID)
+		status = webhookUpdateStatus(a, enabled, enableErr, "webhooks.enabled", enable, locale)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       locales.T(locale, "webhooks.title"),
+		Description: webhookList(a, i.GuildID, locale),
+		Color:       a.Config().Discord.EmbedColour,
+	}
+	if status != "" {
+		embed.Description = status + "\n\n" + embed.Description
+	}
+	respond(a, s, i, embed)
+}
+
+// addWebhook registers a webhook for the server if it has not reached the webhook limit
+// set in config.toml and the URL can be used, and returns the status to show the user.
+func addWebhook(a *app.App, serverID string, kind string, rawURL string, platform string, leadTime int, locale string) string {
+	count, countErr := a.DB.CountServerWebhooks(serverID)
+	if countErr != nil {
+		a.Log.LogError(" CMND", "error counting webhooks",
+			"server", serverID,
+			"err", countErr)
+		return locales.T(locale, "webhooks.add_error")
+	}
+	if limit := streams.WebhookServerLimit(a); count >= limit {
+		return locales.T(locale, "webhooks.limit", limit)
+	}
+	w, newErr := streams.NewWebhook(serverID, kind, rawURL, platform, leadTime)
+	if newErr != nil {
+		return locales.T(locale, "webhooks.invalid", newErr.Error())
+	}
+	if insertErr := w.Insert(a.DB); insertErr != nil {
+		a.Log.LogError(" CMND", "error adding webhook",
+			"server", serverID,
+			"err", insertErr)
+		return locales.T(locale, "webhooks.add_error")
+	}
+	a.Log.LogInfo(" CMND", "added webhook", false,
+		"server", serverID,
+		"webhook", w.ID,
+		"kind", w.Kind)
+	status := locales.T(locale, "webhooks.added", w.ID)
+	if w.Secret != "" {
+		status += "\n" + locales.T(locale, "webhooks.secret", w.Secret)
+	}
+	return status
+}
+
+// webhookUpdateStatus returns the status to show the user after removing or enabling a
+// webhook.
+func webhookUpdateStatus(a *app.App, updated bool, updateErr error, key string, id int, locale string) string {
+	if updateErr != nil {
+		a.Log.LogError(" CMND", "error updating webhook",
+			"webhook", id,
+			"err", updateErr)
+		return locales.T(locale, "webhooks.error")
+	}
+	if !updated {
+		return locales.T(locale, "webhooks.not_found", id)
+	}
+	return locales.T(locale, key, id)
+}
+
+// webhookList returns a description of each webhook registered by the server, or a
+// message explaining how to add one if there are none.
+func webhookList(a *app.App, serverID string, locale string) string {
+	registered, getErr := a.DB.GetWebhooks(serverID)
+	if getErr != nil {
+		a.Log.LogError(" CMND", "error getting webhooks",
+			"server", serverID,
+			"err", getErr)
+		return locales.T(locale, "common.error_occurred")
+	}
+	if len(registered) == 0 {
+		return locales.T(locale, "webhooks.none")
+	}
+	var descriptions []string
+	for _, w := range registered {
+		descriptions = append(descriptions, streams.DescribeWebhook(a, w, locale))
+	}
+	return locales.T(locale, "webhooks.registered") + "\n" + strings.Join(descriptions, "\n\n")
+}
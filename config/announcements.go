@@ -0,0 +1,24 @@
+package config
+
+// Announcements is a struct that holds the configuration values for posting stream
+// announcements to servers.
+type Announcements struct {
+	// The number of announcements that are posted at the same time.
+	Workers int `toml:"workers"`
+	// The number of times a failed post is retried.
+	Retries int `toml:"retries"`
+	// The number of times the retry schedule reposts an announcement that failed to
+	// post before giving up.
+	RetryLimit int `toml:"retry_limit"`
+	// The number of minutes after a stream starts that a failed announcement can still
+	// be retried.
+	RetryWindowMinutes int `toml:"retry_window_minutes"`
+	// The Discord ID of an announcement channel that every announceable stream is
+	// posted and crossposted in, so that servers can follow it instead of configuring
+	// the bot. Leave empty to disable. The channel's server should not also have
+	// announcements set up, as a server only records one announcement of each stream.
+	PublicChannel string `toml:"public_channel"`
+	// An invite to the server of the public announcement channel, shown in the help
+	// command so users can follow the channel.
+	PublicInvite string `toml:"public_invite"`
+}
@@ -8,4 +8,7 @@ type Bot struct {
 	ReleaseDate string `toml:"release_date"`
 	// Flag to determine if the bot should restore the database from a backup.
 	RestoreDatabase bool `toml:"restore_database"`
+	// The number of seconds to wait for running jobs and announcements to finish when
+	// the bot shuts down. Defaults to 30.
+	ShutdownTimeoutSeconds int `toml:"shutdown_timeout_seconds"`
 }
@@ -2,16 +2,12 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"runtime"
 
 	"github.com/BurntSushi/toml"
 )
 
-// Values is the global configuration values for the bot.
-var Values Config
-
 // Config is a struct that holds all the configuration values for the bot.
 type Config struct {
 	// The bot configuration values.
@@ -38,24 +34,60 @@ type Config struct {
 	Suggestions Suggestions `toml:"suggestions"`
 	// The configuration values for the commands.
 	Commands Commands `toml:"commands"`
+	// The configuration values for users following streams and platforms.
+	Follows Follows `toml:"follows"`
+	// The configuration values for Discord scheduled events.
+	Events Events `toml:"events"`
+	// The configuration values for posting stream announcements.
+	Announcements Announcements `toml:"announcements"`
+	// The configuration values for outbound HTTP requests.
+	HTTP HTTP `toml:"http"`
+	// The configuration values for delivering announcements to webhooks.
+	Webhooks Webhooks `toml:"webhooks"`
+	// The configuration values for announcing streams on Telegram.
+	Telegram Telegram `toml:"telegram"`
+	// The configuration values for the RSS and Atom feeds of streams.
+	Feeds Feeds `toml:"feeds"`
+	// The configuration values for checking whether streams have gone live.
+	Live Live `toml:"live"`
+	// The configuration values for onboarding new servers.
+	Onboarding Onboarding `toml:"onboarding"`
 	// Allows cron jobs to be scheduled and enabled/disabled.
 	Schedule Schedules `toml:"schedule"`
 }
 
-// LoadConfig loads the configuration values from the config.toml file into the
-// Values variable.
-func (c *Config) Load() {
-	var configFile string
+// DefaultPath returns the path of the config file used if no path is given with the
+// --config flag. It is the GS_CONFIG environment variable if set, otherwise
+// config_files/config.toml on Windows and ~/.config/game-streams/config.toml elsewhere.
+func DefaultPath() string {
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path
+	}
 	if runtime.GOOS == "windows" {
-		configFile = "config_files/config.toml"
-	} else {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			log.Output(5, fmt.Sprintf("CONFG: could not set filepaths err=%s", err))
-		}
-		configFile = fmt.Sprintf("%s/.config/game-streams/config.toml", home)
+		return "config_files/config.toml"
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "config.toml"
+	}
+	return fmt.Sprintf("%s/.config/game-streams/config.toml", home)
+}
+
+// Load loads the configuration values from the config file at the path, overrides them
+// with any set in GS_* environment variables, then validates them. If the values are
+// invalid, the error is a *ValidationError listing every problem found.
+func (c *Config) Load(path string) error {
+	if _, decodeErr := toml.DecodeFile(path, c); decodeErr != nil {
+		return fmt.Errorf("could not load config file %s: %w", path, decodeErr)
+	}
+	c.Files.Config = path
+	if pathsErr := c.Files.SetPaths(); pathsErr != nil {
+		return fmt.Errorf("could not load config file %s: %w", path, pathsErr)
+	}
+	problems := c.applyEnv()
+	problems = append(problems, c.Validate()...)
+	if len(problems) > 0 {
+		return &ValidationError{Path: path, Problems: problems}
 	}
-	toml.DecodeFile(configFile, &c)
-	c.Files.Config = configFile
-	c.Files.SetPaths()
+	return nil
 }
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a configuration value that differs between two configurations.
+type Change struct {
+	// The TOML key of the value, e.g. schedule.backup.cron.
+	Key string
+	// The old value, or "(hidden)" if the value is a secret.
+	Old string
+	// The new value, or "(hidden)" if the value is a secret.
+	New string
+}
+
+// String returns the change as key: old -> new.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff returns the values that differ between the old and next configurations, in the
+// order they are declared. The values of tokens, secrets and access keys are hidden.
+func Diff(old *Config, next *Config) []Change {
+	return diffStruct(reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem(), "")
+}
+
+// diffStruct returns the fields that differ between the two structs, with their keys
+// prefixed with the prefix.
+func diffStruct(old reflect.Value, next reflect.Value, prefix string) []Change {
+	var changes []Change
+	for i := 0; i < old.NumField(); i++ {
+		key, _, _ := strings.Cut(old.Type().Field(i).Tag.Get("toml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		key = prefix + key
+		oldField, newField := old.Field(i), next.Field(i)
+		if oldField.Kind() == reflect.Struct {
+			changes = append(changes, diffStruct(oldField, newField, key+".")...)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		change := Change{
+			Key: key,
+			Old: fmt.Sprintf("%v", oldField.Interface()),
+			New: fmt.Sprintf("%v", newField.Interface()),
+		}
+		if isSecret(key) {
+			change.Old, change.New = "(hidden)", "(hidden)"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// isSecret reports whether the value with the key should not be shown.
+func isSecret(key string) bool {
+	for _, word := range []string{"token", "secret", "access_key"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envPrefix is the prefix of the environment variables that override configuration
+// values.
+const envPrefix = "GS_"
+
+// applyEnv overrides the configuration values with those set in environment variables.
+// Each variable is named GS_ followed by the TOML keys of the value in upper case,
+// joined with underscores, e.g. GS_DISCORD_TOKEN or GS_SCHEDULE_BACKUP_CRON. Secrets
+// can be kept out of config.toml by setting a variable ending in _FILE instead, e.g.
+// GS_DISCORD_TOKEN_FILE, to the path of a file holding the value. Lists are separated
+// by commas. It returns a problem for each variable that could not be applied.
+func (c *Config) applyEnv() []string {
+	return applyEnvStruct(reflect.ValueOf(c).Elem(), envPrefix)
+}
+
+// applyEnvStruct overrides the fields of the struct with the environment variables
+// named with the prefix and the TOML key of each field, and returns the problems found.
+func applyEnvStruct(v reflect.Value, prefix string) []string {
+	var problems []string
+	for i := 0; i < v.NumField(); i++ {
+		key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("toml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		name := prefix + strings.ToUpper(key)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			problems = append(problems, applyEnvStruct(field, name+"_")...)
+			continue
+		}
+		value, set, readErr := lookupEnv(name)
+		if readErr != nil {
+			problems = append(problems, readErr.Error())
+			continue
+		}
+		if !set {
+			continue
+		}
+		if setErr := setField(field, value); setErr != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, setErr))
+		}
+	}
+	return problems
+}
+
+// lookupEnv returns the value of the environment variable with the name, or the
+// contents of the file named by the variable with _FILE appended, with surrounding
+// whitespace removed. It returns false if neither is set.
+func lookupEnv(name string) (string, bool, error) {
+	if value, set := os.LookupEnv(name); set {
+		return value, true, nil
+	}
+	path, set := os.LookupEnv(name + "_FILE")
+	if !set {
+		return "", false, nil
+	}
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return "", false, fmt.Errorf("%s_FILE: could not read %s: %s", name, path, readErr)
+	}
+	return strings.TrimSpace(string(contents)), true, nil
+}
+
+// setField sets the field to the value parsed as the type of the field.
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, parseErr := strconv.Atoi(value)
+		if parseErr != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("cannot be set from the environment")
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("cannot be set from the environment")
+	}
+	return nil
+}
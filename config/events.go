@@ -0,0 +1,11 @@
+package config
+
+// Events is a struct that holds the configuration values for posting streams as
+// Discord scheduled events.
+type Events struct {
+	// The number of days ahead to create scheduled events for upcoming streams.
+	DaysAhead int `toml:"days_ahead"`
+	// The number of minutes a stream is expected to last. Used as the end time of the
+	// scheduled event.
+	DurationMinutes int `toml:"duration_minutes"`
+}
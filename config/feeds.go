@@ -0,0 +1,16 @@
+package config
+
+// Feeds is a struct that holds the configuration values for the RSS and Atom feeds of
+// new and upcoming streams.
+type Feeds struct {
+	// The address the feeds are served on, e.g. 127.0.0.1:8080. The feeds are not served
+	// if it is empty.
+	Address string `toml:"address"`
+	// The directory the feeds are written to each time streams.toml is imported. The
+	// feeds are not written if it is empty.
+	Directory string `toml:"directory"`
+	// The public URL the feeds are served from, used for the links between feeds.
+	BaseURL string `toml:"base_url"`
+	// The number of streams in each feed.
+	Limit int `toml:"limit"`
+}
@@ -1,8 +1,8 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"runtime"
 
@@ -21,37 +21,66 @@ type FilePaths struct {
 	EncryptionKey string `toml:"encryption_key"`
 }
 
-// SetPaths sets the file paths for the bot depending on the operating system.
-func (f *FilePaths) SetPaths() {
+// SetPaths sets the file paths for the bot depending on the operating system. It
+// returns an error if the config file cannot be read or its paths are invalid.
+func (f *FilePaths) SetPaths() error {
 	file, err := os.ReadFile(f.Config)
 	if err != nil {
-		log.Output(5, fmt.Sprintf("CONFG: could not read config file err=%s", err))
+		return fmt.Errorf("could not read config file: %w", err)
 	}
-	toml.Unmarshal(file, &f)
+	return toml.Unmarshal(file, f)
 }
 
 // UnmarshalTOML unmarshals the TOML data into the FilePaths struct. This function
 // is required as there are two different sets of paths for Windows and Linux in
 // config.toml but only one struct at runtime. This prevents the TOML decoder from
-// unmarshalling the paths directly into the struct.
+// unmarshalling the paths directly into the struct. It returns an error if the paths
+// for the operating system are missing or are not strings.
 func (f *FilePaths) UnmarshalTOML(data interface{}) error {
-	m := data.(map[string]interface{})
-	if w, ok := m["paths"]; ok {
-		if runtime.GOOS == "windows" {
-			v := w.(map[string]interface{})["windows"].(map[string]interface{})
-			f.Database = v["database"].(string)
-			f.EncryptedDatabase = v["encrypted_database"].(string)
-			f.EncryptionKey = v["encryption_key"].(string)
-		} else {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				log.Output(5, fmt.Sprintf("MAIN: could not set filepaths err=%s", err))
-			}
-			v := w.(map[string]interface{})["linux"].(map[string]interface{})
-			f.Database = fmt.Sprintf(v["database"].(string), home)
-			f.EncryptedDatabase = fmt.Sprintf(v["encrypted_database"].(string), home)
-			f.EncryptionKey = fmt.Sprintf(v["encryption_key"].(string), home)
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.New("files: expected a table")
+	}
+	w, ok := m["paths"]
+	if !ok {
+		return nil
+	}
+	platforms, ok := w.(map[string]interface{})
+	if !ok {
+		return errors.New("files.paths: expected a table")
+	}
+	platform := "linux"
+	if runtime.GOOS == "windows" {
+		platform = "windows"
+	}
+	v, ok := platforms[platform].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("files.paths.%s: missing table", platform)
+	}
+	var home string
+	if platform == "linux" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return fmt.Errorf("could not set filepaths: %w", err)
+		}
+	}
+	paths := []struct {
+		key  string
+		dest *string
+	}{
+		{"database", &f.Database},
+		{"encrypted_database", &f.EncryptedDatabase},
+		{"encryption_key", &f.EncryptionKey},
+	}
+	for _, p := range paths {
+		path, ok := v[p.key].(string)
+		if !ok {
+			return fmt.Errorf("files.paths.%s.%s: missing or not a string", platform, p.key)
+		}
+		if platform == "linux" {
+			path = fmt.Sprintf(path, home)
 		}
+		*p.dest = path
 	}
 	return nil
 }
@@ -0,0 +1,10 @@
+package config
+
+// Follows is a struct that holds the configuration values for users following streams
+// and platforms.
+type Follows struct {
+	// The maximum number of streams and platforms a user can follow.
+	MaxFollows int `toml:"max_follows"`
+	// The maximum number of stream reminders a user can be sent by DM per day.
+	DailyDMLimit int `toml:"daily_dm_limit"`
+}
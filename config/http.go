@@ -0,0 +1,16 @@
+package config
+
+// HTTP is a struct that holds the configuration values for outbound HTTP requests,
+// such as scraping stream pages for thumbnails.
+type HTTP struct {
+	// The number of seconds before a request times out.
+	TimeoutSeconds int `toml:"timeout_seconds"`
+	// The number of times a failed request is retried.
+	Retries int `toml:"retries"`
+	// The minimum number of milliseconds between requests to the same host.
+	HostIntervalMillis int `toml:"host_interval_millis"`
+	// The number of minutes that metadata scraped from stream pages is cached for.
+	CacheMinutes int `toml:"cache_minutes"`
+	// The User-Agent header sent with each request.
+	UserAgent string `toml:"user_agent"`
+}
@@ -0,0 +1,15 @@
+package config
+
+// Live is a struct that holds the configuration values for checking whether streams
+// have gone live.
+type Live struct {
+	// The number of seconds between each check of a stream URL.
+	PollSeconds int `toml:"poll_seconds"`
+	// The number of minutes before the scheduled start time to begin checking.
+	EarlyMinutes int `toml:"early_minutes"`
+	// The number of minutes after the scheduled start time to wait before alerting the
+	// owner that a stream has not started.
+	GraceMinutes int `toml:"grace_minutes"`
+	// The number of minutes after the scheduled start time to stop checking.
+	GiveUpMinutes int `toml:"give_up_minutes"`
+}
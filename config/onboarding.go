@@ -0,0 +1,9 @@
+package config
+
+// Onboarding is a struct that holds the configuration values for guiding new servers
+// through setting up the bot.
+type Onboarding struct {
+	// The number of days after joining a server to remind the owner to set up the bot
+	// if it has not been configured. A value of 0 disables the reminder.
+	ReminderDays int `toml:"reminder_days"`
+}
@@ -12,6 +12,10 @@ type Schedules struct {
 	StreamNotifications Schedule `toml:"stream_notifications"`
 	// The schedule for checking streams with no time set
 	CheckTimelessStreams Schedule `toml:"timeless_streams"`
+	// The schedule for retrying announcements that failed to post
+	AnnouncementRetry Schedule `toml:"announcement_retry"`
+	// The schedule for checking that announce channels are still usable
+	ChannelHealth Schedule `toml:"channel_health"`
 	// The number of minutes before a stream starts to send a notification.
 	NotificationTMinus int `toml:"notification_t_minus"`
 }
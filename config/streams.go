@@ -6,4 +6,6 @@ type Streams struct {
 	Limit int `toml:"limit"`
 	// The number of months to keep the streams in the database before deleting them.
 	MonthsToKeep int `toml:"months_to_keep"`
+	// The number of days ahead to create streams from recurring templates.
+	RecurrenceDays int `toml:"recurrence_days"`
 }
@@ -0,0 +1,14 @@
+package config
+
+// Telegram is a struct that holds the configuration values for announcing streams on
+// Telegram. Telegram is only used if the token is set.
+type Telegram struct {
+	// The token of the Telegram bot, given by BotFather.
+	Token string `toml:"token"`
+	// The Telegram user ID of the bot owner. If the Discord token is not set, errors are
+	// sent to this user instead.
+	OwnerID string `toml:"owner_id"`
+	// The IDs or @usernames of the Telegram channels and groups that every stream is
+	// announced in. The bot must be able to post in each of them.
+	Chats []string `toml:"chats"`
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ValidationError is returned by Load when the configuration values are invalid. It
+// lists every problem found, so they can all be fixed at once.
+type ValidationError struct {
+	// The path of the config file the values were loaded from.
+	Path string
+	// A human-readable description of each problem, prefixed with the key of the value.
+	Problems []string
+}
+
+// Error returns the problems with the configuration, one per line.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration in %s:\n  - %s", e.Path,
+		strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the configuration values and returns a description of each problem
+// found: a missing token, paths or IDs, invalid cron strings in enabled schedules,
+// limits that must be positive but are not, and values that must not be negative. It
+// returns nil if the values are valid.
+func (c *Config) Validate() []string {
+	var p problems
+
+	if c.Discord.Token == "" && c.Telegram.Token == "" {
+		p.add("discord.token: either a Discord or Telegram token must be set " +
+			"(or GS_DISCORD_TOKEN / GS_TELEGRAM_TOKEN)")
+	}
+	if c.Discord.Token != "" {
+		p.required("discord.application_id", c.Discord.ApplicationID)
+		p.required("discord.owner_id", c.Discord.OwnerID)
+	}
+	if len(c.Telegram.Chats) > 0 && c.Telegram.Token == "" {
+		p.add("telegram.chats: chats are set but telegram.token is not")
+	}
+	p.required("files.database", c.Files.Database)
+	if c.Schedule.Backup.Enabled {
+		p.required("files.encrypted_database", c.Files.EncryptedDatabase)
+		p.required("files.encryption_key", c.Files.EncryptionKey)
+		p.positive("backup.days_to_keep", c.Backup.DaysToKeep)
+	}
+
+	schedules := []struct {
+		key      string
+		schedule Schedule
+	}{
+		{"backup", c.Schedule.Backup},
+		{"maintenance", c.Schedule.Maintenance},
+		{"stream_update", c.Schedule.StreamUpdate},
+		{"stream_notifications", c.Schedule.StreamNotifications},
+		{"timeless_streams", c.Schedule.CheckTimelessStreams},
+		{"announcement_retry", c.Schedule.AnnouncementRetry},
+		{"channel_health", c.Schedule.ChannelHealth},
+	}
+	for _, s := range schedules {
+		if !s.schedule.Enabled {
+			continue
+		}
+		if _, parseErr := cron.ParseStandard(s.schedule.Cron); parseErr != nil {
+			p.add("schedule.%s.cron: invalid cron string %q: %s", s.key, s.schedule.Cron, parseErr)
+		}
+	}
+
+	p.positive("schedule.notification_t_minus", c.Schedule.NotificationTMinus)
+	p.positive("streams.limit", c.Streams.Limit)
+	p.positive("streams.months_to_keep", c.Streams.MonthsToKeep)
+	p.positive("blacklist.hourly_command_limit", c.Blacklist.HourlyCommandLimit)
+	p.positive("blacklist.daily_command_limit", c.Blacklist.DailyCommandLimit)
+	p.positive("suggestions.daily_limit", c.Suggestions.DailyLimit)
+	p.positive("suggestions.days_to_keep", c.Suggestions.DaysToKeep)
+	p.positive("commands.months_to_keep", c.Commands.MonthsToKeep)
+	p.positive("logs.days_to_keep", c.Logs.DaysToKeep)
+
+	// These values fall back to a default or disable a feature when they are 0.
+	p.notNegative("bot.shutdown_timeout_seconds", c.Bot.ShutdownTimeoutSeconds)
+	p.notNegative("blacklist.days_between_messages", c.Blacklist.DaysBetweenMessages)
+	p.notNegative("streams.recurrence_days", c.Streams.RecurrenceDays)
+	p.notNegative("follows.max_follows", c.Follows.MaxFollows)
+	p.notNegative("follows.daily_dm_limit", c.Follows.DailyDMLimit)
+	p.notNegative("events.days_ahead", c.Events.DaysAhead)
+	p.notNegative("events.duration_minutes", c.Events.DurationMinutes)
+	p.notNegative("announcements.workers", c.Announcements.Workers)
+	p.notNegative("announcements.retries", c.Announcements.Retries)
+	p.notNegative("announcements.retry_limit", c.Announcements.RetryLimit)
+	p.notNegative("announcements.retry_window_minutes", c.Announcements.RetryWindowMinutes)
+	p.notNegative("http.timeout_seconds", c.HTTP.TimeoutSeconds)
+	p.notNegative("http.retries", c.HTTP.Retries)
+	p.notNegative("http.host_interval_millis", c.HTTP.HostIntervalMillis)
+	p.notNegative("http.cache_minutes", c.HTTP.CacheMinutes)
+	p.notNegative("webhooks.disable_after", c.Webhooks.DisableAfter)
+	p.notNegative("webhooks.server_limit", c.Webhooks.ServerLimit)
+	p.notNegative("feeds.limit", c.Feeds.Limit)
+	p.notNegative("live.poll_seconds", c.Live.PollSeconds)
+	p.notNegative("live.grace_minutes", c.Live.GraceMinutes)
+	p.notNegative("live.give_up_minutes", c.Live.GiveUpMinutes)
+	p.notNegative("onboarding.reminder_days", c.Onboarding.ReminderDays)
+
+	return p
+}
+
+// problems collects the problems found while validating the configuration.
+type problems []string
+
+// add adds a problem, formatted with the arguments.
+func (p *problems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+// required adds a problem if the value is empty.
+func (p *problems) required(key string, value string) {
+	if value == "" {
+		p.add("%s: must be set", key)
+	}
+}
+
+// positive adds a problem if the value is not greater than 0.
+func (p *problems) positive(key string, value int) {
+	if value <= 0 {
+		p.add("%s: must be greater than 0, got %d", key, value)
+	}
+}
+
+// notNegative adds a problem if the value is less than 0.
+func (p *problems) notNegative(key string, value int) {
+	if value < 0 {
+		p.add("%s: must not be negative, got %d", key, value)
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+// Webhooks is a struct that holds the configuration values for delivering stream
+// announcements to outbound webhooks. Failed deliveries are retried using the retries
+// value of the http section.
+type Webhooks struct {
+	// The number of deliveries in a row that can fail before a webhook is disabled.
+	DisableAfter int `toml:"disable_after"`
+	// The maximum number of webhooks each server can register.
+	ServerLimit int `toml:"server_limit"`
+}
@@ -0,0 +1,467 @@
+/*
+announcements.go contains the Announcement struct and functions that interact with the
+announcements table of the database. Each row records a stream announcement posted to a
+server, or the error if it failed to post, so that failed posts can be retried and the
+messages can later be edited, deleted, or followed up if the stream is cancelled or
+postponed.
+*/
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The delivery statuses of an announcement.
+const (
+	// AnnouncementSent is an announcement that has been posted.
+	AnnouncementSent = "sent"
+	// AnnouncementFailed is an announcement that failed to post and will be retried.
+	AnnouncementFailed = "failed"
+	// AnnouncementAbandoned is an announcement that failed to post and will not be
+	// retried, either because the error was permanent or the retry limit was reached.
+	AnnouncementAbandoned = "abandoned"
+	// AnnouncementDeleted is an announcement whose message has been deleted.
+	AnnouncementDeleted = "deleted"
+)
+
+// The publish statuses of an announcement posted in an announcement channel. The
+// publish status of announcements that are not crossposted is empty.
+const (
+	// PublishPublished is an announcement that has been crossposted to the servers
+	// following its channel.
+	PublishPublished = "published"
+	// PublishFailed is an announcement that failed to crosspost and will be retried.
+	PublishFailed = "failed"
+	// PublishAbandoned is an announcement that failed to crosspost and will not be
+	// retried.
+	PublishAbandoned = "abandoned"
+)
+
+// Announcement represents a row in the announcements table of the database.
+type Announcement struct {
+	// The Discord ID of the server the announcement was posted in.
+	ServerID string
+	// The ID of the stream that was announced.
+	StreamID int
+	// The Discord ID of the channel the announcement was posted in.
+	ChannelID string
+	// The Discord ID of the announcement message, if it was posted.
+	MessageID string
+	// The time the announcement was posted, in RFC3339 format.
+	SentAt string
+	// The stream status that a follow-up message has been posted for, if any.
+	FollowupStatus string
+	// The delivery status of the announcement.
+	Status string
+	// The error returned when the announcement last failed to post.
+	Error string
+	// The number of times the announcement has been attempted.
+	Attempts int
+	// The URL of the stream when it was announced, before it was made direct. Used to
+	// tell when the URL of the stream has changed.
+	StreamURL string
+	// The time the announcement was last changed, in RFC3339 format.
+	UpdatedAt string
+	// The publish status of the announcement, if it was posted in an announcement
+	// channel with crossposting enabled.
+	PublishStatus string
+}
+
+// announcementColumns are the columns selected by the functions that return
+// announcements, in the order they are scanned by queryAnnouncements.
+const announcementColumns = `announcements.server_id,
+								announcements.stream_id,
+								IFNULL(announcements.channel_id, ''),
+								IFNULL(announcements.message_id, ''),
+								IFNULL(announcements.sent_at, ''),
+								IFNULL(announcements.followup_status, ''),
+								IFNULL(announcements.status, ''),
+								IFNULL(announcements.error, ''),
+								IFNULL(announcements.attempts, 0),
+								IFNULL(announcements.stream_url, ''),
+								IFNULL(announcements.updated_at, ''),
+								IFNULL(announcements.publish_status, '')`
+
+// Recipient is a server that a stream announcement is posted to.
+type Recipient struct {
+	// The Discord ID of the server.
+	ServerID string
+	// The Discord ID of the channel the announcement is posted in.
+	ChannelID string
+	// The Discord ID of the role mentioned in the announcement, if any.
+	RoleID string
+	// The announcement templates of the server.
+	Template MessageTemplate
+	// The preferred locale of the server, used to translate the announcement.
+	Locale string
+	// A flag to determine if the announcement is crossposted to the servers following
+	// the channel, when it is an announcement channel.
+	Crosspost bool
+}
+
+// platformColumns maps the lower case name of each platform to its column in the
+// server_settings table.
+var platformColumns = map[string]string{
+	"playstation": "playstation",
+	"xbox":        "xbox",
+	"nintendo":    "nintendo",
+	"pc":          "pc",
+	"vr":          "vr",
+}
+
+// GetAnnouncementRecipients returns the servers that the stream should be announced in
+// the given number of minutes before it starts, in a single query. These are the
+// servers that have an announcement channel set, use the given lead time, and follow
+// one of the platforms or publishers of the stream. Servers that already have a row in
+// the announcements table for the stream are left out, so the stream is not announced
+// twice; announcements that failed to post are retried separately. The
+// announcement templates of each server are returned with it, along with its locale
+// and whether it crossposts.
+func (d *Database) GetAnnouncementRecipients(stream Stream, leadTime int) ([]Recipient, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	var clauses []string
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		if column, exists := platformColumns[strings.ToLower(strings.TrimSpace(platform))]; exists {
+			clauses = append(clauses, fmt.Sprintf("server_settings.%s = 1", column))
+		}
+	}
+	clauses = append(clauses, `server_settings.server_id IN
+									(SELECT server_publishers.server_id
+									FROM server_publishers
+									JOIN stream_publishers
+										ON server_publishers.publisher_id = stream_publishers.publisher_id
+									WHERE stream_publishers.stream_id = ?)`)
+
+	query := fmt.Sprintf(`SELECT server_settings.server_id,
+								server_settings.announce_channel,
+								IFNULL(server_settings.announce_role, ''),
+								IFNULL(message_templates.content, ''),
+								IFNULL(message_templates.title, ''),
+								IFNULL(message_templates.description, ''),
+								IFNULL(message_templates.footer, ''),
+								IFNULL(message_templates.hide_thumbnail, 0),
+								IFNULL(message_templates.hide_platforms, 0),
+								IFNULL(servers.locale, ''),
+								IFNULL(server_settings.crosspost, 0)
+							FROM server_settings
+							LEFT JOIN message_templates
+								ON server_settings.server_id = message_templates.server_id
+							LEFT JOIN servers
+								ON server_settings.server_id = servers.server_id
+							WHERE IFNULL(server_settings.announce_channel, '') != ''
+							AND (CASE WHEN IFNULL(server_settings.lead_time, 0) > 0
+								THEN server_settings.lead_time
+								ELSE ? END) = ?
+							AND server_settings.server_id NOT IN
+								(SELECT announcements.server_id
+								FROM announcements
+								WHERE announcements.stream_id = ?)
+							AND (%s)`, strings.Join(clauses, " OR "))
+
+	rows, queryErr := db.Query(query,
+		d.config().Schedule.NotificationTMinus,
+		leadTime,
+		stream.ID,
+		stream.ID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var r Recipient
+		scanErr := rows.Scan(&r.ServerID,
+			&r.ChannelID,
+			&r.RoleID,
+			&r.Template.Content,
+			&r.Template.Title,
+			&r.Template.Description,
+			&r.Template.Footer,
+			&r.Template.HideThumbnail,
+			&r.Template.HidePlatforms,
+			&r.Locale,
+			&r.Crosspost)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		r.Template.ServerID = r.ServerID
+		recipients = append(recipients, r)
+	}
+	return recipients, rows.Err()
+}
+
+// Insert adds the announcement to the announcements table of the database, replacing
+// any earlier announcement of the same stream in the server.
+func (a *Announcement) Insert(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if a.SentAt == "" {
+		a.SentAt = now
+	}
+	if a.Status == "" {
+		a.Status = AnnouncementSent
+	}
+	a.UpdatedAt = now
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO announcements
+								(server_id,
+								stream_id,
+								channel_id,
+								message_id,
+								sent_at,
+								followup_status,
+								status,
+								error,
+								attempts,
+								stream_url,
+								updated_at,
+								publish_status)
+							VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ServerID,
+		a.StreamID,
+		a.ChannelID,
+		a.MessageID,
+		a.SentAt,
+		a.FollowupStatus,
+		a.Status,
+		a.Error,
+		a.Attempts,
+		a.StreamURL,
+		a.UpdatedAt,
+		a.PublishStatus)
+	return execErr
+}
+
+// Update updates the channel, message, delivery status, error, attempts, stream URL and
+// publish status of the announcement in the announcements table of the database.
+func (a *Announcement) Update(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	a.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	_, execErr := db.Exec(`UPDATE announcements
+							SET channel_id = ?,
+								message_id = ?,
+								sent_at = ?,
+								status = ?,
+								error = ?,
+								attempts = ?,
+								stream_url = ?,
+								updated_at = ?,
+								publish_status = ?
+							WHERE server_id = ?
+							AND stream_id = ?`,
+		a.ChannelID,
+		a.MessageID,
+		a.SentAt,
+		a.Status,
+		a.Error,
+		a.Attempts,
+		a.StreamURL,
+		a.UpdatedAt,
+		a.PublishStatus,
+		a.ServerID,
+		a.StreamID)
+	return execErr
+}
+
+// GetPendingFollowups returns the announcements of streams that have since been
+// cancelled or postponed and whose server has not yet been told about the change.
+func (d *Database) GetPendingFollowups() ([]Announcement, error) {
+	return d.queryAnnouncements(`SELECT `+announcementColumns+`
+								FROM announcements
+								JOIN streams
+									ON announcements.stream_id = streams.id
+								WHERE announcements.status = ?
+								AND streams.status IN (?, ?)
+								AND IFNULL(announcements.followup_status, '') != streams.status`,
+		AnnouncementSent,
+		StatusCancelled,
+		StatusPostponed)
+}
+
+// GetFailedAnnouncements returns the announcements that failed to post and are due to
+// be retried.
+func (d *Database) GetFailedAnnouncements() ([]Announcement, error) {
+	return d.queryAnnouncements(`SELECT `+announcementColumns+`
+								FROM announcements
+								WHERE announcements.status = ?
+								ORDER BY announcements.sent_at`,
+		AnnouncementFailed)
+}
+
+// GetUnpublishedAnnouncements returns the posted announcements that failed to
+// crosspost and are due to be retried.
+func (d *Database) GetUnpublishedAnnouncements() ([]Announcement, error) {
+	return d.queryAnnouncements(`SELECT `+announcementColumns+`
+								FROM announcements
+								WHERE announcements.status = ?
+								AND announcements.publish_status = ?
+								ORDER BY announcements.sent_at`,
+		AnnouncementSent,
+		PublishFailed)
+}
+
+// Announced returns true if the announcements table has a row for the stream in the
+// server, whether the announcement was posted or failed to post.
+func (d *Database) Announced(serverID string, streamID int) (bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	var count int
+	scanErr := db.QueryRow(`SELECT COUNT(*)
+							FROM announcements
+							WHERE server_id = ?
+							AND stream_id = ?`,
+		serverID,
+		streamID).Scan(&count)
+	return count > 0, scanErr
+}
+
+// GetStreamAnnouncements returns the posted announcements of the stream with the given
+// ID.
+func (d *Database) GetStreamAnnouncements(streamID int) ([]Announcement, error) {
+	return d.queryAnnouncements(`SELECT `+announcementColumns+`
+								FROM announcements
+								WHERE announcements.stream_id = ?
+								AND announcements.status = ?`,
+		streamID,
+		AnnouncementSent)
+}
+
+// GetChangedAnnouncements returns the posted announcements of streams whose URL has
+// changed since they were announced.
+func (d *Database) GetChangedAnnouncements() ([]Announcement, error) {
+	return d.queryAnnouncements(`SELECT `+announcementColumns+`
+								FROM announcements
+								JOIN streams
+									ON announcements.stream_id = streams.id
+								WHERE announcements.status = ?
+								AND IFNULL(announcements.stream_url, '') != ''
+								AND announcements.stream_url != streams.url`,
+		AnnouncementSent)
+}
+
+// queryAnnouncements runs the query, which must select announcementColumns, and
+// returns the announcements.
+func (d *Database) queryAnnouncements(query string, args ...any) ([]Announcement, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(query, args...)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var announcements []Announcement
+	for rows.Next() {
+		var a Announcement
+		scanErr := rows.Scan(&a.ServerID,
+			&a.StreamID,
+			&a.ChannelID,
+			&a.MessageID,
+			&a.SentAt,
+			&a.FollowupStatus,
+			&a.Status,
+			&a.Error,
+			&a.Attempts,
+			&a.StreamURL,
+			&a.UpdatedAt,
+			&a.PublishStatus)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+// SetFollowupStatus records that a follow-up message has been posted for the given
+// stream status.
+func (a *Announcement) SetFollowupStatus(database *Database, status string) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	a.FollowupStatus = status
+	_, execErr := db.Exec(`UPDATE announcements
+							SET followup_status = ?
+							WHERE server_id = ?
+							AND stream_id = ?`,
+		status,
+		a.ServerID,
+		a.StreamID)
+	return execErr
+}
+
+// CountStreamAnnouncements returns the number of announcements of the stream with the
+// given ID in each delivery status.
+func (d *Database) CountStreamAnnouncements(streamID int) (map[string]int, error) {
+	return d.countStreamAnnouncements(streamID, "status")
+}
+
+// CountStreamPublishes returns the number of announcements of the stream with the given
+// ID in each publish status. Announcements that are not crossposted are not counted.
+func (d *Database) CountStreamPublishes(streamID int) (map[string]int, error) {
+	counts, countErr := d.countStreamAnnouncements(streamID, "publish_status")
+	delete(counts, "")
+	return counts, countErr
+}
+
+// countStreamAnnouncements returns the number of announcements of the stream with the
+// given ID for each value of the given column.
+func (d *Database) countStreamAnnouncements(streamID int, column string) (map[string]int, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(fmt.Sprintf(`SELECT IFNULL(%[1]s, ''),
+												COUNT(*)
+											FROM announcements
+											WHERE stream_id = ?
+											GROUP BY %[1]s`, column),
+		streamID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if scanErr := rows.Scan(&status, &count); scanErr != nil {
+			return nil, scanErr
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
@@ -4,12 +4,8 @@ blacklist.go provides functions to interact with the blacklist table of the data
 package db
 
 import (
-	"database/sql"
 	"math"
 	//"time"
-
-	"gamestreams/config"
-	"gamestreams/logs"
 )
 
 // Blacklist is a struct that holds the values from a row in the blacklist table
@@ -31,10 +27,10 @@ type Blacklist struct {
 
 // IsBlacklisted checks if the given ID is blacklisted. Returns true and the blacklist
 // values if the ID is blacklisted, otherwise returns false and an empty Blacklist struct.
-func IsBlacklisted(id string) (bool, Blacklist) {
-	logs.LogInfo("   DB", "checking if blacklisted", false,
+func (d *Database) IsBlacklisted(id string) (bool, Blacklist) {
+	d.log.LogInfo("   DB", "checking if blacklisted", false,
 		"id", id)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return false, Blacklist{}
 	}
@@ -64,19 +60,19 @@ func IsBlacklisted(id string) (bool, Blacklist) {
 // If the ID is already blacklisted, the length of time is raised to the
 // power of the number of times the ID has been blacklisted. The maximum
 // length of time is 365 days.
-func AddToBlacklist(id string, idType string, reason string, length_days int) error {
-	blacklisted, _ := IsBlacklisted(id)
+func (d *Database) AddToBlacklist(id string, idType string, reason string, length_days int) error {
+	blacklisted, _ := d.IsBlacklisted(id)
 	if blacklisted {
-		logs.LogInfo("   DB", "ID already blacklisted", false, "id", id)
+		d.log.LogInfo("   DB", "ID already blacklisted", false, "id", id)
 		return nil
 	}
-	logs.LogInfo("   DB", "adding to blacklist table", false,
+	d.log.LogInfo("   DB", "adding to blacklist table", false,
 		"id", id,
 		"idType", idType,
 		"days", length_days,
 		"reason", reason)
 
-	bCount, countErr := countBlacklistEntries(id, 2)
+	bCount, countErr := d.countBlacklistEntries(id, 2)
 	if countErr != nil {
 		return countErr
 	}
@@ -87,7 +83,7 @@ func AddToBlacklist(id string, idType string, reason string, length_days int) er
 		}
 	}
 
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -105,9 +101,9 @@ func AddToBlacklist(id string, idType string, reason string, length_days int) er
 }
 
 // RemoveFromBlacklist removes the given ID from the blacklist table.
-func RemoveFromBlacklist(id string) error {
-	logs.LogInfo("   DB", "removing from blacklist table", false, "id", id)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) RemoveFromBlacklist(id string) error {
+	d.log.LogInfo("   DB", "removing from blacklist table", false, "id", id)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -121,9 +117,9 @@ func RemoveFromBlacklist(id string) error {
 
 // GetBlacklist returns a slice of Blacklist structs containing all IDs in the
 // blacklist.
-func GetBlacklist() ([]Blacklist, error) {
-	logs.LogInfo("   DB", "getting blacklist", false)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) GetBlacklist() ([]Blacklist, error) {
+	d.log.LogInfo("   DB", "getting blacklist", false)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return nil, openErr
 	}
@@ -156,9 +152,9 @@ func GetBlacklist() ([]Blacklist, error) {
 
 // CountBlacklistEntries returns the number of entries in the blacklist table for the
 // given ID.
-func countBlacklistEntries(id string, num_years int) (int, error) {
-	logs.LogInfo("   DB", "counting blacklist entries", false, "id", id)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) countBlacklistEntries(id string, num_years int) (int, error) {
+	d.log.LogInfo("   DB", "counting blacklist entries", false, "id", id)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return 0, openErr
 	}
@@ -181,9 +177,9 @@ func countBlacklistEntries(id string, num_years int) (int, error) {
 
 // UpdateLastMessaged updates the last_messaged field of the given ID in the blacklist
 // table to the current date.
-func UpdateLastMessaged(id string) error {
-	logs.LogInfo("   DB", "updating last messaged", false, "id", id)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) UpdateLastMessaged(id string) error {
+	d.log.LogInfo("   DB", "updating last messaged", false, "id", id)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -4,15 +4,12 @@ commands.go provides the functions for interactions with the database regarding
 package db
 
 import (
-	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
 	"gamestreams/discord"
-	"gamestreams/logs"
 )
 
 // CommandData is a struct that contains values for a row in the commands table of the
@@ -43,7 +40,7 @@ type CommandData struct {
 
 // Start initializes the CommandData struct with the necessary data from the interaction.
 // It sets the server ID, user ID, start time, used date, used time, command, and options.
-func (d *CommandData) Start(interaction *discordgo.InteractionCreate) {
+func (d *CommandData) Start(database *Database, interaction *discordgo.InteractionCreate) {
 	d.ServerID = interaction.GuildID
 	d.UserID = discord.GetUserID(interaction)
 	d.StartTime = time.Now().UnixMilli()
@@ -55,35 +52,35 @@ func (d *CommandData) Start(interaction *discordgo.InteractionCreate) {
 		len(interaction.ApplicationCommandData().Options) > 0) {
 		d.Options = interaction.ApplicationCommandData().Options[0].StringValue()
 	}
-	d.Initialise()
+	d.Initialise(database)
 }
 
 // Initialise sets the CommandID of the CommandData struct to the latest command ID in the
 // database and inserts the data into the database. This is done so that when a suggestion
 // is created, the foreign key constraint is satisfied and the suggestion contains the
 // correct command ID.
-func (d *CommandData) Initialise() {
-	d.CommandID, _ = getLatestCommandID()
+func (d *CommandData) Initialise(database *Database) {
+	d.CommandID, _ = database.getLatestCommandID()
 	d.CommandID += 1
-	d.DBInsert()
+	d.DBInsert(database)
 }
 
 // End finalizes the CommandData struct by calculating the response time and inserting
 // the data into the database.
-func (d *CommandData) End() {
+func (d *CommandData) End(database *Database) {
 	d.EndTime = time.Now().UnixMilli()
 	d.ResponseTime = d.EndTime - d.StartTime
-	updateErr := d.DBUpdateResponseTime()
+	updateErr := d.DBUpdateResponseTime(database)
 	if updateErr != nil {
-		logs.LogError(" CMND", "error updating command",
+		database.log.LogError(" CMND", "error updating command",
 			"command", d.Command,
 			"err", updateErr)
 	}
 }
 
 // DBInsert inserts the CommandData struct into the commands table of the database.
-func (d *CommandData) DBInsert() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *CommandData) DBInsert(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -107,8 +104,8 @@ func (d *CommandData) DBInsert() error {
 
 // DBUpdateResponseTime updates the response time of the CommandData struct in the
 // commands table of the database.
-func (d *CommandData) DBUpdateResponseTime() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *CommandData) DBUpdateResponseTime(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -123,8 +120,8 @@ func (d *CommandData) DBUpdateResponseTime() error {
 
 // CheckUsageByUser checks the number of commands used by a user in a given period.
 // Period example: "-1 day", "-1 hour", "-1 minute"
-func CheckUsageByUser(userID string, period string) (int, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) CheckUsageByUser(userID string, period string) (int, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return 0, openErr
 	}
@@ -142,8 +139,8 @@ func CheckUsageByUser(userID string, period string) (int, error) {
 	return count, scanErr
 }
 
-func getLatestCommandID() (int, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) getLatestCommandID() (int, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return 0, openErr
 	}
@@ -159,8 +156,8 @@ func getLatestCommandID() (int, error) {
 
 // PerformMaintenance performs maintenance on the commands table of the database. It
 // deletes commands older than the specified number of days.
-func PerformCommandMaintenance() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) PerformCommandMaintenance() error {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -168,7 +165,7 @@ func PerformCommandMaintenance() error {
 
 	_, execErr := db.Exec(`DELETE FROM commands
 							WHERE used_date < DATE('now', ?)`,
-		fmt.Sprintf("-%d months", config.Values.Commands.MonthsToKeep))
+		fmt.Sprintf("-%d months", d.config().Commands.MonthsToKeep))
 
 	return execErr
 }
@@ -0,0 +1,254 @@
+/*
+entities.go contains the Publisher and Game structs and functions that interact with the
+publishers, games, stream_publishers, stream_games and server_publishers tables of the
+database. Publishers and games are linked to streams when streams are imported from the
+streams.toml file, and servers can follow publishers to have their streams announced.
+*/
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Publisher represents a row in the publishers table of the database.
+type Publisher struct {
+	// The unique identifier for the publisher.
+	ID int
+	// The name of the publisher or showcase (e.g. Nintendo Direct).
+	Name string
+}
+
+// Game represents a row in the games table of the database.
+type Game struct {
+	// The unique identifier for the game.
+	ID int
+	// The name of the game.
+	Name string
+}
+
+// linkStreamEntities replaces the publishers and games linked to the stream with the
+// given ID. Publishers and games that are not yet in the database are created.
+func (d *Database) linkStreamEntities(db *sql.DB, streamID int, publishers []string, games []string) error {
+	if _, execErr := db.Exec(`DELETE FROM stream_publishers
+								WHERE stream_id = ?`,
+		streamID); execErr != nil {
+		return execErr
+	}
+	if _, execErr := db.Exec(`DELETE FROM stream_games
+								WHERE stream_id = ?`,
+		streamID); execErr != nil {
+		return execErr
+	}
+	for _, name := range publishers {
+		publisherID, getErr := d.getOrCreate(db, "publishers", name)
+		if getErr != nil {
+			return getErr
+		}
+		if _, execErr := db.Exec(`INSERT OR IGNORE INTO stream_publishers
+									(stream_id,
+									publisher_id)
+								VALUES (?, ?)`,
+			streamID, publisherID); execErr != nil {
+			return execErr
+		}
+	}
+	for _, name := range games {
+		gameID, getErr := d.getOrCreate(db, "games", name)
+		if getErr != nil {
+			return getErr
+		}
+		if _, execErr := db.Exec(`INSERT OR IGNORE INTO stream_games
+									(stream_id,
+									game_id)
+								VALUES (?, ?)`,
+			streamID, gameID); execErr != nil {
+			return execErr
+		}
+	}
+	return nil
+}
+
+// getOrCreate returns the ID of the row in the given table (publishers or games) with
+// the given name, inserting a new row if one does not exist. Names are matched without
+// regard to case.
+func (d *Database) getOrCreate(db *sql.DB, table string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	row := db.QueryRow(fmt.Sprintf(`SELECT id
+									FROM %s
+									WHERE name = ? COLLATE NOCASE`, table),
+		name)
+
+	var id int
+	scanErr := row.Scan(&id)
+	if scanErr == nil {
+		return id, nil
+	} else if scanErr != sql.ErrNoRows {
+		return 0, scanErr
+	}
+	d.log.LogInfo("   DB", "adding entity", false,
+		"table", table,
+		"name", name)
+	result, execErr := db.Exec(fmt.Sprintf(`INSERT INTO %s (name)
+											VALUES (?)`, table),
+		name)
+	if execErr != nil {
+		return 0, execErr
+	}
+	newID, idErr := result.LastInsertId()
+	return int(newID), idErr
+}
+
+// LoadEntities populates the Publishers and Games fields of the stream from the
+// stream_publishers and stream_games tables of the database.
+func (s *Stream) LoadEntities(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	publishers, pubErr := database.queryNames(db, `SELECT publishers.name
+										FROM publishers
+										JOIN stream_publishers
+											ON publishers.id = stream_publishers.publisher_id
+										WHERE stream_publishers.stream_id = ?
+										ORDER BY publishers.name`, s.ID)
+	if pubErr != nil {
+		return pubErr
+	}
+	games, gameErr := database.queryNames(db, `SELECT games.name
+									FROM games
+									JOIN stream_games
+										ON games.id = stream_games.game_id
+									WHERE stream_games.stream_id = ?
+									ORDER BY games.name`, s.ID)
+	if gameErr != nil {
+		return gameErr
+	}
+	s.Publishers = publishers
+	s.Games = games
+	return nil
+}
+
+// queryNames runs the given query and returns the first column of each row.
+func (d *Database) queryNames(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, queryErr := db.Query(query, args...)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if scanErr := rows.Scan(&name); scanErr != nil {
+			return nil, scanErr
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// FindPublisher returns the publisher whose name best matches the given name. Partial
+// matches are allowed, with exact matches preferred.
+func (d *Database) FindPublisher(name string) (Publisher, bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return Publisher{}, false, openErr
+	}
+	defer db.Close()
+
+	name = strings.TrimSpace(name)
+	row := db.QueryRow(`SELECT id,
+							name
+						FROM publishers
+						WHERE name LIKE ? COLLATE NOCASE
+						ORDER BY name = ? COLLATE NOCASE DESC, LENGTH(name)
+						LIMIT 1`,
+		fmt.Sprintf("%%%s%%", name), name)
+
+	var p Publisher
+	scanErr := row.Scan(&p.ID, &p.Name)
+	if scanErr == sql.ErrNoRows {
+		return Publisher{}, false, nil
+	} else if scanErr != nil {
+		return Publisher{}, false, scanErr
+	}
+	return p, true, nil
+}
+
+// GetPublisher returns the publisher with the given ID.
+func (d *Database) GetPublisher(id int) (Publisher, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return Publisher{}, openErr
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`SELECT id,
+							name
+						FROM publishers
+						WHERE id = ?`,
+		id)
+
+	var p Publisher
+	scanErr := row.Scan(&p.ID, &p.Name)
+	return p, scanErr
+}
+
+// FollowPublisher adds the publisher to the publishers followed by the server.
+func (d *Database) FollowPublisher(serverID string, publisherID int) error {
+	d.log.LogInfo("   DB", "following publisher", false,
+		"server", serverID,
+		"publisher", publisherID)
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT OR IGNORE INTO server_publishers
+								(server_id,
+								publisher_id)
+							VALUES (?, ?)`,
+		serverID, publisherID)
+	return execErr
+}
+
+// UnfollowPublisher removes the publisher from the publishers followed by the server.
+func (d *Database) UnfollowPublisher(serverID string, publisherID int) error {
+	d.log.LogInfo("   DB", "unfollowing publisher", false,
+		"server", serverID,
+		"publisher", publisherID)
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`DELETE FROM server_publishers
+							WHERE server_id = ?
+							AND publisher_id = ?`,
+		serverID, publisherID)
+	return execErr
+}
+
+// GetServerPublishers returns the names of the publishers followed by the server.
+func (d *Database) GetServerPublishers(serverID string) ([]string, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	return d.queryNames(db, `SELECT publishers.name
+							FROM publishers
+							JOIN server_publishers
+								ON publishers.id = server_publishers.publisher_id
+							WHERE server_publishers.server_id = ?
+							ORDER BY publishers.name`, serverID)
+}
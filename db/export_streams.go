@@ -0,0 +1,92 @@
+/*
+export_streams.go contains functions that export the streams and templates in the
+database in the format of the streams.toml file, so they can be imported again.
+*/
+package db
+
+import (
+	"io"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	_ "github.com/mattn/go-sqlite3"
+
+	"gamestreams/utils"
+)
+
+// Export returns the streams and templates in the database in the format of
+// streams.toml. Stream IDs are left out so the file can be imported into another
+// database, and streams created from templates are left out as they are created again
+// from their templates. If upcoming is true, only streams from today onwards are
+// included.
+func (d *Database) Export(upcoming bool) (Streams, error) {
+	query := `SELECT *
+				FROM streams
+				WHERE id NOT IN (SELECT stream_id
+								FROM template_occurrences)`
+	if upcoming {
+		query += ` AND stream_date >= DATE('now')`
+	}
+	var s Streams
+	if queryErr := s.Query(d, query+` ORDER BY stream_date, start_time`); queryErr != nil {
+		return Streams{}, queryErr
+	}
+	for i := range s.Streams {
+		if loadErr := s.Streams[i].LoadEntities(d); loadErr != nil {
+			return Streams{}, loadErr
+		}
+		date, dateErr := utils.FormatTomlDate(s.Streams[i].Date)
+		if dateErr != nil {
+			return Streams{}, dateErr
+		}
+		s.Streams[i].Date = date
+		s.Streams[i].ID = 0
+	}
+
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return Streams{}, openErr
+	}
+	defer db.Close()
+
+	templates, templateErr := d.getTemplates(db)
+	if templateErr != nil {
+		return Streams{}, templateErr
+	}
+	for i, t := range templates {
+		overrides, overrideErr := d.getOverrides(db, t.ID)
+		if overrideErr != nil {
+			return Streams{}, overrideErr
+		}
+		dates := make([]string, 0, len(overrides))
+		for date := range overrides {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		for _, date := range dates {
+			o := overrides[date]
+			if o.Date, overrideErr = utils.FormatTomlDate(o.Date); overrideErr != nil {
+				return Streams{}, overrideErr
+			}
+			if o.NewDate, overrideErr = utils.FormatTomlDate(o.NewDate); overrideErr != nil {
+				return Streams{}, overrideErr
+			}
+			t.Overrides = append(t.Overrides, o)
+		}
+		var dateErr error
+		if t.Start, dateErr = utils.FormatTomlDate(t.Start); dateErr != nil {
+			return Streams{}, dateErr
+		}
+		if t.Until, dateErr = utils.FormatTomlDate(t.Until); dateErr != nil {
+			return Streams{}, dateErr
+		}
+		templates[i] = t
+	}
+	s.Templates = templates
+	return s, nil
+}
+
+// WriteTOML writes the streams and templates to w in the format of streams.toml.
+func (s Streams) WriteTOML(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(s)
+}
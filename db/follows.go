@@ -0,0 +1,356 @@
+/*
+follows.go contains the Follow struct and functions that interact with the user_follows,
+user_settings and user_notifications tables of the database. These tables allow users to
+follow streams, platforms and publishers and be reminded by DM when a followed stream is starting.
+*/
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Follow represents a row in the user_follows table of the database.
+type Follow struct {
+	// The unique identifier for the follow.
+	ID int
+	// The Discord ID of the user.
+	UserID string
+	// The type of follow (stream, platform or publisher).
+	Type string
+	// The stream ID, platform name or publisher ID being followed.
+	Value string
+	// The date the follow was added.
+	DateAdded string
+}
+
+// ErrFollowLimit is returned when a follow is added for a user that has reached the
+// follow limit.
+var ErrFollowLimit = errors.New("follow limit reached")
+
+// Insert adds the follow to the user_follows table of the database. If the user is
+// already following the same stream or platform, nothing is changed. If limit is
+// greater than 0 and the user already has that many follows, the follow is not added
+// and ErrFollowLimit is returned. The limit is checked in the same statement as the
+// insert, so concurrent commands cannot take the user over it.
+func (f *Follow) Insert(database *Database, limit int) error {
+	database.log.LogInfo("   DB", "adding follow", false,
+		"user", f.UserID,
+		"type", f.Type,
+		"value", f.Value)
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	result, execErr := db.Exec(`INSERT OR IGNORE INTO user_follows
+								(user_id,
+								follow_type,
+								follow_value,
+								date_added)
+							SELECT ?, ?, ?, DATE('now')
+							WHERE ? <= 0
+							OR (SELECT COUNT(*)
+								FROM user_follows
+								WHERE user_id = ?) < ?`,
+		f.UserID,
+		f.Type,
+		f.Value,
+		limit,
+		f.UserID,
+		limit)
+	if execErr != nil {
+		return execErr
+	}
+	inserted, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return rowsErr
+	}
+	if inserted > 0 || limit <= 0 {
+		return nil
+	}
+	// Nothing is inserted if the user already follows it or has reached the limit.
+	var existing int
+	scanErr := db.QueryRow(`SELECT COUNT(*)
+							FROM user_follows
+							WHERE user_id = ?
+							AND follow_type = ?
+							AND follow_value = ?`,
+		f.UserID,
+		f.Type,
+		f.Value).Scan(&existing)
+	if scanErr != nil {
+		return scanErr
+	}
+	if existing == 0 {
+		return ErrFollowLimit
+	}
+	return nil
+}
+
+// GetFollows returns all follows of the given user from the user_follows table.
+func (d *Database) GetFollows(userID string) ([]Follow, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(`SELECT id,
+									user_id,
+									follow_type,
+									follow_value,
+									date_added
+								FROM user_follows
+								WHERE user_id = ?
+								ORDER BY follow_type, id`,
+		userID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var follows []Follow
+	for rows.Next() {
+		var f Follow
+		if scanErr := rows.Scan(&f.ID, &f.UserID, &f.Type, &f.Value, &f.DateAdded); scanErr != nil {
+			return nil, scanErr
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
+
+// RemoveFollow removes the follow with the given ID from the user_follows table. The
+// user ID is required so that users can only remove their own follows.
+func (d *Database) RemoveFollow(userID string, followID int) error {
+	d.log.LogInfo("   DB", "removing follow", false,
+		"user", userID,
+		"id", followID)
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`DELETE FROM user_follows
+							WHERE user_id = ?
+							AND id = ?`,
+		userID,
+		followID)
+	return execErr
+}
+
+// GetStreamFollowers returns the IDs of the users that follow the given stream or one
+// of its platforms or publishers, have not opted out of DM notifications, and have not
+// already been notified about the stream.
+func (d *Database) GetStreamFollowers(stream Stream) ([]string, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	var platforms []string
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		platforms = append(platforms, strings.ToLower(strings.TrimSpace(platform)))
+	}
+	rows, queryErr := db.Query(`SELECT DISTINCT user_follows.user_id
+								FROM user_follows
+								LEFT JOIN user_settings
+									ON user_follows.user_id = user_settings.user_id
+								WHERE ((follow_type = 'stream' AND follow_value = ?)
+									OR (follow_type = 'platform'
+										AND INSTR(',' || ? || ',', ',' || follow_value || ',') > 0)
+									OR (follow_type = 'publisher'
+										AND CAST(follow_value AS INTEGER) IN (
+											SELECT publisher_id
+											FROM stream_publishers
+											WHERE stream_id = ?)))
+								AND IFNULL(user_settings.dm_notifications, 1) = 1
+								AND user_follows.user_id NOT IN (
+									SELECT user_id
+									FROM user_notifications
+									WHERE stream_id = ?)`,
+		strconv.Itoa(stream.ID),
+		strings.Join(platforms, ","),
+		stream.ID,
+		stream.ID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if scanErr := rows.Scan(&userID); scanErr != nil {
+			return nil, scanErr
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// DMNotificationsEnabled returns true if the user has not opted out of DM
+// notifications.
+func (d *Database) DMNotificationsEnabled(userID string) (bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`SELECT dm_notifications
+						FROM user_settings
+						WHERE user_id = ?`,
+		userID)
+
+	var enabled bool
+	scanErr := row.Scan(&enabled)
+	if scanErr == sql.ErrNoRows {
+		return true, nil
+	}
+	return enabled, scanErr
+}
+
+// SetDMNotifications sets whether the user receives DM notifications for the streams
+// and platforms they follow.
+func (d *Database) SetDMNotifications(userID string, enabled bool) error {
+	d.log.LogInfo("   DB", "setting DM notifications", false,
+		"user", userID,
+		"enabled", enabled)
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT INTO user_settings
+								(user_id,
+								dm_notifications)
+							VALUES (?, ?)
+							ON CONFLICT (user_id) DO UPDATE
+							SET dm_notifications = excluded.dm_notifications`,
+		userID,
+		enabled)
+	return execErr
+}
+
+// GetUserLocale returns the locale the user last used the bot in, for sending them DMs.
+// An empty string is returned if the user's locale is not known.
+func (d *Database) GetUserLocale(userID string) (string, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return "", openErr
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`SELECT IFNULL(locale, '')
+						FROM user_settings
+						WHERE user_id = ?`,
+		userID)
+
+	var locale string
+	scanErr := row.Scan(&locale)
+	if scanErr == sql.ErrNoRows {
+		return "", nil
+	}
+	return locale, scanErr
+}
+
+// SetUserLocale sets the locale used for the user's DMs.
+func (d *Database) SetUserLocale(userID string, locale string) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT INTO user_settings
+								(user_id,
+								locale)
+							VALUES (?, ?)
+							ON CONFLICT (user_id) DO UPDATE
+							SET locale = excluded.locale`,
+		userID,
+		locale)
+	return execErr
+}
+
+// CountUserNotifications returns the number of stream reminders sent to the user in the
+// last 24 hours.
+func (d *Database) CountUserNotifications(userID string) (int, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return 0, openErr
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`SELECT COUNT(*)
+						FROM user_notifications
+						WHERE user_id = ?
+						AND sent_at > ?`,
+		userID,
+		time.Now().UTC().Add(-24*time.Hour).Format(time.RFC3339))
+
+	var count int
+	scanErr := row.Scan(&count)
+	return count, scanErr
+}
+
+// AddUserNotification records that the user has been sent a reminder for the stream.
+func (d *Database) AddUserNotification(userID string, streamID int) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT OR IGNORE INTO user_notifications
+								(user_id,
+								stream_id,
+								sent_at)
+							VALUES (?, ?, ?)`,
+		userID,
+		streamID,
+		time.Now().UTC().Format(time.RFC3339))
+	return execErr
+}
+
+// PerformFollowMaintenance removes follows of streams and publishers that no longer
+// exist in the database and stream reminders that were sent more than a month ago.
+func (d *Database) PerformFollowMaintenance() error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`DELETE FROM user_follows
+							WHERE follow_type = 'stream'
+							AND CAST(follow_value AS INTEGER) NOT IN (
+								SELECT id
+								FROM streams)`)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM user_follows
+							WHERE follow_type = 'publisher'
+							AND CAST(follow_value AS INTEGER) NOT IN (
+								SELECT id
+								FROM publishers)`)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM user_notifications
+							WHERE sent_at < ?`,
+		time.Now().UTC().AddDate(0, -1, 0).Format(time.RFC3339))
+	return execErr
+}
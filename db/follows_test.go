@@ -0,0 +1,67 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFollowInsertLimit(t *testing.T) {
+	d, _ := openTestDB(t)
+	follows := []Follow{
+		{UserID: "1", Type: "platform", Value: "xbox"},
+		{UserID: "1", Type: "platform", Value: "playstation"},
+	}
+	for _, f := range follows {
+		if insertErr := f.Insert(d, 2); insertErr != nil {
+			t.Fatalf("Insert(%+v) error = %v", f, insertErr)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		follow  Follow
+		limit   int
+		wantErr error
+	}{
+		{"existing follow at the limit", follows[0], 2, nil},
+		{"new follow at the limit", Follow{UserID: "1", Type: "platform", Value: "nintendo"}, 2, ErrFollowLimit},
+		{"other user", Follow{UserID: "2", Type: "platform", Value: "nintendo"}, 2, nil},
+		{"no limit", Follow{UserID: "1", Type: "stream", Value: "1"}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if insertErr := tt.follow.Insert(d, tt.limit); !errors.Is(insertErr, tt.wantErr) {
+				t.Errorf("Insert() error = %v, want %v", insertErr, tt.wantErr)
+			}
+		})
+	}
+
+	saved, getErr := d.GetFollows("1")
+	if getErr != nil {
+		t.Fatalf("GetFollows() error = %v", getErr)
+	}
+	if len(saved) != 3 {
+		t.Errorf("user has %d follows, want 3", len(saved))
+	}
+}
+
+func TestUserLocale(t *testing.T) {
+	d, _ := openTestDB(t)
+	if locale, getErr := d.GetUserLocale("1"); getErr != nil || locale != "" {
+		t.Fatalf("GetUserLocale() = %q, %v, want empty for an unknown user", locale, getErr)
+	}
+	if setErr := d.SetDMNotifications("1", false); setErr != nil {
+		t.Fatalf("SetDMNotifications() error = %v", setErr)
+	}
+	if setErr := d.SetUserLocale("1", "de"); setErr != nil {
+		t.Fatalf("SetUserLocale() error = %v", setErr)
+	}
+
+	if locale, getErr := d.GetUserLocale("1"); getErr != nil || locale != "de" {
+		t.Errorf("GetUserLocale() = %q, %v, want de", locale, getErr)
+	}
+	// Setting the locale must not turn DM notifications back on.
+	if enabled, getErr := d.DMNotificationsEnabled("1"); getErr != nil || enabled {
+		t.Errorf("DMNotificationsEnabled() = %v, %v, want false", enabled, getErr)
+	}
+}
@@ -4,7 +4,6 @@ get_streams.go contains functions that query the streams table of the database.
 package db
 
 import (
-	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,8 +11,6 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/robfig/cron/v3"
-
-	"gamestreams/config"
 )
 
 // Stream is a representation of a row in the streams table of the database.
@@ -21,50 +18,63 @@ import (
 // This is used when batch deleting streams using streams.toml.
 type Stream struct {
 	// The unique identifier for the stream.
-	ID int
+	ID int `toml:"id,omitzero"`
 	// The name of the stream.
-	Name string
+	Name string `toml:"name"`
 	// The platform the stream is on (xbox, playstation, pc, nintendo, vr).
-	Platform string
+	Platform string `toml:"platform"`
 	// The date the stream is scheduled for.
-	Date string
+	Date string `toml:"date"`
 	// The time the stream is scheduled for.
-	Time string
+	Time string `toml:"time,omitempty"`
 	// Description of the stream.
-	Description string
+	Description string `toml:"description,omitempty"`
 	// The URL of the stream.
-	URL string
+	URL string `toml:"url,omitempty"`
+	// The status of the stream (rumoured, announced, confirmed, live, ended, cancelled
+	// or postponed).
+	Status string `toml:"status,omitempty"`
+	// The time the stream actually went live, in RFC3339 format. Empty if it has not
+	// been detected.
+	ActualStart string `toml:"-"`
+	// The time the stream was added to the streams table, in RFC3339 format. Empty for
+	// streams added before this was recorded.
+	AddedAt string `toml:"-"`
+	// The names of the publishers presenting the stream. Not stored in the streams
+	// table, see LoadEntities.
+	Publishers []string `toml:"publishers,omitempty"`
+	// The names of the games featured in the stream. Not stored in the streams table,
+	// see LoadEntities.
+	Games []string `toml:"games,omitempty"`
 	// A flag to determine if the stream should be deleted.
-	Delete bool
+	Delete bool `toml:"delete,omitempty"`
 }
 
 // Streams contains a slice of Stream structs.
 type Streams struct {
 	// A slice of Stream structs.
-	Streams []Stream
+	Streams []Stream `toml:"streams"`
+	// Recurring streams from the templates section of streams.toml.
+	Templates []Template `toml:"templates,omitempty"`
 }
 
 // Query is a helper function to query the database using the given query string (q)
 // and optional parameters. It will scan the results of the query into a Stream struct,
 // appending each stream to the Streams slice of the struct.
-func (s *Streams) Query(q string, params ...string) error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (s *Streams) Query(database *Database, q string, params ...string) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
 	defer db.Close()
 
-	var rows *sql.Rows
-	if len(params) > 0 {
-		rows, openErr = db.Query(q, params[0])
-		if openErr != nil {
-			return openErr
-		}
-	} else {
-		rows, openErr = db.Query(q)
-		if openErr != nil {
-			return openErr
-		}
+	args := make([]any, len(params))
+	for i, param := range params {
+		args[i] = param
+	}
+	rows, queryErr := db.Query(q, args...)
+	if queryErr != nil {
+		return queryErr
 	}
 	defer rows.Close()
 
@@ -77,7 +87,10 @@ func (s *Streams) Query(q string, params ...string) error {
 			&stream.Date,
 			&stream.Time,
 			&stream.Description,
-			&stream.URL)
+			&stream.URL,
+			&stream.Status,
+			&stream.ActualStart,
+			&stream.AddedAt)
 
 		if scanErr != nil {
 			return scanErr
@@ -89,14 +102,14 @@ func (s *Streams) Query(q string, params ...string) error {
 
 // GetUpcoming gets the next [limit] upcoming streams from the streams table of the
 // database. The limit is set in config.toml.
-func (s *Streams) GetUpcoming(params ...int) error {
+func (s *Streams) GetUpcoming(database *Database, params ...int) error {
 	var limit int
 	if len(params) == 0 {
-		limit = config.Values.Streams.Limit
+		limit = database.config().Streams.Limit
 	} else {
 		limit = params[0]
 	}
-	if err := s.Query(`SELECT *
+	if err := s.Query(database, `SELECT *
 						FROM streams
 						WHERE stream_date > DATE('now')
 					UNION
@@ -112,16 +125,55 @@ func (s *Streams) GetUpcoming(params ...int) error {
 	return nil
 }
 
+// GetUpcomingOn gets the next [limit] upcoming streams for the platform from the streams
+// table of the database, ordered by start time. If the platform is empty, streams for
+// every platform are returned.
+func (s *Streams) GetUpcomingOn(database *Database, platform string, limit int) error {
+	if err := s.Query(database, `SELECT *
+						FROM streams
+						WHERE (stream_date > DATE('now')
+							OR (stream_date = DATE('now')
+								AND start_time >= TIME('now')))
+						AND (? = ''
+							OR ',' || LOWER(REPLACE(platform, ' ', '')) || ',' LIKE '%,' || ? || ',%')
+						ORDER BY stream_date, start_time
+						LIMIT ?`,
+		platform,
+		platform,
+		strconv.Itoa(limit)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetNewest gets the [limit] most recently added streams for the platform from the
+// streams table of the database, newest first. If the platform is empty, streams for
+// every platform are returned.
+func (s *Streams) GetNewest(database *Database, platform string, limit int) error {
+	if err := s.Query(database, `SELECT *
+						FROM streams
+						WHERE ? = ''
+						OR ',' || LOWER(REPLACE(platform, ' ', '')) || ',' LIKE '%,' || ? || ',%'
+						ORDER BY id DESC
+						LIMIT ?`,
+		platform,
+		platform,
+		strconv.Itoa(limit)); err != nil {
+		return err
+	}
+	return nil
+}
+
 // GetToday gets all streams for today that have not yet started from the streams
 // table of the database. It also gets streams that are scheduled for tomorrow but
 // are scheduled to start before the configured stream notification cron time.
-func (s *Streams) GetToday() error {
-	schedule, err := cron.ParseStandard(config.Values.Schedule.StreamNotifications.Cron)
+func (s *Streams) GetToday(database *Database) error {
+	schedule, err := cron.ParseStandard(database.config().Schedule.StreamNotifications.Cron)
 	if err != nil {
 		return err
 	}
 	scheduleTime := schedule.Next(time.Now().UTC()).Format("15:04")
-	if err := s.Query(` SELECT *
+	if err := s.Query(database, ` SELECT *
 						FROM streams
 						WHERE stream_date = DATE('now')
 						AND start_time >= TIME('now')
@@ -137,15 +189,43 @@ func (s *Streams) GetToday() error {
 	return nil
 }
 
-// CheckTimeless checks for streams that are scheduled for the next 5 days
-// that do not have a time set. It notifies the owner which streams are missing a time
-// so they can be updated.
-func (s *Streams) CheckTimeless() error {
-	if err := s.Query(`SELECT *
+// CheckTimeless checks for streams that are scheduled for the next 5 days that do not
+// have a time set or whose time has not been confirmed. It notifies the owner which
+// streams are missing a time so they can be updated.
+func (s *Streams) CheckTimeless(database *Database) error {
+	if err := s.Query(database, `SELECT *
 						FROM streams
 						WHERE stream_date > DATE('now')
 						AND stream_date <= DATE('now', '+5 days')
-						AND start_time = ''`); err != nil {
+						AND (start_time = ''
+							OR status IN ('rumoured', 'announced'))`); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetByID gets the stream with the given ID from the streams table of the database.
+func (s *Streams) GetByID(database *Database, id int) error {
+	if err := s.Query(database, `SELECT *
+						FROM streams
+						WHERE id = ?`,
+		strconv.Itoa(id)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetEventWindow gets the streams with a start time set that are scheduled between
+// yesterday and the number of days ahead set in config.toml. Yesterday is included so
+// that streams which are still in progress are returned.
+func (s *Streams) GetEventWindow(database *Database) error {
+	if err := s.Query(database, `SELECT *
+						FROM streams
+						WHERE stream_date >= DATE('now', '-1 day')
+						AND stream_date <= DATE('now', ?)
+						AND start_time != ''
+						ORDER BY stream_date, start_time`,
+		fmt.Sprintf("+%d days", database.config().Events.DaysAhead)); err != nil {
 		return err
 	}
 	return nil
@@ -154,10 +234,10 @@ func (s *Streams) CheckTimeless() error {
 // GetInfo gets a stream from the streams table of the database by name. It appends
 // wildcard characters to the name to allow for partial matching so that the user
 // does not have to type the full name of the stream.
-func (s *Streams) GetInfo(name string) error {
+func (s *Streams) GetInfo(database *Database, name string) error {
 	name = fmt.Sprintf("%%%s%%", strings.Trim(name, " "))
 
-	if err := s.Query(`SELECT *
+	if err := s.Query(database, `SELECT *
 						FROM (
 							SELECT *
 							FROM streams
@@ -0,0 +1,145 @@
+/*
+job_runs.go contains the JobRun struct and functions that interact with the job_runs
+table of the database. Each row records a run of a scheduled job: what triggered it,
+when it started, how long it took and whether it succeeded, so the owner can see the
+history of each job and runs missed while the bot was not running can be caught up.
+*/
+package db
+
+import (
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The outcomes of a job run.
+const (
+	// JobSucceeded is a run that finished without an error.
+	JobSucceeded = "success"
+	// JobFailed is a run that returned an error or panicked.
+	JobFailed = "failure"
+)
+
+// JobRun is a run of a scheduled job.
+type JobRun struct {
+	// The ID of the run in the job_runs table.
+	ID int
+	// The name of the job, e.g. stream_update.
+	Job string
+	// What started the run, e.g. schedule, startup, catch-up or owner.
+	Trigger string
+	// The time the run started, in UTC.
+	StartedAt time.Time
+	// How long the run took.
+	Duration time.Duration
+	// The outcome of the run, either JobSucceeded or JobFailed.
+	Outcome string
+	// The error returned by the run, or "" if it succeeded.
+	Error string
+}
+
+// Insert adds the run to the job_runs table of the database and sets its ID.
+func (r *JobRun) Insert(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	result, execErr := db.Exec(`INSERT INTO job_runs
+								(job,
+								trigger,
+								started_at,
+								duration_ms,
+								outcome,
+								error)
+							VALUES (?, ?, ?, ?, ?, ?)`,
+		r.Job,
+		r.Trigger,
+		r.StartedAt.UTC().Format(time.RFC3339),
+		r.Duration.Milliseconds(),
+		r.Outcome,
+		r.Error)
+	if execErr != nil {
+		return execErr
+	}
+	id, idErr := result.LastInsertId()
+	if idErr != nil {
+		return idErr
+	}
+	r.ID = int(id)
+	return nil
+}
+
+// GetLastJobRuns returns the most recent run of each job that has been run, by the name
+// of the job.
+func (d *Database) GetLastJobRuns() (map[string]JobRun, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(`SELECT id,
+									job,
+									trigger,
+									started_at,
+									duration_ms,
+									outcome,
+									error
+								FROM job_runs
+								WHERE id IN (
+									SELECT MAX(id)
+									FROM job_runs
+									GROUP BY job)`)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	runs := make(map[string]JobRun)
+	for rows.Next() {
+		var r JobRun
+		var startedAt string
+		var durationMillis int64
+		scanErr := rows.Scan(&r.ID,
+			&r.Job,
+			&r.Trigger,
+			&startedAt,
+			&durationMillis,
+			&r.Outcome,
+			&r.Error)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		started, parseErr := time.Parse(time.RFC3339, startedAt)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		r.StartedAt = started
+		r.Duration = time.Duration(durationMillis) * time.Millisecond
+		runs[r.Job] = r
+	}
+	return runs, rows.Err()
+}
+
+// RemoveOldJobRuns removes the runs from the job_runs table of the database that
+// started more than the given number of days ago. The most recent run of each job is
+// kept, so missed runs can still be caught up.
+func (d *Database) RemoveOldJobRuns(daysToKeep int) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -daysToKeep).Format(time.RFC3339)
+	_, execErr := db.Exec(`DELETE FROM job_runs
+							WHERE started_at < ?
+							AND id NOT IN (
+								SELECT MAX(id)
+								FROM job_runs
+								GROUP BY job)`,
+		cutoff)
+	return execErr
+}
@@ -0,0 +1,102 @@
+/*
+message_templates.go contains the MessageTemplate struct and functions that interact with
+the message_templates table of the database. Each row holds the templates a server uses
+for the content and embed of its stream announcements.
+*/
+package db
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// MessageTemplate holds the announcement templates of a server. Templates use Go
+// text/template syntax. An empty template uses the default for that part of the message.
+type MessageTemplate struct {
+	// The Discord ID of the server.
+	ServerID string
+	// The template for the message content posted above the embed.
+	Content string
+	// The template for the title of the embed.
+	Title string
+	// The template for the description of the embed.
+	Description string
+	// The template for the footer of the embed.
+	Footer string
+	// A flag to determine if the stream thumbnail is left out of the embed.
+	HideThumbnail bool
+	// A flag to determine if the platforms field is left out of the embed.
+	HidePlatforms bool
+}
+
+// Custom returns true if any part of the template differs from the default.
+func (t *MessageTemplate) Custom() bool {
+	return t.Content != "" || t.Title != "" || t.Description != "" || t.Footer != "" ||
+		t.HideThumbnail || t.HidePlatforms
+}
+
+// GetMessageTemplate returns the announcement templates of the server with the given ID.
+// If the server has not set any templates, the default templates are returned.
+func (d *Database) GetMessageTemplate(serverID string) (MessageTemplate, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return MessageTemplate{}, openErr
+	}
+	defer db.Close()
+
+	t := MessageTemplate{ServerID: serverID}
+	scanErr := db.QueryRow(`SELECT content,
+								title,
+								description,
+								footer,
+								hide_thumbnail,
+								hide_platforms
+							FROM message_templates
+							WHERE server_id = ?`,
+		serverID).Scan(&t.Content,
+		&t.Title,
+		&t.Description,
+		&t.Footer,
+		&t.HideThumbnail,
+		&t.HidePlatforms)
+	if scanErr == sql.ErrNoRows {
+		return t, nil
+	}
+	return t, scanErr
+}
+
+// Set writes the templates to the message_templates table of the database, replacing
+// any templates the server had before. If every template is the default, the row is
+// removed.
+func (t *MessageTemplate) Set(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	if !t.Custom() {
+		_, execErr := db.Exec(`DELETE FROM message_templates
+								WHERE server_id = ?`,
+			t.ServerID)
+		return execErr
+	}
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO message_templates
+								(server_id,
+								content,
+								title,
+								description,
+								footer,
+								hide_thumbnail,
+								hide_platforms)
+							VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		t.ServerID,
+		t.Content,
+		t.Title,
+		t.Description,
+		t.Footer,
+		t.HideThumbnail,
+		t.HidePlatforms)
+	return execErr
+}
@@ -0,0 +1,113 @@
+/*
+scheduled_events.go contains the ScheduledEvent struct and functions that interact with
+the scheduled_events table of the database.
+*/
+package db
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ScheduledEvent represents a row in the scheduled_events table of the database. Each
+// row links a stream to the Discord scheduled event created for it in a server.
+type ScheduledEvent struct {
+	// The Discord ID of the server the event was created in.
+	ServerID string
+	// The ID of the stream the event was created for.
+	StreamID int
+	// The Discord ID of the scheduled event.
+	EventID string
+	// The name of the event when it was last synced.
+	Name string
+	// The start time of the event when it was last synced, in RFC3339 format.
+	StartTime string
+	// The URL of the stream when it was last synced.
+	URL string
+}
+
+// GetScheduledEvents returns all scheduled events in the scheduled_events table for the
+// given server ID. If the server ID is empty, the events for all servers are returned.
+func (d *Database) GetScheduledEvents(serverID string) ([]ScheduledEvent, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(`SELECT server_id,
+									stream_id,
+									event_id,
+									event_name,
+									start_time,
+									stream_url
+								FROM scheduled_events
+								WHERE ? = ''
+								OR server_id = ?`,
+		serverID, serverID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var events []ScheduledEvent
+	for rows.Next() {
+		var e ScheduledEvent
+		scanErr := rows.Scan(&e.ServerID,
+			&e.StreamID,
+			&e.EventID,
+			&e.Name,
+			&e.StartTime,
+			&e.URL)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// Set writes the scheduled event to the scheduled_events table of the database,
+// replacing any existing row for the same server and stream.
+func (e *ScheduledEvent) Set(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO scheduled_events
+								(server_id,
+								stream_id,
+								event_id,
+								event_name,
+								start_time,
+								stream_url)
+							VALUES (?, ?, ?, ?, ?, ?)`,
+		e.ServerID,
+		e.StreamID,
+		e.EventID,
+		e.Name,
+		e.StartTime,
+		e.URL)
+	return execErr
+}
+
+// Delete removes the scheduled event from the scheduled_events table of the database.
+func (e *ScheduledEvent) Delete(database *Database) error {
+	database.log.LogInfo("   DB", "removing scheduled event", false,
+		"server", e.ServerID,
+		"stream", e.StreamID)
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`DELETE FROM scheduled_events
+							WHERE server_id = ?
+							AND stream_id = ?`,
+		e.ServerID,
+		e.StreamID)
+	return execErr
+}
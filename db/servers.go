@@ -5,12 +5,10 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
-
-	"gamestreams/config"
-	"gamestreams/logs"
 )
 
 // Server represents a row in the servers table of the database.
@@ -32,8 +30,8 @@ type Server struct {
 }
 
 // GetAllServerIDs returns a slice of all server IDs from the servers table
-func GetAllServerIDs() ([]string, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) GetAllServerIDs() ([]string, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return nil, openErr
 	}
@@ -61,8 +59,8 @@ func GetAllServerIDs() ([]string, error) {
 
 // CheckServerID checks if the given server ID exists in the servers table. Returns
 // true if the server ID exists, false if it does not.
-func CheckServerID(serverID string) (bool, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) CheckServerID(serverID string) (bool, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return false, openErr
 	}
@@ -83,11 +81,33 @@ func CheckServerID(serverID string) (bool, error) {
 	return true, nil
 }
 
+// GetServerLocale returns the preferred locale of the given server from the servers
+// table. An empty string is returned if the server or its locale are not known.
+func (d *Database) GetServerLocale(serverID string) (string, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return "", openErr
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`SELECT IFNULL(locale, '')
+						FROM servers
+						WHERE server_id = ?`,
+		serverID)
+
+	var locale string
+	scanErr := row.Scan(&locale)
+	if scanErr == sql.ErrNoRows {
+		return "", nil
+	}
+	return locale, scanErr
+}
+
 // RemoveServer removes the given server ID from the servers table.
-func RemoveServer(serverID string) error {
-	logs.LogInfo("   DB", "removing server from servers table", false,
+func (d *Database) RemoveServer(serverID string) error {
+	d.log.LogInfo("   DB", "removing server from servers table", false,
 		"serverID", serverID)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -101,8 +121,8 @@ func RemoveServer(serverID string) error {
 }
 
 // NewServer adds a new server to the servers table in the database.
-func NewServer(serverID string, serverName string, ownerID string, joinedAt time.Time, memberCount int, locale string) error {
-	logs.LogInfo("   DB", "adding new server to servers table", false,
+func (d *Database) NewServer(serverID string, serverName string, ownerID string, joinedAt time.Time, memberCount int, locale string) error {
+	d.log.LogInfo("   DB", "adding new server to servers table", false,
 		"serverID", serverID)
 
 	s := Server{
@@ -114,19 +134,19 @@ func NewServer(serverID string, serverName string, ownerID string, joinedAt time
 		Locale:      locale,
 		Settings:    NewSettings(serverID),
 	}
-	if s.Set() != nil {
-		return s.Set()
+	if s.Set(d) != nil {
+		return s.Set(d)
 	}
-	if s.Settings.Set() != nil {
-		return s.Settings.Set()
+	if s.Settings.Set(d) != nil {
+		return s.Settings.Set(d)
 	}
 	return nil
 }
 
 // CheckServerColumns checks for servers that have missing columns in the servers table
 // and returns a slice of server IDs that have missing columns.
-func CheckServerColumns() ([]string, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) CheckServerColumns() ([]string, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return nil, openErr
 	}
@@ -161,21 +181,21 @@ func CheckServerColumns() ([]string, error) {
 // Set writes the server information from the struct to the servers table in the
 // database. If the server is not in the table, it will insert a new row. If the server
 // is in the table, it will update the row.
-func (s *Server) Set() error {
-	logs.LogInfo("   DB", "setting server settings", false,
+func (s *Server) Set(database *Database) error {
+	database.log.LogInfo("   DB", "setting server settings", false,
 		"serverID", s.ID)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
 	defer db.Close()
 
-	inServerTable, checkErr := CheckServerID(s.ID)
+	inServerTable, checkErr := database.CheckServerID(s.ID)
 	if checkErr != nil {
 		return checkErr
 	}
 	if !inServerTable {
-		db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+		db, openErr := database.Conn()
 		if openErr != nil {
 			return openErr
 		}
@@ -212,10 +232,10 @@ func (s *Server) Set() error {
 
 // Get populates the struct with information from the servers table in the database.
 // It uses the server ID from the struct to query the database.
-func (s *Server) Get() error {
-	logs.LogInfo("   DB", "getting server settings", false,
+func (s *Server) Get(database *Database) error {
+	database.log.LogInfo("   DB", "getting server settings", false,
 		"serverID", s.ID)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -235,8 +255,66 @@ func (s *Server) Get() error {
 		return scanErr
 	}
 
-	if getErr := s.Settings.Get(s.ID); getErr != nil {
+	if getErr := s.Settings.Get(database, s.ID); getErr != nil {
 		return getErr
 	}
 	return nil
 }
+
+// GetUnconfiguredServers returns the servers that joined at least the given number of
+// days ago, have not set an announce channel or any platforms, and have not already been
+// sent a setup reminder.
+func (d *Database) GetUnconfiguredServers(days int) ([]Server, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(`SELECT servers.server_id,
+									servers.server_name,
+									servers.owner_id
+								FROM servers
+								LEFT JOIN server_settings
+									ON servers.server_id = server_settings.server_id
+								WHERE servers.setup_reminded = 0
+								AND servers.date_joined != ''
+								AND servers.date_joined <= DATE('now', ?)
+								AND (IFNULL(server_settings.announce_channel, '') = ''
+									OR (IFNULL(server_settings.playstation, 0) = 0
+										AND IFNULL(server_settings.xbox, 0) = 0
+										AND IFNULL(server_settings.nintendo, 0) = 0
+										AND IFNULL(server_settings.pc, 0) = 0
+										AND IFNULL(server_settings.vr, 0) = 0))`,
+		fmt.Sprintf("-%d days", days))
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var servers []Server
+	for rows.Next() {
+		var s Server
+		if scanErr := rows.Scan(&s.ID, &s.Name, &s.OwnerID); scanErr != nil {
+			return nil, scanErr
+		}
+		servers = append(servers, s)
+	}
+	return servers, rows.Err()
+}
+
+// SetSetupReminded records that the owner of the given server has been sent a reminder
+// to set up the bot.
+func (d *Database) SetSetupReminded(serverID string) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`UPDATE servers
+							SET setup_reminded = 1
+							WHERE server_id = ?`,
+		serverID)
+	return execErr
+}
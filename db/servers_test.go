@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemoveServerDeletesServerRows(t *testing.T) {
+	d, _ := openTestDB(t)
+	if serverErr := d.NewServer("1", "Test Server", "2", time.Now(), 2, "en-US"); serverErr != nil {
+		t.Fatalf("NewServer() error = %v", serverErr)
+	}
+	settings := NewSettings("1")
+	settings.AnnounceChannel = StringSet{Value: "3", Set: true}
+	if setErr := settings.Set(d); setErr != nil {
+		t.Fatalf("Settings.Set() error = %v", setErr)
+	}
+	webhook := Webhook{ServerID: "1", Kind: WebhookJSON, URL: "https://example.com/hook", Enabled: true}
+	if insertErr := webhook.Insert(d); insertErr != nil {
+		t.Fatalf("Webhook.Insert() error = %v", insertErr)
+	}
+
+	if removeErr := d.RemoveServer("1"); removeErr != nil {
+		t.Fatalf("RemoveServer() error = %v", removeErr)
+	}
+	if d.CheckSettings("1") {
+		t.Error("settings of the removed server were kept")
+	}
+	webhooks, getErr := d.GetWebhooks("1")
+	if getErr != nil {
+		t.Fatalf("GetWebhooks() error = %v", getErr)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("removed server has %d webhooks, want 0", len(webhooks))
+	}
+}
+
+func TestSettingsSetAddsMissingServer(t *testing.T) {
+	d, _ := openTestDB(t)
+	settings := NewSettings("1")
+	settings.AnnounceChannel = StringSet{Value: "3", Set: true}
+	if setErr := settings.Set(d); setErr != nil {
+		t.Fatalf("Settings.Set() error = %v", setErr)
+	}
+	if inServerTable, checkErr := d.CheckServerID("1"); checkErr != nil || !inServerTable {
+		t.Errorf("CheckServerID() = %t, %v, want the server to be added", inServerTable, checkErr)
+	}
+}
@@ -5,15 +5,10 @@ server_settings table of the database.
 package db
 
 import (
-	"database/sql"
-	"fmt"
 	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
-
-	"gamestreams/config"
-	"gamestreams/logs"
 )
 
 // Settings is a struct that contains the settings for a server. These settings are used
@@ -36,6 +31,14 @@ type Settings struct {
 	PC BoolSet
 	// A flag to determine if the server wants VR stream announcements.
 	VR BoolSet
+	// The number of minutes before a stream starts that it is announced in the server.
+	// A value of 0 uses the notification_t_minus value from config.toml.
+	LeadTime IntSet
+	// A flag to determine if streams should also be posted as Discord scheduled events.
+	ScheduledEvents BoolSet
+	// A flag to determine if announcements are published to the servers following the
+	// announce channel, when it is an announcement channel.
+	Crosspost BoolSet
 	// A flag to determine if the server settings should be reset to default values.
 	Reset bool
 }
@@ -58,6 +61,15 @@ type BoolSet struct {
 	Set bool
 }
 
+// IntSet is a struct that contains an integer value and a boolean flag to determine
+// if the value has been set.
+type IntSet struct {
+	// The integer value.
+	Value int
+	// A flag to determine if the value has been set.
+	Set bool
+}
+
 // NewSettings returns a new Settings struct with default values and the given server ID.
 func NewSettings(serverID string) Settings {
 	return Settings{
@@ -69,6 +81,9 @@ func NewSettings(serverID string) Settings {
 		Nintendo:        BoolSet{false, false},
 		PC:              BoolSet{false, false},
 		VR:              BoolSet{false, false},
+		LeadTime:        IntSet{0, false},
+		ScheduledEvents: BoolSet{false, false},
+		Crosspost:       BoolSet{false, false},
 		Reset:           false,
 	}
 }
@@ -77,16 +92,16 @@ func NewSettings(serverID string) Settings {
 // database. If the server is not in the table, it will insert a new row. If the server
 // is in the table, it will update the row. If the server is not in the servers table,
 // it will first insert a new record in that table.
-func (s *Settings) Set() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (s *Settings) Set(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
 	defer db.Close()
-	logs.LogInfo("   DB", "applying settings", false, "server", s.ServerID, "settings", s)
+	database.log.LogInfo("   DB", "applying settings", false, "server", s.ServerID, "settings", s)
 
-	if !CheckSettings(s.ServerID) {
-		inServerTable, err := CheckServerID(s.ServerID)
+	if !database.CheckSettings(s.ServerID) {
+		inServerTable, err := database.CheckServerID(s.ServerID)
 		if err != nil {
 			return err
 		}
@@ -95,8 +110,9 @@ func (s *Settings) Set() error {
 									(server_id,
 									server_name,
 									owner_id,
-									date_joined)
-								VALUES (?, ?, ?, ?)`,
+									date_joined,
+									member_count)
+								VALUES (?, ?, ?, ?, ?)`,
 				s.ServerID,
 				"",
 				"",
@@ -115,8 +131,11 @@ func (s *Settings) Set() error {
 									xbox,
 									nintendo,
 									pc,
-									vr)
-								VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+									vr,
+									lead_time,
+									scheduled_events,
+									crosspost)
+								VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			s.ServerID,
 			s.AnnounceChannel.Value,
 			s.AnnounceRole.Value,
@@ -124,7 +143,10 @@ func (s *Settings) Set() error {
 			s.Xbox.Value,
 			s.Nintendo.Value,
 			s.PC.Value,
-			s.VR.Value)
+			s.VR.Value,
+			s.LeadTime.Value,
+			s.ScheduledEvents.Value,
+			s.Crosspost.Value)
 
 		if execErr != nil {
 			return execErr
@@ -138,7 +160,10 @@ func (s *Settings) Set() error {
 									xbox = ?,
 									nintendo = ?,
 									pc = ?,
-									vr = ?
+									vr = ?,
+									lead_time = ?,
+									scheduled_events = ?,
+									crosspost = ?
 								WHERE server_id = ?`,
 			s.AnnounceChannel.Value,
 			s.AnnounceRole.Value,
@@ -147,6 +172,9 @@ func (s *Settings) Set() error {
 			s.Nintendo.Value,
 			s.PC.Value,
 			s.VR.Value,
+			s.LeadTime.Value,
+			s.ScheduledEvents.Value,
+			s.Crosspost.Value,
 			s.ServerID)
 
 		if execErr != nil {
@@ -158,14 +186,14 @@ func (s *Settings) Set() error {
 
 // Get populates the Settings struct with information from the server_settings table in
 // the database. It uses the server ID from the struct to query the database.
-func (s *Settings) Get(serverID string) error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (s *Settings) Get(database *Database, serverID string) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
 	defer db.Close()
-	if !CheckSettings(serverID) {
-		if s.Set() != nil {
+	if !database.CheckSettings(serverID) {
+		if s.Set(database) != nil {
 			return openErr
 		}
 	}
@@ -176,7 +204,10 @@ func (s *Settings) Get(serverID string) error {
 							xbox,
 							nintendo,
 							pc,
-							vr
+							vr,
+							lead_time,
+							scheduled_events,
+							IFNULL(crosspost, 0)
 						FROM server_settings
 						WHERE server_id = ?`,
 		serverID)
@@ -188,7 +219,10 @@ func (s *Settings) Get(serverID string) error {
 		&s.Xbox.Value,
 		&s.Nintendo.Value,
 		&s.PC.Value,
-		&s.VR.Value)
+		&s.VR.Value,
+		&s.LeadTime.Value,
+		&s.ScheduledEvents.Value,
+		&s.Crosspost.Value)
 
 	if scanErr != nil {
 		return scanErr
@@ -196,44 +230,105 @@ func (s *Settings) Get(serverID string) error {
 	return nil
 }
 
-// GetPlatformServerIDs returns a list of server IDs that have the given platform set
-// to true in the servers table.
-func GetPlatformServerIDs(platform string) ([]string, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+// GetLeadTimes returns the distinct notification lead times, in minutes, used by the
+// servers that have an announce channel set and by the enabled webhooks. A lead time of
+// 0 is replaced with the notification_t_minus value from config.toml.
+func (d *Database) GetLeadTimes() ([]int, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return nil, openErr
 	}
 	defer db.Close()
 
-	platform = strings.ToLower(platform)
-	logs.Log.Info.WithPrefix("   DB").Info("getting server IDs for",
-		"platform", platform)
+	rows, queryErr := db.Query(`SELECT DISTINCT lead_time
+								FROM server_settings
+								WHERE announce_channel != ''
+								UNION
+								SELECT DISTINCT lead_time
+								FROM webhooks
+								WHERE enabled = 1`)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
 
-	query := fmt.Sprintf(`SELECT server_id
-							FROM server_settings
-							WHERE %s = 1`, platform)
+	seen := make(map[int]bool)
+	var leadTimes []int
+	for rows.Next() {
+		var leadTime int
+		if scanErr := rows.Scan(&leadTime); scanErr != nil {
+			return nil, scanErr
+		}
+		if leadTime <= 0 {
+			leadTime = d.config().Schedule.NotificationTMinus
+		}
+		if !seen[leadTime] {
+			seen[leadTime] = true
+			leadTimes = append(leadTimes, leadTime)
+		}
+	}
+	return leadTimes, rows.Err()
+}
+
+// GetAnnounceSettings returns the settings of every server that has an announce channel
+// set in the server_settings table.
+func (d *Database) GetAnnounceSettings() ([]Settings, error) {
+	return d.querySettings(`WHERE announce_channel != ''`)
+}
+
+// GetEventSettings returns the settings of every server that has enabled posting streams
+// as Discord scheduled events.
+func (d *Database) GetEventSettings() ([]Settings, error) {
+	return d.querySettings(`WHERE scheduled_events = 1`)
+}
 
-	rows, queryErr := db.Query(query)
+// querySettings returns the settings of every server in the server_settings table that
+// matches the given WHERE clause.
+func (d *Database) querySettings(where string, args ...interface{}) ([]Settings, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
 
+	rows, queryErr := db.Query(`SELECT server_id,
+									announce_channel,
+									announce_role,
+									playstation,
+									xbox,
+									nintendo,
+									pc,
+									vr,
+									lead_time,
+									scheduled_events,
+									IFNULL(crosspost, 0)
+								FROM server_settings `+where, args...)
 	if queryErr != nil {
 		return nil, queryErr
 	}
 	defer rows.Close()
 
-	var serverIDs []string
+	var settingsList []Settings
 	for rows.Next() {
-		var serverID string
-		scanErr := rows.Scan(&serverID)
+		var s Settings
+		scanErr := rows.Scan(&s.ServerID,
+			&s.AnnounceChannel.Value,
+			&s.AnnounceRole.Value,
+			&s.Playstation.Value,
+			&s.Xbox.Value,
+			&s.Nintendo.Value,
+			&s.PC.Value,
+			&s.VR.Value,
+			&s.LeadTime.Value,
+			&s.ScheduledEvents.Value,
+			&s.Crosspost.Value)
+
 		if scanErr != nil {
 			return nil, scanErr
 		}
-		serverIDs = append(serverIDs, fmt.Sprint(serverID))
+		settingsList = append(settingsList, s)
 	}
-	err := rows.Err()
-	if err != nil {
-		logs.Log.Info.Error(err)
-	}
-	return serverIDs, nil
+	return settingsList, rows.Err()
 }
 
 // Merge will merge the values of the given settings struct into the settings struct
@@ -261,14 +356,68 @@ func (s *Settings) Merge(t Settings) {
 	if t.VR.Set {
 		s.VR = t.VR
 	}
+	if t.LeadTime.Set {
+		s.LeadTime = t.LeadTime
+	}
+	if t.ScheduledEvents.Set {
+		s.ScheduledEvents = t.ScheduledEvents
+	}
+	if t.Crosspost.Set {
+		s.Crosspost = t.Crosspost
+	}
+}
+
+// FollowsPlatform returns true if the server is following the given platform.
+func (s *Settings) FollowsPlatform(platform string) bool {
+	switch strings.ToLower(strings.TrimSpace(platform)) {
+	case "playstation":
+		return s.Playstation.Value
+	case "xbox":
+		return s.Xbox.Value
+	case "nintendo":
+		return s.Nintendo.Value
+	case "pc":
+		return s.PC.Value
+	case "vr":
+		return s.VR.Value
+	}
+	return false
+}
+
+// FollowsStream returns true if the server is following one or more of the platforms
+// of the given stream.
+func (s *Settings) FollowsStream(stream Stream) bool {
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		if s.FollowsPlatform(platform) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationLead returns the number of minutes before a stream starts that it should
+// be announced in the server. If the server has not set a lead time, defaultLead, the
+// notification_t_minus value from config.toml, is used.
+func (s *Settings) NotificationLead(defaultLead int) int {
+	if s.LeadTime.Value > 0 {
+		return s.LeadTime.Value
+	}
+	return defaultLead
+}
+
+// Configured returns true if the server has set an announce channel and is following
+// at least one platform.
+func (s *Settings) Configured() bool {
+	return s.AnnounceChannel.Value != "" &&
+		(s.Playstation.Value || s.Xbox.Value || s.Nintendo.Value || s.PC.Value || s.VR.Value)
 }
 
 // checkOptions checks if the given server ID exists in the servers table of the
 // database. Returns true if the server ID exists.
-func CheckSettings(serverID string) bool {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) CheckSettings(serverID string) bool {
+	db, openErr := d.Conn()
 	if openErr != nil {
-		logs.LogError("   DB", "error opening database", "err", openErr)
+		d.log.LogError("   DB", "error opening database", "err", openErr)
 		return false
 	}
 	defer db.Close()
@@ -281,3 +430,45 @@ func CheckSettings(serverID string) bool {
 	getErr := rows.Scan(&serverID)
 	return getErr == nil
 }
+
+// SetChannelUnusable records that the bot cannot post in the announce channel of the
+// server. It returns true if the channel was last recorded as usable, so the server
+// owner is only told about the problem once.
+func (d *Database) SetChannelUnusable(serverID string) (bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	result, execErr := db.Exec(`UPDATE server_settings
+								SET channel_unusable = 1
+								WHERE server_id = ?
+								AND IFNULL(channel_unusable, 0) = 0`,
+		serverID)
+	if execErr != nil {
+		return false, execErr
+	}
+	changed, rowsErr := result.RowsAffected()
+	if rowsErr != nil {
+		return false, rowsErr
+	}
+	return changed > 0, nil
+}
+
+// SetChannelUsable records that the bot can post in the announce channel of the server,
+// so the owner is told again if it later becomes unusable.
+func (d *Database) SetChannelUsable(serverID string) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`UPDATE server_settings
+							SET channel_unusable = 0
+							WHERE server_id = ?
+							AND channel_unusable = 1`,
+		serverID)
+	return execErr
+}
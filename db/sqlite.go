@@ -1,18 +1,86 @@
 /*
-sqlite.go contains functions to create the database and tables.
+sqlite.go contains the Database type and functions to open the database and create its
+tables.
 */
 package db
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	"gamestreams/config"
 	"gamestreams/logs"
+	"gamestreams/utils"
 )
 
-// CreateDB creates the database if it does not exist. It creates the streams, config,
+// ErrClosed is returned when the database is used after it has been closed.
+var ErrClosed = errors.New("database is closed")
+
+// Database is the SQLite database that the bot stores its data in.
+type Database struct {
+	// The path of the database file.
+	Path string
+	// True once the database has been closed.
+	closed atomic.Bool
+	// Returns the current configuration values.
+	config func() *config.Config
+	// The logger that database events are logged with.
+	log *logs.Logger
+	// The client that streams.toml is downloaded with.
+	http *utils.Client
+}
+
+// New returns the database at the path without opening it. The database reads its
+// settings with cfg, logs with log and downloads streams.toml with client.
+func New(path string, cfg func() *config.Config, log *logs.Logger, client *utils.Client) *Database {
+	return &Database{Path: path, config: cfg, log: log, http: client}
+}
+
+// Open opens the database at the path, creating it and its tables if they do not exist.
+// The arguments are the same as those of New.
+func Open(path string, cfg func() *config.Config, log *logs.Logger, client *utils.Client) (*Database, error) {
+	d := New(path, cfg, log, client)
+	if createErr := d.create(); createErr != nil {
+		return nil, createErr
+	}
+	return d, nil
+}
+
+// foreignKeys is added to the path of the database when it is opened. SQLite only
+// enforces foreign keys, and so ON DELETE CASCADE, on connections that turn them on.
+const foreignKeys = "?_fk=1"
+
+// Conn returns a connection to the database. It must be closed by the caller. It
+// returns ErrClosed if the database has been closed.
+func (d *Database) Conn() (*sql.DB, error) {
+	if d.closed.Load() {
+		return nil, ErrClosed
+	}
+	return sql.Open("sqlite3", d.Path+foreignKeys)
+}
+
+// Close optimises the database and closes it, so it is left in a consistent state when
+// the bot exits. Connections that are already open are not affected, but no new ones
+// can be opened.
+func (d *Database) Close() error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+	d.closed.Store(true)
+
+	if _, execErr := db.Exec("PRAGMA optimize"); execErr != nil {
+		return execErr
+	}
+	return nil
+}
+
+// create creates the database if it does not exist. It creates the streams, config,
 // and servers tables.
 // streams contains information about the streams.
 // streams_toml contains information about updating the streams table from a toml file.
@@ -23,9 +91,25 @@ import (
 // commands contains information about commands that are run by users.
 // suggestions contains information about stream suggestions that are made by users.
 // suggestions_archive contains anonymised suggestions for later use.
-func CreateDB() error {
-	logs.LogInfo(" MAIN", "loading/creating database", false)
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database+"?_fk=1&_cache_size=10000")
+// publishers and games contain the publishers and games that streams are linked to.
+// stream_publishers and stream_games link streams to publishers and games.
+// server_publishers contains the publishers that servers follow.
+// user_follows contains the streams, platforms and publishers that users follow.
+// user_settings contains the notification preferences and locales of users.
+// user_notifications contains the stream reminders that have been sent to users.
+// scheduled_events contains the Discord scheduled events created for streams.
+// stream_templates contains recurring streams that are expanded into the streams table.
+// template_overrides contains changes to and cancellations of single occurrences.
+// template_occurrences links each occurrence of a template to its row in streams.
+// announcements contains the stream announcements that have been posted to servers,
+// and those that failed to post.
+// url_metadata caches the direct URLs and metadata resolved for stream URLs.
+// message_templates contains the announcement templates of each server.
+// webhooks contains the outbound webhooks that stream announcements are delivered to.
+// job_runs contains the history of the scheduled jobs that have been run.
+func (d *Database) create() error {
+	d.log.LogInfo(" MAIN", "loading/creating database", false)
+	db, openErr := sql.Open("sqlite3", d.Path+foreignKeys+"&_cache_size=10000")
 	if openErr != nil {
 		return openErr
 	}
@@ -44,6 +128,30 @@ func CreateDB() error {
 		return tableErr
 	}
 
+	if colErr := d.addColumn(db, "streams", "status", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "streams", "actual_start", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "streams", "added_at", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	// Streams added before the status column existed are confirmed if they have a
+	// start time and announced otherwise.
+	_, tableErr = db.Exec(`UPDATE streams
+							SET status = CASE WHEN IFNULL(start_time, '') = ''
+								THEN 'announced'
+								ELSE 'confirmed' END
+							WHERE IFNULL(status, '') = ''`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
 	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS stream_toml
 								(id INTEGER PRIMARY KEY AUTOINCREMENT,
 								last_updated TEXT)`)
@@ -64,6 +172,10 @@ func CreateDB() error {
 		return tableErr
 	}
 
+	if colErr := d.addColumn(db, "servers", "setup_reminded", "BOOLEAN DEFAULT 0"); colErr != nil {
+		return colErr
+	}
+
 	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS server_settings
 								(server_id TEXT NOT NULL PRIMARY KEY,
 								announce_channel TEXT,
@@ -80,6 +192,22 @@ func CreateDB() error {
 		return tableErr
 	}
 
+	if colErr := d.addColumn(db, "server_settings", "lead_time", "INTEGER DEFAULT 0"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "server_settings", "scheduled_events", "BOOLEAN DEFAULT 0"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "server_settings", "crosspost", "BOOLEAN DEFAULT 0"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "server_settings", "channel_unusable", "BOOLEAN DEFAULT 0"); colErr != nil {
+		return colErr
+	}
+
 	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS blacklist
 								(discord_id TEXT NOT NULL,
 								id_type TEXT,
@@ -134,5 +262,311 @@ func CreateDB() error {
 		return tableErr
 	}
 
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS publishers
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								name TEXT NOT NULL UNIQUE COLLATE NOCASE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS games
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								name TEXT NOT NULL UNIQUE COLLATE NOCASE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS stream_publishers
+								(stream_id INTEGER NOT NULL,
+								publisher_id INTEGER NOT NULL,
+								PRIMARY KEY (stream_id, publisher_id),
+								FOREIGN KEY (stream_id) REFERENCES streams (id)
+									ON DELETE CASCADE,
+								FOREIGN KEY (publisher_id) REFERENCES publishers (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS stream_games
+								(stream_id INTEGER NOT NULL,
+								game_id INTEGER NOT NULL,
+								PRIMARY KEY (stream_id, game_id),
+								FOREIGN KEY (stream_id) REFERENCES streams (id)
+									ON DELETE CASCADE,
+								FOREIGN KEY (game_id) REFERENCES games (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS server_publishers
+								(server_id TEXT NOT NULL,
+								publisher_id INTEGER NOT NULL,
+								PRIMARY KEY (server_id, publisher_id),
+								FOREIGN KEY (server_id) REFERENCES servers (server_id)
+									ON DELETE CASCADE,
+								FOREIGN KEY (publisher_id) REFERENCES publishers (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS user_follows
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								user_id TEXT NOT NULL,
+								follow_type TEXT NOT NULL,
+								follow_value TEXT NOT NULL,
+								date_added TEXT,
+								UNIQUE (user_id, follow_type, follow_value))`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS user_settings
+								(user_id TEXT NOT NULL PRIMARY KEY,
+								dm_notifications BOOLEAN DEFAULT 1)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	if colErr := d.addColumn(db, "user_settings", "locale", "TEXT"); colErr != nil {
+		return colErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS user_notifications
+								(user_id TEXT NOT NULL,
+								stream_id INTEGER NOT NULL,
+								sent_at TEXT,
+								PRIMARY KEY (user_id, stream_id))`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS scheduled_events
+								(server_id TEXT NOT NULL,
+								stream_id INTEGER NOT NULL,
+								event_id TEXT,
+								event_name TEXT,
+								start_time TEXT,
+								stream_url TEXT,
+								PRIMARY KEY (server_id, stream_id),
+								FOREIGN KEY (server_id) REFERENCES servers (server_id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS stream_templates
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								template_key TEXT NOT NULL UNIQUE,
+								stream_name TEXT,
+								platform TEXT,
+								start_time TEXT,
+								stream_desc TEXT,
+								stream_url TEXT,
+								publishers TEXT,
+								games TEXT,
+								rrule TEXT NOT NULL,
+								start_date TEXT NOT NULL,
+								until_date TEXT)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	if colErr := d.addColumn(db, "stream_templates", "status", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS template_overrides
+								(template_id INTEGER NOT NULL,
+								occurrence_date TEXT NOT NULL,
+								cancelled BOOLEAN DEFAULT 0,
+								stream_date TEXT,
+								start_time TEXT,
+								stream_name TEXT,
+								stream_desc TEXT,
+								stream_url TEXT,
+								PRIMARY KEY (template_id, occurrence_date),
+								FOREIGN KEY (template_id) REFERENCES stream_templates (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	if colErr := d.addColumn(db, "template_overrides", "status", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS template_occurrences
+								(template_id INTEGER NOT NULL,
+								occurrence_date TEXT NOT NULL,
+								stream_id INTEGER NOT NULL UNIQUE,
+								PRIMARY KEY (template_id, occurrence_date),
+								FOREIGN KEY (template_id) REFERENCES stream_templates (id)
+									ON DELETE CASCADE,
+								FOREIGN KEY (stream_id) REFERENCES streams (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS announcements
+								(server_id TEXT NOT NULL,
+								stream_id INTEGER NOT NULL,
+								channel_id TEXT,
+								message_id TEXT,
+								sent_at TEXT,
+								followup_status TEXT DEFAULT '',
+								PRIMARY KEY (server_id, stream_id),
+								FOREIGN KEY (server_id) REFERENCES servers (server_id)
+									ON DELETE CASCADE,
+								FOREIGN KEY (stream_id) REFERENCES streams (id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "status", "TEXT DEFAULT 'sent'"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "error", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "attempts", "INTEGER DEFAULT 1"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "stream_url", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "updated_at", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	if colErr := d.addColumn(db, "announcements", "publish_status", "TEXT DEFAULT ''"); colErr != nil {
+		return colErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS url_metadata
+								(url TEXT NOT NULL,
+								kind TEXT NOT NULL,
+								value TEXT,
+								fetched_at TEXT,
+								PRIMARY KEY (url, kind))`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS message_templates
+								(server_id TEXT NOT NULL PRIMARY KEY,
+								content TEXT DEFAULT '',
+								title TEXT DEFAULT '',
+								description TEXT DEFAULT '',
+								footer TEXT DEFAULT '',
+								hide_thumbnail BOOLEAN DEFAULT 0,
+								hide_platforms BOOLEAN DEFAULT 0,
+								FOREIGN KEY (server_id) REFERENCES servers (server_id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS webhooks
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								server_id TEXT,
+								kind TEXT NOT NULL,
+								url TEXT NOT NULL,
+								secret TEXT DEFAULT '',
+								platforms TEXT DEFAULT '',
+								lead_time INTEGER DEFAULT 0,
+								enabled BOOLEAN DEFAULT 1,
+								failures INTEGER DEFAULT 0,
+								last_error TEXT DEFAULT '',
+								created_at TEXT,
+								delivered_at TEXT DEFAULT '',
+								FOREIGN KEY (server_id) REFERENCES servers (server_id)
+									ON DELETE CASCADE)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE TABLE IF NOT EXISTS job_runs
+								(id INTEGER PRIMARY KEY AUTOINCREMENT,
+								job TEXT NOT NULL,
+								trigger TEXT NOT NULL,
+								started_at TEXT NOT NULL,
+								duration_ms INTEGER DEFAULT 0,
+								outcome TEXT NOT NULL,
+								error TEXT DEFAULT '')`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
+	_, tableErr = db.Exec(`CREATE INDEX IF NOT EXISTS job_runs_job
+								ON job_runs (job, started_at)`)
+
+	if tableErr != nil {
+		return tableErr
+	}
+
 	return nil
 }
+
+// addColumn adds a column with the given definition to a table if the table does not
+// already contain it. This allows columns to be added to databases that were created
+// before the column existed.
+func (d *Database) addColumn(db *sql.DB, table string, column string, definition string) error {
+	rows, queryErr := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if scanErr := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); scanErr != nil {
+			return scanErr
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return rowsErr
+	}
+	rows.Close()
+
+	d.log.LogInfo("   DB", "adding column", false,
+		"table", table,
+		"column", column)
+	_, execErr := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return execErr
+}
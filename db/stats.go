@@ -0,0 +1,59 @@
+/*
+stats.go contains functions that report the size of the tables of the database.
+*/
+package db
+
+import (
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TableStats is the number of rows in a table of the database.
+type TableStats struct {
+	// The name of the table.
+	Name string
+	// The number of rows in the table.
+	Rows int
+}
+
+// Stats returns the number of rows in each table of the database, ordered by the name
+// of the table.
+func (d *Database) Stats() ([]TableStats, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(`SELECT name
+								FROM sqlite_master
+								WHERE type = 'table'
+								AND name NOT LIKE 'sqlite_%'
+								ORDER BY name`)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	var tables []TableStats
+	for rows.Next() {
+		var t TableStats
+		if scanErr := rows.Scan(&t.Name); scanErr != nil {
+			rows.Close()
+			return nil, scanErr
+		}
+		tables = append(tables, t)
+	}
+	rows.Close()
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	for i, t := range tables {
+		// table names come from sqlite_master, so are safe to quote into the query
+		row := db.QueryRow(fmt.Sprintf(`SELECT count(*) FROM "%s"`, t.Name))
+		if scanErr := row.Scan(&tables[i].Rows); scanErr != nil {
+			return nil, scanErr
+		}
+	}
+	return tables, nil
+}
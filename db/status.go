@@ -0,0 +1,135 @@
+/*
+status.go contains the statuses a stream can have and functions for setting and checking
+them. A stream starts as rumoured or announced, is confirmed once its start time is known,
+and ends as ended, cancelled or postponed.
+*/
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// StatusRumoured is a stream whose date is not officially known.
+	StatusRumoured = "rumoured"
+	// StatusAnnounced is a stream whose date has been announced but whose start time
+	// has not been confirmed.
+	StatusAnnounced = "announced"
+	// StatusConfirmed is a stream whose date and start time have been confirmed.
+	StatusConfirmed = "confirmed"
+	// StatusLive is a stream that is currently live.
+	StatusLive = "live"
+	// StatusEnded is a stream that has finished.
+	StatusEnded = "ended"
+	// StatusCancelled is a stream that will not take place.
+	StatusCancelled = "cancelled"
+	// StatusPostponed is a stream that has been delayed to an unknown date.
+	StatusPostponed = "postponed"
+)
+
+// statusAliases maps the statuses accepted in streams.toml to the stored statuses.
+var statusAliases = map[string]string{
+	"rumoured":       StatusRumoured,
+	"rumored":        StatusRumoured,
+	"announced":      StatusAnnounced,
+	"confirmed":      StatusConfirmed,
+	"time-confirmed": StatusConfirmed,
+	"time_confirmed": StatusConfirmed,
+	"live":           StatusLive,
+	"ended":          StatusEnded,
+	"cancelled":      StatusCancelled,
+	"canceled":       StatusCancelled,
+	"postponed":      StatusPostponed,
+}
+
+// ParseStatus returns the stored form of the given status. If the status is empty, the
+// default status for a stream with the given start time is returned.
+func ParseStatus(status string, startTime string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" {
+		return defaultStatus(startTime), nil
+	}
+	parsed, found := statusAliases[status]
+	if !found {
+		return "", fmt.Errorf("invalid stream status %q", status)
+	}
+	return parsed, nil
+}
+
+// defaultStatus returns confirmed if the start time is set and announced otherwise.
+func defaultStatus(startTime string) string {
+	if strings.TrimSpace(startTime) == "" {
+		return StatusAnnounced
+	}
+	return StatusConfirmed
+}
+
+// Announceable returns true if the stream has a confirmed start time and has not been
+// cancelled, postponed or ended, so it can be announced to servers and followers.
+func (s Stream) Announceable() bool {
+	return s.Time != "" && (s.Status == StatusConfirmed || s.Status == StatusLive)
+}
+
+// Upcoming returns true if the stream is expected to take place on its date. Rumoured,
+// cancelled, postponed and ended streams are not upcoming.
+func (s Stream) Upcoming() bool {
+	return s.Status == StatusAnnounced || s.Status == StatusConfirmed || s.Status == StatusLive
+}
+
+// SetStatus sets the status of the stream with the given ID in the streams table.
+func (d *Database) SetStatus(streamID int, status string) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`UPDATE streams
+							SET status = ?
+							WHERE id = ?`,
+		status,
+		streamID)
+	return execErr
+}
+
+// SetLive sets the status of the stream with the given ID to live and records the time
+// it was detected as live.
+func (d *Database) SetLive(streamID int, actualStart time.Time) error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`UPDATE streams
+							SET status = ?,
+								actual_start = ?
+							WHERE id = ?`,
+		StatusLive,
+		actualStart.UTC().Format(time.RFC3339),
+		streamID)
+	return execErr
+}
+
+// MarkEndedStreams sets the status of confirmed and live streams from previous days to
+// ended.
+func (d *Database) MarkEndedStreams() error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`UPDATE streams
+							SET status = ?
+							WHERE stream_date < DATE('now')
+							AND status IN (?, ?)`,
+		StatusEnded,
+		StatusConfirmed,
+		StatusLive)
+	return execErr
+}
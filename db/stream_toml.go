@@ -8,19 +8,12 @@ package db
 import (
 	"database/sql"
 	"io"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/tidwall/gjson"
-
-	"gamestreams/config"
-	"gamestreams/logs"
 )
 
-// StreamToml holds information about the streams.toml file.
-var StreamToml StreamTOML
-
 // StreamTOML represents a row in the stream_toml table of the database.
 type StreamTOML struct {
 	// The ID of the row.
@@ -32,8 +25,8 @@ type StreamTOML struct {
 }
 
 // Get retrieves the stream_toml values from the database and stores them in the struct.
-func (t *StreamTOML) Get() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (t *StreamTOML) Get(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -45,8 +38,8 @@ func (t *StreamTOML) Get() error {
 
 	scanErr := row.Scan(&t.ID, &t.LastUpdate)
 	if scanErr == sql.ErrNoRows {
-		logs.LogInfo("   DB", "No stream_toml values found, setting default", false)
-		if defaultErr := t.SetDefault(); defaultErr != nil {
+		database.log.LogInfo("   DB", "No stream_toml values found, setting default", false)
+		if defaultErr := t.SetDefault(database); defaultErr != nil {
 			return defaultErr
 		}
 	} else if scanErr != nil {
@@ -56,10 +49,10 @@ func (t *StreamTOML) Get() error {
 }
 
 // SetDefault sets the default values for the stream_toml table in the database.
-func (t *StreamTOML) SetDefault() error {
-	logs.LogInfo("   DB", "Setting default stream_toml values", false)
+func (t *StreamTOML) SetDefault(database *Database) error {
+	database.log.LogInfo("   DB", "Setting default stream_toml values", false)
 
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -78,10 +71,10 @@ func (t *StreamTOML) SetDefault() error {
 }
 
 // Set writes the current values of the struct to the stream_toml table in the database.
-func (t *StreamTOML) Set() error {
-	logs.LogInfo("   DB", "Updating stream_toml values", false)
+func (t *StreamTOML) Set(database *Database) error {
+	database.log.LogInfo("   DB", "Updating stream_toml values", false)
 
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -103,11 +96,11 @@ func (t *StreamTOML) Set() error {
 // last commit to the streams.toml in the flat-files repository and compares it to the
 // last update time stored in the database. If the commit time is after the last update
 // time, it returns true.
-func (t *StreamTOML) Check() (bool, error) {
+func (t *StreamTOML) Check(database *Database) (bool, error) {
 	if t.LastUpdate == "" {
 		return true, nil
 	}
-	response, httpErr := http.Get(config.Values.Github.APIURL)
+	response, httpErr := database.http.Get(database.config().Github.APIURL)
 	if httpErr != nil {
 		return false, httpErr
 	}
@@ -5,13 +5,11 @@ database.
 package db
 
 import (
-	"database/sql"
 	"errors"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
-	"gamestreams/config"
 	"gamestreams/utils"
 )
 
@@ -59,8 +57,8 @@ func NewSuggestion(name, date, url string) (*Suggestion, error) {
 }
 
 // Insert inserts the suggestion into the suggestions table of the database.
-func (s *Suggestion) Insert() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (s *Suggestion) Insert(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -78,8 +76,8 @@ func (s *Suggestion) Insert() error {
 
 // GetSuggestions gets the last [limit] suggestions from the suggestions table of the
 // database. It returns a slice of Suggestion structs.
-func GetSuggestions(limit int) ([]Suggestion, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) GetSuggestions(limit int) ([]Suggestion, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return nil, openErr
 	}
@@ -110,8 +108,8 @@ func GetSuggestions(limit int) ([]Suggestion, error) {
 
 // RemoveOldSuggestions removes suggestions that are older than the number of days
 // specified in config.toml.
-func RemoveOldSuggestions() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) RemoveOldSuggestions() error {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -123,7 +121,7 @@ func RemoveOldSuggestions() error {
 								FROM commands
 								WHERE used_date < DATETIME('now', ? || ' days')
 								AND command = "suggest")`,
-		-config.Values.Suggestions.DaysToKeep)
+		-d.config().Suggestions.DaysToKeep)
 
 	if execErr != nil {
 		return execErr
@@ -132,8 +130,8 @@ func RemoveOldSuggestions() error {
 }
 
 // ArchiveSuggestions archives suggestions that are not already in the suggestions_archive
-func ArchiveSuggestions() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) ArchiveSuggestions() error {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -155,8 +153,8 @@ func ArchiveSuggestions() error {
 }
 
 // CountSuggestions counts the number of suggestions made by a user in the last [days] days.
-func CountSuggestions(userID string, days int) (int, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) CountSuggestions(userID string, days int) (int, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return 0, openErr
 	}
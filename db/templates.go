@@ -0,0 +1,694 @@
+/*
+templates.go contains the Template and Override structs and functions that interact with
+the stream_templates, template_overrides and template_occurrences tables of the database.
+Templates describe streams that recur on a schedule, such as monthly showcases, and are
+expanded into rows of the streams table a number of days ahead by the stream update job.
+Overrides change or cancel a single occurrence and are applied every time the template is
+expanded, so they are not lost when the occurrences are regenerated.
+*/
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"gamestreams/utils"
+)
+
+// Template is a recurring stream as given in the templates section of the streams.toml
+// file and stored in the stream_templates table of the database.
+type Template struct {
+	// The unique identifier for the template in the database.
+	ID int `toml:"-"`
+	// A unique name for the template used to update it from streams.toml, e.g.
+	// state-of-play.
+	Key string `toml:"key"`
+	// The name given to each stream created from the template.
+	Name string `toml:"name"`
+	// The platforms of the streams.
+	Platform string `toml:"platform"`
+	// The time the streams start, in UTC.
+	Time string `toml:"time,omitempty"`
+	// Description of the streams.
+	Description string `toml:"description,omitempty"`
+	// The URL of the streams.
+	URL string `toml:"url,omitempty"`
+	// The status given to each stream created from the template. Defaults to
+	// confirmed if the time is set and announced otherwise.
+	Status string `toml:"status,omitempty"`
+	// The names of the publishers presenting the streams.
+	Publishers []string `toml:"publishers,omitempty"`
+	// The names of the games featured in the streams.
+	Games []string `toml:"games,omitempty"`
+	// The RRULE-style recurrence rule, e.g. FREQ=MONTHLY;BYDAY=2TH.
+	Rule string `toml:"rule"`
+	// The first date the template can occur on. DD/MM/YYYY in streams.toml and
+	// YYYY-MM-DD in the database.
+	Start string `toml:"start"`
+	// The last date the template can occur on, if any. Same format as Start.
+	Until string `toml:"until,omitempty"`
+	// Changes to individual occurrences of the template.
+	Overrides []Override `toml:"overrides,omitempty"`
+	// A flag to determine if the template should be deleted along with its upcoming
+	// streams.
+	Delete bool `toml:"delete,omitempty"`
+}
+
+// Override changes or cancels a single occurrence of a template. Empty fields keep the
+// value from the template. An override with no fields set and Cancel false removes any
+// existing override for the occurrence.
+type Override struct {
+	// The date the occurrence would have been on according to the rule.
+	Date string `toml:"date"`
+	// A flag to determine if the occurrence is cancelled.
+	Cancel bool `toml:"cancel,omitempty"`
+	// The date the occurrence has moved to.
+	NewDate string `toml:"new_date,omitempty"`
+	// The time the occurrence starts, in UTC.
+	Time string `toml:"time,omitempty"`
+	// The name of the occurrence.
+	Name string `toml:"name,omitempty"`
+	// Description of the occurrence.
+	Description string `toml:"description,omitempty"`
+	// The URL of the occurrence.
+	URL string `toml:"url,omitempty"`
+	// The status of the occurrence.
+	Status string `toml:"status,omitempty"`
+}
+
+// empty returns true if the override changes nothing.
+func (o Override) empty() bool {
+	return !o.Cancel &&
+		o.NewDate == "" &&
+		o.Time == "" &&
+		o.Name == "" &&
+		o.Description == "" &&
+		o.URL == "" &&
+		o.Status == ""
+}
+
+// UpdateTemplates writes the templates of the Streams struct to the database. New
+// templates are inserted, templates with an existing key are replaced, and templates
+// marked for deletion are removed along with their upcoming streams. Templates with an
+// invalid rule or date are skipped.
+func (s *Streams) UpdateTemplates(database *Database) error {
+	if len(s.Templates) == 0 {
+		return nil
+	}
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	for _, t := range s.Templates {
+		if t.Key == "" {
+			database.log.LogError("   DB", "template has no key",
+				"name", t.Name)
+			continue
+		}
+		if t.Delete {
+			if delErr := database.deleteTemplate(db, t.Key); delErr != nil {
+				return delErr
+			}
+			continue
+		}
+		if validErr := t.normalise(); validErr != nil {
+			database.log.LogError("   DB", "invalid stream template",
+				"key", t.Key,
+				"err", validErr)
+			continue
+		}
+		database.log.LogInfo("   DB", "updating stream template", false,
+			"key", t.Key,
+			"rule", t.Rule)
+
+		_, execErr := db.Exec(`INSERT INTO stream_templates
+									(template_key,
+									stream_name,
+									platform,
+									start_time,
+									stream_desc,
+									stream_url,
+									publishers,
+									games,
+									rrule,
+									start_date,
+									until_date,
+									status)
+								VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+								ON CONFLICT (template_key) DO UPDATE
+								SET stream_name = excluded.stream_name,
+									platform = excluded.platform,
+									start_time = excluded.start_time,
+									stream_desc = excluded.stream_desc,
+									stream_url = excluded.stream_url,
+									publishers = excluded.publishers,
+									games = excluded.games,
+									rrule = excluded.rrule,
+									start_date = excluded.start_date,
+									until_date = excluded.until_date,
+									status = excluded.status`,
+			t.Key,
+			t.Name,
+			t.Platform,
+			t.Time,
+			t.Description,
+			t.URL,
+			strings.Join(t.Publishers, ","),
+			strings.Join(t.Games, ","),
+			t.Rule,
+			t.Start,
+			t.Until,
+			t.Status)
+		if execErr != nil {
+			return execErr
+		}
+		row := db.QueryRow(`SELECT id
+							FROM stream_templates
+							WHERE template_key = ?`,
+			t.Key)
+		if scanErr := row.Scan(&t.ID); scanErr != nil {
+			return scanErr
+		}
+		for _, o := range t.Overrides {
+			if overrideErr := setOverride(db, t.ID, o); overrideErr != nil {
+				database.log.LogError("   DB", "error setting template override",
+					"key", t.Key,
+					"date", o.Date,
+					"err", overrideErr)
+			}
+		}
+	}
+	return nil
+}
+
+// normalise checks the rule and status of the template and converts its dates from
+// DD/MM/YYYY to YYYY-MM-DD.
+func (t *Template) normalise() error {
+	if _, ruleErr := utils.ParseRule(t.Rule); ruleErr != nil {
+		return ruleErr
+	}
+	start, dateErr := utils.ParseTomlDate(t.Start)
+	if dateErr != nil {
+		return dateErr
+	}
+	t.Start = start
+	if t.Until != "" {
+		until, untilErr := utils.ParseTomlDate(t.Until)
+		if untilErr != nil {
+			return untilErr
+		}
+		t.Until = until
+	}
+	status, statusErr := ParseStatus(t.Status, t.Time)
+	if statusErr != nil {
+		return statusErr
+	}
+	t.Status = status
+	t.Platform = capitalisePlatforms(t.Platform)
+	return nil
+}
+
+// setOverride inserts or replaces the override of an occurrence of the template with
+// the given ID. Empty overrides remove the existing override.
+func setOverride(db *sql.DB, templateID int, o Override) error {
+	date, dateErr := utils.ParseTomlDate(o.Date)
+	if dateErr != nil {
+		return dateErr
+	}
+	if o.empty() {
+		_, execErr := db.Exec(`DELETE FROM template_overrides
+								WHERE template_id = ?
+								AND occurrence_date = ?`,
+			templateID,
+			date)
+		return execErr
+	}
+	if o.NewDate != "" {
+		newDate, newErr := utils.ParseTomlDate(o.NewDate)
+		if newErr != nil {
+			return newErr
+		}
+		o.NewDate = newDate
+	}
+	if o.Status != "" {
+		status, statusErr := ParseStatus(o.Status, o.Time)
+		if statusErr != nil {
+			return statusErr
+		}
+		o.Status = status
+	}
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO template_overrides
+								(template_id,
+								occurrence_date,
+								cancelled,
+								stream_date,
+								start_time,
+								stream_name,
+								stream_desc,
+								stream_url,
+								status)
+							VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		templateID,
+		date,
+		o.Cancel,
+		o.NewDate,
+		o.Time,
+		o.Name,
+		o.Description,
+		o.URL,
+		o.Status)
+	return execErr
+}
+
+// deleteTemplate removes the template with the given key and its upcoming streams from
+// the database. Streams that have already happened are kept.
+func (d *Database) deleteTemplate(db *sql.DB, key string) error {
+	d.log.LogInfo("   DB", "deleting stream template", false,
+		"key", key)
+
+	_, execErr := db.Exec(`DELETE FROM streams
+							WHERE stream_date >= DATE('now')
+							AND id IN (
+								SELECT template_occurrences.stream_id
+								FROM template_occurrences
+								JOIN stream_templates
+									ON template_occurrences.template_id = stream_templates.id
+								WHERE stream_templates.template_key = ?)`,
+		key)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM template_occurrences
+							WHERE template_id IN (
+								SELECT id
+								FROM stream_templates
+								WHERE template_key = ?)`,
+		key)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM template_overrides
+							WHERE template_id IN (
+								SELECT id
+								FROM stream_templates
+								WHERE template_key = ?)`,
+		key)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM stream_templates
+							WHERE template_key = ?`,
+		key)
+	return execErr
+}
+
+// getTemplates returns all templates from the stream_templates table of the database.
+func (d *Database) getTemplates(db *sql.DB) ([]Template, error) {
+	rows, queryErr := db.Query(`SELECT id,
+									template_key,
+									stream_name,
+									platform,
+									start_time,
+									stream_desc,
+									stream_url,
+									publishers,
+									games,
+									rrule,
+									start_date,
+									until_date,
+									IFNULL(status, '')
+								FROM stream_templates`)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var templates []Template
+	for rows.Next() {
+		var t Template
+		var publishers, games string
+		scanErr := rows.Scan(&t.ID,
+			&t.Key,
+			&t.Name,
+			&t.Platform,
+			&t.Time,
+			&t.Description,
+			&t.URL,
+			&publishers,
+			&games,
+			&t.Rule,
+			&t.Start,
+			&t.Until,
+			&t.Status)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		t.Publishers = splitList(publishers)
+		t.Games = splitList(games)
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// splitList splits a comma separated list, dropping empty values.
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ExpandTemplates creates a stream for each occurrence of each template between today
+// and the number of days ahead set in config.toml. Overrides are applied to the
+// occurrences they match, cancelled occurrences are given the cancelled status, and
+// upcoming streams for occurrences that no longer match the rule are deleted. Streams
+// that already exist for an occurrence are updated in place so their IDs do not change.
+// A template that cannot be expanded does not stop the others, and the errors of all
+// the templates that failed are returned.
+func (d *Database) ExpandTemplates() error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	templates, getErr := d.getTemplates(db)
+	if getErr != nil {
+		return getErr
+	}
+	days := d.config().Streams.RecurrenceDays
+	if days <= 0 {
+		days = 60
+	}
+	from := time.Now().UTC()
+	to := from.AddDate(0, 0, days)
+
+	var errs []error
+	for _, t := range templates {
+		if expandErr := d.expandTemplate(db, t, from, to); expandErr != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", t.Key, expandErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expandTemplate creates, updates and deletes the streams of a single template for the
+// occurrences between from and to.
+func (d *Database) expandTemplate(db *sql.DB, t Template, from time.Time, to time.Time) error {
+	rule, ruleErr := utils.ParseRule(t.Rule)
+	if ruleErr != nil {
+		return ruleErr
+	}
+	start, startErr := time.Parse(time.DateOnly, t.Start)
+	if startErr != nil {
+		return startErr
+	}
+	if t.Until != "" {
+		until, untilErr := time.Parse(time.DateOnly, t.Until)
+		if untilErr != nil {
+			return untilErr
+		}
+		if rule.Until.IsZero() || until.Before(rule.Until) {
+			rule.Until = until
+		}
+	}
+	overrides, overrideErr := d.getOverrides(db, t.ID)
+	if overrideErr != nil {
+		return overrideErr
+	}
+	existing, existErr := d.getOccurrences(db, t.ID, from.Format(time.DateOnly))
+	if existErr != nil {
+		return existErr
+	}
+
+	wanted := make(map[string]bool)
+	for _, date := range rule.Between(start, from, to) {
+		occurrence := date.Format(time.DateOnly)
+		wanted[occurrence] = true
+		streamID, exists := existing[occurrence]
+
+		o := overrides[occurrence]
+		if o.Cancel && !exists {
+			continue
+		}
+		stream := Stream{
+			ID:          streamID,
+			Name:        firstSet(o.Name, t.Name),
+			Platform:    t.Platform,
+			Date:        firstSet(o.NewDate, occurrence),
+			Time:        firstSet(o.Time, t.Time),
+			Description: firstSet(o.Description, t.Description),
+			URL:         firstSet(o.URL, t.URL),
+		}
+		stream.Status = firstSet(o.Status, t.Status, defaultStatus(stream.Time))
+		// Cancelled occurrences that already have a stream are kept with the cancelled
+		// status so that servers which announced them can be told.
+		if o.Cancel {
+			stream.Status = StatusCancelled
+		}
+		if exists {
+			if updateErr := updateOccurrence(db, stream); updateErr != nil {
+				return updateErr
+			}
+		} else if insertErr := d.insertOccurrence(db, t.ID, occurrence, &stream); insertErr != nil {
+			return insertErr
+		}
+		if linkErr := d.linkStreamEntities(db, stream.ID, t.Publishers, t.Games); linkErr != nil {
+			return linkErr
+		}
+	}
+	for occurrence, streamID := range existing {
+		if !wanted[occurrence] {
+			if delErr := d.deleteOccurrence(db, t.ID, occurrence, streamID); delErr != nil {
+				return delErr
+			}
+		}
+	}
+	return nil
+}
+
+// firstSet returns the first of the given values that is not empty.
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getOverrides returns the overrides of the template with the given ID, keyed by the
+// date of the occurrence they change.
+func (d *Database) getOverrides(db *sql.DB, templateID int) (map[string]Override, error) {
+	rows, queryErr := db.Query(`SELECT occurrence_date,
+									cancelled,
+									IFNULL(stream_date, ''),
+									IFNULL(start_time, ''),
+									IFNULL(stream_name, ''),
+									IFNULL(stream_desc, ''),
+									IFNULL(stream_url, ''),
+									IFNULL(status, '')
+								FROM template_overrides
+								WHERE template_id = ?`,
+		templateID)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]Override)
+	for rows.Next() {
+		var o Override
+		scanErr := rows.Scan(&o.Date,
+			&o.Cancel,
+			&o.NewDate,
+			&o.Time,
+			&o.Name,
+			&o.Description,
+			&o.URL,
+			&o.Status)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		overrides[o.Date] = o
+	}
+	return overrides, rows.Err()
+}
+
+// getOccurrences returns the IDs of the streams created for the template with the
+// given ID for occurrences on or after the given date, keyed by occurrence date.
+func (d *Database) getOccurrences(db *sql.DB, templateID int, from string) (map[string]int, error) {
+	rows, queryErr := db.Query(`SELECT occurrence_date,
+									stream_id
+								FROM template_occurrences
+								WHERE template_id = ?
+								AND occurrence_date >= ?`,
+		templateID,
+		from)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	occurrences := make(map[string]int)
+	for rows.Next() {
+		var date string
+		var streamID int
+		if scanErr := rows.Scan(&date, &streamID); scanErr != nil {
+			return nil, scanErr
+		}
+		occurrences[date] = streamID
+	}
+	return occurrences, rows.Err()
+}
+
+// insertOccurrence inserts the stream into the streams table, sets its ID and records
+// it as the stream for the occurrence of the template.
+func (d *Database) insertOccurrence(db *sql.DB, templateID int, occurrence string, stream *Stream) error {
+	d.log.LogInfo("UPDAT", "inserting recurring stream", false,
+		"name", stream.Name,
+		"date", stream.Date)
+
+	result, insertErr := db.Exec(`INSERT INTO streams
+									(stream_name,
+									platform,
+									stream_date,
+									start_time,
+									stream_desc,
+									stream_url,
+									status,
+									added_at)
+								VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		stream.Name,
+		stream.Platform,
+		stream.Date,
+		stream.Time,
+		stream.Description,
+		stream.URL,
+		stream.Status,
+		time.Now().UTC().Format(time.RFC3339))
+	if insertErr != nil {
+		return insertErr
+	}
+	streamID, idErr := result.LastInsertId()
+	if idErr != nil {
+		return idErr
+	}
+	stream.ID = int(streamID)
+
+	_, execErr := db.Exec(`INSERT INTO template_occurrences
+								(template_id,
+								occurrence_date,
+								stream_id)
+							VALUES (?, ?, ?)`,
+		templateID,
+		occurrence,
+		stream.ID)
+	return execErr
+}
+
+// updateOccurrence updates the row of the streams table for an existing occurrence.
+func updateOccurrence(db *sql.DB, stream Stream) error {
+	_, execErr := db.Exec(`UPDATE streams
+							SET stream_name = ?,
+								platform = ?,
+								stream_date = ?,
+								start_time = ?,
+								stream_desc = ?,
+								stream_url = ?,
+								status = ?
+							WHERE id = ?`,
+		stream.Name,
+		stream.Platform,
+		stream.Date,
+		stream.Time,
+		stream.Description,
+		stream.URL,
+		stream.Status,
+		stream.ID)
+	return execErr
+}
+
+// deleteOccurrence removes the stream created for the occurrence of the template.
+func (d *Database) deleteOccurrence(db *sql.DB, templateID int, occurrence string, streamID int) error {
+	d.log.LogInfo("UPDAT", "removing recurring stream", false,
+		"template", templateID,
+		"date", occurrence)
+
+	if _, execErr := db.Exec(`DELETE FROM streams
+								WHERE id = ?`,
+		streamID); execErr != nil {
+		return execErr
+	}
+	_, execErr := db.Exec(`DELETE FROM template_occurrences
+							WHERE template_id = ?
+							AND occurrence_date = ?`,
+		templateID,
+		occurrence)
+	return execErr
+}
+
+// recordOverride saves the current values of the stream with the given ID as an
+// override of its occurrence, if the stream was created from a template. This keeps
+// changes made to recurring streams through streams.toml when the template is expanded
+// again.
+func recordOverride(db *sql.DB, streamID int) error {
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO template_overrides
+								(template_id,
+								occurrence_date,
+								cancelled,
+								stream_date,
+								start_time,
+								stream_name,
+								stream_desc,
+								stream_url,
+								status)
+							SELECT template_occurrences.template_id,
+								template_occurrences.occurrence_date,
+								streams.status = 'cancelled',
+								streams.stream_date,
+								streams.start_time,
+								streams.stream_name,
+								streams.stream_desc,
+								streams.stream_url,
+								streams.status
+							FROM template_occurrences
+							JOIN streams
+								ON template_occurrences.stream_id = streams.id
+							WHERE streams.id = ?`,
+		streamID)
+	return execErr
+}
+
+// recordCancellation cancels the occurrence of the stream with the given ID, if the
+// stream was created from a template, so that deleting a recurring stream through
+// streams.toml does not create it again when the template is expanded.
+func recordCancellation(db *sql.DB, streamID int) error {
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO template_overrides
+								(template_id,
+								occurrence_date,
+								cancelled)
+							SELECT template_id,
+								occurrence_date,
+								1
+							FROM template_occurrences
+							WHERE stream_id = ?`,
+		streamID)
+	if execErr != nil {
+		return execErr
+	}
+	_, execErr = db.Exec(`DELETE FROM template_occurrences
+							WHERE stream_id = ?`,
+		streamID)
+	return execErr
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gamestreams/config"
+	"gamestreams/logs"
+	"gamestreams/utils"
+)
+
+// openTestDB opens a new database in a temporary directory and returns it with a
+// connection to it.
+func openTestDB(t *testing.T) (*Database, *sql.DB) {
+	t.Helper()
+	cfg := &config.Config{}
+	getConfig := func() *config.Config { return cfg }
+	log := logs.New(cfg.Logs)
+	d, openErr := Open(filepath.Join(t.TempDir(), "test.db"), getConfig, log,
+		utils.NewClient(context.Background(), getConfig, log))
+	if openErr != nil {
+		t.Fatalf("Open() error = %v", openErr)
+	}
+	db, connErr := d.Conn()
+	if connErr != nil {
+		t.Fatalf("Conn() error = %v", connErr)
+	}
+	t.Cleanup(func() { db.Close() })
+	return d, db
+}
+
+// expandTestTemplate expands the template with the key between January and April 2030.
+func expandTestTemplate(t *testing.T, d *Database, db *sql.DB, key string) {
+	t.Helper()
+	templates, getErr := d.getTemplates(db)
+	if getErr != nil {
+		t.Fatalf("getTemplates() error = %v", getErr)
+	}
+	for _, template := range templates {
+		if template.Key != key {
+			continue
+		}
+		from := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2030, time.April, 30, 0, 0, 0, 0, time.UTC)
+		if expandErr := d.expandTemplate(db, template, from, to); expandErr != nil {
+			t.Fatalf("expandTemplate() error = %v", expandErr)
+		}
+		return
+	}
+	t.Fatalf("template %s was not saved", key)
+}
+
+// templateStreams returns the streams created for the template with the key, keyed by
+// occurrence date.
+func templateStreams(t *testing.T, db *sql.DB, key string) map[string]Stream {
+	t.Helper()
+	rows, queryErr := db.Query(`SELECT template_occurrences.occurrence_date,
+									streams.id,
+									streams.stream_name,
+									streams.stream_date,
+									streams.start_time,
+									streams.status
+								FROM template_occurrences
+								JOIN stream_templates
+									ON template_occurrences.template_id = stream_templates.id
+								JOIN streams
+									ON template_occurrences.stream_id = streams.id
+								WHERE stream_templates.template_key = ?`,
+		key)
+	if queryErr != nil {
+		t.Fatalf("error querying streams: %v", queryErr)
+	}
+	defer rows.Close()
+
+	streams := make(map[string]Stream)
+	for rows.Next() {
+		var occurrence string
+		var s Stream
+		if scanErr := rows.Scan(&occurrence, &s.ID, &s.Name, &s.Date, &s.Time, &s.Status); scanErr != nil {
+			t.Fatalf("error scanning stream: %v", scanErr)
+		}
+		streams[occurrence] = s
+	}
+	return streams
+}
+
+func TestTemplateOverridesSurviveExpansion(t *testing.T) {
+	d, db := openTestDB(t)
+	// The second Thursdays of January to April 2030 are the 10th, 14th, 14th and 11th.
+	templates := Streams{Templates: []Template{{
+		Key:      "showcase",
+		Name:     "Monthly Showcase",
+		Platform: "xbox",
+		Time:     "17:00",
+		Rule:     "FREQ=MONTHLY;BYDAY=2TH",
+		Start:    "01/01/2030",
+		Overrides: []Override{
+			{Date: "14/02/2030", NewDate: "15/02/2030", Name: "Showcase Special", Time: "18:00"},
+			{Date: "14/03/2030", Cancel: true},
+		},
+	}}}
+	if updateErr := templates.UpdateTemplates(d); updateErr != nil {
+		t.Fatalf("UpdateTemplates() error = %v", updateErr)
+	}
+
+	expandTestTemplate(t, d, db, "showcase")
+	first := templateStreams(t, db, "showcase")
+	checkOccurrences := func(streams map[string]Stream) {
+		t.Helper()
+		if len(streams) != 3 {
+			t.Fatalf("streams = %+v, want January, February and April", streams)
+		}
+		if s := streams["2030-01-10"]; s.Name != "Monthly Showcase" || s.Date != "2030-01-10" ||
+			s.Time != "17:00" || s.Status != StatusConfirmed {
+			t.Errorf("January stream = %+v, want the template values", s)
+		}
+		if s := streams["2030-02-14"]; s.Name != "Showcase Special" || s.Date != "2030-02-15" ||
+			s.Time != "18:00" {
+			t.Errorf("February stream = %+v, want the override values", s)
+		}
+		if _, exists := streams["2030-03-14"]; exists {
+			t.Error("cancelled March occurrence was created")
+		}
+	}
+	checkOccurrences(first)
+
+	// Expanding again keeps the overrides and the IDs of the streams.
+	expandTestTemplate(t, d, db, "showcase")
+	second := templateStreams(t, db, "showcase")
+	checkOccurrences(second)
+	for occurrence, s := range first {
+		if second[occurrence].ID != s.ID {
+			t.Errorf("stream for %s was recreated with ID %d, want %d", occurrence,
+				second[occurrence].ID, s.ID)
+		}
+	}
+
+	// Changes made to a stream through streams.toml are kept as an override.
+	april := second["2030-04-11"]
+	april.Name = "Showcase Extended"
+	april.Platform = "Xbox"
+	april.Status = StatusConfirmed
+	edits := Streams{Streams: []Stream{april}}
+	if updateErr := edits.UpdateRow(d); updateErr != nil {
+		t.Fatalf("UpdateRow() error = %v", updateErr)
+	}
+	// Deleting a stream through streams.toml cancels its occurrence.
+	deletes := Streams{Streams: []Stream{{ID: second["2030-01-10"].ID, Delete: true}}}
+	deletes.DeleteStreams(d)
+
+	expandTestTemplate(t, d, db, "showcase")
+	third := templateStreams(t, db, "showcase")
+	if _, exists := third["2030-01-10"]; exists {
+		t.Error("deleted January occurrence was created again")
+	}
+	if s := third["2030-04-11"]; s.Name != "Showcase Extended" || s.ID != april.ID {
+		t.Errorf("April stream = %+v, want the edit to be kept", s)
+	}
+	if s := third["2030-02-14"]; s.Name != "Showcase Special" {
+		t.Errorf("February stream = %+v, want the override to be kept", s)
+	}
+
+	// Cancelling an occurrence that already has a stream keeps it with the cancelled
+	// status, so servers that announced it can be told.
+	cancel := Streams{Templates: []Template{templates.Templates[0]}}
+	cancel.Templates[0].Overrides = []Override{{Date: "14/02/2030", Cancel: true}}
+	if updateErr := cancel.UpdateTemplates(d); updateErr != nil {
+		t.Fatalf("UpdateTemplates() error = %v", updateErr)
+	}
+	expandTestTemplate(t, d, db, "showcase")
+	fourth := templateStreams(t, db, "showcase")
+	if s := fourth["2030-02-14"]; s.Status != StatusCancelled || s.ID != first["2030-02-14"].ID {
+		t.Errorf("February stream = %+v, want it to be kept as cancelled", s)
+	}
+}
+
+func TestExpandTemplatesReturnsErrors(t *testing.T) {
+	d, db := openTestDB(t)
+	templates := Streams{Templates: []Template{{
+		Key:      "weekly",
+		Name:     "Weekly Stream",
+		Platform: "xbox",
+		Time:     "17:00",
+		Rule:     "FREQ=WEEKLY;BYDAY=TU",
+		Start:    "01/01/2020",
+	}}}
+	if updateErr := templates.UpdateTemplates(d); updateErr != nil {
+		t.Fatalf("UpdateTemplates() error = %v", updateErr)
+	}
+	// A template saved before its rule was validated cannot be expanded.
+	if _, execErr := db.Exec(`INSERT INTO stream_templates
+								(template_key, stream_name, platform, start_time, stream_desc,
+								stream_url, publishers, games, rrule, start_date, until_date)
+							VALUES ('broken', 'Broken Stream', 'xbox', '17:00', '', '', '', '',
+								'FREQ=DAILY', '2030-01-01', '')`); execErr != nil {
+		t.Fatalf("error inserting template: %v", execErr)
+	}
+
+	expandErr := d.ExpandTemplates()
+	if expandErr == nil || !strings.Contains(expandErr.Error(), "broken") {
+		t.Fatalf("ExpandTemplates() error = %v, want an error for the broken template", expandErr)
+	}
+	if strings.Contains(expandErr.Error(), "weekly") {
+		t.Errorf("ExpandTemplates() error = %v, want no error for the weekly template", expandErr)
+	}
+	if streams := templateStreams(t, db, "weekly"); len(streams) == 0 {
+		t.Error("weekly template was not expanded after the broken template failed")
+	}
+}
@@ -5,101 +5,201 @@ information from the streams.toml file in the flat-files repository.
 package db
 
 import (
-	"database/sql"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	_ "github.com/mattn/go-sqlite3"
 
-	"gamestreams/config"
-	"gamestreams/logs"
+	"gamestreams/providers"
 	"gamestreams/utils"
 )
 
 // Update checks for new streams in the streams.toml file of the flat-files repository
 // and updates the database by inserting new streams, updating existing streams, and
 // deleting streams that have been marked for deletion.
-func (s *Streams) Update() error {
+func (s *Streams) Update(database *Database) error {
 	var t StreamTOML
 
-	if getErr := t.Get(); getErr != nil {
+	if getErr := t.Get(database); getErr != nil {
 		return getErr
 	}
 
-	updated, checkErr := t.Check()
+	updated, checkErr := t.Check(database)
 	if checkErr != nil {
 		return checkErr
 	}
 	if !updated {
-		logs.LogInfo("   DB", "no new streams found", false)
+		database.log.LogInfo("   DB", "no new streams found", false)
 		return nil
 	}
-	logs.LogInfo("   DB", "found new version of toml", false)
+	database.log.LogInfo("   DB", "found new version of toml", false)
 
-	*s = parseToml()
+	*s = database.parseToml()
 
 	// if new version of toml is empty, update the last update time and return
 	if len(s.Streams) == 0 {
-		logs.LogInfo("   DB", "toml is empty", false)
-		if setErr := t.Set(); setErr != nil {
+		database.log.LogInfo("   DB", "toml is empty", false)
+		if setErr := t.Set(database); setErr != nil {
 			return setErr
 		}
 	}
 
-	if dateErr := s.FormatDate(); dateErr != nil {
-		return dateErr
+	if importErr := s.Import(database); importErr != nil {
+		return importErr
 	}
 
-	s.correctPlatformCapitalisation()
+	if setErr := t.Set(database); setErr != nil {
+		return setErr
+	}
+	return nil
+}
+
+// Import writes the streams and templates of the Streams struct to the database, as
+// parsed from a streams.toml file. Templates are updated, streams with an ID are
+// updated, new streams that are not duplicates are inserted, and streams marked for
+// deletion are deleted.
+func (s *Streams) Import(database *Database) error {
+	if prepareErr := s.Prepare(); prepareErr != nil {
+		return prepareErr
+	}
+
+	if templateErr := s.UpdateTemplates(database); templateErr != nil {
+		return templateErr
+	}
 
-	if rowErr := s.UpdateRow(); rowErr != nil {
+	if rowErr := s.UpdateRow(database); rowErr != nil {
 		return rowErr
 	}
 
-	if dupErr := s.CheckForDuplicates(); dupErr != nil {
+	if dupErr := s.CheckForDuplicates(database); dupErr != nil {
 		return dupErr
 	}
 	if len(s.Streams) == 0 {
-		logs.LogInfo("   DB", "no new streams found", false)
-		if setErr := t.Set(); setErr != nil {
-			return setErr
-		}
+		database.log.LogInfo("   DB", "no new streams found", false)
 		return nil
 	}
 
-	s.InsertStreams()
+	s.InsertStreams(database)
+
+	s.DeleteStreams(database)
+	return nil
+}
+
+// ImportSummary describes what importing a streams.toml file would change.
+type ImportSummary struct {
+	// The number of templates that would be added or replaced.
+	Templates int
+	// The number of templates that would be deleted along with their upcoming streams.
+	DeletedTemplates int
+	// The problems with the templates that would be skipped, one per template.
+	InvalidTemplates []string
+	// The number of streams with an ID that would be updated.
+	Updated int
+	// The number of streams that would be deleted.
+	Deleted int
+	// The names of the new streams that would be inserted.
+	New []string
+	// The number of new streams that are already in the streams table and would be
+	// skipped.
+	Duplicates int
+}
 
-	s.DeleteStreams()
+// DryRun prepares the streams of the Streams struct as Import does, and returns what
+// importing them would change without writing to the database.
+func (s *Streams) DryRun(database *Database) (ImportSummary, error) {
+	var summary ImportSummary
+	if prepareErr := s.Prepare(); prepareErr != nil {
+		return summary, prepareErr
+	}
 
-	if setErr := t.Set(); setErr != nil {
-		return setErr
+	for _, t := range s.Templates {
+		switch {
+		case t.Key == "":
+			summary.InvalidTemplates = append(summary.InvalidTemplates,
+				fmt.Sprintf("%s: template has no key", t.Name))
+		case t.Delete:
+			summary.DeletedTemplates++
+		default:
+			if validErr := t.normalise(); validErr != nil {
+				summary.InvalidTemplates = append(summary.InvalidTemplates,
+					fmt.Sprintf("%s: %s", t.Key, validErr))
+				continue
+			}
+			summary.Templates++
+		}
+	}
+
+	var newStreams Streams
+	for _, stream := range s.Streams {
+		switch {
+		case stream.Delete:
+			summary.Deleted++
+		case stream.ID != 0:
+			summary.Updated++
+		case stream.Name != "":
+			newStreams.Streams = append(newStreams.Streams, stream)
+		}
+	}
+	total := len(newStreams.Streams)
+	if dupErr := newStreams.CheckForDuplicates(database); dupErr != nil {
+		return summary, dupErr
+	}
+	summary.Duplicates = total - len(newStreams.Streams)
+	for _, stream := range newStreams.Streams {
+		summary.New = append(summary.New, stream.Name)
+	}
+	return summary, nil
+}
+
+// Prepare converts the streams of the Streams struct from the format of streams.toml
+// to the format of the database: dates are converted, statuses are checked, platforms
+// are capitalised and URLs are canonicalised. It does not write to the database.
+func (s *Streams) Prepare() error {
+	if dateErr := s.FormatDate(); dateErr != nil {
+		return dateErr
+	}
+
+	if statusErr := s.FormatStatus(); statusErr != nil {
+		return statusErr
 	}
+
+	s.correctPlatformCapitalisation()
+
+	s.canonicaliseURLs()
 	return nil
 }
 
+// ParseStreams parses the contents of a streams.toml file into a Streams struct.
+func ParseStreams(data string) (Streams, error) {
+	var streamList Streams
+	if _, tomlErr := toml.Decode(data, &streamList); tomlErr != nil {
+		return Streams{}, tomlErr
+	}
+	return streamList, nil
+}
+
 // parseToml parses the streams.toml file from the flat-files repository and returns
 // as a Streams struct.
-func parseToml() Streams {
-	response, httpErr := http.Get(config.Values.Github.StreamsTOMLURL)
+func (d *Database) parseToml() Streams {
+	response, httpErr := d.http.Get(d.config().Github.StreamsTOMLURL)
 	if httpErr != nil {
-		logs.LogError("   DB", "error getting toml", "err", httpErr)
+		d.log.LogError("   DB", "error getting toml", "err", httpErr)
 		return Streams{}
 	}
 
 	defer response.Body.Close()
 	body, readErr := io.ReadAll(response.Body)
 	if readErr != nil {
-		logs.LogError("   DB", "error reading toml", "err", readErr)
+		d.log.LogError("   DB", "error reading toml", "err", readErr)
 		return Streams{}
 	}
 
-	var streamList Streams
-	_, tomlErr := toml.Decode(string(body), &streamList)
+	streamList, tomlErr := ParseStreams(string(body))
 	if tomlErr != nil {
-		logs.LogError("   DB", "error decoding toml", "err", tomlErr)
+		d.log.LogError("   DB", "error decoding toml", "err", tomlErr)
 		return Streams{}
 	}
 	return streamList
@@ -118,38 +218,74 @@ func (s *Streams) FormatDate() error {
 	return nil
 }
 
+// FormatStatus runs the ParseStatus function on each stream in the Streams struct.
+// This checks the status is valid and sets the default status for streams without one.
+func (s *Streams) FormatStatus() error {
+	for i, stream := range s.Streams {
+		status, err := ParseStatus(stream.Status, stream.Time)
+		if err != nil {
+			return err
+		}
+		s.Streams[i].Status = status
+	}
+	return nil
+}
+
 // correctPlatformCapitalisation corrects the capitalisation of the platforms in the
 // Streams struct. This is done to ensure that the platforms are capitalised correctly
 // when displayed in the Discord embed.
 func (s *Streams) correctPlatformCapitalisation() {
 	for i, stream := range s.Streams {
-		splitPlatforms := strings.Split(stream.Platform, ",")
-
-		for j, platform := range splitPlatforms {
-			switch strings.TrimSpace(strings.ToLower(platform)) {
-			case "pc":
-				splitPlatforms[j] = "PC"
-			case "playstation":
-				splitPlatforms[j] = "PlayStation"
-			case "xbox":
-				splitPlatforms[j] = "Xbox"
-			case "nintendo":
-				splitPlatforms[j] = "Nintendo"
-			case "vr":
-				splitPlatforms[j] = "VR"
-			default:
-				continue
-			}
+		s.Streams[i].Platform = capitalisePlatforms(stream.Platform)
+	}
+}
+
+// canonicaliseURLs replaces the URL of each stream and template in the Streams struct
+// with its canonical form, removing tracking parameters and normalising the host.
+func (s *Streams) canonicaliseURLs() {
+	for i, stream := range s.Streams {
+		if stream.URL != "" {
+			s.Streams[i].URL = providers.Canonicalise(stream.URL)
+		}
+	}
+	for i, template := range s.Templates {
+		if template.URL != "" {
+			s.Templates[i].URL = providers.Canonicalise(template.URL)
 		}
-		s.Streams[i].Platform = strings.Join(splitPlatforms, ", ")
 	}
 }
 
+// capitalisePlatforms corrects the capitalisation of a comma separated list of
+// platforms.
+func capitalisePlatforms(platforms string) string {
+	splitPlatforms := strings.Split(platforms, ",")
+
+	for j, platform := range splitPlatforms {
+		switch strings.TrimSpace(strings.ToLower(platform)) {
+		case "pc":
+			splitPlatforms[j] = "PC"
+		case "playstation":
+			splitPlatforms[j] = "PlayStation"
+		case "xbox":
+			splitPlatforms[j] = "Xbox"
+		case "nintendo":
+			splitPlatforms[j] = "Nintendo"
+		case "vr":
+			splitPlatforms[j] = "VR"
+		default:
+			continue
+		}
+	}
+	return strings.Join(splitPlatforms, ", ")
+}
+
 // UpdateRow updates streams in the streams table of the database with information
 // from the Streams struct. This is done when the ID of a stream in the Streams struct
-// has been set to a non-zero value.
-func (s *Streams) UpdateRow() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+// has been set to a non-zero value. The publishers and games linked to the stream are
+// replaced with those in the Streams struct. If the stream was created from a template,
+// the changes are kept as an override of its occurrence.
+func (s *Streams) UpdateRow(database *Database) error {
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -158,7 +294,7 @@ func (s *Streams) UpdateRow() error {
 	var updateCount int
 	for i, stream := range s.Streams {
 		if stream.ID != 0 {
-			logs.LogInfo("   DB", "updating stream", false,
+			database.log.LogInfo("   DB", "updating stream", false,
 				"id", stream.ID,
 				"name", stream.Name)
 
@@ -168,7 +304,8 @@ func (s *Streams) UpdateRow() error {
 										stream_date = ?,
 										start_time = ?,
 										stream_desc = ?,
-										stream_url = ?
+										stream_url = ?,
+										status = ?
 									WHERE id = ?`,
 				stream.Name,
 				stream.Platform,
@@ -176,11 +313,18 @@ func (s *Streams) UpdateRow() error {
 				stream.Time,
 				stream.Description,
 				stream.URL,
+				stream.Status,
 				stream.ID)
 
 			if updateErr != nil {
 				return updateErr
 			}
+			if linkErr := database.linkStreamEntities(db, stream.ID, stream.Publishers, stream.Games); linkErr != nil {
+				return linkErr
+			}
+			if overrideErr := recordOverride(db, stream.ID); overrideErr != nil {
+				return overrideErr
+			}
 			s.Streams[i] = Stream{}
 			updateCount++
 		}
@@ -191,8 +335,8 @@ func (s *Streams) UpdateRow() error {
 // CheckForDuplicates checks the streams table of the database for duplicates of
 // streams in the Streams struct. If a a stream already exists in the streams table of
 // the database, it is removed from the Streams struct.
-func (s *Streams) CheckForDuplicates() error {
-	rowNumber, countErr := countRows()
+func (s *Streams) CheckForDuplicates(database *Database) error {
+	rowNumber, countErr := database.countRows()
 	if countErr != nil {
 		return countErr
 	}
@@ -200,7 +344,7 @@ func (s *Streams) CheckForDuplicates() error {
 		return nil
 	}
 
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+	db, openErr := database.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -216,25 +360,25 @@ func (s *Streams) CheckForDuplicates() error {
 	}
 	defer rows.Close()
 
+	// the rows can only be read once, so they are collected before checking each stream
+	existing := make(map[[4]string]bool)
+	for rows.Next() {
+		var stream Stream
+		scanErr := rows.Scan(&stream.Name,
+			&stream.Platform,
+			&stream.Date,
+			&stream.Time)
+
+		if scanErr != nil {
+			return scanErr
+		}
+		existing[[4]string{stream.Name, stream.Platform, stream.Date, stream.Time}] = true
+	}
+
 	var checkedList Streams
-OUTER:
 	for _, s := range s.Streams {
-		for rows.Next() {
-			var stream Stream
-			scanErr := rows.Scan(&stream.Name,
-				&stream.Platform,
-				&stream.Date,
-				&stream.Time)
-
-			if scanErr != nil {
-				return scanErr
-			}
-			if s.Name == stream.Name &&
-				s.Platform == stream.Platform &&
-				s.Date == stream.Date &&
-				s.Time == stream.Time {
-				continue OUTER
-			}
+		if existing[[4]string{s.Name, s.Platform, s.Date, s.Time}] {
+			continue
 		}
 		checkedList.Streams = append(checkedList.Streams, s)
 	}
@@ -243,8 +387,8 @@ OUTER:
 }
 
 // countRows returns the number of rows in the streams table of the database.
-func countRows() (int, error) {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) countRows() (int, error) {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return 0, openErr
 	}
@@ -263,11 +407,11 @@ func countRows() (int, error) {
 }
 
 // InsertStreams inserts all of the streams from the Streams struct into the streams
-// table of the database.
-func (s *Streams) InsertStreams() {
-	db, sqlErr := sql.Open("sqlite3", config.Values.Files.Database)
+// table of the database and links them to their publishers and games.
+func (s *Streams) InsertStreams(database *Database) {
+	db, sqlErr := database.Conn()
 	if sqlErr != nil {
-		logs.LogError("   DB", "error opening db", "err", sqlErr)
+		database.log.LogError("   DB", "error opening db", "err", sqlErr)
 		return
 	}
 	defer db.Close()
@@ -276,57 +420,79 @@ func (s *Streams) InsertStreams() {
 		if stream.Name == "" {
 			continue
 		}
-		logs.LogInfo("UPDAT", "inserting stream", false,
+		database.log.LogInfo("UPDAT", "inserting stream", false,
 			"name", stream.Name)
 
-		_, insertErr := db.Exec(`INSERT INTO streams
+		result, insertErr := db.Exec(`INSERT INTO streams
 									(stream_name,
 									platform,
 									stream_date,
 									start_time,
 									stream_desc,
-									stream_url)
-								VALUES (?, ?, ?, ?, ?, ?)`,
+									stream_url,
+									status,
+									added_at)
+								VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 			stream.Name,
 			stream.Platform,
 			stream.Date,
 			stream.Time,
 			stream.Description,
-			stream.URL)
+			stream.URL,
+			stream.Status,
+			time.Now().UTC().Format(time.RFC3339))
 
 		if insertErr != nil {
-			logs.LogError("   DB", "error inserting stream",
+			database.log.LogError("   DB", "error inserting stream",
 				"stream", stream.Name,
 				"err", insertErr)
 
 			continue
 		}
+		streamID, idErr := result.LastInsertId()
+		if idErr != nil {
+			database.log.LogError("   DB", "error getting stream ID",
+				"stream", stream.Name,
+				"err", idErr)
+			continue
+		}
+		if linkErr := database.linkStreamEntities(db, int(streamID), stream.Publishers, stream.Games); linkErr != nil {
+			database.log.LogError("   DB", "error linking publishers and games",
+				"stream", stream.Name,
+				"err", linkErr)
+		}
 	}
 }
 
 // DeleteStreams deletes streams from the streams table of the database that have been
 // marked for deletion. This is done by setting the delete flag of a stream in the
-// Streams struct to true.
-func (s *Streams) DeleteStreams() {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+// Streams struct to true. Deleted streams that were created from a template are
+// cancelled so they are not created again.
+func (s *Streams) DeleteStreams(database *Database) {
+	db, openErr := database.Conn()
 	if openErr != nil {
-		logs.LogError("   DB", "error opening db", "err", openErr)
+		database.log.LogError("   DB", "error opening db", "err", openErr)
 		return
 	}
 	defer db.Close()
 
 	for _, x := range s.Streams {
 		if x.Delete {
-			logs.LogInfo("   DB", "deleting stream", false,
+			database.log.LogInfo("   DB", "deleting stream", false,
 				"id", x.ID,
 				"name", x.Name)
 
+			if cancelErr := recordCancellation(db, x.ID); cancelErr != nil {
+				database.log.LogError("   DB", "error cancelling recurring stream",
+					"stream", x.Name,
+					"err", cancelErr)
+			}
 			_, deleteErr := db.Exec(`DELETE FROM streams
 									WHERE id = ?`,
 				x.ID)
 
 			if deleteErr != nil {
-				logs.LogError("   DB", "error deleting stream",
+				database.log.LogError("   DB", "error deleting stream",
 					"stream", x.Name,
 					"err", deleteErr)
 				continue
@@ -337,8 +503,8 @@ func (s *Streams) DeleteStreams() {
 
 // RemoveOldStreams removes streams from the streams table of the database that are
 // older than the number of months specified in the config.toml file.
-func RemoveOldStreams() error {
-	db, openErr := sql.Open("sqlite3", config.Values.Files.Database)
+func (d *Database) RemoveOldStreams() error {
+	db, openErr := d.Conn()
 	if openErr != nil {
 		return openErr
 	}
@@ -346,6 +512,6 @@ func RemoveOldStreams() error {
 
 	_, execErr := db.Exec(`DELETE FROM streams
 							WHERE stream_date < date('now', ?)`,
-		fmt.Sprintf("-%d months", config.Values.Streams.MonthsToKeep))
+		fmt.Sprintf("-%d months", d.config().Streams.MonthsToKeep))
 	return execErr
 }
@@ -0,0 +1,89 @@
+/*
+url_metadata.go contains the MetadataCache struct, which caches the direct URLs and
+metadata resolved for stream URLs in the url_metadata table of the database.
+*/
+package db
+
+import (
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// MetadataCache implements providers.Cache using the url_metadata table of the database.
+// Entries expire after the number of minutes set in config.toml.
+type MetadataCache struct {
+	// The database that the entries are stored in.
+	DB *Database
+}
+
+// metadataTTL returns how long cached metadata is kept for, defaulting to an hour.
+func (d *Database) metadataTTL() time.Duration {
+	ttl := time.Duration(d.config().HTTP.CacheMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return ttl
+}
+
+// Get returns the value of the given kind cached for the URL and true, or false if
+// there is no value or it has expired.
+func (c MetadataCache) Get(url string, kind string) (string, bool) {
+	db, openErr := c.DB.Conn()
+	if openErr != nil {
+		return "", false
+	}
+	defer db.Close()
+
+	var value string
+	cutoff := time.Now().UTC().Add(-c.DB.metadataTTL()).Format(time.RFC3339)
+	scanErr := db.QueryRow(`SELECT value
+							FROM url_metadata
+							WHERE url = ?
+							AND kind = ?
+							AND fetched_at > ?`,
+		url,
+		kind,
+		cutoff).Scan(&value)
+	if scanErr != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// Set caches the value of the given kind for the URL.
+func (c MetadataCache) Set(url string, kind string, value string) error {
+	db, openErr := c.DB.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	_, execErr := db.Exec(`INSERT OR REPLACE INTO url_metadata
+								(url,
+								kind,
+								value,
+								fetched_at)
+							VALUES (?, ?, ?, ?)`,
+		url,
+		kind,
+		value,
+		time.Now().UTC().Format(time.RFC3339))
+	return execErr
+}
+
+// RemoveExpiredMetadata removes the expired entries from the url_metadata table of the
+// database.
+func (d *Database) RemoveExpiredMetadata() error {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	cutoff := time.Now().UTC().Add(-d.metadataTTL()).Format(time.RFC3339)
+	_, execErr := db.Exec(`DELETE FROM url_metadata
+							WHERE fetched_at <= ?`,
+		cutoff)
+	return execErr
+}
@@ -0,0 +1,313 @@
+/*
+webhooks.go contains the Webhook struct and functions that interact with the webhooks
+table of the database. Webhooks are outbound delivery targets for stream announcements,
+registered by servers or, for external consumers, by the bot owner. They receive
+announcements without the bot needing to be in a server or have channel permissions.
+*/
+package db
+
+import (
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The kinds of webhook that announcements can be delivered to.
+const (
+	// WebhookDiscord is a Discord channel webhook, sent the announcement embed.
+	WebhookDiscord = "discord"
+	// WebhookSlack is a Slack incoming webhook, also accepted by Matrix bridges that
+	// support Slack-compatible webhooks. It is sent the announcement as text.
+	WebhookSlack = "slack"
+	// WebhookJSON is a generic endpoint sent the stream as JSON, signed with HMAC.
+	WebhookJSON = "json"
+)
+
+// Webhook represents a row in the webhooks table of the database.
+type Webhook struct {
+	// The ID of the webhook.
+	ID int
+	// The Discord ID of the server that registered the webhook, or empty if it was
+	// registered by the bot owner for an external consumer.
+	ServerID string
+	// The kind of webhook, which determines the payload sent to it.
+	Kind string
+	// The URL that announcements are posted to.
+	URL string
+	// The secret used to sign JSON payloads.
+	Secret string
+	// The lower case platforms the webhook receives streams for, separated by commas.
+	// An empty value receives streams for every platform.
+	Platforms string
+	// The number of minutes before a stream starts that it is delivered. A value of 0
+	// uses the notification_t_minus value from config.toml.
+	LeadTime int
+	// A flag to determine if announcements are delivered to the webhook.
+	Enabled bool
+	// The number of deliveries that have failed in a row.
+	Failures int
+	// The error returned by the last failed delivery.
+	LastError string
+	// The time the webhook was registered, in RFC3339 format.
+	CreatedAt string
+	// The time of the last successful delivery, in RFC3339 format.
+	DeliveredAt string
+}
+
+// webhookColumns are the columns selected by the functions that return webhooks, in
+// the order they are scanned by queryWebhooks.
+const webhookColumns = `id,
+						IFNULL(server_id, ''),
+						kind,
+						url,
+						IFNULL(secret, ''),
+						IFNULL(platforms, ''),
+						IFNULL(lead_time, 0),
+						IFNULL(enabled, 1),
+						IFNULL(failures, 0),
+						IFNULL(last_error, ''),
+						IFNULL(created_at, ''),
+						IFNULL(delivered_at, '')`
+
+// Insert adds the webhook to the webhooks table of the database and sets its ID.
+func (w *Webhook) Insert(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	w.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	w.Enabled = true
+	result, execErr := db.Exec(`INSERT INTO webhooks
+									(server_id,
+									kind,
+									url,
+									secret,
+									platforms,
+									lead_time,
+									enabled,
+									created_at)
+								VALUES (NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?)`,
+		w.ServerID,
+		w.Kind,
+		w.URL,
+		w.Secret,
+		w.Platforms,
+		w.LeadTime,
+		w.Enabled,
+		w.CreatedAt)
+	if execErr != nil {
+		return execErr
+	}
+	id, idErr := result.LastInsertId()
+	if idErr != nil {
+		return idErr
+	}
+	w.ID = int(id)
+	return nil
+}
+
+// GetWebhooks returns the webhooks registered by the server with the given ID, or the
+// webhooks registered by the bot owner if the ID is empty.
+func (d *Database) GetWebhooks(serverID string) ([]Webhook, error) {
+	return d.queryWebhooks(`SELECT `+webhookColumns+`
+							FROM webhooks
+							WHERE IFNULL(server_id, '') = ?
+							ORDER BY id`,
+		serverID)
+}
+
+// GetStreamWebhooks returns the enabled webhooks that the stream should be delivered to
+// the given number of minutes before it starts. These are the webhooks that use the
+// given lead time and receive streams for one of the platforms of the stream.
+func (d *Database) GetStreamWebhooks(stream Stream, leadTime int) ([]Webhook, error) {
+	webhooks, queryErr := d.queryWebhooks(`SELECT `+webhookColumns+`
+											FROM webhooks
+											WHERE enabled = 1
+											AND (CASE WHEN IFNULL(lead_time, 0) > 0
+												THEN lead_time
+												ELSE ? END) = ?`,
+		d.config().Schedule.NotificationTMinus,
+		leadTime)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	var matching []Webhook
+	for _, w := range webhooks {
+		if w.FollowsStream(stream) {
+			matching = append(matching, w)
+		}
+	}
+	return matching, nil
+}
+
+// FollowsStream returns true if the webhook receives streams for one or more of the
+// platforms of the given stream.
+func (w *Webhook) FollowsStream(stream Stream) bool {
+	if w.Platforms == "" {
+		return true
+	}
+	followed := strings.Split(w.Platforms, ",")
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		for _, f := range followed {
+			if strings.EqualFold(strings.TrimSpace(platform), strings.TrimSpace(f)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CountServerWebhooks returns the number of webhooks registered by the server with the
+// given ID.
+func (d *Database) CountServerWebhooks(serverID string) (int, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return 0, openErr
+	}
+	defer db.Close()
+
+	var count int
+	scanErr := db.QueryRow(`SELECT COUNT(*)
+							FROM webhooks
+							WHERE server_id = ?`,
+		serverID).Scan(&count)
+	return count, scanErr
+}
+
+// DeleteWebhook removes the webhook with the given ID from the webhooks table, if it
+// was registered by the server with the given ID, or by the bot owner if the server ID
+// is empty. It returns true if a webhook was removed.
+func (d *Database) DeleteWebhook(id int, serverID string) (bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	result, execErr := db.Exec(`DELETE FROM webhooks
+								WHERE id = ?
+								AND IFNULL(server_id, '') = ?`,
+		id,
+		serverID)
+	if execErr != nil {
+		return false, execErr
+	}
+	affected, affectedErr := result.RowsAffected()
+	return affected > 0, affectedErr
+}
+
+// EnableWebhook enables the webhook with the given ID and resets its failure count, if
+// it was registered by the server with the given ID, or by the bot owner if the server
+// ID is empty. It returns true if a webhook was enabled.
+func (d *Database) EnableWebhook(id int, serverID string) (bool, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	result, execErr := db.Exec(`UPDATE webhooks
+								SET enabled = 1,
+									failures = 0,
+									last_error = ''
+								WHERE id = ?
+								AND IFNULL(server_id, '') = ?`,
+		id,
+		serverID)
+	if execErr != nil {
+		return false, execErr
+	}
+	affected, affectedErr := result.RowsAffected()
+	return affected > 0, affectedErr
+}
+
+// RecordSuccess resets the failure count of the webhook and records the time of the
+// delivery.
+func (w *Webhook) RecordSuccess(database *Database) error {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return openErr
+	}
+	defer db.Close()
+
+	w.Failures = 0
+	w.LastError = ""
+	w.DeliveredAt = time.Now().UTC().Format(time.RFC3339)
+	_, execErr := db.Exec(`UPDATE webhooks
+							SET failures = 0,
+								last_error = '',
+								delivered_at = ?
+							WHERE id = ?`,
+		w.DeliveredAt,
+		w.ID)
+	return execErr
+}
+
+// RecordFailure increments the failure count of the webhook and records the error. The
+// webhook is disabled once the failure count reaches the given limit. It returns true
+// if the webhook was disabled.
+func (w *Webhook) RecordFailure(database *Database, failure error, limit int) (bool, error) {
+	db, openErr := database.Conn()
+	if openErr != nil {
+		return false, openErr
+	}
+	defer db.Close()
+
+	w.Failures++
+	w.LastError = failure.Error()
+	disabled := w.Failures >= limit
+	if disabled {
+		w.Enabled = false
+	}
+	_, execErr := db.Exec(`UPDATE webhooks
+							SET failures = ?,
+								last_error = ?,
+								enabled = ?
+							WHERE id = ?`,
+		w.Failures,
+		w.LastError,
+		w.Enabled,
+		w.ID)
+	return disabled, execErr
+}
+
+// queryWebhooks runs the query, which must select webhookColumns, and returns the
+// webhooks.
+func (d *Database) queryWebhooks(query string, args ...any) ([]Webhook, error) {
+	db, openErr := d.Conn()
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer db.Close()
+
+	rows, queryErr := db.Query(query, args...)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		var w Webhook
+		scanErr := rows.Scan(&w.ID,
+			&w.ServerID,
+			&w.Kind,
+			&w.URL,
+			&w.Secret,
+			&w.Platforms,
+			&w.LeadTime,
+			&w.Enabled,
+			&w.Failures,
+			&w.LastError,
+			&w.CreatedAt,
+			&w.DeliveredAt)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
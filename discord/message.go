@@ -4,36 +4,54 @@ message.go contains functions that send messages to users.
 package discord
 
 import (
-	"gamestreams/config"
-	"gamestreams/logs"
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/locales"
+	"gamestreams/utils"
 )
 
 // IntroDM sends an introductory DM to a server owner when the bot is added to a server.
-func IntroDM(userID string) {
-	message := "🕹 Hello! Thank you for adding me to your server! 🕹\n\n" +
-		"To set up the bot to announce when streams are starting, and which platforms you" +
-		" want to follow, type `/settings` in the server you added me to.\n\nFor help" +
-		" with the bot and its commands, type `/help`. Commands can only be used" +
-		" in servers."
-	logs.LogInfo("DSCRD", "sending intro DM", false, "user", userID)
-
-	DM(userID, message)
+// The DM is written in the given locale and contains a button that launches the setup
+// wizard for the server. The error is returned if the DM could not be sent, e.g. because
+// the owner does not accept DMs.
+func IntroDM(s *discordgo.Session, userID string, serverID string, locale string) error {
+	return DMComplex(s, userID, &discordgo.MessageSend{
+		Content:    locales.T(locale, "server.intro"),
+		Components: SetupButton(serverID, locales.T(locale, "setup.start")),
+	})
 }
 
-// DM sends a direct message containing the given message to the user with the given ID.
-func DM(userID string, message string) {
-	st, err := Session.UserChannelCreate(userID)
-	if err != nil {
-		logs.LogError("DSCRD", "error creating DM channel", "err", err)
-		return
-	}
-	_, err = Session.ChannelMessageSend(st.ID, message)
-	if err != nil {
-		logs.LogError("DSCRD", "error sending DM", "err", err)
+// SetupButton returns a row containing a button with the given label that launches
+// the setup wizard for the server with the given ID.
+func SetupButton(serverID string, label string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    label,
+					Style:    discordgo.PrimaryButton,
+					CustomID: "setup:start:" + serverID,
+				},
+			},
+		},
 	}
 }
 
-// DM sends a direct message to the bot owner. The owner ID is set in config.toml.
-func DMOwner(message string) {
-	DM(config.Values.Discord.OwnerID, message)
+// DM sends a direct message containing the given message to the user with the given ID,
+// truncated to the length Discord allows.
+func DM(s *discordgo.Session, userID string, message string) error {
+	return DMComplex(s, userID, &discordgo.MessageSend{Content: utils.Truncate(message, 2000)})
+}
+
+// DMComplex sends a direct message containing the given message data to the user with
+// the given ID. This allows embeds and components to be sent. The error is returned
+// rather than logged, as users often have DMs from servers turned off and callers decide
+// whether that needs the bot owner's attention.
+func DMComplex(s *discordgo.Session, userID string, message *discordgo.MessageSend) error {
+	st, err := s.UserChannelCreate(userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.ChannelMessageSendComplex(st.ID, message)
+	return err
 }
@@ -0,0 +1,111 @@
+/*
+permissions.go contains functions that check whether the bot is able to use the
+channels and roles that servers have configured for stream announcements.
+*/
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/locales"
+)
+
+// ChannelCheck holds the result of checking whether the bot can announce streams in a
+// channel.
+type ChannelCheck struct {
+	// A flag to determine if the channel exists.
+	Exists bool
+	// A flag to determine if the bot can view the channel.
+	View bool
+	// A flag to determine if the bot can send messages in the channel.
+	Send bool
+	// A flag to determine if the bot can embed links in the channel.
+	EmbedLinks bool
+	// A flag to determine if the channel is an announcement channel that messages can
+	// be crossposted from.
+	News bool
+}
+
+// Usable returns true if the bot can post announcements in the channel.
+func (c ChannelCheck) Usable() bool {
+	return c.Exists && c.View && c.Send
+}
+
+// Warnings returns a slice of human-readable warnings, in the given locale, describing
+// the problems found with the channel.
+func (c ChannelCheck) Warnings(locale string) []string {
+	if !c.Exists {
+		return []string{locales.T(locale, "warning.channel_missing")}
+	}
+	var warnings []string
+	if !c.View {
+		warnings = append(warnings, locales.T(locale, "warning.channel_view"))
+	}
+	if !c.Send {
+		warnings = append(warnings, locales.T(locale, "warning.channel_send"))
+	}
+	if !c.EmbedLinks {
+		warnings = append(warnings, locales.T(locale, "warning.channel_embed"))
+	}
+	return warnings
+}
+
+// CheckChannel checks that the channel with the given ID exists in the given server and
+// that the bot has permission to view it, send messages in it and embed links in it.
+// The session state is checked first, falling back to the Discord API.
+func CheckChannel(s *discordgo.Session, guildID string, channelID string) ChannelCheck {
+	var check ChannelCheck
+	channel, err := s.State.Channel(channelID)
+	if err != nil {
+		channel, err = s.Channel(channelID)
+		if err != nil {
+			return check
+		}
+	}
+	if channel.GuildID != guildID ||
+		(channel.Type != discordgo.ChannelTypeGuildText &&
+			channel.Type != discordgo.ChannelTypeGuildNews) {
+		return check
+	}
+	check.Exists = true
+	check.News = channel.Type == discordgo.ChannelTypeGuildNews
+
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		perms, err = s.UserChannelPermissions(s.State.User.ID, channelID)
+		if err != nil {
+			return check
+		}
+	}
+	check.View = perms&discordgo.PermissionViewChannel != 0
+	check.Send = perms&discordgo.PermissionSendMessages != 0
+	check.EmbedLinks = perms&discordgo.PermissionEmbedLinks != 0
+	return check
+}
+
+// CheckRole checks whether the bot is able to mention the role with the given ID in the
+// given channel. It returns a warning in the given locale describing the problem, or an
+// empty string if the role can be mentioned.
+func CheckRole(s *discordgo.Session, guildID string, channelID string, roleID string, locale string) string {
+	role, err := s.State.Role(guildID, roleID)
+	if err != nil {
+		return locales.T(locale, "warning.role_missing")
+	}
+	if role.Mentionable && role.ID != guildID {
+		return ""
+	}
+	if channelID == "" {
+		return locales.T(locale, "warning.role_no_channel")
+	}
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		perms, err = s.UserChannelPermissions(s.State.User.ID, channelID)
+		if err != nil {
+			return locales.T(locale, "warning.role_check_error")
+		}
+	}
+	if perms&discordgo.PermissionMentionEveryone == 0 {
+		return locales.T(locale, "warning.role_not_mentionable")
+	}
+	return ""
+}
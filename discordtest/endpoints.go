@@ -0,0 +1,117 @@
+/*
+endpoints.go contains functions for pointing the discordgo endpoints at the fake server.
+The endpoint functions of discordgo build their URLs from the base endpoints below, so
+only these need to be changed.
+*/
+package discordtest
+
+import "github.com/bwmarrin/discordgo"
+
+// endpoints holds the base endpoints of discordgo.
+type endpoints struct {
+	// The base URL of Discord.
+	discord string
+	// The base URL of the REST API.
+	api string
+	// The base URL of the guilds API.
+	guilds string
+	// The base URL of the channels API.
+	channels string
+	// The base URL of the users API.
+	users string
+	// The URL of the gateway API.
+	gateway string
+	// The URL of the bot gateway API.
+	gatewayBot string
+	// The base URL of the webhooks API.
+	webhooks string
+	// The base URL of the stickers API.
+	stickers string
+	// The URL of the stage instances API.
+	stageInstances string
+	// The base URL of the voice API.
+	voice string
+	// The URL of the voice regions API.
+	voiceRegions string
+	// The URL of the sticker packs API.
+	stickerPacks string
+	// The URL for creating guilds.
+	guildCreate string
+	// The base URL of the applications API.
+	applications string
+	// The base URL of the OAuth2 API.
+	oauth2 string
+	// The URL of the OAuth2 applications API.
+	oauth2Applications string
+}
+
+// currentEndpoints returns the base endpoints discordgo is using.
+func currentEndpoints() endpoints {
+	return endpoints{
+		discord:            discordgo.EndpointDiscord,
+		api:                discordgo.EndpointAPI,
+		guilds:             discordgo.EndpointGuilds,
+		channels:           discordgo.EndpointChannels,
+		users:              discordgo.EndpointUsers,
+		gateway:            discordgo.EndpointGateway,
+		gatewayBot:         discordgo.EndpointGatewayBot,
+		webhooks:           discordgo.EndpointWebhooks,
+		stickers:           discordgo.EndpointStickers,
+		stageInstances:     discordgo.EndpointStageInstances,
+		voice:              discordgo.EndpointVoice,
+		voiceRegions:       discordgo.EndpointVoiceRegions,
+		stickerPacks:       discordgo.EndpointNitroStickersPacks,
+		guildCreate:        discordgo.EndpointGuildCreate,
+		applications:       discordgo.EndpointApplications,
+		oauth2:             discordgo.EndpointOAuth2,
+		oauth2Applications: discordgo.EndpointOAuth2Applications,
+	}
+}
+
+// setEndpoints points discordgo at the given base URL, which must end with a slash.
+func setEndpoints(base string) {
+	api := base + "api/v" + discordgo.APIVersion + "/"
+	oauth2 := api + "oauth2/"
+	endpoints{
+		discord:            base,
+		api:                api,
+		guilds:             api + "guilds/",
+		channels:           api + "channels/",
+		users:              api + "users/",
+		gateway:            api + "gateway",
+		gatewayBot:         api + "gateway/bot",
+		webhooks:           api + "webhooks/",
+		stickers:           api + "stickers/",
+		stageInstances:     api + "stage-instances",
+		voice:              api + "/voice/",
+		voiceRegions:       api + "/voice/regions",
+		stickerPacks:       api + "/sticker-packs",
+		guildCreate:        api + "guilds",
+		applications:       api + "applications",
+		oauth2:             oauth2,
+		oauth2Applications: oauth2 + "applications",
+	}.apply()
+}
+
+// apply points discordgo at the base endpoints.
+func (e endpoints) apply() {
+	discordgo.EndpointDiscord = e.discord
+	discordgo.EndpointAPI = e.api
+	discordgo.EndpointGuilds = e.guilds
+	discordgo.EndpointChannels = e.channels
+	discordgo.EndpointUsers = e.users
+	discordgo.EndpointGateway = e.gateway
+	discordgo.EndpointGatewayBot = e.gatewayBot
+	discordgo.EndpointWebhooks = e.webhooks
+	discordgo.EndpointStickers = e.stickers
+	discordgo.EndpointStageInstances = e.stageInstances
+	discordgo.EndpointVoice = e.voice
+	discordgo.EndpointVoiceRegions = e.voiceRegions
+	discordgo.EndpointNitroStickersPacks = e.stickerPacks
+	discordgo.EndpointGuildCreate = e.guildCreate
+	discordgo.EndpointApplications = e.applications
+	discordgo.EndpointOAuth2 = e.oauth2
+	discordgo.EndpointOAuth2Applications = e.oauth2Applications
+	discordgo.EndpointOauth2 = e.oauth2
+	discordgo.EndpointOauth2Applications = e.oauth2Applications
+}
@@ -0,0 +1,159 @@
+/*
+events.go contains functions for injecting the events the bot handles, and for waiting
+for the messages the bot sends in response. Handlers run in their own goroutines, so
+tests should wait for the messages they expect instead of checking straight away.
+*/
+package discordtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Command injects a slash command with the given name and options, used by the user in
+// the channel of the server. If the server ID is empty, the command is used in a DM.
+// It returns the interaction, which can be passed to Responses or WaitForResponses.
+func (s *Server) Command(guildID, channelID, userID, name string,
+	options ...*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.Interaction, error) {
+	i := &discordgo.Interaction{
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Data: discordgo.ApplicationCommandInteractionData{
+			ID:          s.newID(),
+			Name:        name,
+			CommandType: discordgo.ChatApplicationCommand,
+			Options:     options,
+		},
+	}
+	return i, s.Interaction(i, userID)
+}
+
+// Component injects a click on the message component with the custom ID, or a choice
+// of the values in a select menu, by the user in the channel of the server.
+func (s *Server) Component(guildID, channelID, userID, customID string,
+	values ...string) (*discordgo.Interaction, error) {
+	componentType := discordgo.ButtonComponent
+	if len(values) > 0 {
+		componentType = discordgo.SelectMenuComponent
+	}
+	i := &discordgo.Interaction{
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Message:   &discordgo.Message{ID: s.newID(), ChannelID: channelID, Author: s.User},
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      customID,
+			ComponentType: componentType,
+			Values:        values,
+		},
+	}
+	return i, s.Interaction(i, userID)
+}
+
+// Interaction injects the interaction, used by the user with the given ID. The ID,
+// token, application ID and locale are set if they are empty, and in servers the user
+// is given the administrator permission.
+func (s *Server) Interaction(i *discordgo.Interaction, userID string) error {
+	if i.ID == "" {
+		i.ID = s.newID()
+	}
+	if i.Token == "" {
+		i.Token = "token-" + i.ID
+	}
+	if i.AppID == "" {
+		i.AppID = s.AppID
+	}
+	if i.Locale == "" {
+		i.Locale = discordgo.EnglishUS
+	}
+	user := &discordgo.User{ID: userID, Username: "user-" + userID}
+	if i.GuildID != "" {
+		if i.Member == nil {
+			i.Member = &discordgo.Member{
+				GuildID:     i.GuildID,
+				User:        user,
+				Permissions: discordgo.PermissionAdministrator,
+			}
+		}
+	} else if i.User == nil {
+		i.User = user
+	}
+	s.mu.Lock()
+	s.interactions[i.Token] = i.ChannelID
+	s.mu.Unlock()
+	return s.Dispatch("INTERACTION_CREATE", i)
+}
+
+// MessageCreate injects a message sent by the user in the channel, e.g. an owner
+// command. It returns the message.
+func (s *Server) MessageCreate(channelID, userID, content string) (*discordgo.Message, error) {
+	message := &discordgo.Message{
+		ID:        s.newID(),
+		ChannelID: channelID,
+		Content:   content,
+		Timestamp: time.Now().UTC(),
+		Author:    &discordgo.User{ID: userID, Username: "user-" + userID},
+	}
+	s.mu.Lock()
+	if channel, exists := s.channels[channelID]; exists {
+		message.GuildID = channel.GuildID
+	}
+	s.mu.Unlock()
+	return message, s.Dispatch("MESSAGE_CREATE", message)
+}
+
+// Option returns a command option with the given name and value. The type of the option
+// is chosen from the type of the value, and values of any other type are sent as
+// strings.
+func Option(name string, value any) *discordgo.ApplicationCommandInteractionDataOption {
+	option := &discordgo.ApplicationCommandInteractionDataOption{Name: name, Value: value}
+	switch value.(type) {
+	case bool:
+		option.Type = discordgo.ApplicationCommandOptionBoolean
+	case int:
+		option.Type = discordgo.ApplicationCommandOptionInteger
+	case float64:
+		option.Type = discordgo.ApplicationCommandOptionNumber
+	case string:
+		option.Type = discordgo.ApplicationCommandOptionString
+	default:
+		option.Type = discordgo.ApplicationCommandOptionString
+		option.Value = fmt.Sprint(value)
+	}
+	return option
+}
+
+// WaitForMessages waits until the bot has sent at least count messages in the channel,
+// then returns them. It returns an error if the timeout passes first.
+func (s *Server) WaitForMessages(channelID string, count int, timeout time.Duration) ([]*discordgo.Message, error) {
+	return waitFor(func() []*discordgo.Message { return s.Messages(channelID) }, count, timeout,
+		"channel "+channelID)
+}
+
+// WaitForResponses waits until the bot has sent at least count responses to the
+// interaction, then returns them. It returns an error if the timeout passes first.
+func (s *Server) WaitForResponses(i *discordgo.Interaction, count int, timeout time.Duration) ([]*discordgo.Message, error) {
+	return waitFor(func() []*discordgo.Message { return s.Responses(i) }, count, timeout,
+		"interaction "+i.ID)
+}
+
+// waitFor polls the messages until there are at least count of them or the timeout
+// passes.
+func waitFor(messages func() []*discordgo.Message, count int, timeout time.Duration,
+	target string) ([]*discordgo.Message, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		found := messages()
+		if len(found) >= count {
+			return found, nil
+		}
+		if time.Now().After(deadline) {
+			return found, fmt.Errorf("timed out waiting for %d messages in %s, got %d",
+				count, target, len(found))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+gateway.go contains the fake Discord gateway. It says hello to each connection,
+responds to identify with a READY event followed by the servers the bot is in, and
+acknowledges heartbeats. Events are dispatched to every connection that has identified.
+*/
+package discordtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// The gateway opcodes used by the fake gateway.
+const (
+	opDispatch     = 0
+	opHeartbeat    = 1
+	opIdentify     = 2
+	opHello        = 10
+	opHeartbeatAck = 11
+)
+
+// upgrader upgrades gateway requests to websocket connections.
+var upgrader = websocket.Upgrader{}
+
+// gatewayConn is a connection to the gateway.
+type gatewayConn struct {
+	// The websocket connection.
+	ws *websocket.Conn
+	// Guards writes to the connection.
+	mu sync.Mutex
+}
+
+// payload is a message sent over the gateway.
+type payload struct {
+	// The opcode of the message.
+	Op int `json:"op"`
+	// The data of the message.
+	Data any `json:"d"`
+	// The sequence number of the event, for dispatches.
+	Sequence int64 `json:"s,omitempty"`
+	// The name of the event, for dispatches.
+	Type string `json:"t,omitempty"`
+}
+
+// write sends the payload over the connection.
+func (c *gatewayConn) write(p payload) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(p)
+}
+
+// close closes the connection.
+func (c *gatewayConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ws.Close()
+}
+
+// serveGateway upgrades the request to a gateway connection and handles the messages
+// sent over it until it is closed.
+func (s *Server) serveGateway(w http.ResponseWriter, r *http.Request) {
+	ws, upgradeErr := upgrader.Upgrade(w, r, nil)
+	if upgradeErr != nil {
+		return
+	}
+	conn := &gatewayConn{ws: ws}
+	defer s.removeConn(conn)
+	if writeErr := conn.write(payload{Op: opHello, Data: map[string]int{"heartbeat_interval": 45000}}); writeErr != nil {
+		return
+	}
+	for {
+		var p struct {
+			Op int `json:"op"`
+		}
+		if readErr := ws.ReadJSON(&p); readErr != nil {
+			return
+		}
+		switch p.Op {
+		case opHeartbeat:
+			conn.write(payload{Op: opHeartbeatAck})
+		case opIdentify:
+			if identifyErr := s.identify(conn); identifyErr != nil {
+				return
+			}
+		}
+	}
+}
+
+// identify sends the READY event and the servers the bot is in to the connection, then
+// adds it to the connections that events are dispatched to.
+func (s *Server) identify(conn *gatewayConn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var guilds []*discordgo.Guild
+	for _, guild := range s.guilds {
+		guilds = append(guilds, &discordgo.Guild{ID: guild.ID, Unavailable: true})
+	}
+	ready := discordgo.Ready{
+		Version:   9,
+		SessionID: "test-session",
+		User:      s.User,
+		Guilds:    guilds,
+	}
+	s.sequence++
+	if writeErr := conn.write(payload{Op: opDispatch, Data: ready, Sequence: s.sequence, Type: "READY"}); writeErr != nil {
+		return writeErr
+	}
+	for _, guild := range s.guilds {
+		s.sequence++
+		if writeErr := conn.write(payload{Op: opDispatch, Data: guild, Sequence: s.sequence, Type: "GUILD_CREATE"}); writeErr != nil {
+			return writeErr
+		}
+	}
+	s.conns = append(s.conns, conn)
+	return nil
+}
+
+// removeConn closes the connection and stops dispatching events to it.
+func (s *Server) removeConn(conn *gatewayConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.conns {
+		if c == conn {
+			s.conns = append(s.conns[:i], s.conns[i+1:]...)
+			break
+		}
+	}
+	conn.close()
+}
+
+// Dispatch sends an event with the given name, e.g. MESSAGE_CREATE, to every connected
+// session. The data is sent as JSON.
+func (s *Server) Dispatch(eventType string, data any) error {
+	raw, marshalErr := json.Marshal(data)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		s.sequence++
+		if writeErr := conn.write(payload{Op: opDispatch, Data: json.RawMessage(raw), Sequence: s.sequence, Type: eventType}); writeErr != nil {
+			return writeErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+rest.go contains the handlers for the parts of the Discord REST API used by the bot.
+Messages the bot sends are recorded so tests can check them. Requests to any other part
+of the API succeed and respond with the body of the request.
+*/
+package discordtest
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleGateway responds with the URL of the gateway.
+func (s *Server) handleGateway(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"url":    "ws" + strings.TrimPrefix(s.server.URL, "http") + "/gateway/",
+		"shards": 1,
+	})
+}
+
+// handleChannel responds with the channel, or an Unknown Channel error if the bot is not
+// in a server with the channel.
+func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	channel, exists := s.channels[r.PathValue("channel")]
+	s.mu.Unlock()
+	if !exists {
+		writeError(w, http.StatusNotFound, discordgo.ErrCodeUnknownChannel, "Unknown Channel")
+		return
+	}
+	writeJSON(w, http.StatusOK, channel)
+}
+
+// handleGuild responds with the server, or an Unknown Guild error if the bot is not in
+// the server.
+func (s *Server) handleGuild(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	guild, exists := s.guilds[r.PathValue("guild")]
+	s.mu.Unlock()
+	if !exists {
+		writeError(w, http.StatusNotFound, discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+		return
+	}
+	writeJSON(w, http.StatusOK, guild)
+}
+
+// handleGuildChannels responds with the channels of the server.
+func (s *Server) handleGuildChannels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	guild, exists := s.guilds[r.PathValue("guild")]
+	s.mu.Unlock()
+	if !exists {
+		writeError(w, http.StatusNotFound, discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+		return
+	}
+	writeJSON(w, http.StatusOK, guild.Channels)
+}
+
+// handleMessage records a message sent or edited by the bot and responds with it.
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	message, decodeErr := decodeMessage(r)
+	if decodeErr != nil {
+		writeError(w, http.StatusBadRequest, 50109, decodeErr.Error())
+		return
+	}
+	message.ID = r.PathValue("message")
+	if message.ID == "" {
+		message.ID = s.newID()
+	} else {
+		now := time.Now().UTC()
+		message.EditedTimestamp = &now
+	}
+	message.ChannelID = r.PathValue("channel")
+	message.Author = s.User
+	message.Timestamp = time.Now().UTC()
+	s.mu.Lock()
+	if channel, exists := s.channels[message.ChannelID]; exists {
+		message.GuildID = channel.GuildID
+	}
+	s.mu.Unlock()
+	s.addMessage(message, "")
+	writeJSON(w, http.StatusOK, message)
+}
+
+// handleInteractionResponse records the response to an interaction.
+func (s *Server) handleInteractionResponse(w http.ResponseWriter, r *http.Request) {
+	var response struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data json.RawMessage                   `json:"data"`
+	}
+	if decodeErr := json.NewDecoder(r.Body).Decode(&response); decodeErr != nil {
+		writeError(w, http.StatusBadRequest, 50109, decodeErr.Error())
+		return
+	}
+	message := &discordgo.Message{}
+	if len(response.Data) > 0 {
+		if unmarshalErr := json.Unmarshal(response.Data, message); unmarshalErr != nil {
+			writeError(w, http.StatusBadRequest, 50109, unmarshalErr.Error())
+			return
+		}
+	}
+	token := r.PathValue("token")
+	message.ID = s.newID()
+	message.Author = s.User
+	s.mu.Lock()
+	message.ChannelID = s.interactions[token]
+	s.mu.Unlock()
+	s.addMessage(message, token)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFollowup records a follow up to an interaction, or an edit of the response to
+// it, and responds with the message.
+func (s *Server) handleFollowup(w http.ResponseWriter, r *http.Request) {
+	message, decodeErr := decodeMessage(r)
+	if decodeErr != nil {
+		writeError(w, http.StatusBadRequest, 50109, decodeErr.Error())
+		return
+	}
+	token := r.PathValue("token")
+	message.ID = s.newID()
+	message.Author = s.User
+	s.mu.Lock()
+	message.ChannelID = s.interactions[token]
+	s.mu.Unlock()
+	s.addMessage(message, token)
+	writeJSON(w, http.StatusOK, message)
+}
+
+// handleDMChannel responds with the DM channel of the user in the request.
+func (s *Server) handleDMChannel(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RecipientID string `json:"recipient_id"`
+	}
+	if decodeErr := json.NewDecoder(r.Body).Decode(&body); decodeErr != nil {
+		writeError(w, http.StatusBadRequest, 50109, decodeErr.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.DMChannel(body.RecipientID))
+}
+
+// DMChannel returns the DM channel between the bot and the user. Messages sent to the
+// user can be found with Messages using the ID of the channel.
+func (s *Server) DMChannel(userID string) *discordgo.Channel {
+	id := "dm-" + userID
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if channel, exists := s.channels[id]; exists {
+		return channel
+	}
+	channel := &discordgo.Channel{
+		ID:         id,
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: userID}},
+	}
+	s.channels[id] = channel
+	return channel
+}
+
+// handleCommands saves the application commands registered by the bot, or responds with
+// them.
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == http.MethodPut {
+		s.commands, _ = io.ReadAll(r.Body)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(s.commands) == 0 {
+		w.Write([]byte("[]"))
+		return
+	}
+	w.Write(s.commands)
+}
+
+// handleEcho responds with the body of the request if it is JSON, or an empty object.
+func (s *Server) handleEcho(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	if !json.Valid(body) {
+		body = []byte("{}")
+	}
+	w.Write(body)
+}
+
+// handleNoContent responds with no content.
+func (s *Server) handleNoContent(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeMessage returns the message in the body of the request. Messages with files are
+// sent as multipart forms, with the message in the payload_json field.
+func decodeMessage(r *http.Request) (*discordgo.Message, error) {
+	message := &discordgo.Message{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if parseErr := r.ParseMultipartForm(32 << 20); parseErr != nil {
+			return nil, parseErr
+		}
+		return message, json.Unmarshal([]byte(r.FormValue("payload_json")), message)
+	}
+	return message, json.NewDecoder(r.Body).Decode(message)
+}
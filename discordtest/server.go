@@ -0,0 +1,235 @@
+/*
+server.go contains a fake Discord server for running the bot offline in end-to-end
+tests. It serves the parts of the REST API and gateway used by the bot, records every
+request and message the bot sends, and lets tests inject gateway events such as
+interactions, new servers and messages.
+
+The discordgo endpoints are package variables, so a Server points them at itself while
+it is running and only one Server can be used at a time.
+*/
+package discordtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Request is a request the bot sent to the REST API.
+type Request struct {
+	// The HTTP method of the request.
+	Method string
+	// The path of the request relative to the API, e.g. channels/1/messages.
+	Path string
+	// The body of the request.
+	Body []byte
+}
+
+// Server is a fake Discord server that serves the REST API and gateway.
+type Server struct {
+	// The ID of the application the bot belongs to.
+	AppID string
+	// The user the bot is logged in as.
+	User *discordgo.User
+	// The HTTP server that serves the REST API and gateway.
+	server *httptest.Server
+	// The endpoints of discordgo before the server was started, restored by Close.
+	endpoints endpoints
+	// Guards the fields below.
+	mu sync.Mutex
+	// The last ID given out by newID.
+	lastID int64
+	// The requests sent to the REST API, in order.
+	requests []Request
+	// The messages sent by the bot, in order.
+	messages []posted
+	// The servers the bot is in, by ID.
+	guilds map[string]*discordgo.Guild
+	// The channels of the servers the bot is in, by ID.
+	channels map[string]*discordgo.Channel
+	// The channels of the interactions that have been injected, by token.
+	interactions map[string]string
+	// The application commands registered by the bot.
+	commands json.RawMessage
+	// The gateway connections that have identified.
+	conns []*gatewayConn
+	// The sequence number of the last event dispatched.
+	sequence int64
+}
+
+// posted is a message sent by the bot.
+type posted struct {
+	// The message, with the ID of the channel it was sent in.
+	message *discordgo.Message
+	// The token of the interaction the message responds to, if any.
+	token string
+}
+
+// New starts a fake Discord server and points discordgo at it. The server must be
+// closed with Close once the test is finished.
+func New() *Server {
+	s := &Server{
+		AppID: "100000000000000001",
+		User: &discordgo.User{
+			ID:       "100000000000000002",
+			Username: "Game Streams",
+			Bot:      true,
+		},
+		lastID:       100000000000000100,
+		guilds:       map[string]*discordgo.Guild{},
+		channels:     map[string]*discordgo.Channel{},
+		interactions: map[string]string{},
+	}
+	api := "/api/v" + discordgo.APIVersion
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gateway/", s.serveGateway)
+	mux.HandleFunc("GET "+api+"/gateway", s.handleGateway)
+	mux.HandleFunc("GET "+api+"/gateway/bot", s.handleGateway)
+	mux.HandleFunc("GET "+api+"/channels/{channel}", s.handleChannel)
+	mux.HandleFunc("POST "+api+"/channels/{channel}/messages", s.handleMessage)
+	mux.HandleFunc("PATCH "+api+"/channels/{channel}/messages/{message}", s.handleMessage)
+	mux.HandleFunc("POST "+api+"/channels/{channel}/messages/{message}/crosspost", s.handleEcho)
+	mux.HandleFunc("DELETE "+api+"/channels/{channel}/messages/{message}", s.handleNoContent)
+	mux.HandleFunc("GET "+api+"/guilds/{guild}", s.handleGuild)
+	mux.HandleFunc("GET "+api+"/guilds/{guild}/channels", s.handleGuildChannels)
+	mux.HandleFunc("POST "+api+"/users/@me/channels", s.handleDMChannel)
+	mux.HandleFunc("GET "+api+"/applications/{app}/commands", s.handleCommands)
+	mux.HandleFunc("PUT "+api+"/applications/{app}/commands", s.handleCommands)
+	mux.HandleFunc("POST "+api+"/interactions/{interaction}/{token}/callback", s.handleInteractionResponse)
+	mux.HandleFunc("POST "+api+"/webhooks/{app}/{token}", s.handleFollowup)
+	mux.HandleFunc("PATCH "+api+"/webhooks/{app}/{token}/messages/{message}", s.handleFollowup)
+	mux.HandleFunc("/", s.handleEcho)
+	s.server = httptest.NewServer(s.record(mux))
+	s.endpoints = currentEndpoints()
+	setEndpoints(s.server.URL + "/")
+	return s
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close closes the gateway connections, stops the server and points discordgo back at
+// Discord.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for _, conn := range s.conns {
+		conn.close()
+	}
+	s.conns = nil
+	s.mu.Unlock()
+	s.server.Close()
+	s.endpoints.apply()
+}
+
+// Session returns a new discordgo session that is connected to the server. Handlers can
+// be added to it before events are injected, e.g. by setting it as the Session of an
+// app.App and calling commands.RegisterHandler.
+func (s *Server) Session() (*discordgo.Session, error) {
+	session, newErr := discordgo.New("Bot test-token")
+	if newErr != nil {
+		return nil, newErr
+	}
+	session.ShouldRetryOnRateLimit = false
+	if openErr := session.Open(); openErr != nil {
+		return nil, openErr
+	}
+	return session, nil
+}
+
+// AddGuild adds a server and its channels to the bot. If the bot is connected, the
+// server is sent to it in a GUILD_CREATE event.
+func (s *Server) AddGuild(guild *discordgo.Guild) error {
+	s.mu.Lock()
+	s.guilds[guild.ID] = guild
+	for _, channel := range guild.Channels {
+		channel.GuildID = guild.ID
+		s.channels[channel.ID] = channel
+	}
+	s.mu.Unlock()
+	return s.Dispatch("GUILD_CREATE", guild)
+}
+
+// Requests returns the requests sent to the REST API, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Messages returns the messages the bot sent or edited in the channel, including
+// responses to interactions in the channel, in order.
+func (s *Server) Messages(channelID string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []*discordgo.Message
+	for _, p := range s.messages {
+		if p.message.ChannelID == channelID {
+			messages = append(messages, p.message)
+		}
+	}
+	return messages
+}
+
+// Responses returns the responses, follow ups and edits the bot sent for the
+// interaction, in order.
+func (s *Server) Responses(interaction *discordgo.Interaction) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []*discordgo.Message
+	for _, p := range s.messages {
+		if p.token != "" && p.token == interaction.Token {
+			messages = append(messages, p.message)
+		}
+	}
+	return messages
+}
+
+// newID returns a new unique snowflake ID.
+func (s *Server) newID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	return strconv.FormatInt(s.lastID, 10)
+}
+
+// record records every request before passing it on to the handler.
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if _, path, isAPI := strings.Cut(r.URL.Path, "/api/v"+discordgo.APIVersion+"/"); isAPI {
+			s.mu.Lock()
+			s.requests = append(s.requests, Request{Method: r.Method, Path: path, Body: body})
+			s.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addMessage records a message sent by the bot.
+func (s *Server) addMessage(message *discordgo.Message, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, posted{message: message, token: token})
+}
+
+// writeJSON responds with the value as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with a Discord API error.
+func writeError(w http.ResponseWriter, status int, code int, message string) {
+	writeJSON(w, status, map[string]any{"code": code, "message": message})
+}
@@ -0,0 +1,107 @@
+/*
+atom.go contains the types and function for rendering feeds as Atom 1.0.
+*/
+package feeds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// atomFeed is the root element of an Atom feed.
+type atomFeed struct {
+	// The name and namespace of the root element.
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	// A unique and permanent ID for the feed.
+	ID string `xml:"id"`
+	// The title of the feed.
+	Title string `xml:"title"`
+	// The time the feed was last changed, in RFC3339 format.
+	Updated string `xml:"updated"`
+	// The links to the feed itself.
+	Links []atomLink `xml:"link"`
+	// The author of the feed.
+	Author atomAuthor `xml:"author"`
+	// The streams in the feed.
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is a link from an Atom feed or entry.
+type atomLink struct {
+	// The URL linked to.
+	Href string `xml:"href,attr"`
+	// The relation of the URL to the feed or entry.
+	Rel string `xml:"rel,attr,omitempty"`
+	// The media type of the URL.
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// atomAuthor is the author of an Atom feed.
+type atomAuthor struct {
+	// The name of the author.
+	Name string `xml:"name"`
+}
+
+// atomEntry is a single stream in an Atom feed.
+type atomEntry struct {
+	// The unique ID of the stream.
+	ID string `xml:"id"`
+	// The name of the stream.
+	Title string `xml:"title"`
+	// The time the stream was added, in RFC3339 format.
+	Updated string `xml:"updated"`
+	// The time the stream was added, in RFC3339 format.
+	Published string `xml:"published"`
+	// The link to the stream.
+	Links []atomLink `xml:"link"`
+	// The details of the stream.
+	Content atomContent `xml:"content"`
+	// The platforms of the stream.
+	Categories []atomCategory `xml:"category"`
+}
+
+// atomContent is the content of an Atom entry.
+type atomContent struct {
+	// The type of content, always text.
+	Type string `xml:"type,attr"`
+	// The content.
+	Value string `xml:",chardata"`
+}
+
+// atomCategory is a category of an Atom entry.
+type atomCategory struct {
+	// The name of the category.
+	Term string `xml:"term,attr"`
+}
+
+// Atom returns the feed as an Atom 1.0 document.
+func (f Feed) Atom() ([]byte, error) {
+	feed := atomFeed{
+		ID:      "urn:gamestreams:feed:" + feedPath(f.Kind, f.Platform, FormatAtom),
+		Title:   f.Title,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links:   []atomLink{{Href: f.URL(FormatAtom), Rel: "self", Type: "application/atom+xml"}},
+		Author:  atomAuthor{Name: "Game Streams"},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Updated:   item.Published.Format(time.RFC3339),
+			Published: item.Published.Format(time.RFC3339),
+			Content:   atomContent{Type: "text", Value: item.Description},
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Link, Rel: "alternate"})
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	out, marshalErr := xml.MarshalIndent(feed, "", "  ")
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,208 @@
+/*
+feeds.go contains functions for building feeds of newly added and upcoming streams from
+the streams table of the database. Each feed can be rendered as RSS or Atom, and there
+is a feed of each kind for every platform as well as for all platforms.
+*/
+package feeds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/locales"
+)
+
+// The kinds of feed.
+const (
+	// FeedNew lists the most recently added streams, newest first.
+	FeedNew = "new"
+	// FeedUpcoming lists the streams that have not started yet, soonest first.
+	FeedUpcoming = "upcoming"
+)
+
+// The formats feeds are rendered in, which are also the extensions of their files.
+const (
+	// FormatRSS is an RSS 2.0 feed.
+	FormatRSS = "rss"
+	// FormatAtom is an Atom 1.0 feed.
+	FormatAtom = "atom"
+)
+
+// kinds are the kinds of feed that are served and written.
+var kinds = []string{FeedNew, FeedUpcoming}
+
+// formats are the formats that feeds are served and written in.
+var formats = []string{FormatRSS, FormatAtom}
+
+// platformNames maps the lower case name of each platform that has its own feeds to the
+// name shown in the feed title.
+var platformNames = map[string]string{
+	"playstation": "PlayStation",
+	"xbox":        "Xbox",
+	"nintendo":    "Nintendo",
+	"pc":          "PC",
+	"vr":          "VR",
+}
+
+// Feed is a list of streams that can be rendered as RSS or Atom.
+type Feed struct {
+	// The kind of feed, new or upcoming.
+	Kind string
+	// The lower case platform of the streams in the feed, or empty for all platforms.
+	Platform string
+	// The title of the feed.
+	Title string
+	// A description of the streams in the feed.
+	Description string
+	// The time the feed was last changed.
+	Updated time.Time
+	// The streams in the feed.
+	Items []Item
+	// The base URL set in config.toml when the feed was built, which its URLs start with.
+	baseURL string
+}
+
+// Item is a single stream in a feed.
+type Item struct {
+	// A unique and permanent ID for the stream.
+	ID string
+	// The name of the stream.
+	Title string
+	// The URL of the stream.
+	Link string
+	// The start time, platforms, status and description of the stream as plain text.
+	Description string
+	// The platforms of the stream.
+	Categories []string
+	// The time the stream was added.
+	Published time.Time
+}
+
+// Build returns the feed of the given kind for the platform, or for every platform if
+// the platform is empty. The number of streams in the feed is set in config.toml.
+func Build(a *app.App, kind string, platform string) (Feed, error) {
+	if _, exists := platformNames[platform]; platform != "" && !exists {
+		return Feed{}, fmt.Errorf("unknown platform %q", platform)
+	}
+	var s db.Streams
+	var f Feed
+	switch kind {
+	case FeedNew:
+		if getErr := s.GetNewest(a.DB, platform, feedLimit(a)); getErr != nil {
+			return Feed{}, getErr
+		}
+		f.Title = "New streams"
+		f.Description = "Game announcement streams, newest first"
+	case FeedUpcoming:
+		if getErr := s.GetUpcomingOn(a.DB, platform, feedLimit(a)); getErr != nil {
+			return Feed{}, getErr
+		}
+		f.Title = "Upcoming streams"
+		f.Description = "Game announcement streams that have not started yet, soonest first"
+	default:
+		return Feed{}, fmt.Errorf("unknown feed %q", kind)
+	}
+	f.Kind = kind
+	f.Platform = platform
+	f.baseURL = a.Config().Feeds.BaseURL
+	if platform != "" {
+		f.Title = fmt.Sprintf("%s: %s", f.Title, platformNames[platform])
+	}
+	f.Title = "Game Streams - " + f.Title
+	for _, stream := range s.Streams {
+		item := newItem(stream)
+		if item.Published.After(f.Updated) {
+			f.Updated = item.Published
+		}
+		f.Items = append(f.Items, item)
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now().UTC()
+	}
+	return f, nil
+}
+
+// Render returns the feed in the given format.
+func (f Feed) Render(format string) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return f.RSS()
+	case FormatAtom:
+		return f.Atom()
+	default:
+		return nil, fmt.Errorf("unknown feed format %q", format)
+	}
+}
+
+// URL returns the public URL of the feed in the given format, using the base URL set in
+// config.toml.
+func (f Feed) URL(format string) string {
+	return strings.TrimSuffix(f.baseURL, "/") + "/feeds/" +
+		feedPath(f.Kind, f.Platform, format)
+}
+
+// newItem returns the feed item for the stream.
+func newItem(stream db.Stream) Item {
+	locale := locales.Default
+	starts := stream.Date
+	if stream.Time != "" {
+		starts += " " + stream.Time + " UTC"
+	}
+	lines := []string{
+		fmt.Sprintf("%s: %s", locales.T(locale, "field.date"), starts),
+		fmt.Sprintf("%s: %s", locales.T(locale, "field.platforms"), stream.Platform),
+	}
+	if stream.Status != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", locales.T(locale, "field.status"),
+			locales.T(locale, "status."+stream.Status)))
+	}
+	if stream.Description != "" {
+		lines = append(lines, "", stream.Description)
+	}
+	var categories []string
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			categories = append(categories, platform)
+		}
+	}
+	return Item{
+		ID:          "urn:gamestreams:stream:" + strconv.Itoa(stream.ID),
+		Title:       stream.Name,
+		Link:        stream.URL,
+		Description: strings.Join(lines, "\n"),
+		Categories:  categories,
+		Published:   publishedTime(stream),
+	}
+}
+
+// publishedTime returns the time the stream was added, or the start of the day it is
+// scheduled for if it was added before this was recorded.
+func publishedTime(stream db.Stream) time.Time {
+	if added, parseErr := time.Parse(time.RFC3339, stream.AddedAt); parseErr == nil {
+		return added
+	}
+	date, _ := time.Parse("2006-01-02", stream.Date)
+	return date
+}
+
+// feedPath returns the path of the feed file relative to the feeds directory, e.g.
+// xbox/upcoming.rss.
+func feedPath(kind string, platform string, format string) string {
+	file := kind + "." + format
+	if platform == "" {
+		return file
+	}
+	return platform + "/" + file
+}
+
+// feedLimit returns the number of streams in each feed, as set in config.toml.
+func feedLimit(a *app.App) int {
+	if a.Config().Feeds.Limit <= 0 {
+		return 50
+	}
+	return a.Config().Feeds.Limit
+}
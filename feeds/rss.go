@@ -0,0 +1,91 @@
+/*
+rss.go contains the types and function for rendering feeds as RSS 2.0.
+*/
+package feeds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// rssFeed is the root element of an RSS feed.
+type rssFeed struct {
+	// The name of the root element.
+	XMLName xml.Name `xml:"rss"`
+	// The version of RSS, always 2.0.
+	Version string `xml:"version,attr"`
+	// The Atom namespace, used for the self link.
+	AtomNS string `xml:"xmlns:atom,attr"`
+	// The details and items of the feed.
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel holds the details and items of an RSS feed.
+type rssChannel struct {
+	// The title of the feed.
+	Title string `xml:"title"`
+	// The URL of the feed.
+	Link string `xml:"link"`
+	// A description of the feed.
+	Description string `xml:"description"`
+	// A link to the feed itself.
+	AtomLink atomLink `xml:"atom:link"`
+	// The time the feed was last changed, in RFC 1123 format.
+	LastBuildDate string `xml:"lastBuildDate"`
+	// The streams in the feed.
+	Items []rssItem `xml:"item"`
+}
+
+// rssItem is a single stream in an RSS feed.
+type rssItem struct {
+	// The name of the stream.
+	Title string `xml:"title"`
+	// The URL of the stream.
+	Link string `xml:"link,omitempty"`
+	// The details of the stream.
+	Description string `xml:"description"`
+	// The unique ID of the stream.
+	GUID rssGUID `xml:"guid"`
+	// The time the stream was added, in RFC 1123 format.
+	PubDate string `xml:"pubDate"`
+	// The platforms of the stream.
+	Categories []string `xml:"category"`
+}
+
+// rssGUID is the unique ID of an RSS item.
+type rssGUID struct {
+	// False, as the ID is not a URL.
+	IsPermaLink bool `xml:"isPermaLink,attr"`
+	// The ID.
+	Value string `xml:",chardata"`
+}
+
+// RSS returns the feed as an RSS 2.0 document.
+func (f Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.URL(FormatRSS),
+		Description:   f.Description,
+		AtomLink:      atomLink{Href: f.URL(FormatRSS), Rel: "self", Type: "application/rss+xml"},
+		LastBuildDate: f.Updated.Format(time.RFC1123Z),
+	}
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Categories:  item.Categories,
+		})
+	}
+	out, marshalErr := xml.MarshalIndent(rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	}, "", "  ")
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,132 @@
+/*
+server.go contains functions for serving the feeds over HTTP and writing them to files,
+so they can be read by RSS readers directly or published by a web server.
+*/
+package feeds
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gamestreams/app"
+)
+
+// contentTypes maps each feed format to its media type.
+var contentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+}
+
+// Serve serves the feeds on the address set in config.toml, in a new goroutine. The
+// feeds for all platforms are served at /feeds/<kind>.<format>, e.g. /feeds/new.rss,
+// and the feeds for a platform at /feeds/<platform>/<kind>.<format>. It returns the
+// server, or nil if no address is set.
+func Serve(a *app.App) *http.Server {
+	if a.Config().Feeds.Address == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /feeds/{file}", handleFeed(a))
+	mux.HandleFunc("GET /feeds/{platform}/{file}", handleFeed(a))
+	server := &http.Server{
+		Addr:              a.Config().Feeds.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		a.Log.LogInfo("FEEDS", "serving feeds", false,
+			"address", server.Addr)
+		if serveErr := server.ListenAndServe(); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			a.Log.LogError("FEEDS", "error serving feeds",
+				"err", serveErr)
+		}
+	}()
+	return server
+}
+
+// handleFeed returns a handler that responds with the feed named by the path of the
+// request.
+func handleFeed(a *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		kind, format, _ := strings.Cut(r.PathValue("file"), ".")
+		platform := r.PathValue("platform")
+		if _, exists := platformNames[platform]; !slices.Contains(kinds, kind) ||
+			!slices.Contains(formats, format) || (platform != "" && !exists) {
+			http.NotFound(w, r)
+			return
+		}
+		f, buildErr := Build(a, kind, platform)
+		if buildErr != nil {
+			a.Log.LogError("FEEDS", "error building feed",
+				"kind", kind,
+				"platform", platform,
+				"err", buildErr)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		out, renderErr := f.Render(format)
+		if renderErr != nil {
+			a.Log.LogError("FEEDS", "error rendering feed",
+				"kind", kind,
+				"platform", platform,
+				"err", renderErr)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Write(out)
+	}
+}
+
+// WriteFiles writes every feed to the directory set in config.toml, using the same
+// paths the feeds are served at. Each file is replaced in one step, so a web server
+// publishing the directory never serves a partly written feed.
+func WriteFiles(a *app.App) error {
+	directory := a.Config().Feeds.Directory
+	if directory == "" {
+		return nil
+	}
+	platforms := []string{""}
+	for platform := range platformNames {
+		platforms = append(platforms, platform)
+	}
+	for _, platform := range platforms {
+		for _, kind := range kinds {
+			f, buildErr := Build(a, kind, platform)
+			if buildErr != nil {
+				return buildErr
+			}
+			for _, format := range formats {
+				out, renderErr := f.Render(format)
+				if renderErr != nil {
+					return renderErr
+				}
+				path := filepath.Join(directory, filepath.FromSlash(feedPath(kind, platform, format)))
+				if writeErr := writeFile(path, out); writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+	}
+	a.Log.LogInfo("FEEDS", "wrote feeds", false,
+		"directory", directory)
+	return nil
+}
+
+// writeFile writes the data to a temporary file next to the path, then renames it to
+// the path.
+func writeFile(path string, data []byte) error {
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0o755); mkdirErr != nil {
+		return mkdirErr
+	}
+	tmp := path + ".tmp"
+	if writeErr := os.WriteFile(tmp, data, 0o644); writeErr != nil {
+		return writeErr
+	}
+	return os.Rename(tmp, path)
+}
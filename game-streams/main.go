@@ -6,24 +6,11 @@ package main
 import (
 	"os"
 
-	"gamestreams/bot"
-	"gamestreams/config"
-	"gamestreams/db"
-	"gamestreams/logs"
+	"gamestreams/cli"
 )
 
-// main loads the configuration values from config.toml, initialises the logs, creates
-// the database, and starts the bot.
+// main runs the subcommand given on the command line, or the bot if none is given, and
+// exits with its exit code. See cli.Run for the subcommands.
 func main() {
-	config.Values.Load()
-	logs.Log.Init()
-	logs.Log.Info.WithPrefix(" MAIN").Info("starting bot")
-
-	createErr := db.CreateDB()
-	if createErr != nil {
-		logs.Log.ErrorWarn.WithPrefix(" MAIN").Error("error creating database",
-			"err", createErr)
-		os.Exit(1)
-	}
-	bot.Run(config.Values.Discord.Token, config.Values.Discord.ApplicationID)
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -0,0 +1,336 @@
+/*
+jobs.go contains the registry of the jobs the bot runs on a schedule. Each job has a
+name, is run by cron on the schedule set in config.toml and can also be run on demand.
+Only one run of a job can be in progress at a time, and every run is recorded in the
+job_runs table with its duration and outcome. When the bot starts, jobs that missed a
+scheduled run while it was not running are run to catch up.
+*/
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"gamestreams/db"
+	"gamestreams/logs"
+)
+
+// The triggers that start a job run.
+const (
+	// TriggerSchedule is a run started by cron.
+	TriggerSchedule = "schedule"
+	// TriggerStartup is a run started when the bot starts.
+	TriggerStartup = "startup"
+	// TriggerCatchUp is a run started when the bot starts because a scheduled run was
+	// missed while it was not running.
+	TriggerCatchUp = "catch-up"
+	// TriggerOwner is a run started by the bot owner.
+	TriggerOwner = "owner"
+)
+
+var (
+	// ErrUnknownJob is returned when no job is registered with the name.
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrRunning is returned when a job is started while a run of it is in progress.
+	ErrRunning = errors.New("job is already running")
+	// ErrStopped is returned when a job is started after the bot has started shutting
+	// down.
+	ErrStopped = errors.New("jobs have been stopped")
+)
+
+// job is a job in the registry.
+type job struct {
+	// The name of the job, e.g. stream_update.
+	name string
+	// A short description of what the job does.
+	description string
+	// The function that runs the job. It returns an error if the run failed.
+	run func() error
+	// Held while the job is running, so only one run is in progress at a time.
+	lock sync.Mutex
+	// The cron string the job is scheduled with, or "" if it is not scheduled.
+	spec string
+	// The cron entry of the job, if it is scheduled.
+	entry cron.EntryID
+}
+
+// Registry holds the jobs of the bot and the cron scheduler that runs them.
+type Registry struct {
+	// Cancelled when the bot starts shutting down, after which no jobs are started.
+	ctx context.Context
+	// The database that job runs are recorded in.
+	db *db.Database
+	// The logger that job runs are logged with.
+	log *logs.Logger
+	// Records that a task has started and returns the function to call when it has
+	// finished, so shutdown waits for running jobs.
+	track func(name string) (done func())
+	// Guards jobs and scheduler.
+	mu sync.Mutex
+	// The registered jobs, in the order they were registered.
+	jobs []*job
+	// The scheduler the jobs are scheduled with, or nil if Schedule has not been called.
+	scheduler *cron.Cron
+}
+
+// Status is the state of a job and its most recent run.
+type Status struct {
+	// The name of the job.
+	Name string
+	// A short description of what the job does.
+	Description string
+	// The cron string the job is scheduled with, or "" if it is not scheduled.
+	Schedule string
+	// True if a run of the job is in progress.
+	Running bool
+	// The time of the next scheduled run, or the zero time if it is not scheduled.
+	Next time.Time
+	// The most recent run of the job. Its ID is 0 if the job has never been run.
+	Last db.JobRun
+}
+
+// NewRegistry returns an empty registry that records runs in database and logs them with
+// log. No jobs are started once ctx is cancelled, and each run is tracked with track.
+func NewRegistry(ctx context.Context, database *db.Database, log *logs.Logger, track func(name string) (done func())) *Registry {
+	return &Registry{ctx: ctx, db: database, log: log, track: track}
+}
+
+// Register adds a job with the name. If a job with the name is already registered, its
+// description and function are replaced.
+func (r *Registry) Register(name string, description string, run func() error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if j := r.find(name); j != nil {
+		j.description, j.run = description, run
+		return
+	}
+	r.jobs = append(r.jobs, &job{name: name, description: description, run: run})
+}
+
+// find returns the job with the name, or nil if there is none. r.mu must be held.
+func (r *Registry) find(name string) *job {
+	for _, j := range r.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// Schedule starts a new scheduler that runs each registered job with a cron string in
+// specs, keyed by the name of the job, replacing the previous scheduler. Jobs without a
+// cron string are not scheduled but can still be run with Run or Trigger. Runs already
+// in progress are not affected. If any cron string is invalid an error is returned and
+// the previous schedule is kept.
+func (r *Registry) Schedule(specs map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scheduler := cron.New(cron.WithLocation(time.UTC))
+	entries := make([]cron.EntryID, len(r.jobs))
+	for i, j := range r.jobs {
+		spec := specs[j.name]
+		if spec == "" {
+			continue
+		}
+		entry, addErr := scheduler.AddFunc(spec, func() {
+			r.run(j, TriggerSchedule)
+		})
+		if addErr != nil {
+			return fmt.Errorf("%s: %w", j.name, addErr)
+		}
+		entries[i] = entry
+	}
+	for i, j := range r.jobs {
+		j.spec, j.entry = specs[j.name], entries[i]
+	}
+	if r.scheduler != nil {
+		r.scheduler.Stop()
+	}
+	r.scheduler = scheduler
+	scheduler.Start()
+	return nil
+}
+
+// Stop stops the scheduler, so no more jobs are run on their schedule. Runs already in
+// progress are not affected.
+func (r *Registry) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.scheduler != nil {
+		r.scheduler.Stop()
+	}
+}
+
+// Run runs the job with the name and waits for it to finish. It returns the recorded
+// run and the error returned by the job, or ErrUnknownJob, ErrRunning or ErrStopped if
+// the job was not run.
+func (r *Registry) Run(name string, trigger string) (db.JobRun, error) {
+	r.mu.Lock()
+	j := r.find(name)
+	r.mu.Unlock()
+
+	if j == nil {
+		return db.JobRun{}, ErrUnknownJob
+	}
+	return r.run(j, trigger)
+}
+
+// Trigger starts a run of the job with the name in a new goroutine. It returns
+// ErrUnknownJob, ErrRunning or ErrStopped if the job cannot be started.
+func (r *Registry) Trigger(name string, trigger string) error {
+	r.mu.Lock()
+	j := r.find(name)
+	r.mu.Unlock()
+
+	if j == nil {
+		return ErrUnknownJob
+	}
+	if r.ctx.Err() != nil {
+		return ErrStopped
+	}
+	if !j.lock.TryLock() {
+		return ErrRunning
+	}
+	go func() {
+		defer j.lock.Unlock()
+		r.record(j, trigger)
+	}()
+	return nil
+}
+
+// run runs the job unless a run of it is already in progress or the bot is shutting
+// down.
+func (r *Registry) run(j *job, trigger string) (db.JobRun, error) {
+	if r.ctx.Err() != nil {
+		return db.JobRun{}, ErrStopped
+	}
+	if !j.lock.TryLock() {
+		r.log.LogInfo(" JOBS", "skipping job, already running", false,
+			"job", j.name,
+			"trigger", trigger)
+		return db.JobRun{}, ErrRunning
+	}
+	defer j.lock.Unlock()
+	return r.record(j, trigger)
+}
+
+// record runs the job, which must be locked, and records the run in the job_runs table.
+// A panic in the job is recovered and recorded as a failure.
+func (r *Registry) record(j *job, trigger string) (db.JobRun, error) {
+	defer r.track(j.name)()
+
+	r.log.LogInfo(" JOBS", "running job", false,
+		"job", j.name,
+		"trigger", trigger)
+	run := db.JobRun{
+		Job:       j.name,
+		Trigger:   trigger,
+		StartedAt: time.Now().UTC(),
+		Outcome:   db.JobSucceeded,
+	}
+	runErr := safeRun(j.run)
+	run.Duration = time.Since(run.StartedAt)
+	if runErr != nil {
+		run.Outcome = db.JobFailed
+		run.Error = runErr.Error()
+	}
+	if insertErr := run.Insert(r.db); insertErr != nil {
+		r.log.LogError(" JOBS", "error recording job run",
+			"job", j.name,
+			"err", insertErr)
+	}
+	r.log.LogInfo(" JOBS", "finished job", false,
+		"job", j.name,
+		"outcome", run.Outcome,
+		"duration", run.Duration.Round(time.Millisecond).String())
+	return run, runErr
+}
+
+// safeRun calls f and returns its error, or an error describing the panic if it
+// panicked.
+func safeRun(f func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return f()
+}
+
+// CatchUp runs each scheduled job that missed a run while the bot was not running,
+// once, in the order the jobs were registered. A run was missed if the job was due to
+// run between its most recent recorded run and now. Jobs that have never been run are
+// not caught up.
+func (r *Registry) CatchUp() {
+	last, getErr := r.db.GetLastJobRuns()
+	if getErr != nil {
+		r.log.LogError(" JOBS", "error getting job runs",
+			"err", getErr)
+		return
+	}
+
+	r.mu.Lock()
+	var missed []*job
+	now := time.Now().UTC()
+	for _, j := range r.jobs {
+		run, exists := last[j.name]
+		if j.spec == "" || !exists {
+			continue
+		}
+		schedule, parseErr := cron.ParseStandard(j.spec)
+		if parseErr != nil {
+			continue
+		}
+		if next := schedule.Next(run.StartedAt.UTC()); next.Before(now) {
+			r.log.LogInfo(" JOBS", "missed scheduled run", false,
+				"job", j.name,
+				"last_run", run.StartedAt.Format(time.RFC3339),
+				"missed", next.Format(time.RFC3339))
+			missed = append(missed, j)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, j := range missed {
+		r.run(j, TriggerCatchUp)
+	}
+}
+
+// List returns the status of each registered job, in the order they were registered.
+func (r *Registry) List() ([]Status, error) {
+	last, getErr := r.db.GetLastJobRuns()
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		status := Status{
+			Name:        j.name,
+			Description: j.description,
+			Schedule:    j.spec,
+			Last:        last[j.name],
+		}
+		if j.lock.TryLock() {
+			j.lock.Unlock()
+		} else {
+			status.Running = true
+		}
+		if j.spec != "" && r.scheduler != nil {
+			status.Next = r.scheduler.Entry(j.entry).Next
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gamestreams/config"
+	"gamestreams/db"
+	"gamestreams/logs"
+	"gamestreams/utils"
+)
+
+// newTestRegistry returns a registry with a new database and two jobs that do nothing.
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	cfg := &config.Config{}
+	getConfig := func() *config.Config { return cfg }
+	log := logs.New(cfg.Logs)
+	database, openErr := db.Open(filepath.Join(t.TempDir(), "test.db"), getConfig, log,
+		utils.NewClient(context.Background(), getConfig, log))
+	if openErr != nil {
+		t.Fatalf("db.Open() error = %v", openErr)
+	}
+	r := NewRegistry(context.Background(), database, log, func(string) func() { return func() {} })
+	t.Cleanup(r.Stop)
+	r.Register("first", "the first job", func() error { return nil })
+	r.Register("second", "the second job", func() error { return nil })
+	return r
+}
+
+func TestScheduleInvalidSpecKeepsSchedule(t *testing.T) {
+	r := newTestRegistry(t)
+	if scheduleErr := r.Schedule(map[string]string{"first": "0 * * * *", "second": "30 * * * *"}); scheduleErr != nil {
+		t.Fatalf("Schedule() error = %v", scheduleErr)
+	}
+	if scheduleErr := r.Schedule(map[string]string{"first": "15 * * * *", "second": "not a spec"}); scheduleErr == nil {
+		t.Fatal("Schedule() with an invalid spec succeeded, want an error")
+	}
+
+	statuses, listErr := r.List()
+	if listErr != nil {
+		t.Fatalf("List() error = %v", listErr)
+	}
+	want := map[string]string{"first": "0 * * * *", "second": "30 * * * *"}
+	wantMinute := map[string]int{"first": 0, "second": 30}
+	for _, status := range statuses {
+		if status.Schedule != want[status.Name] {
+			t.Errorf("%s is scheduled with %q, want the previous schedule %q", status.Name,
+				status.Schedule, want[status.Name])
+		}
+		if status.Next.IsZero() || status.Next.Minute() != wantMinute[status.Name] {
+			t.Errorf("next run of %s = %s, want it to use the previous schedule", status.Name, status.Next)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package locales
+
+// german holds the German messages.
+var german = map[string]string{
+	// Commands
+	"command.streams.description":                     "Kommende Streams für alle Plattformen auflisten",
+	"command.streaminfo.description":                  "Mehr Informationen zu einem bestimmten Stream anhand des Namens",
+	"command.streaminfo.name.description":             "Der Name des Streams",
+	"command.help.name":                               "hilfe",
+	"command.help.description":                        "Hilfe zum Bot erhalten",
+	"command.help.command.description":                "Der Befehl, zu dem du Hilfe brauchst. Leer lassen für allgemeine Hilfe.",
+	"command.suggest.description":                     "Einen Stream für die Datenbank des Bots vorschlagen",
+	"command.suggest.name.description":                "Der Name des Streams",
+	"command.suggest.date.description":                "Das Datum des Streams (JJJJ-MM-TT)",
+	"command.suggest.url.description":                 "Die URL des Streams oder einer Seite mit Informationen dazu",
+	"command.settings.name":                           "einstellungen",
+	"command.settings.description":                    "Bot-Einstellungen ändern",
+	"command.settings.channel.description":            "Den Kanal festlegen, in dem der Start eines Streams angekündigt wird",
+	"command.settings.role.description":               "Die Rolle festlegen, die beim Start eines Streams erwähnt wird",
+	"command.settings.playstation.description":        "PlayStation-Ankündigungen aktivieren oder deaktivieren",
+	"command.settings.xbox.description":               "Xbox-Ankündigungen aktivieren oder deaktivieren",
+	"command.settings.nintendo.description":           "Nintendo-Ankündigungen aktivieren oder deaktivieren",
+	"command.settings.pc.description":                 "PC-Ankündigungen aktivieren oder deaktivieren",
+	"command.settings.vr.description":                 "VR-Ankündigungen aktivieren oder deaktivieren",
+	"command.settings.events.description":             "Discord-Events für kommende Streams erstellen oder nicht",
+	"command.settings.crosspost.description":          "Ankündigungen an Server veröffentlichen, die dem Kanal folgen, falls es ein Ankündigungskanal ist",
+	"command.settings.lead_time.description":          "Wie viele Minuten vor dem Start ein Stream angekündigt wird",
+	"command.settings.follow_publisher.description":   "Alle Streams eines Publishers ankündigen, unabhängig von der Plattform",
+	"command.settings.unfollow_publisher.description": "Streams eines Publishers nicht mehr ankündigen",
+	"command.settings.message.description":            "Vorlage für den Nachrichtentext der Ankündigung oder \"default\"",
+	"command.settings.embed_title.description":        "Vorlage für den Embed-Titel der Ankündigung oder \"default\"",
+	"command.settings.embed_description.description":  "Vorlage für die Embed-Beschreibung der Ankündigung oder \"default\"",
+	"command.settings.embed_footer.description":       "Vorlage für die Embed-Fußzeile der Ankündigung oder \"default\"",
+	"command.settings.thumbnail.description":          "Das Vorschaubild in Ankündigungen anzeigen oder ausblenden",
+	"command.settings.show_platforms.description":     "Das Plattform-Feld in Ankündigungen anzeigen oder ausblenden",
+	"command.settings.reset_template.description":     "Die Ankündigungsvorlagen zurücksetzen",
+	"command.settings.preview.description":            "Eine Vorschau der Ankündigung mit den aktuellen Vorlagen anzeigen",
+	"command.settings.reset.description":              "Alle Einstellungen zurücksetzen",
+	"command.setup.description":                       "Stream-Ankündigungen Schritt für Schritt einrichten",
+	"command.follow.description":                      "Einem Stream, einer Plattform oder einem Publisher folgen und per DM erinnert werden",
+	"command.follow.stream.description":               "Der Name oder die ID des Streams",
+	"command.follow.platform.description":             "Die Plattform, der du folgen möchtest",
+	"command.follow.publisher.description":            "Der Publisher, dem du folgen möchtest (z. B. Nintendo, Ubisoft)",
+	"command.following.description":                   "Gefolgte Streams, Plattformen und Publisher anzeigen und entfernen",
+	"command.webhooks.description":                    "Stream-Ankündigungen an Discord-, Slack- oder JSON-Webhooks senden",
+	"command.webhooks.add.description":                "Die URL eines hinzuzufügenden Webhooks",
+	"command.webhooks.type.description":               "Der Typ des Webhooks, wird aus der URL ermittelt, wenn nicht gesetzt",
+	"command.webhooks.platform.description":           "Nur Streams dieser Plattform an den neuen Webhook senden",
+	"command.webhooks.lead_time.description":          "Wie viele Minuten vor Streambeginn er an den neuen Webhook gesendet wird",
+	"command.webhooks.remove.description":             "Die ID eines zu entfernenden Webhooks",
+	"command.webhooks.enable.description":             "Die ID eines deaktivierten Webhooks, der wieder aktiviert werden soll",
+
+	// Common
+	"common.error":          "Fehler",
+	"common.error_occurred": "Ein Fehler ist aufgetreten",
+	"common.not_set":        "nicht festgelegt",
+	"common.minutes":        "%d Minuten",
+	"common.default":        "Standard",
+	"common.custom":         "Benutzerdefiniert",
+	"blacklist.dm":          "Du bist von der Nutzung dieses Bots ausgeschlossen.\n\n**Grund:** `%s`\n**Läuft ab:** `%s`",
+
+	// Streams
+	"streams.title":         "Kommende Streams",
+	"streams.none":          "Keine Streams gefunden",
+	"streaminfo.title":      "Stream-Info",
+	"streaminfo.none":       "Keine Streams mit diesem Namen gefunden",
+	"field.status":          "Status",
+	"field.platforms":       "Plattformen",
+	"field.date":            "Datum",
+	"field.time":            "Uhrzeit",
+	"field.url":             "URL",
+	"field.description":     "Beschreibung",
+	"field.went_live":       "Live seit",
+	"field.publishers":      "Publisher",
+	"field.games":           "Spiele",
+	"status.rumoured":       "Gerücht",
+	"status.announced":      "Angekündigt",
+	"status.confirmed":      "Bestätigt",
+	"status.live":           "Live",
+	"status.ended":          "Beendet",
+	"status.cancelled":      "Abgesagt",
+	"status.postponed":      "Verschoben",
+	"announcement.starting": "Stream beginnt %s",
+	"announcement.started":  "Stream hat %s begonnen",
+	"followup.cancelled":    "**%s** wurde abgesagt.",
+	"followup.postponed":    "**%s** wurde verschoben. Der Stream wird erneut angekündigt, sobald ein neuer Termin feststeht.",
+
+	// Suggestions
+	"suggest.limit":        "Du hast das tägliche Limit für Vorschläge erreicht. Versuche es morgen erneut.",
+	"suggest.thanks_title": "Danke",
+	"suggest.thanks":       "Dein Vorschlag ist eingegangen",
+	"suggest.error":        "**Ein Fehler ist aufgetreten.** Dein Vorschlag ist möglicherweise nicht eingegangen.",
+
+	// Settings
+	"settings.title":               "Einstellungen",
+	"settings.current":             "Aktuelle Einstellungen:",
+	"settings.updated":             "Einstellungen erfolgreich aktualisiert.\n\n**Aktuelle Einstellungen:**",
+	"settings.reset_error":         "Ein Fehler ist aufgetreten. Die Einstellungen wurden möglicherweise nicht zurückgesetzt.",
+	"settings.get_error":           "Ein Fehler ist aufgetreten. Die Einstellungen wurden möglicherweise nicht aktualisiert.",
+	"settings.set_error":           "Ein Fehler ist aufgetreten. Die Einstellungen wurden nicht aktualisiert.",
+	"settings.channel_refused":     "Der Ankündigungskanal wurde nicht geändert, da ich dort nicht posten kann.\n\n**Aktuelle Einstellungen:**",
+	"settings.channel":             "Ankündigungskanal",
+	"settings.role":                "Ankündigungsrolle",
+	"settings.lead_time":           "Vorlaufzeit",
+	"settings.events":              "Geplante Events",
+	"settings.crosspost":           "Crossposten",
+	"settings.publishers":          "Publisher",
+	"settings.template":            "Ankündigungsvorlage",
+	"settings.warnings":            "Warnungen",
+	"settings.preview":             "**Vorschau** (vor und nach dem Start des Streams):",
+	"settings.preview_error":       "Vorschau der Ankündigungsvorlage nicht möglich: %s",
+	"settings.template_invalid":    "Die Ankündigungsvorlage wurde nicht aktualisiert: `%s`",
+	"settings.template_error":      "Ein Fehler ist aufgetreten. Die Ankündigungsvorlage wurde nicht aktualisiert.",
+	"settings.publisher_not_found": "Keine Publisher gefunden, die zu **%s** passen.",
+	"settings.publisher_error":     "Ein Fehler ist aufgetreten. **%s** wurde möglicherweise nicht aktualisiert.",
+
+	// Webhooks
+	"webhooks.title":           "Webhooks",
+	"webhooks.none":            "Keine Webhooks registriert. Nutze `add` mit einer Webhook-URL, um einen hinzuzufügen.",
+	"webhooks.registered":      "**Registrierte Webhooks:**",
+	"webhooks.limit":           "Server können bis zu %d Webhooks registrieren. Entferne einen, bevor du einen weiteren hinzufügst.",
+	"webhooks.invalid":         "Der Webhook wurde nicht hinzugefügt: `%s`",
+	"webhooks.add_error":       "Ein Fehler ist aufgetreten. Der Webhook wurde nicht hinzugefügt.",
+	"webhooks.added":           "Webhook `#%d` hinzugefügt.",
+	"webhooks.secret":          "Nutzdaten werden mit diesem Geheimnis signiert, es wird nicht erneut angezeigt:\n`%s`",
+	"webhooks.removed":         "Webhook `#%d` entfernt.",
+	"webhooks.enabled":         "Webhook `#%d` aktiviert.",
+	"webhooks.not_found":       "Kein Webhook mit der ID `%d` gefunden.",
+	"webhooks.error":           "Ein Fehler ist aufgetreten. Der Webhook wurde möglicherweise nicht aktualisiert.",
+	"webhooks.all_platforms":   "alle Plattformen",
+	"webhooks.enabled_status":  "✅ Aktiviert",
+	"webhooks.failing_status":  "⚠️ Aktiviert, %d fehlgeschlagene Zustellungen: `%s`",
+	"webhooks.disabled_status": "❌ Deaktiviert nach %d fehlgeschlagenen Zustellungen: `%s`",
+
+	// Setup
+	"setup.title":                 "Einrichtung: %s",
+	"setup.description":           "Wähle mit den Menüs unten, wo Streams angekündigt werden, wer erwähnt wird, welchen Plattformen gefolgt wird und wie früh Streams angekündigt werden. Änderungen werden sofort gespeichert.",
+	"setup.owner_only":            "Nur der Serverbesitzer kann den Bot per DM einrichten. Serveradministratoren können `/setup` auf dem Server nutzen.",
+	"setup.channel_refused":       "Ich kann in diesem Kanal nicht posten, bitte wähle einen anderen.",
+	"setup.complete":              "Einrichtung abgeschlossen! Nutze `/setup` oder `/settings`, um jederzeit Änderungen vorzunehmen.",
+	"setup.incomplete":            "Einrichtung gespeichert, aber Streams werden erst angekündigt, wenn ein Ankündigungskanal und mindestens eine Plattform gewählt sind.",
+	"setup.error":                 "Ein Fehler ist aufgetreten. Die Einstellungen wurden möglicherweise nicht aktualisiert.",
+	"setup.channel_placeholder":   "Ankündigungskanal wählen",
+	"setup.role_placeholder":      "Zu erwähnende Rolle wählen (optional)",
+	"setup.platforms_placeholder": "Plattformen zum Folgen wählen",
+	"setup.lead_placeholder":      "Wählen, wie früh Streams angekündigt werden",
+	"setup.no_channels":           "Keine Kanäle verfügbar",
+	"setup.no_roles":              "Keine Rollen verfügbar",
+	"setup.lead_option":           "%d Minuten vorher",
+	"setup.done":                  "Fertig",
+	"setup.start":                 "Einrichtung starten",
+	"setup.now":                   "Jetzt einrichten",
+
+	// Follows
+	"follow.title":               "Folgen",
+	"follow.choose":              "Wähle einen `stream`, eine `platform` oder einen `publisher` zum Folgen.",
+	"follow.stream_not_found":    "Keine kommenden Streams mit diesem Namen oder dieser ID gefunden.",
+	"follow.publisher_not_found": "Keine Publisher mit diesem Namen gefunden.",
+	"follow.all_streams":         "allen **%s**-Streams",
+	"follow.and":                 " und ",
+	"follow.limit":               "Du kannst bis zu %d Streams, Plattformen und Publishern folgen. Nutze `/following`, um einige zu entfernen.",
+	"follow.added":               "Du folgst jetzt %s.",
+	"follow.reminder":            "Ich schicke dir %d Minuten vor dem Start eine DM.\n\nNutze `/following`, um deine Follows anzusehen und zu verwalten.",
+	"follow.dms_off":             "⚠️ Du hast DM-Erinnerungen ausgeschaltet, schalte sie mit `/following` wieder ein.",
+	"follow.error":               "**Ein Fehler ist aufgetreten.** Der Follow wurde möglicherweise nicht hinzugefügt.",
+	"follow.dm":                  "🔔 Ein Stream, dem du folgst, beginnt bald. Nutze `/following`, um deine Follows zu verwalten oder diese Nachrichten abzustellen.",
+	"following.title":            "Gefolgt",
+	"following.none":             "Du folgst keinen Streams, Plattformen oder Publishern. Nutze `/follow`, um per DM erinnert zu werden, wenn Streams beginnen.",
+	"following.removed":          "Follows entfernt.",
+	"following.remove_error":     "Ein Fehler ist aufgetreten. Einige Follows wurden möglicherweise nicht entfernt.",
+	"following.remove":           "Zu entfernende Follows wählen",
+	"following.dms":              "DM-Erinnerungen",
+	"following.on":               "an",
+	"following.off":              "aus",
+	"following.turn_on":          "DM-Erinnerungen einschalten",
+	"following.turn_off":         "DM-Erinnerungen ausschalten",
+	"following.turned_on":        "DM-Erinnerungen eingeschaltet.",
+	"following.turned_off":       "DM-Erinnerungen ausgeschaltet.",
+	"following.dms_error":        "Ein Fehler ist aufgetreten. Die DM-Erinnerungen wurden nicht geändert.",
+	"following.all_streams":      "Alle %s-Streams",
+	"following.publisher":        "Publisher %d",
+	"following.stream":           "Stream %d",
+
+	// Servers
+	"server.intro":                 "🕹 Hallo! Danke, dass du mich zu deinem Server hinzugefügt hast! 🕹\n\nUm festzulegen, dass der Bot den Start von Streams ankündigt und welchen Plattformen du folgen möchtest, drücke den Button unten oder gib `/setup` auf dem Server ein, zu dem du mich hinzugefügt hast.\n\nHilfe zum Bot und seinen Befehlen erhältst du mit `/help`. Befehle können nur auf Servern genutzt werden.",
+	"server.reminder":              "👋 Ich wurde vor %[2]d Tagen zu **%[1]s** hinzugefügt, bin aber noch nicht für Stream-Ankündigungen eingerichtet. Drücke den Button unten oder gib `/setup` auf dem Server ein, um einen Kanal und die Plattformen zum Folgen zu wählen.",
+	"server.leaving":               "Ich verlasse deinen Server, weil: %s",
+	"server.blacklisted":           "Die Server-ID ist gesperrt.\n\n**Grund:** `%s`\n**Läuft ab:** `%s`",
+	"server.unusable":              "⚠️ Ich kann auf **%s** keine Streams ankündigen.\n\n- %s\n\nNutze `/settings` auf deinem Server, um einen Kanal zu wählen, in dem ich posten kann.",
+	"warning.channel_missing":      "Der Ankündigungskanal existiert nicht mehr oder ist kein Textkanal.",
+	"warning.channel_view":         "Ich habe keine Berechtigung, den Ankündigungskanal zu sehen.",
+	"warning.channel_send":         "Ich habe keine Berechtigung, Nachrichten im Ankündigungskanal zu senden.",
+	"warning.channel_embed":        "Ich habe keine Berechtigung, Links im Ankündigungskanal einzubetten, Ankündigungen fehlen daher die Details.",
+	"warning.crosspost":            "Crossposting ist aktiviert, aber der Ankündigungskanal ist kein Ankündigungskanal von Discord, daher werden Ankündigungen nicht veröffentlicht.",
+	"warning.role_missing":         "Die Ankündigungsrolle existiert nicht mehr.",
+	"warning.role_no_channel":      "Die Ankündigungsrolle ist nicht erwähnbar, lege einen Kanal fest, damit ich prüfen kann, ob ich sie erwähnen darf.",
+	"warning.role_check_error":     "Ich konnte nicht prüfen, ob ich die Ankündigungsrolle erwähnen darf.",
+	"warning.role_not_mentionable": "Die Ankündigungsrolle ist nicht erwähnbar und ich habe keine Berechtigung, alle Rollen zu erwähnen, daher wird niemand benachrichtigt.",
+
+	// Help
+	"help.general.description": "Game Streams ist ein Bot, der Ankündigungs-Streams für Spiele verfolgt " +
+		"und ankündigen kann, wenn sie beginnen. \n\nNutze den Befehl `/settings` auf deinem Server, " +
+		"um Stream-Ankündigungen einzurichten.",
+	"help.general.commands": "Befehle",
+	"help.general.commands_list": "`/streams` - Kommende Streams auflisten" +
+		"\n`/streaminfo` - Informationen zu einem bestimmten Stream" +
+		"\n`/suggest` - Einen Stream für die Datenbank vorschlagen" +
+		"\n`/follow` - Per DM erinnert werden, wenn ein Stream, eine Plattform oder ein Publisher live geht" +
+		"\n`/following` - Gefolgtes anzeigen und entfernen" +
+		"\n`/help` - Hilfe zum Bot und zu den Befehlen" +
+		"\n`/settings` [Admin] - Stream-Ankündigungen einrichten" +
+		"\n`/setup` [Admin] - Stream-Ankündigungen Schritt für Schritt einrichten" +
+		"\n`/webhooks` [Admin] - Stream-Ankündigungen an Webhooks senden",
+	"help.general.documents":     "Dokumente",
+	"help.general.privacy":       "Datenschutzerklärung",
+	"help.general.terms":         "Nutzungsbedingungen",
+	"help.general.changelog":     "Änderungsprotokoll",
+	"help.general.version":       "Version",
+	"help.general.release_date":  "Veröffentlicht am",
+	"help.general.public":        "Ankündigungskanal",
+	"help.general.public_follow": "Folge dem öffentlichen Ankündigungskanal, um Ankündigungen ohne Einrichtung des Bots zu erhalten: %s",
+	"help.streams.description": "Kommende Streams auflisten, sortiert nach Datum und Uhrzeit." +
+		"\n\nBereits begonnene Streams werden nicht angezeigt. " +
+		"Jeder Stream zeigt seinen Status, z. B. Gerücht, angekündigt oder bestätigt. " +
+		"Nur Streams mit bestätigter Uhrzeit werden angekündigt." +
+		"\n\nDie Liste ist auf %d Streams begrenzt.",
+	"help.streaminfo.description": "Informationen zu einem bestimmten Stream anhand des Titels." +
+		"\n\nDas Feld name ist erforderlich.",
+	"help.streaminfo.name": "Der Name des gesuchten Streams, Teiltreffer sind erlaubt.",
+	"help.suggest.description": "Einen Stream für die Datenbank vorschlagen. Vorschläge werden vom " +
+		"Bot-Betreiber geprüft und bei Gültigkeit hinzugefügt.\n\nAlle Felder sind erforderlich.",
+	"help.suggest.name": "Der Name des vorgeschlagenen Streams",
+	"help.suggest.date": "Das Datum des Streams im Format `JJJJ-MM-TT`",
+	"help.suggest.url":  "Die URL des Streams",
+	"help.settings.description": "Einstellungen für den Game Streams Bot. Diese müssen auf deinem Server " +
+		"festgelegt werden, damit der Bot Streams ankündigen kann.\n\nNur Server-Administratoren können " +
+		"diesen Befehl nutzen.\n\nAlle Felder sind optional.",
+	"help.settings.channel": "Der Kanal, in dem der Start eines Streams angekündigt wird. " +
+		"**Ohne Kanal kündigt der Bot keine Streams an.**",
+	"help.settings.role": "Die Rolle, die beim Start eines Streams erwähnt wird. " +
+		"**Ohne Rolle kündigt der Bot Streams weiterhin an, erwähnt aber niemanden.**",
+	"help.settings.events": "Ein Discord-Event für jeden kommenden Stream deiner Plattformen erstellen. " +
+		"Events werden aktuell gehalten und nach dem Ende des Streams entfernt. " +
+		"Erfordert die Berechtigung Events verwalten.",
+	"help.settings.lead_time": "Wie viele Minuten vor dem Start ein Stream angekündigt wird.",
+	"help.settings.crosspost": "Ankündigungen an die Server veröffentlichen, die dem Ankündigungskanal folgen. Der Kanal muss ein Ankündigungskanal sein.",
+	"help.settings.follow_publisher": "Jeden Stream eines Publishers oder Showcases (z. B. Nintendo, Ubisoft) " +
+		"ankündigen, unabhängig von der Plattform. Mit `unfollow_publisher` beenden.",
+	"help.settings.templates": "Den Wortlaut der Ankündigungen mit Vorlagen anpassen, z. B. " +
+		"`{{.Name}} ist {{if .Started}}live{{else}}ab {{.Starts}} live{{end}}!`. " +
+		"Verfügbare Werte sind `.Name`, `.URL`, `.Description`, `.Platforms`, " +
+		"`.Status`, `.Starts`, `.StartTime`, `.LocalDate`, `.Publishers`, `.Games`, `.Role` und " +
+		"`.Started`, mit den Funktionen `upper`, `lower`, `trim`, `join`, " +
+		"`truncate` und `default`. Mit `default` wird eine Vorlage wiederhergestellt.",
+	"help.settings.display":        "Das Vorschaubild und die Plattformen in Ankündigungen anzeigen oder ausblenden.",
+	"help.settings.preview":        "Eine Beispielankündigung mit deinen Vorlagen anzeigen. Mit `True` anzeigen.",
+	"help.settings.reset_template": "Die Ankündigungsvorlagen zurücksetzen. Mit `True` zurücksetzen.",
+	"help.settings.reset":          "Alle Einstellungen zurücksetzen. Mit `True` zurücksetzen.",
+	"help.settings.platforms": "Ankündigungen pro Plattform aktivieren oder deaktivieren. " +
+		"`True` aktiviert und `False` deaktiviert Ankündigungen.",
+	"help.setup.description": "Stream-Ankündigungen Schritt für Schritt einrichten. Wähle über die Menüs " +
+		"den Ankündigungskanal, die zu erwähnende Rolle, die Plattformen und wie früh Streams " +
+		"angekündigt werden.\n\nNur Server-Administratoren können diesen Befehl nutzen. Der " +
+		"Server-Eigentümer kann die Einrichtung auch über die Nachricht beim Beitritt des Bots starten.",
+	"help.follow.description": "Einem Stream, einer Plattform oder einem Publisher folgen, um %d Minuten " +
+		"vor Beginn per DM erinnert zu werden." +
+		"\n\nMit `/following` kannst du deine Follows anzeigen, entfernen oder DM-Erinnerungen abschalten.",
+	"help.follow.stream":    "Der Name oder die ID eines kommenden Streams, Teiltreffer sind erlaubt.",
+	"help.follow.platform":  "Jedem Stream einer Plattform folgen.",
+	"help.follow.publisher": "Jedem Stream eines Publishers folgen, Teiltreffer sind erlaubt.",
+	"help.webhooks.description": "Stream-Ankündigungen an Webhooks senden, ohne dass der Bot in einem Kanal posten darf. " +
+		"Discord-Webhooks erhalten das Ankündigungs-Embed, Slack- und Slack-kompatible Webhooks " +
+		"erhalten Text und JSON-Webhooks erhalten den Stream, mit HMAC-SHA256 im Header " +
+		"`X-GameStreams-Signature` signiert.\n\nJeder Server kann bis zu %d Webhooks registrieren. " +
+		"Webhooks, die wiederholt fehlschlagen, werden deaktiviert. Nutze den Befehl ohne Optionen, " +
+		"um deine Webhooks anzuzeigen.",
+	"help.webhooks.add":       "Die URL des Webhooks. Nur öffentliche `https`-URLs sind erlaubt.",
+	"help.webhooks.type":      "`Discord`, `Slack` oder `JSON`. Wird aus der URL ermittelt, wenn nicht gesetzt.",
+	"help.webhooks.platform":  "Nur Streams einer Plattform senden. Ohne Angabe werden alle Plattformen gesendet.",
+	"help.webhooks.lead_time": "Wie viele Minuten vor Streambeginn er gesendet wird.",
+	"help.webhooks.remove":    "Die ID eines zu entfernenden Webhooks.",
+	"help.webhooks.enable":    "Die ID eines deaktivierten Webhooks, der wieder aktiviert werden soll.",
+}
@@ -0,0 +1,281 @@
+package locales
+
+// english holds the English messages. Every key used by the bot must be in this
+// catalogue, as it is the fallback for every other locale.
+var english = map[string]string{
+	// Commands
+	"command.streams.description":                     "List upcoming streams for all platforms",
+	"command.streaminfo.description":                  "Get more information about a specific stream by name",
+	"command.streaminfo.name.description":             "The name of the stream",
+	"command.help.description":                        "Get help with the bot",
+	"command.help.command.description":                "The command to get help with. Leave blank for general help.",
+	"command.suggest.description":                     "Suggest a stream to add to the bots database",
+	"command.suggest.name.description":                "The name of the stream",
+	"command.suggest.date.description":                "The date of the stream (YYYY-MM-DD)",
+	"command.suggest.url.description":                 "The URL of the stream or with information about the stream",
+	"command.settings.description":                    "Change bot settings",
+	"command.settings.channel.description":            "Set the channel for announcing when a stream starts",
+	"command.settings.role.description":               "Set the role to ping when a stream starts",
+	"command.settings.playstation.description":        "Enable or disable Playstation stream announcements",
+	"command.settings.xbox.description":               "Enable or disable Xbox stream announcements",
+	"command.settings.nintendo.description":           "Enable or disable Nintendo stream announcements",
+	"command.settings.pc.description":                 "Enable or disable PC stream announcements",
+	"command.settings.vr.description":                 "Enable or disable VR stream announcements",
+	"command.settings.events.description":             "Enable or disable creating Discord events for upcoming streams",
+	"command.settings.crosspost.description":          "Publish announcements to servers following the channel, if it is an announcement channel",
+	"command.settings.lead_time.description":          "Set how many minutes before a stream starts to announce it",
+	"command.settings.follow_publisher.description":   "Announce all streams from a publisher, whatever the platform",
+	"command.settings.unfollow_publisher.description": "Stop announcing streams from a publisher",
+	"command.settings.message.description":            "Template for the announcement message text, or \"default\"",
+	"command.settings.embed_title.description":        "Template for the announcement embed title, or \"default\"",
+	"command.settings.embed_description.description":  "Template for the announcement embed description, or \"default\"",
+	"command.settings.embed_footer.description":       "Template for the announcement embed footer, or \"default\"",
+	"command.settings.thumbnail.description":          "Show or hide the stream thumbnail in announcements",
+	"command.settings.show_platforms.description":     "Show or hide the platforms field in announcements",
+	"command.settings.reset_template.description":     "Reset the announcement templates to default",
+	"command.settings.preview.description":            "Preview an announcement using the current templates",
+	"command.settings.reset.description":              "Reset all settings to default",
+	"command.setup.description":                       "Set up stream announcements step by step",
+	"command.follow.description":                      "Follow a stream, platform or publisher to be reminded by DM when streams start",
+	"command.follow.stream.description":               "The name or ID of the stream to follow",
+	"command.follow.platform.description":             "The platform to follow",
+	"command.follow.publisher.description":            "The publisher to follow (e.g. Nintendo, Ubisoft)",
+	"command.following.description":                   "List and remove the streams, platforms and publishers you follow",
+	"command.webhooks.description":                    "Send stream announcements to Discord, Slack or JSON webhooks",
+	"command.webhooks.add.description":                "The URL of a webhook to add",
+	"command.webhooks.type.description":               "The type of webhook to add, worked out from the URL if not set",
+	"command.webhooks.platform.description":           "Only send streams for this platform to the webhook being added",
+	"command.webhooks.lead_time.description":          "How many minutes before a stream starts to send it to the webhook being added",
+	"command.webhooks.remove.description":             "The ID of a webhook to remove",
+	"command.webhooks.enable.description":             "The ID of a disabled webhook to enable again",
+
+	// Common
+	"common.error":          "Error",
+	"common.error_occurred": "An error occurred",
+	"common.not_set":        "not set",
+	"common.minutes":        "%d minutes",
+	"common.default":        "Default",
+	"common.custom":         "Custom",
+	"blacklist.dm":          "You are blacklisted from using this bot.\n\n**Reason:** `%s`\n**Expires:** `%s`",
+
+	// Streams
+	"streams.title":         "Upcoming Streams",
+	"streams.none":          "No streams found",
+	"streaminfo.title":      "Stream Info",
+	"streaminfo.none":       "No streams found with that name",
+	"field.status":          "Status",
+	"field.platforms":       "Platforms",
+	"field.date":            "Date",
+	"field.time":            "Time",
+	"field.url":             "URL",
+	"field.description":     "Description",
+	"field.went_live":       "Went Live",
+	"field.publishers":      "Publishers",
+	"field.games":           "Games",
+	"status.rumoured":       "Rumoured",
+	"status.announced":      "Announced",
+	"status.confirmed":      "Confirmed",
+	"status.live":           "Live",
+	"status.ended":          "Ended",
+	"status.cancelled":      "Cancelled",
+	"status.postponed":      "Postponed",
+	"announcement.starting": "Stream starting %s",
+	"announcement.started":  "Stream started %s",
+	"followup.cancelled":    "**%s** has been cancelled.",
+	"followup.postponed":    "**%s** has been postponed. It will be announced again once a new time is confirmed.",
+
+	// Suggestions
+	"suggest.limit":        "You have reached the daily suggestion limit. Try again tomorrow.",
+	"suggest.thanks_title": "Thank you",
+	"suggest.thanks":       "Your suggestion has been received",
+	"suggest.error":        "**An error occurred.** Your suggestion may not have been received.",
+
+	// Settings
+	"settings.title":               "Settings",
+	"settings.current":             "Current settings:",
+	"settings.updated":             "Settings successfully updated.\n\n**Current settings:**",
+	"settings.reset_error":         "An error occurred. Settings may not have been reset.",
+	"settings.get_error":           "An error occurred. Settings may have not been updated.",
+	"settings.set_error":           "An error occurred. Settings have not been updated.",
+	"settings.channel_refused":     "The announce channel was not updated as I am unable to post there.\n\n**Current settings:**",
+	"settings.channel":             "Announce Channel",
+	"settings.role":                "Announce Role",
+	"settings.lead_time":           "Lead Time",
+	"settings.events":              "Scheduled Events",
+	"settings.crosspost":           "Crosspost",
+	"settings.publishers":          "Publishers",
+	"settings.template":            "Announcement Template",
+	"settings.warnings":            "Warnings",
+	"settings.preview":             "**Preview** (before and after the stream starts):",
+	"settings.preview_error":       "Unable to preview the announcement template: %s",
+	"settings.template_invalid":    "The announcement template was not updated: `%s`",
+	"settings.template_error":      "An error occurred. The announcement template has not been updated.",
+	"settings.publisher_not_found": "No publishers found matching **%s**.",
+	"settings.publisher_error":     "An error occurred. **%s** may not have been updated.",
+
+	// Webhooks
+	"webhooks.title":           "Webhooks",
+	"webhooks.none":            "No webhooks registered. Use `add` with a webhook URL to add one.",
+	"webhooks.registered":      "**Registered webhooks:**",
+	"webhooks.limit":           "Servers can register up to %d webhooks. Remove one before adding another.",
+	"webhooks.invalid":         "The webhook was not added: `%s`",
+	"webhooks.add_error":       "An error occurred. The webhook has not been added.",
+	"webhooks.added":           "Webhook `#%d` added.",
+	"webhooks.secret":          "Payloads are signed with this secret, which will not be shown again:\n`%s`",
+	"webhooks.removed":         "Webhook `#%d` removed.",
+	"webhooks.enabled":         "Webhook `#%d` enabled.",
+	"webhooks.not_found":       "No webhook found with ID `%d`.",
+	"webhooks.error":           "An error occurred. The webhook may not have been updated.",
+	"webhooks.all_platforms":   "all platforms",
+	"webhooks.enabled_status":  "✅ Enabled",
+	"webhooks.failing_status":  "⚠️ Enabled, %d failed deliveries: `%s`",
+	"webhooks.disabled_status": "❌ Disabled after %d failed deliveries: `%s`",
+
+	// Setup
+	"setup.title":                 "Setup: %s",
+	"setup.description":           "Use the menus below to choose where streams are announced, who is pinged, which platforms to follow and how early to announce streams. Changes are saved as soon as they are made.",
+	"setup.owner_only":            "Only the server owner can set up the bot from a DM. Server administrators can use `/setup` in the server.",
+	"setup.channel_refused":       "I am unable to post in that channel, please choose another.",
+	"setup.complete":              "Setup complete! Use `/setup` or `/settings` to make changes at any time.",
+	"setup.incomplete":            "Setup saved, but streams will not be announced until an announce channel and at least one platform are chosen.",
+	"setup.error":                 "An error occurred. Settings may not have been updated.",
+	"setup.channel_placeholder":   "Choose the announce channel",
+	"setup.role_placeholder":      "Choose the role to ping (optional)",
+	"setup.platforms_placeholder": "Choose the platforms to follow",
+	"setup.lead_placeholder":      "Choose how early to announce streams",
+	"setup.no_channels":           "No channels available",
+	"setup.no_roles":              "No roles available",
+	"setup.lead_option":           "%d minutes before",
+	"setup.done":                  "Done",
+	"setup.start":                 "Start setup",
+	"setup.now":                   "Set up now",
+
+	// Follows
+	"follow.title":               "Follow",
+	"follow.choose":              "Choose a `stream`, `platform` or `publisher` to follow.",
+	"follow.stream_not_found":    "No upcoming streams found with that name or ID.",
+	"follow.publisher_not_found": "No publishers found with that name.",
+	"follow.all_streams":         "all **%s** streams",
+	"follow.and":                 " and ",
+	"follow.limit":               "You can follow up to %d streams, platforms and publishers. Use `/following` to remove some.",
+	"follow.added":               "You are now following %s.",
+	"follow.reminder":            "I will DM you %d minutes before they start.\n\nUse `/following` to see and manage your follows.",
+	"follow.dms_off":             "⚠️ You have turned off DM reminders, turn them back on with `/following`.",
+	"follow.error":               "**An error occurred.** The follow may not have been added.",
+	"follow.dm":                  "🔔 A stream you follow is starting soon. Use `/following` to manage your follows or stop these messages.",
+	"following.title":            "Following",
+	"following.none":             "You are not following any streams, platforms or publishers. Use `/follow` to be reminded by DM when streams start.",
+	"following.removed":          "Follows removed.",
+	"following.remove_error":     "An error occurred. Some follows may not have been removed.",
+	"following.remove":           "Choose follows to remove",
+	"following.dms":              "DM Reminders",
+	"following.on":               "on",
+	"following.off":              "off",
+	"following.turn_on":          "Turn on DM reminders",
+	"following.turn_off":         "Turn off DM reminders",
+	"following.turned_on":        "DM reminders turned on.",
+	"following.turned_off":       "DM reminders turned off.",
+	"following.dms_error":        "An error occurred. DM reminders have not been changed.",
+	"following.all_streams":      "All %s streams",
+	"following.publisher":        "Publisher %d",
+	"following.stream":           "Stream %d",
+
+	// Servers
+	"server.intro":                 "🕹 Hello! Thank you for adding me to your server! 🕹\n\nTo set up the bot to announce when streams are starting, and which platforms you want to follow, press the button below or type `/setup` in the server you added me to.\n\nFor help with the bot and its commands, type `/help`. Commands can only be used in servers.",
+	"server.reminder":              "👋 I was added to **%s** %d days ago but have not been set up to announce streams yet. Press the button below or type `/setup` in the server to choose a channel and the platforms to follow.",
+	"server.leaving":               "I am leaving your server because: %s",
+	"server.blacklisted":           "Server ID is blacklisted.\n\n**Reason:** `%s`\n**Expires:** `%s`",
+	"server.unusable":              "⚠️ I am unable to announce streams in **%s**.\n\n- %s\n\nUse `/settings` in your server to choose a channel I can post in.",
+	"warning.channel_missing":      "The announce channel no longer exists or is not a text channel.",
+	"warning.channel_view":         "I do not have permission to view the announce channel.",
+	"warning.channel_send":         "I do not have permission to send messages in the announce channel.",
+	"warning.channel_embed":        "I do not have permission to embed links in the announce channel, announcements will be missing their details.",
+	"warning.crosspost":            "Crossposting is enabled but the announce channel is not an announcement channel, so announcements will not be published.",
+	"warning.role_missing":         "The announce role no longer exists.",
+	"warning.role_no_channel":      "The announce role is not mentionable, set a channel so I can check whether I am allowed to mention it.",
+	"warning.role_check_error":     "Could not check whether I am allowed to mention the announce role.",
+	"warning.role_not_mentionable": "The announce role is not mentionable and I do not have permission to mention all roles, so nobody will be pinged.",
+
+	// Help
+	"help.general.description": "Game Streams is a bot that keeps track of game announcement streams " +
+		"and can announce when streams are beginning. \n\nUse the `/settings` command in your server " +
+		"to configure stream announcements.",
+	"help.general.commands": "Commands",
+	"help.general.commands_list": "`/streams` - List upcoming streams" +
+		"\n`/streaminfo` - Get information on a specific stream by title" +
+		"\n`/suggest` - Suggest a stream to be added to the database" +
+		"\n`/follow` - Be reminded by DM when a stream, platform or publisher goes live" +
+		"\n`/following` - List and remove your follows" +
+		"\n`/help` - Get help with the bot and commands" +
+		"\n`/settings` [admin] - Configure stream announcements" +
+		"\n`/setup` [admin] - Set up stream announcements step by step" +
+		"\n`/webhooks` [admin] - Send stream announcements to webhooks",
+	"help.general.documents":     "Documents",
+	"help.general.privacy":       "Privacy Policy",
+	"help.general.terms":         "Terms of Service",
+	"help.general.changelog":     "Changelog",
+	"help.general.version":       "Version",
+	"help.general.release_date":  "Release Date",
+	"help.general.public":        "Announcement Channel",
+	"help.general.public_follow": "Follow the public announcement channel to get announcements in your server without setting up the bot: %s",
+	"help.streams.description": "List upcoming streams. Streams are sorted by date and time." +
+		"\n\nStreams that have already started will not be listed. " +
+		"Each stream shows its status, e.g. rumoured, announced or confirmed. " +
+		"Only streams with a confirmed time are announced." +
+		"\n\nList is limited to %d streams.",
+	"help.streaminfo.description": "Get information on a specific stream by title." +
+		"\n\nThe name field is required.",
+	"help.streaminfo.name": "The name of the stream to search for, partial matches are allowed.",
+	"help.suggest.description": "Suggest a stream to be added to the database. Suggestions will be reviewed by " +
+		"the bot owner and added to the database if they are valid.\n\nAll fields are required.",
+	"help.suggest.name": "The name of the stream to suggest",
+	"help.suggest.date": "The date of the stream in the format `YYYY-MM-DD`",
+	"help.suggest.url":  "The URL of the stream",
+	"help.settings.description": "Settings for the Game Streams bot. These need to be set in your server to " +
+		"enable the bot to announce streams.\n\nOnly server administrators can use this command." +
+		"\n\nAll fields are optional.",
+	"help.settings.channel": "The channel for announcing when a stream starts. " +
+		"**If not set, the bot will not announce streams.**",
+	"help.settings.role": "The role to ping when a stream starts. " +
+		"**If not set, the bot will still announce streams but will not ping anyone.**",
+	"help.settings.events": "Create a Discord event for each upcoming stream on your followed platforms. " +
+		"Events are kept up to date and removed once the stream has ended. " +
+		"Requires the Manage Events permission.",
+	"help.settings.lead_time": "How many minutes before a stream starts to announce it.",
+	"help.settings.crosspost": "Publish announcements to the servers following the announce channel. The announce channel must be an announcement channel.",
+	"help.settings.follow_publisher": "Announce every stream from a publisher or showcase (e.g. Nintendo, Ubisoft) " +
+		"whatever its platforms. Use `unfollow_publisher` to stop.",
+	"help.settings.templates": "Customise the wording of announcements using templates, e.g. " +
+		"`{{.Name}} is {{if .Started}}live{{else}}starting {{.Starts}}{{end}}!`. " +
+		"Available values are `.Name`, `.URL`, `.Description`, `.Platforms`, " +
+		"`.Status`, `.Starts`, `.StartTime`, `.LocalDate`, `.Publishers`, `.Games`, `.Role` and " +
+		"`.Started`, with the functions `upper`, `lower`, `trim`, `join`, " +
+		"`truncate` and `default`. Use `default` to restore a template.",
+	"help.settings.display":        "Show or hide the stream thumbnail and platforms in announcements.",
+	"help.settings.preview":        "Show an example announcement using your templates. Use `True` to preview.",
+	"help.settings.reset_template": "Reset the announcement templates to default. Use `True` to reset.",
+	"help.settings.reset":          "Reset all settings to default. Use `True` to reset.",
+	"help.settings.platforms": "Enable or disable announcements by platform. " +
+		"Use `True` to enable and `False` to disable annoucements.",
+	"help.setup.description": "Set up stream announcements step by step. Choose the announce channel, " +
+		"the role to ping, the platforms to follow and how early streams are announced " +
+		"using the menus.\n\nOnly server administrators can use this command. The server " +
+		"owner can also start the setup from the message sent when the bot joined the server.",
+	"help.follow.description": "Follow a stream, a platform or a publisher to be reminded by DM " +
+		"%d minutes before streams start." +
+		"\n\nUse `/following` to list your follows, remove them, or turn DM reminders off.",
+	"help.follow.stream":    "The name or ID of an upcoming stream, partial matches are allowed.",
+	"help.follow.platform":  "Follow every stream for a platform.",
+	"help.follow.publisher": "Follow every stream from a publisher, partial matches are allowed.",
+	"help.webhooks.description": "Send stream announcements to webhooks, without the bot needing permission to post in a " +
+		"channel. Discord webhooks receive the announcement embed, Slack and Slack-compatible " +
+		"webhooks receive text, and JSON webhooks receive the stream signed with HMAC-SHA256 in the " +
+		"`X-GameStreams-Signature` header.\n\nEach server can register up to %d webhooks. Webhooks " +
+		"that fail repeatedly are disabled. Use the command with no options to list your webhooks.",
+	"help.webhooks.add":       "The URL of the webhook. Only public `https` URLs can be used.",
+	"help.webhooks.type":      "`Discord`, `Slack` or `JSON`. Worked out from the URL if not set.",
+	"help.webhooks.platform":  "Only send streams for one platform. All platforms are sent if not set.",
+	"help.webhooks.lead_time": "How many minutes before a stream starts to send it.",
+	"help.webhooks.remove":    "The ID of a webhook to remove.",
+	"help.webhooks.enable":    "The ID of a disabled webhook to enable again.",
+}
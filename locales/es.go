@@ -0,0 +1,283 @@
+package locales
+
+// spanish holds the Spanish messages, used for both Spain and Latin America.
+var spanish = map[string]string{
+	// Commands
+	"command.streams.description":                     "Ver los próximos streams de todas las plataformas",
+	"command.streaminfo.description":                  "Obtener más información sobre un stream por su nombre",
+	"command.streaminfo.name.description":             "El nombre del stream",
+	"command.help.name":                               "ayuda",
+	"command.help.description":                        "Obtener ayuda con el bot",
+	"command.help.command.description":                "El comando sobre el que obtener ayuda. Déjalo vacío para la ayuda general.",
+	"command.suggest.description":                     "Sugerir un stream para añadirlo a la base de datos del bot",
+	"command.suggest.name.description":                "El nombre del stream",
+	"command.suggest.date.description":                "La fecha del stream (AAAA-MM-DD)",
+	"command.suggest.url.description":                 "La URL del stream o de una página con información sobre él",
+	"command.settings.name":                           "ajustes",
+	"command.settings.description":                    "Cambiar los ajustes del bot",
+	"command.settings.channel.description":            "Elegir el canal donde anunciar cuándo empieza un stream",
+	"command.settings.role.description":               "Elegir el rol a mencionar cuándo empieza un stream",
+	"command.settings.playstation.description":        "Activar o desactivar los anuncios de PlayStation",
+	"command.settings.xbox.description":               "Activar o desactivar los anuncios de Xbox",
+	"command.settings.nintendo.description":           "Activar o desactivar los anuncios de Nintendo",
+	"command.settings.pc.description":                 "Activar o desactivar los anuncios de PC",
+	"command.settings.vr.description":                 "Activar o desactivar los anuncios de VR",
+	"command.settings.events.description":             "Activar o desactivar la creación de eventos de Discord para los streams",
+	"command.settings.crosspost.description":          "Publicar los anuncios en los servidores que siguen el canal, si es un canal de anuncios",
+	"command.settings.lead_time.description":          "Cuántos minutos antes de que empiece un stream anunciarlo",
+	"command.settings.follow_publisher.description":   "Anunciar todos los streams de una editora, sea cual sea la plataforma",
+	"command.settings.unfollow_publisher.description": "Dejar de anunciar los streams de una editora",
+	"command.settings.message.description":            "Plantilla del texto del anuncio, o \"default\"",
+	"command.settings.embed_title.description":        "Plantilla del título del embed del anuncio, o \"default\"",
+	"command.settings.embed_description.description":  "Plantilla de la descripción del embed del anuncio, o \"default\"",
+	"command.settings.embed_footer.description":       "Plantilla del pie del embed del anuncio, o \"default\"",
+	"command.settings.thumbnail.description":          "Mostrar u ocultar la miniatura del stream en los anuncios",
+	"command.settings.show_platforms.description":     "Mostrar u ocultar el campo de plataformas en los anuncios",
+	"command.settings.reset_template.description":     "Restablecer las plantillas de anuncio",
+	"command.settings.preview.description":            "Ver una vista previa del anuncio con las plantillas actuales",
+	"command.settings.reset.description":              "Restablecer todos los ajustes",
+	"command.setup.description":                       "Configurar los anuncios de streams paso a paso",
+	"command.follow.description":                      "Seguir un stream, plataforma o editora para recibir un recordatorio por MD",
+	"command.follow.stream.description":               "El nombre o ID del stream a seguir",
+	"command.follow.platform.description":             "La plataforma a seguir",
+	"command.follow.publisher.description":            "La editora a seguir (p. ej. Nintendo, Ubisoft)",
+	"command.following.description":                   "Ver y quitar los streams, plataformas y editoras que sigues",
+	"command.webhooks.description":                    "Enviar los anuncios de streams a webhooks de Discord, Slack o JSON",
+	"command.webhooks.add.description":                "La URL de un webhook para añadir",
+	"command.webhooks.type.description":               "El tipo de webhook para añadir, se deduce de la URL si no se indica",
+	"command.webhooks.platform.description":           "Enviar al nuevo webhook solo los streams de esta plataforma",
+	"command.webhooks.lead_time.description":          "Cuántos minutos antes de que empiece un stream enviarlo al nuevo webhook",
+	"command.webhooks.remove.description":             "El ID de un webhook para quitar",
+	"command.webhooks.enable.description":             "El ID de un webhook desactivado para volver a activarlo",
+
+	// Common
+	"common.error":          "Error",
+	"common.error_occurred": "Se ha producido un error",
+	"common.not_set":        "sin configurar",
+	"common.minutes":        "%d minutos",
+	"common.default":        "Predeterminada",
+	"common.custom":         "Personalizada",
+	"blacklist.dm":          "Se te ha bloqueado el uso de este bot.\n\n**Motivo:** `%s`\n**Caduca:** `%s`",
+
+	// Streams
+	"streams.title":         "Próximos streams",
+	"streams.none":          "No se han encontrado streams",
+	"streaminfo.title":      "Información del stream",
+	"streaminfo.none":       "No se han encontrado streams con ese nombre",
+	"field.status":          "Estado",
+	"field.platforms":       "Plataformas",
+	"field.date":            "Fecha",
+	"field.time":            "Hora",
+	"field.url":             "URL",
+	"field.description":     "Descripción",
+	"field.went_live":       "En directo desde",
+	"field.publishers":      "Editoras",
+	"field.games":           "Juegos",
+	"status.rumoured":       "Rumor",
+	"status.announced":      "Anunciado",
+	"status.confirmed":      "Confirmado",
+	"status.live":           "En directo",
+	"status.ended":          "Terminado",
+	"status.cancelled":      "Cancelado",
+	"status.postponed":      "Aplazado",
+	"announcement.starting": "El stream empieza %s",
+	"announcement.started":  "El stream empezó %s",
+	"followup.cancelled":    "**%s** se ha cancelado.",
+	"followup.postponed":    "**%s** se ha aplazado. Se volverá a anunciar cuando se confirme una nueva hora.",
+
+	// Suggestions
+	"suggest.limit":        "Has alcanzado el límite diario de sugerencias. Inténtalo de nuevo mañana.",
+	"suggest.thanks_title": "Gracias",
+	"suggest.thanks":       "Hemos recibido tu sugerencia",
+	"suggest.error":        "**Se ha producido un error.** Es posible que tu sugerencia no se haya recibido.",
+
+	// Settings
+	"settings.title":               "Ajustes",
+	"settings.current":             "Ajustes actuales:",
+	"settings.updated":             "Ajustes actualizados.\n\n**Ajustes actuales:**",
+	"settings.reset_error":         "Se ha producido un error. Es posible que los ajustes no se hayan restablecido.",
+	"settings.get_error":           "Se ha producido un error. Es posible que los ajustes no se hayan actualizado.",
+	"settings.set_error":           "Se ha producido un error. Los ajustes no se han actualizado.",
+	"settings.channel_refused":     "El canal de anuncios no se ha cambiado porque no puedo publicar en él.\n\n**Ajustes actuales:**",
+	"settings.channel":             "Canal de anuncios",
+	"settings.role":                "Rol de anuncios",
+	"settings.lead_time":           "Antelación",
+	"settings.events":              "Eventos programados",
+	"settings.crosspost":           "Publicación cruzada",
+	"settings.publishers":          "Editoras",
+	"settings.template":            "Plantilla de anuncio",
+	"settings.warnings":            "Advertencias",
+	"settings.preview":             "**Vista previa** (antes y después de que empiece el stream):",
+	"settings.preview_error":       "No se puede previsualizar la plantilla de anuncio: %s",
+	"settings.template_invalid":    "La plantilla de anuncio no se ha actualizado: `%s`",
+	"settings.template_error":      "Se ha producido un error. La plantilla de anuncio no se ha actualizado.",
+	"settings.publisher_not_found": "No se han encontrado editoras que coincidan con **%s**.",
+	"settings.publisher_error":     "Se ha producido un error. Es posible que **%s** no se haya actualizado.",
+
+	// Webhooks
+	"webhooks.title":           "Webhooks",
+	"webhooks.none":            "No hay webhooks registrados. Usa `add` con la URL de un webhook para añadir uno.",
+	"webhooks.registered":      "**Webhooks registrados:**",
+	"webhooks.limit":           "Los servidores pueden registrar hasta %d webhooks. Quita uno antes de añadir otro.",
+	"webhooks.invalid":         "El webhook no se ha añadido: `%s`",
+	"webhooks.add_error":       "Se ha producido un error. El webhook no se ha añadido.",
+	"webhooks.added":           "Webhook `#%d` añadido.",
+	"webhooks.secret":          "Los envíos se firman con este secreto, que no se volverá a mostrar:\n`%s`",
+	"webhooks.removed":         "Webhook `#%d` quitado.",
+	"webhooks.enabled":         "Webhook `#%d` activado.",
+	"webhooks.not_found":       "No se ha encontrado ningún webhook con el ID `%d`.",
+	"webhooks.error":           "Se ha producido un error. Es posible que el webhook no se haya actualizado.",
+	"webhooks.all_platforms":   "todas las plataformas",
+	"webhooks.enabled_status":  "✅ Activado",
+	"webhooks.failing_status":  "⚠️ Activado, %d envíos fallidos: `%s`",
+	"webhooks.disabled_status": "❌ Desactivado tras %d envíos fallidos: `%s`",
+
+	// Setup
+	"setup.title":                 "Configuración: %s",
+	"setup.description":           "Usa los menús de abajo para elegir dónde se anuncian los streams, a quién se menciona, qué plataformas seguir y con cuánta antelación anunciar los streams. Los cambios se guardan en cuanto se hacen.",
+	"setup.owner_only":            "Solo el propietario del servidor puede configurar el bot desde un MD. Los administradores del servidor pueden usar `/setup` en el servidor.",
+	"setup.channel_refused":       "No puedo publicar en ese canal, elige otro.",
+	"setup.complete":              "¡Configuración completada! Usa `/setup` o `/settings` para hacer cambios en cualquier momento.",
+	"setup.incomplete":            "Configuración guardada, pero no se anunciarán streams hasta que se elijan un canal de anuncios y al menos una plataforma.",
+	"setup.error":                 "Se ha producido un error. Es posible que los ajustes no se hayan actualizado.",
+	"setup.channel_placeholder":   "Elige el canal de anuncios",
+	"setup.role_placeholder":      "Elige el rol a mencionar (opcional)",
+	"setup.platforms_placeholder": "Elige las plataformas a seguir",
+	"setup.lead_placeholder":      "Elige con cuánta antelación anunciar los streams",
+	"setup.no_channels":           "No hay canales disponibles",
+	"setup.no_roles":              "No hay roles disponibles",
+	"setup.lead_option":           "%d minutos antes",
+	"setup.done":                  "Listo",
+	"setup.start":                 "Empezar la configuración",
+	"setup.now":                   "Configurar ahora",
+
+	// Follows
+	"follow.title":               "Seguir",
+	"follow.choose":              "Elige un `stream`, una `platform` o un `publisher` para seguir.",
+	"follow.stream_not_found":    "No se han encontrado próximos streams con ese nombre o ID.",
+	"follow.publisher_not_found": "No se han encontrado editoras con ese nombre.",
+	"follow.all_streams":         "todos los streams de **%s**",
+	"follow.and":                 " y ",
+	"follow.limit":               "Puedes seguir hasta %d streams, plataformas y editoras. Usa `/following` para quitar algunos.",
+	"follow.added":               "Ahora sigues %s.",
+	"follow.reminder":            "Te enviaré un MD %d minutos antes de que empiecen.\n\nUsa `/following` para ver y gestionar lo que sigues.",
+	"follow.dms_off":             "⚠️ Has desactivado los recordatorios por MD, vuelve a activarlos con `/following`.",
+	"follow.error":               "**Se ha producido un error.** Es posible que no se haya añadido el seguimiento.",
+	"follow.dm":                  "🔔 Un stream que sigues empieza pronto. Usa `/following` para gestionar lo que sigues o dejar de recibir estos mensajes.",
+	"following.title":            "Siguiendo",
+	"following.none":             "No sigues ningún stream, plataforma ni editora. Usa `/follow` para recibir un recordatorio por MD cuando empiecen los streams.",
+	"following.removed":          "Seguimientos eliminados.",
+	"following.remove_error":     "Se ha producido un error. Es posible que algunos seguimientos no se hayan eliminado.",
+	"following.remove":           "Elige los seguimientos a eliminar",
+	"following.dms":              "Recordatorios por MD",
+	"following.on":               "activados",
+	"following.off":              "desactivados",
+	"following.turn_on":          "Activar los recordatorios por MD",
+	"following.turn_off":         "Desactivar los recordatorios por MD",
+	"following.turned_on":        "Recordatorios por MD activados.",
+	"following.turned_off":       "Recordatorios por MD desactivados.",
+	"following.dms_error":        "Se ha producido un error. Los recordatorios por MD no se han cambiado.",
+	"following.all_streams":      "Todos los streams de %s",
+	"following.publisher":        "Editora %d",
+	"following.stream":           "Stream %d",
+
+	// Servers
+	"server.intro":                 "🕹 ¡Hola! ¡Gracias por añadirme a tu servidor! 🕹\n\nPara configurar el bot para que anuncie cuándo empiezan los streams y qué plataformas quieres seguir, pulsa el botón de abajo o escribe `/setup` en el servidor al que me has añadido.\n\nPara obtener ayuda con el bot y sus comandos, escribe `/help`. Los comandos solo se pueden usar en servidores.",
+	"server.reminder":              "👋 Me añadieron a **%s** hace %d días, pero todavía no se me ha configurado para anunciar streams. Pulsa el botón de abajo o escribe `/setup` en el servidor para elegir un canal y las plataformas a seguir.",
+	"server.leaving":               "Voy a salir de tu servidor por el siguiente motivo: %s",
+	"server.blacklisted":           "El ID del servidor está bloqueado.\n\n**Motivo:** `%s`\n**Caduca:** `%s`",
+	"server.unusable":              "⚠️ No puedo anunciar streams en **%s**.\n\n- %s\n\nUsa `/settings` en tu servidor para elegir un canal en el que pueda publicar.",
+	"warning.channel_missing":      "El canal de anuncios ya no existe o no es un canal de texto.",
+	"warning.channel_view":         "No tengo permiso para ver el canal de anuncios.",
+	"warning.channel_send":         "No tengo permiso para enviar mensajes en el canal de anuncios.",
+	"warning.channel_embed":        "No tengo permiso para insertar enlaces en el canal de anuncios, por lo que a los anuncios les faltarán los detalles.",
+	"warning.crosspost":            "La publicación cruzada está activada, pero el canal de anuncios no es un canal de anuncios de Discord, por lo que los anuncios no se publicarán.",
+	"warning.role_missing":         "El rol de anuncios ya no existe.",
+	"warning.role_no_channel":      "El rol de anuncios no se puede mencionar, configura un canal para que pueda comprobar si puedo mencionarlo.",
+	"warning.role_check_error":     "No he podido comprobar si puedo mencionar el rol de anuncios.",
+	"warning.role_not_mentionable": "El rol de anuncios no se puede mencionar y no tengo permiso para mencionar todos los roles, así que no se avisará a nadie.",
+
+	// Help
+	"help.general.description": "Game Streams es un bot que sigue los streams de anuncios de videojuegos " +
+		"y puede avisar cuando empiezan. \n\nUsa el comando `/settings` en tu servidor " +
+		"para configurar los anuncios.",
+	"help.general.commands": "Comandos",
+	"help.general.commands_list": "`/streams` - Ver los próximos streams" +
+		"\n`/streaminfo` - Obtener información sobre un stream" +
+		"\n`/suggest` - Sugerir un stream para la base de datos" +
+		"\n`/follow` - Recibir un MD cuando un stream, plataforma o editora empiece" +
+		"\n`/following` - Ver y quitar lo que sigues" +
+		"\n`/help` - Obtener ayuda con el bot y los comandos" +
+		"\n`/settings` [admin] - Configurar los anuncios de streams" +
+		"\n`/setup` [admin] - Configurar los anuncios paso a paso" +
+		"\n`/webhooks` [admin] - Enviar los anuncios a webhooks",
+	"help.general.documents":     "Documentos",
+	"help.general.privacy":       "Política de privacidad",
+	"help.general.terms":         "Términos del servicio",
+	"help.general.changelog":     "Registro de cambios",
+	"help.general.version":       "Versión",
+	"help.general.release_date":  "Fecha de publicación",
+	"help.general.public":        "Canal de anuncios",
+	"help.general.public_follow": "Sigue el canal de anuncios público para recibir los anuncios sin configurar el bot: %s",
+	"help.streams.description": "Ver los próximos streams, ordenados por fecha y hora." +
+		"\n\nLos streams que ya han empezado no aparecen. " +
+		"Cada stream muestra su estado, p. ej. rumor, anunciado o confirmado. " +
+		"Solo se anuncian los streams con hora confirmada." +
+		"\n\nLa lista está limitada a %d streams.",
+	"help.streaminfo.description": "Obtener información sobre un stream por su título." +
+		"\n\nEl campo name es obligatorio.",
+	"help.streaminfo.name": "El nombre del stream a buscar, se admiten coincidencias parciales.",
+	"help.suggest.description": "Sugerir un stream para añadirlo a la base de datos. El propietario del bot " +
+		"revisará las sugerencias y añadirá las válidas.\n\nTodos los campos son obligatorios.",
+	"help.suggest.name": "El nombre del stream a sugerir",
+	"help.suggest.date": "La fecha del stream en formato `AAAA-MM-DD`",
+	"help.suggest.url":  "La URL del stream",
+	"help.settings.description": "Ajustes del bot Game Streams. Deben configurarse en tu servidor para " +
+		"que el bot anuncie streams.\n\nSolo los administradores del servidor pueden usar este comando." +
+		"\n\nTodos los campos son opcionales.",
+	"help.settings.channel": "El canal donde anunciar cuándo empieza un stream. " +
+		"**Sin canal, el bot no anunciará streams.**",
+	"help.settings.role": "El rol a mencionar cuándo empieza un stream. " +
+		"**Sin rol, el bot seguirá anunciando streams pero no mencionará a nadie.**",
+	"help.settings.events": "Crear un evento de Discord para cada próximo stream de tus plataformas. " +
+		"Los eventos se mantienen actualizados y se eliminan cuando termina el stream. " +
+		"Requiere el permiso Gestionar eventos.",
+	"help.settings.lead_time": "Cuántos minutos antes de que empiece un stream anunciarlo.",
+	"help.settings.crosspost": "Publicar los anuncios en los servidores que siguen el canal de anuncios. El canal debe ser un canal de anuncios.",
+	"help.settings.follow_publisher": "Anunciar todos los streams de una editora o showcase (p. ej. Nintendo, " +
+		"Ubisoft) sea cual sea la plataforma. Usa `unfollow_publisher` para dejar de hacerlo.",
+	"help.settings.templates": "Personalizar el texto de los anuncios con plantillas, p. ej. " +
+		"`¡{{.Name}} {{if .Started}}está en directo{{else}}empieza {{.Starts}}{{end}}!`. " +
+		"Los valores disponibles son `.Name`, `.URL`, `.Description`, `.Platforms`, " +
+		"`.Status`, `.Starts`, `.StartTime`, `.LocalDate`, `.Publishers`, `.Games`, `.Role` y " +
+		"`.Started`, con las funciones `upper`, `lower`, `trim`, `join`, " +
+		"`truncate` y `default`. Usa `default` para restablecer una plantilla.",
+	"help.settings.display":        "Mostrar u ocultar la miniatura y las plataformas en los anuncios.",
+	"help.settings.preview":        "Ver un anuncio de ejemplo con tus plantillas. Usa `True` para verlo.",
+	"help.settings.reset_template": "Restablecer las plantillas de anuncio. Usa `True` para restablecerlas.",
+	"help.settings.reset":          "Restablecer todos los ajustes. Usa `True` para restablecerlos.",
+	"help.settings.platforms": "Activar o desactivar los anuncios por plataforma. " +
+		"Usa `True` para activarlos y `False` para desactivarlos.",
+	"help.setup.description": "Configurar los anuncios de streams paso a paso. Elige con los menús el canal " +
+		"de anuncios, el rol a mencionar, las plataformas a seguir y con cuánta antelación se " +
+		"anuncian los streams.\n\nSolo los administradores del servidor pueden usar este comando. " +
+		"El propietario del servidor también puede empezar desde el mensaje enviado al unirse el bot.",
+	"help.follow.description": "Seguir un stream, una plataforma o una editora para recibir un MD " +
+		"%d minutos antes de que empiecen los streams." +
+		"\n\nUsa `/following` para ver lo que sigues, quitarlo o desactivar los recordatorios por MD.",
+	"help.follow.stream":    "El nombre o ID de un próximo stream, se admiten coincidencias parciales.",
+	"help.follow.platform":  "Seguir todos los streams de una plataforma.",
+	"help.follow.publisher": "Seguir todos los streams de una editora, se admiten coincidencias parciales.",
+	"help.webhooks.description": "Enviar los anuncios de streams a webhooks, sin que el bot necesite permiso para publicar " +
+		"en un canal. Los webhooks de Discord reciben el embed del anuncio, los de Slack y " +
+		"compatibles con Slack reciben texto, y los webhooks JSON reciben el stream firmado con " +
+		"HMAC-SHA256 en la cabecera `X-GameStreams-Signature`.\n\nCada servidor puede registrar hasta " +
+		"%d webhooks. Los webhooks que fallan repetidamente se desactivan. Usa el comando sin " +
+		"opciones para ver tus webhooks.",
+	"help.webhooks.add":       "La URL del webhook. Solo se admiten URL `https` públicas.",
+	"help.webhooks.type":      "`Discord`, `Slack` o `JSON`. Se deduce de la URL si no se indica.",
+	"help.webhooks.platform":  "Enviar solo los streams de una plataforma. Si no se indica, se envían todas.",
+	"help.webhooks.lead_time": "Cuántos minutos antes de que empiece un stream enviarlo.",
+	"help.webhooks.remove":    "El ID de un webhook para quitar.",
+	"help.webhooks.enable":    "El ID de un webhook desactivado para volver a activarlo.",
+}
@@ -0,0 +1,284 @@
+package locales
+
+// french holds the French messages.
+var french = map[string]string{
+	// Commands
+	"command.streams.description":                     "Lister les prochains streams pour toutes les plateformes",
+	"command.streaminfo.description":                  "Obtenir plus d'informations sur un stream à partir de son nom",
+	"command.streaminfo.name.description":             "Le nom du stream",
+	"command.help.name":                               "aide",
+	"command.help.description":                        "Obtenir de l'aide sur le bot",
+	"command.help.command.description":                "La commande pour laquelle obtenir de l'aide. Laisser vide pour l'aide générale.",
+	"command.suggest.description":                     "Suggérer un stream à ajouter à la base de données du bot",
+	"command.suggest.name.description":                "Le nom du stream",
+	"command.suggest.date.description":                "La date du stream (AAAA-MM-JJ)",
+	"command.suggest.url.description":                 "L'URL du stream ou d'une page qui le présente",
+	"command.settings.name":                           "parametres",
+	"command.settings.description":                    "Modifier les paramètres du bot",
+	"command.settings.channel.description":            "Définir le salon où annoncer le début d'un stream",
+	"command.settings.role.description":               "Définir le rôle à mentionner au début d'un stream",
+	"command.settings.playstation.description":        "Activer ou désactiver les annonces PlayStation",
+	"command.settings.xbox.description":               "Activer ou désactiver les annonces Xbox",
+	"command.settings.nintendo.description":           "Activer ou désactiver les annonces Nintendo",
+	"command.settings.pc.description":                 "Activer ou désactiver les annonces PC",
+	"command.settings.vr.description":                 "Activer ou désactiver les annonces VR",
+	"command.settings.events.description":             "Activer ou désactiver la création d'événements Discord pour les streams",
+	"command.settings.crosspost.description":          "Publier les annonces aux serveurs qui suivent le salon, s'il s'agit d'un salon d'annonces",
+	"command.settings.lead_time.description":          "Combien de minutes avant le début d'un stream l'annoncer",
+	"command.settings.follow_publisher.description":   "Annoncer tous les streams d'un éditeur, quelle que soit la plateforme",
+	"command.settings.unfollow_publisher.description": "Ne plus annoncer les streams d'un éditeur",
+	"command.settings.message.description":            "Modèle du texte de l'annonce, ou \"default\"",
+	"command.settings.embed_title.description":        "Modèle du titre de l'embed de l'annonce, ou \"default\"",
+	"command.settings.embed_description.description":  "Modèle de la description de l'embed de l'annonce, ou \"default\"",
+	"command.settings.embed_footer.description":       "Modèle du pied de l'embed de l'annonce, ou \"default\"",
+	"command.settings.thumbnail.description":          "Afficher ou masquer la miniature du stream dans les annonces",
+	"command.settings.show_platforms.description":     "Afficher ou masquer le champ des plateformes dans les annonces",
+	"command.settings.reset_template.description":     "Réinitialiser les modèles d'annonce",
+	"command.settings.preview.description":            "Prévisualiser une annonce avec les modèles actuels",
+	"command.settings.reset.description":              "Réinitialiser tous les paramètres",
+	"command.setup.description":                       "Configurer les annonces de streams étape par étape",
+	"command.follow.description":                      "Suivre un stream, une plateforme ou un éditeur pour être prévenu par MP",
+	"command.follow.stream.description":               "Le nom ou l'ID du stream à suivre",
+	"command.follow.platform.description":             "La plateforme à suivre",
+	"command.follow.publisher.description":            "L'éditeur à suivre (par ex. Nintendo, Ubisoft)",
+	"command.following.description":                   "Lister et retirer les streams, plateformes et éditeurs que vous suivez",
+	"command.webhooks.description":                    "Envoyer les annonces de streams vers des webhooks Discord, Slack ou JSON",
+	"command.webhooks.add.description":                "L'URL d'un webhook à ajouter",
+	"command.webhooks.type.description":               "Le type de webhook à ajouter, déduit de l'URL si non défini",
+	"command.webhooks.platform.description":           "N'envoyer que les streams de cette plateforme au nouveau webhook",
+	"command.webhooks.lead_time.description":          "Combien de minutes avant le début d'un stream l'envoyer au nouveau webhook",
+	"command.webhooks.remove.description":             "L'ID d'un webhook à retirer",
+	"command.webhooks.enable.description":             "L'ID d'un webhook désactivé à réactiver",
+
+	// Common
+	"common.error":          "Erreur",
+	"common.error_occurred": "Une erreur s'est produite",
+	"common.not_set":        "non défini",
+	"common.minutes":        "%d minutes",
+	"common.default":        "Par défaut",
+	"common.custom":         "Personnalisé",
+	"blacklist.dm":          "Vous n'êtes pas autorisé à utiliser ce bot.\n\n**Raison :** `%s`\n**Expire le :** `%s`",
+
+	// Streams
+	"streams.title":         "Prochains streams",
+	"streams.none":          "Aucun stream trouvé",
+	"streaminfo.title":      "Infos du stream",
+	"streaminfo.none":       "Aucun stream trouvé avec ce nom",
+	"field.status":          "Statut",
+	"field.platforms":       "Plateformes",
+	"field.date":            "Date",
+	"field.time":            "Heure",
+	"field.url":             "URL",
+	"field.description":     "Description",
+	"field.went_live":       "En direct depuis",
+	"field.publishers":      "Éditeurs",
+	"field.games":           "Jeux",
+	"status.rumoured":       "Rumeur",
+	"status.announced":      "Annoncé",
+	"status.confirmed":      "Confirmé",
+	"status.live":           "En direct",
+	"status.ended":          "Terminé",
+	"status.cancelled":      "Annulé",
+	"status.postponed":      "Reporté",
+	"announcement.starting": "Le stream commence %s",
+	"announcement.started":  "Le stream a commencé %s",
+	"followup.cancelled":    "**%s** a été annulé.",
+	"followup.postponed":    "**%s** a été reporté. Il sera annoncé à nouveau dès qu'un nouvel horaire sera confirmé.",
+
+	// Suggestions
+	"suggest.limit":        "Vous avez atteint la limite quotidienne de suggestions. Réessayez demain.",
+	"suggest.thanks_title": "Merci",
+	"suggest.thanks":       "Votre suggestion a bien été reçue",
+	"suggest.error":        "**Une erreur s'est produite.** Votre suggestion n'a peut-être pas été reçue.",
+
+	// Settings
+	"settings.title":               "Paramètres",
+	"settings.current":             "Paramètres actuels :",
+	"settings.updated":             "Paramètres mis à jour.\n\n**Paramètres actuels :**",
+	"settings.reset_error":         "Une erreur s'est produite. Les paramètres n'ont peut-être pas été réinitialisés.",
+	"settings.get_error":           "Une erreur s'est produite. Les paramètres n'ont peut-être pas été mis à jour.",
+	"settings.set_error":           "Une erreur s'est produite. Les paramètres n'ont pas été mis à jour.",
+	"settings.channel_refused":     "Le salon d'annonce n'a pas été modifié car je ne peux pas y publier.\n\n**Paramètres actuels :**",
+	"settings.channel":             "Salon d'annonce",
+	"settings.role":                "Rôle d'annonce",
+	"settings.lead_time":           "Délai d'annonce",
+	"settings.events":              "Événements programmés",
+	"settings.crosspost":           "Publication croisée",
+	"settings.publishers":          "Éditeurs",
+	"settings.template":            "Modèle d'annonce",
+	"settings.warnings":            "Avertissements",
+	"settings.preview":             "**Aperçu** (avant et après le début du stream) :",
+	"settings.preview_error":       "Impossible de prévisualiser le modèle d'annonce : %s",
+	"settings.template_invalid":    "Le modèle d'annonce n'a pas été mis à jour : `%s`",
+	"settings.template_error":      "Une erreur s'est produite. Le modèle d'annonce n'a pas été mis à jour.",
+	"settings.publisher_not_found": "Aucun éditeur ne correspond à **%s**.",
+	"settings.publisher_error":     "Une erreur s'est produite. **%s** n'a peut-être pas été mis à jour.",
+
+	// Webhooks
+	"webhooks.title":           "Webhooks",
+	"webhooks.none":            "Aucun webhook enregistré. Utilisez `add` avec l'URL d'un webhook pour en ajouter un.",
+	"webhooks.registered":      "**Webhooks enregistrés :**",
+	"webhooks.limit":           "Les serveurs peuvent enregistrer jusqu'à %d webhooks. Retirez-en un avant d'en ajouter un autre.",
+	"webhooks.invalid":         "Le webhook n'a pas été ajouté : `%s`",
+	"webhooks.add_error":       "Une erreur s'est produite. Le webhook n'a pas été ajouté.",
+	"webhooks.added":           "Webhook `#%d` ajouté.",
+	"webhooks.secret":          "Les contenus sont signés avec ce secret, qui ne sera plus affiché :\n`%s`",
+	"webhooks.removed":         "Webhook `#%d` retiré.",
+	"webhooks.enabled":         "Webhook `#%d` réactivé.",
+	"webhooks.not_found":       "Aucun webhook trouvé avec l'ID `%d`.",
+	"webhooks.error":           "Une erreur s'est produite. Le webhook n'a peut-être pas été mis à jour.",
+	"webhooks.all_platforms":   "toutes les plateformes",
+	"webhooks.enabled_status":  "✅ Activé",
+	"webhooks.failing_status":  "⚠️ Activé, %d envois échoués : `%s`",
+	"webhooks.disabled_status": "❌ Désactivé après %d envois échoués : `%s`",
+
+	// Setup
+	"setup.title":                 "Configuration : %s",
+	"setup.description":           "Utilisez les menus ci-dessous pour choisir où les streams sont annoncés, qui est mentionné, quelles plateformes suivre et combien de temps à l'avance annoncer les streams. Les modifications sont enregistrées immédiatement.",
+	"setup.owner_only":            "Seul le propriétaire du serveur peut configurer le bot depuis un MP. Les administrateurs du serveur peuvent utiliser `/setup` sur le serveur.",
+	"setup.channel_refused":       "Je ne peux pas publier dans ce salon, veuillez en choisir un autre.",
+	"setup.complete":              "Configuration terminée ! Utilisez `/setup` ou `/settings` pour faire des modifications à tout moment.",
+	"setup.incomplete":            "Configuration enregistrée, mais les streams ne seront pas annoncés tant qu'un salon d'annonce et au moins une plateforme n'auront pas été choisis.",
+	"setup.error":                 "Une erreur s'est produite. Les paramètres n'ont peut-être pas été mis à jour.",
+	"setup.channel_placeholder":   "Choisir le salon d'annonce",
+	"setup.role_placeholder":      "Choisir le rôle à mentionner (facultatif)",
+	"setup.platforms_placeholder": "Choisir les plateformes à suivre",
+	"setup.lead_placeholder":      "Choisir combien de temps à l'avance annoncer les streams",
+	"setup.no_channels":           "Aucun salon disponible",
+	"setup.no_roles":              "Aucun rôle disponible",
+	"setup.lead_option":           "%d minutes avant",
+	"setup.done":                  "Terminé",
+	"setup.start":                 "Commencer la configuration",
+	"setup.now":                   "Configurer maintenant",
+
+	// Follows
+	"follow.title":               "Suivre",
+	"follow.choose":              "Choisissez un `stream`, une `platform` ou un `publisher` à suivre.",
+	"follow.stream_not_found":    "Aucun stream à venir trouvé avec ce nom ou cet ID.",
+	"follow.publisher_not_found": "Aucun éditeur trouvé avec ce nom.",
+	"follow.all_streams":         "tous les streams **%s**",
+	"follow.and":                 " et ",
+	"follow.limit":               "Vous pouvez suivre jusqu'à %d streams, plateformes et éditeurs. Utilisez `/following` pour en retirer.",
+	"follow.added":               "Vous suivez maintenant %s.",
+	"follow.reminder":            "Je vous enverrai un MP %d minutes avant leur début.\n\nUtilisez `/following` pour voir et gérer vos suivis.",
+	"follow.dms_off":             "⚠️ Vous avez désactivé les rappels par MP, réactivez-les avec `/following`.",
+	"follow.error":               "**Une erreur s'est produite.** Le suivi n'a peut-être pas été ajouté.",
+	"follow.dm":                  "🔔 Un stream que vous suivez commence bientôt. Utilisez `/following` pour gérer vos suivis ou ne plus recevoir ces messages.",
+	"following.title":            "Suivis",
+	"following.none":             "Vous ne suivez aucun stream, plateforme ou éditeur. Utilisez `/follow` pour être prévenu par MP au début des streams.",
+	"following.removed":          "Suivis retirés.",
+	"following.remove_error":     "Une erreur s'est produite. Certains suivis n'ont peut-être pas été retirés.",
+	"following.remove":           "Choisir les suivis à retirer",
+	"following.dms":              "Rappels par MP",
+	"following.on":               "activés",
+	"following.off":              "désactivés",
+	"following.turn_on":          "Activer les rappels par MP",
+	"following.turn_off":         "Désactiver les rappels par MP",
+	"following.turned_on":        "Rappels par MP activés.",
+	"following.turned_off":       "Rappels par MP désactivés.",
+	"following.dms_error":        "Une erreur s'est produite. Les rappels par MP n'ont pas été modifiés.",
+	"following.all_streams":      "Tous les streams %s",
+	"following.publisher":        "Éditeur %d",
+	"following.stream":           "Stream %d",
+
+	// Servers
+	"server.intro":                 "🕹 Bonjour ! Merci de m'avoir ajouté à votre serveur ! 🕹\n\nPour configurer le bot afin qu'il annonce le début des streams et choisir les plateformes à suivre, appuyez sur le bouton ci-dessous ou tapez `/setup` sur le serveur auquel vous m'avez ajouté.\n\nPour obtenir de l'aide sur le bot et ses commandes, tapez `/help`. Les commandes ne peuvent être utilisées que sur les serveurs.",
+	"server.reminder":              "👋 J'ai été ajouté à **%s** il y a %d jours mais je n'ai pas encore été configuré pour annoncer les streams. Appuyez sur le bouton ci-dessous ou tapez `/setup` sur le serveur pour choisir un salon et les plateformes à suivre.",
+	"server.leaving":               "Je quitte votre serveur pour la raison suivante : %s",
+	"server.blacklisted":           "L'ID du serveur est bloqué.\n\n**Raison :** `%s`\n**Expire le :** `%s`",
+	"server.unusable":              "⚠️ Je ne peux pas annoncer de streams sur **%s**.\n\n- %s\n\nUtilisez `/settings` sur votre serveur pour choisir un salon dans lequel je peux publier.",
+	"warning.channel_missing":      "Le salon d'annonce n'existe plus ou n'est pas un salon textuel.",
+	"warning.channel_view":         "Je n'ai pas la permission de voir le salon d'annonce.",
+	"warning.channel_send":         "Je n'ai pas la permission d'envoyer des messages dans le salon d'annonce.",
+	"warning.channel_embed":        "Je n'ai pas la permission d'intégrer des liens dans le salon d'annonce, les annonces n'auront donc pas leurs détails.",
+	"warning.crosspost":            "La publication croisée est activée mais le salon d'annonce n'est pas un salon d'annonces Discord, les annonces ne seront donc pas publiées.",
+	"warning.role_missing":         "Le rôle d'annonce n'existe plus.",
+	"warning.role_no_channel":      "Le rôle d'annonce n'est pas mentionnable, définissez un salon pour que je puisse vérifier si j'ai le droit de le mentionner.",
+	"warning.role_check_error":     "Impossible de vérifier si j'ai le droit de mentionner le rôle d'annonce.",
+	"warning.role_not_mentionable": "Le rôle d'annonce n'est pas mentionnable et je n'ai pas la permission de mentionner tous les rôles, personne ne sera donc notifié.",
+
+	// Help
+	"help.general.description": "Game Streams est un bot qui suit les streams d'annonces de jeux " +
+		"et peut annoncer leur début. \n\nUtilisez la commande `/settings` sur votre serveur " +
+		"pour configurer les annonces.",
+	"help.general.commands": "Commandes",
+	"help.general.commands_list": "`/streams` - Lister les prochains streams" +
+		"\n`/streaminfo` - Obtenir des informations sur un stream" +
+		"\n`/suggest` - Suggérer un stream à ajouter à la base de données" +
+		"\n`/follow` - Être prévenu par MP quand un stream, une plateforme ou un éditeur est en direct" +
+		"\n`/following` - Lister et retirer vos suivis" +
+		"\n`/help` - Obtenir de l'aide sur le bot et les commandes" +
+		"\n`/settings` [admin] - Configurer les annonces de streams" +
+		"\n`/setup` [admin] - Configurer les annonces étape par étape" +
+		"\n`/webhooks` [admin] - Envoyer les annonces vers des webhooks",
+	"help.general.documents":     "Documents",
+	"help.general.privacy":       "Politique de confidentialité",
+	"help.general.terms":         "Conditions d'utilisation",
+	"help.general.changelog":     "Journal des modifications",
+	"help.general.version":       "Version",
+	"help.general.release_date":  "Date de sortie",
+	"help.general.public":        "Salon d'annonces",
+	"help.general.public_follow": "Suivez le salon d'annonces public pour recevoir les annonces sans configurer le bot : %s",
+	"help.streams.description": "Lister les prochains streams, triés par date et heure." +
+		"\n\nLes streams déjà commencés ne sont pas listés. " +
+		"Chaque stream affiche son statut, par ex. rumeur, annoncé ou confirmé. " +
+		"Seuls les streams dont l'horaire est confirmé sont annoncés." +
+		"\n\nLa liste est limitée à %d streams.",
+	"help.streaminfo.description": "Obtenir des informations sur un stream à partir de son titre." +
+		"\n\nLe champ name est obligatoire.",
+	"help.streaminfo.name": "Le nom du stream à rechercher, les correspondances partielles sont acceptées.",
+	"help.suggest.description": "Suggérer un stream à ajouter à la base de données. Les suggestions sont " +
+		"examinées par le propriétaire du bot et ajoutées si elles sont valides.\n\nTous les champs sont obligatoires.",
+	"help.suggest.name": "Le nom du stream à suggérer",
+	"help.suggest.date": "La date du stream au format `AAAA-MM-JJ`",
+	"help.suggest.url":  "L'URL du stream",
+	"help.settings.description": "Paramètres du bot Game Streams. Ils doivent être définis sur votre serveur " +
+		"pour que le bot annonce les streams.\n\nSeuls les administrateurs du serveur peuvent utiliser " +
+		"cette commande.\n\nTous les champs sont facultatifs.",
+	"help.settings.channel": "Le salon où annoncer le début d'un stream. " +
+		"**Sans salon, le bot n'annonce aucun stream.**",
+	"help.settings.role": "Le rôle à mentionner au début d'un stream. " +
+		"**Sans rôle, le bot annonce toujours les streams mais ne mentionne personne.**",
+	"help.settings.events": "Créer un événement Discord pour chaque stream à venir sur vos plateformes. " +
+		"Les événements sont tenus à jour et supprimés à la fin du stream. " +
+		"Nécessite la permission Gérer les événements.",
+	"help.settings.lead_time": "Combien de minutes avant son début un stream est annoncé.",
+	"help.settings.crosspost": "Publier les annonces aux serveurs qui suivent le salon d'annonces. Le salon doit être un salon d'annonces.",
+	"help.settings.follow_publisher": "Annoncer chaque stream d'un éditeur ou d'un showcase (par ex. Nintendo, " +
+		"Ubisoft) quelles que soient ses plateformes. Utilisez `unfollow_publisher` pour arrêter.",
+	"help.settings.templates": "Personnaliser le texte des annonces avec des modèles, par ex. " +
+		"`{{.Name}} {{if .Started}}est en direct{{else}}commence {{.Starts}}{{end}} !`. " +
+		"Les valeurs disponibles sont `.Name`, `.URL`, `.Description`, `.Platforms`, " +
+		"`.Status`, `.Starts`, `.StartTime`, `.LocalDate`, `.Publishers`, `.Games`, `.Role` et " +
+		"`.Started`, avec les fonctions `upper`, `lower`, `trim`, `join`, " +
+		"`truncate` et `default`. Utilisez `default` pour rétablir un modèle.",
+	"help.settings.display":        "Afficher ou masquer la miniature et les plateformes dans les annonces.",
+	"help.settings.preview":        "Afficher un exemple d'annonce avec vos modèles. Utilisez `True` pour prévisualiser.",
+	"help.settings.reset_template": "Réinitialiser les modèles d'annonce. Utilisez `True` pour réinitialiser.",
+	"help.settings.reset":          "Réinitialiser tous les paramètres. Utilisez `True` pour réinitialiser.",
+	"help.settings.platforms": "Activer ou désactiver les annonces par plateforme. " +
+		"Utilisez `True` pour activer et `False` pour désactiver les annonces.",
+	"help.setup.description": "Configurer les annonces de streams étape par étape. Choisissez avec les menus " +
+		"le salon d'annonce, le rôle à mentionner, les plateformes à suivre et combien de temps " +
+		"à l'avance annoncer les streams.\n\nSeuls les administrateurs du serveur peuvent utiliser " +
+		"cette commande. Le propriétaire du serveur peut aussi lancer la configuration depuis le " +
+		"message envoyé à l'arrivée du bot.",
+	"help.follow.description": "Suivre un stream, une plateforme ou un éditeur pour être prévenu par MP " +
+		"%d minutes avant le début des streams." +
+		"\n\nUtilisez `/following` pour lister vos suivis, les retirer ou désactiver les rappels par MP.",
+	"help.follow.stream":    "Le nom ou l'ID d'un stream à venir, les correspondances partielles sont acceptées.",
+	"help.follow.platform":  "Suivre tous les streams d'une plateforme.",
+	"help.follow.publisher": "Suivre tous les streams d'un éditeur, les correspondances partielles sont acceptées.",
+	"help.webhooks.description": "Envoyer les annonces de streams vers des webhooks, sans que le bot ait besoin de publier " +
+		"dans un salon. Les webhooks Discord reçoivent l'embed d'annonce, les webhooks Slack et " +
+		"compatibles Slack reçoivent du texte, et les webhooks JSON reçoivent le stream signé en " +
+		"HMAC-SHA256 dans l'en-tête `X-GameStreams-Signature`.\n\nChaque serveur peut enregistrer " +
+		"jusqu'à %d webhooks. Les webhooks qui échouent de façon répétée sont désactivés. Utilisez " +
+		"la commande sans options pour lister vos webhooks.",
+	"help.webhooks.add":       "L'URL du webhook. Seules les URL `https` publiques sont acceptées.",
+	"help.webhooks.type":      "`Discord`, `Slack` ou `JSON`. Déduit de l'URL si non défini.",
+	"help.webhooks.platform":  "N'envoyer que les streams d'une plateforme. Toutes les plateformes sont envoyées si non défini.",
+	"help.webhooks.lead_time": "Combien de minutes avant le début d'un stream l'envoyer.",
+	"help.webhooks.remove":    "L'ID d'un webhook à retirer.",
+	"help.webhooks.enable":    "L'ID d'un webhook désactivé à réactiver.",
+}
@@ -0,0 +1,116 @@
+/*
+locales.go contains the message catalogue used to translate the bot's responses. Messages
+are looked up by key in the catalogue for the requested locale, falling back to the
+catalogue for its base language, e.g. es for es-419, and then to English.
+*/
+package locales
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Default is the locale used when a message has not been translated.
+const Default = "en-US"
+
+// catalogues holds the messages of each supported locale, keyed by locale and then by
+// message key. Base languages, e.g. es, are used for every locale of that language.
+var catalogues = map[string]map[string]string{
+	Default: english,
+	"de":    german,
+	"fr":    french,
+	"es":    spanish,
+	"pt-BR": portugueseBR,
+}
+
+// discordLocales are the Discord locales that each catalogue is used for when
+// localising command names and descriptions.
+var discordLocales = map[string][]discordgo.Locale{
+	"de":    {discordgo.German},
+	"fr":    {discordgo.French},
+	"es":    {discordgo.SpanishES, discordgo.SpanishLATAM},
+	"pt-BR": {discordgo.PortugueseBR},
+}
+
+// dateLayouts are the layouts used to format dates in each locale. Locales without a
+// layout use the layout of their base language, then the default layout.
+var dateLayouts = map[string]string{
+	Default: "Jan 2, 2006",
+	"en-GB": "2 Jan 2006",
+	"de":    "02.01.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"pt-BR": "02/01/2006",
+	"nl":    "02-01-2006",
+	"ja":    "2006/01/02",
+	"zh-CN": "2006/01/02",
+	"ko":    "2006. 01. 02.",
+	"sv-SE": "2006-01-02",
+}
+
+// fallbacks returns the locales to look messages up in for the given locale, in order.
+func fallbacks(locale string) []string {
+	chain := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		chain = append(chain, base)
+	}
+	return append(chain, Default)
+}
+
+// T returns the message with the given key in the given locale, formatted with the
+// given arguments. If the message has not been translated into the locale, the English
+// message is used. If there is no English message, the key is returned.
+func T(locale string, key string, args ...any) string {
+	for _, l := range fallbacks(locale) {
+		if message, exists := catalogues[l][key]; exists {
+			if len(args) == 0 {
+				return message
+			}
+			return fmt.Sprintf(message, args...)
+		}
+	}
+	return key
+}
+
+// Localizations returns the translations of the message with the given key for each
+// Discord locale that it has been translated into, for use in command name and
+// description localizations. It returns nil if the message has no translations.
+func Localizations(key string) *map[discordgo.Locale]string {
+	localizations := make(map[discordgo.Locale]string)
+	for catalogue, locales := range discordLocales {
+		message, exists := catalogues[catalogue][key]
+		if !exists {
+			continue
+		}
+		for _, locale := range locales {
+			localizations[locale] = message
+		}
+	}
+	if len(localizations) == 0 {
+		return nil
+	}
+	return &localizations
+}
+
+// FormatDate returns the date formatted in the form used by the given locale.
+func FormatDate(locale string, date time.Time) string {
+	for _, l := range fallbacks(locale) {
+		if layout, exists := dateLayouts[l]; exists {
+			return date.Format(layout)
+		}
+	}
+	return date.Format(dateLayouts[Default])
+}
+
+// FormatDateString parses a date in YYYY-MM-DD format and returns it formatted in the
+// form used by the given locale. If the date cannot be parsed it is returned unchanged.
+func FormatDateString(locale string, date string) string {
+	parsed, parseErr := time.Parse("2006-01-02", date)
+	if parseErr != nil {
+		return date
+	}
+	return FormatDate(locale, parsed)
+}
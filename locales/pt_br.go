@@ -0,0 +1,283 @@
+package locales
+
+// portugueseBR holds the Brazilian Portuguese messages.
+var portugueseBR = map[string]string{
+	// Commands
+	"command.streams.description":                     "Listar as próximas transmissões de todas as plataformas",
+	"command.streaminfo.description":                  "Ver mais informações sobre uma transmissão pelo nome",
+	"command.streaminfo.name.description":             "O nome da transmissão",
+	"command.help.name":                               "ajuda",
+	"command.help.description":                        "Obter ajuda com o bot",
+	"command.help.command.description":                "O comando sobre o qual obter ajuda. Deixe em branco para a ajuda geral.",
+	"command.suggest.description":                     "Sugerir uma transmissão para o banco de dados do bot",
+	"command.suggest.name.description":                "O nome da transmissão",
+	"command.suggest.date.description":                "A data da transmissão (AAAA-MM-DD)",
+	"command.suggest.url.description":                 "A URL da transmissão ou de uma página com informações sobre ela",
+	"command.settings.name":                           "configuracoes",
+	"command.settings.description":                    "Alterar as configurações do bot",
+	"command.settings.channel.description":            "Definir o canal onde anunciar quando uma transmissão começa",
+	"command.settings.role.description":               "Definir o cargo a mencionar quando uma transmissão começa",
+	"command.settings.playstation.description":        "Ativar ou desativar os anúncios de PlayStation",
+	"command.settings.xbox.description":               "Ativar ou desativar os anúncios de Xbox",
+	"command.settings.nintendo.description":           "Ativar ou desativar os anúncios de Nintendo",
+	"command.settings.pc.description":                 "Ativar ou desativar os anúncios de PC",
+	"command.settings.vr.description":                 "Ativar ou desativar os anúncios de VR",
+	"command.settings.events.description":             "Ativar ou desativar a criação de eventos do Discord para as transmissões",
+	"command.settings.crosspost.description":          "Publicar os anúncios para servidores que seguem o canal, se for um canal de anúncios",
+	"command.settings.lead_time.description":          "Quantos minutos antes do início anunciar uma transmissão",
+	"command.settings.follow_publisher.description":   "Anunciar todas as transmissões de uma publicadora, em qualquer plataforma",
+	"command.settings.unfollow_publisher.description": "Parar de anunciar as transmissões de uma publicadora",
+	"command.settings.message.description":            "Modelo do texto do anúncio, ou \"default\"",
+	"command.settings.embed_title.description":        "Modelo do título do embed do anúncio, ou \"default\"",
+	"command.settings.embed_description.description":  "Modelo da descrição do embed do anúncio, ou \"default\"",
+	"command.settings.embed_footer.description":       "Modelo do rodapé do embed do anúncio, ou \"default\"",
+	"command.settings.thumbnail.description":          "Mostrar ou ocultar a miniatura da transmissão nos anúncios",
+	"command.settings.show_platforms.description":     "Mostrar ou ocultar o campo de plataformas nos anúncios",
+	"command.settings.reset_template.description":     "Redefinir os modelos de anúncio",
+	"command.settings.preview.description":            "Pré-visualizar um anúncio com os modelos atuais",
+	"command.settings.reset.description":              "Redefinir todas as configurações",
+	"command.setup.description":                       "Configurar os anúncios de transmissões passo a passo",
+	"command.follow.description":                      "Seguir uma transmissão, plataforma ou publicadora para ser lembrado por MD",
+	"command.follow.stream.description":               "O nome ou ID da transmissão a seguir",
+	"command.follow.platform.description":             "A plataforma a seguir",
+	"command.follow.publisher.description":            "A publicadora a seguir (ex.: Nintendo, Ubisoft)",
+	"command.following.description":                   "Listar e remover as transmissões, plataformas e publicadoras que você segue",
+	"command.webhooks.description":                    "Enviar os anúncios de transmissões para webhooks do Discord, Slack ou JSON",
+	"command.webhooks.add.description":                "A URL de um webhook para adicionar",
+	"command.webhooks.type.description":               "O tipo de webhook para adicionar, deduzido da URL se não definido",
+	"command.webhooks.platform.description":           "Enviar ao novo webhook apenas as transmissões desta plataforma",
+	"command.webhooks.lead_time.description":          "Quantos minutos antes do início de uma transmissão enviá-la ao novo webhook",
+	"command.webhooks.remove.description":             "O ID de um webhook para remover",
+	"command.webhooks.enable.description":             "O ID de um webhook desativado para reativar",
+
+	// Common
+	"common.error":          "Erro",
+	"common.error_occurred": "Ocorreu um erro",
+	"common.not_set":        "não definido",
+	"common.minutes":        "%d minutos",
+	"common.default":        "Padrão",
+	"common.custom":         "Personalizado",
+	"blacklist.dm":          "Você está bloqueado de usar este bot.\n\n**Motivo:** `%s`\n**Expira em:** `%s`",
+
+	// Streams
+	"streams.title":         "Próximas transmissões",
+	"streams.none":          "Nenhuma transmissão encontrada",
+	"streaminfo.title":      "Informações da transmissão",
+	"streaminfo.none":       "Nenhuma transmissão encontrada com esse nome",
+	"field.status":          "Status",
+	"field.platforms":       "Plataformas",
+	"field.date":            "Data",
+	"field.time":            "Horário",
+	"field.url":             "URL",
+	"field.description":     "Descrição",
+	"field.went_live":       "Ao vivo desde",
+	"field.publishers":      "Publicadoras",
+	"field.games":           "Jogos",
+	"status.rumoured":       "Rumor",
+	"status.announced":      "Anunciada",
+	"status.confirmed":      "Confirmada",
+	"status.live":           "Ao vivo",
+	"status.ended":          "Encerrada",
+	"status.cancelled":      "Cancelada",
+	"status.postponed":      "Adiada",
+	"announcement.starting": "A transmissão começa %s",
+	"announcement.started":  "A transmissão começou %s",
+	"followup.cancelled":    "**%s** foi cancelada.",
+	"followup.postponed":    "**%s** foi adiada. Ela será anunciada novamente quando um novo horário for confirmado.",
+
+	// Suggestions
+	"suggest.limit":        "Você atingiu o limite diário de sugestões. Tente novamente amanhã.",
+	"suggest.thanks_title": "Obrigado",
+	"suggest.thanks":       "Sua sugestão foi recebida",
+	"suggest.error":        "**Ocorreu um erro.** Sua sugestão pode não ter sido recebida.",
+
+	// Settings
+	"settings.title":               "Configurações",
+	"settings.current":             "Configurações atuais:",
+	"settings.updated":             "Configurações atualizadas.\n\n**Configurações atuais:**",
+	"settings.reset_error":         "Ocorreu um erro. As configurações podem não ter sido redefinidas.",
+	"settings.get_error":           "Ocorreu um erro. As configurações podem não ter sido atualizadas.",
+	"settings.set_error":           "Ocorreu um erro. As configurações não foram atualizadas.",
+	"settings.channel_refused":     "O canal de anúncios não foi alterado porque não consigo publicar nele.\n\n**Configurações atuais:**",
+	"settings.channel":             "Canal de anúncios",
+	"settings.role":                "Cargo de anúncios",
+	"settings.lead_time":           "Antecedência",
+	"settings.events":              "Eventos agendados",
+	"settings.crosspost":           "Publicação cruzada",
+	"settings.publishers":          "Publicadoras",
+	"settings.template":            "Modelo de anúncio",
+	"settings.warnings":            "Avisos",
+	"settings.preview":             "**Pré-visualização** (antes e depois do início da transmissão):",
+	"settings.preview_error":       "Não foi possível pré-visualizar o modelo de anúncio: %s",
+	"settings.template_invalid":    "O modelo de anúncio não foi atualizado: `%s`",
+	"settings.template_error":      "Ocorreu um erro. O modelo de anúncio não foi atualizado.",
+	"settings.publisher_not_found": "Nenhuma publicadora encontrada para **%s**.",
+	"settings.publisher_error":     "Ocorreu um erro. **%s** pode não ter sido atualizada.",
+
+	// Webhooks
+	"webhooks.title":           "Webhooks",
+	"webhooks.none":            "Nenhum webhook registrado. Use `add` com a URL de um webhook para adicionar um.",
+	"webhooks.registered":      "**Webhooks registrados:**",
+	"webhooks.limit":           "Servidores podem registrar até %d webhooks. Remova um antes de adicionar outro.",
+	"webhooks.invalid":         "O webhook não foi adicionado: `%s`",
+	"webhooks.add_error":       "Ocorreu um erro. O webhook não foi adicionado.",
+	"webhooks.added":           "Webhook `#%d` adicionado.",
+	"webhooks.secret":          "Os envios são assinados com este segredo, que não será mostrado novamente:\n`%s`",
+	"webhooks.removed":         "Webhook `#%d` removido.",
+	"webhooks.enabled":         "Webhook `#%d` reativado.",
+	"webhooks.not_found":       "Nenhum webhook encontrado com o ID `%d`.",
+	"webhooks.error":           "Ocorreu um erro. O webhook pode não ter sido atualizado.",
+	"webhooks.all_platforms":   "todas as plataformas",
+	"webhooks.enabled_status":  "✅ Ativado",
+	"webhooks.failing_status":  "⚠️ Ativado, %d envios com falha: `%s`",
+	"webhooks.disabled_status": "❌ Desativado após %d envios com falha: `%s`",
+
+	// Setup
+	"setup.title":                 "Configuração: %s",
+	"setup.description":           "Use os menus abaixo para escolher onde as transmissões são anunciadas, quem é mencionado, quais plataformas seguir e com quanta antecedência anunciar as transmissões. As alterações são salvas assim que são feitas.",
+	"setup.owner_only":            "Somente o dono do servidor pode configurar o bot por MD. Os administradores do servidor podem usar `/setup` no servidor.",
+	"setup.channel_refused":       "Não consigo publicar nesse canal, escolha outro.",
+	"setup.complete":              "Configuração concluída! Use `/setup` ou `/settings` para fazer alterações a qualquer momento.",
+	"setup.incomplete":            "Configuração salva, mas as transmissões não serão anunciadas até que um canal de anúncios e pelo menos uma plataforma sejam escolhidos.",
+	"setup.error":                 "Ocorreu um erro. As configurações podem não ter sido atualizadas.",
+	"setup.channel_placeholder":   "Escolha o canal de anúncios",
+	"setup.role_placeholder":      "Escolha o cargo a mencionar (opcional)",
+	"setup.platforms_placeholder": "Escolha as plataformas a seguir",
+	"setup.lead_placeholder":      "Escolha com quanta antecedência anunciar as transmissões",
+	"setup.no_channels":           "Nenhum canal disponível",
+	"setup.no_roles":              "Nenhum cargo disponível",
+	"setup.lead_option":           "%d minutos antes",
+	"setup.done":                  "Concluir",
+	"setup.start":                 "Iniciar configuração",
+	"setup.now":                   "Configurar agora",
+
+	// Follows
+	"follow.title":               "Seguir",
+	"follow.choose":              "Escolha uma `stream`, uma `platform` ou uma `publisher` para seguir.",
+	"follow.stream_not_found":    "Nenhuma transmissão futura encontrada com esse nome ou ID.",
+	"follow.publisher_not_found": "Nenhuma publicadora encontrada com esse nome.",
+	"follow.all_streams":         "todas as transmissões de **%s**",
+	"follow.and":                 " e ",
+	"follow.limit":               "Você pode seguir até %d transmissões, plataformas e publicadoras. Use `/following` para remover algumas.",
+	"follow.added":               "Agora você está seguindo %s.",
+	"follow.reminder":            "Vou enviar uma MD %d minutos antes de começarem.\n\nUse `/following` para ver e gerenciar o que você segue.",
+	"follow.dms_off":             "⚠️ Você desativou os lembretes por MD, ative-os novamente com `/following`.",
+	"follow.error":               "**Ocorreu um erro.** O acompanhamento pode não ter sido adicionado.",
+	"follow.dm":                  "🔔 Uma transmissão que você segue vai começar em breve. Use `/following` para gerenciar o que você segue ou parar de receber estas mensagens.",
+	"following.title":            "Seguindo",
+	"following.none":             "Você não segue nenhuma transmissão, plataforma ou publicadora. Use `/follow` para ser lembrado por MD quando as transmissões começarem.",
+	"following.removed":          "Acompanhamentos removidos.",
+	"following.remove_error":     "Ocorreu um erro. Alguns acompanhamentos podem não ter sido removidos.",
+	"following.remove":           "Escolha o que deixar de seguir",
+	"following.dms":              "Lembretes por MD",
+	"following.on":               "ativados",
+	"following.off":              "desativados",
+	"following.turn_on":          "Ativar lembretes por MD",
+	"following.turn_off":         "Desativar lembretes por MD",
+	"following.turned_on":        "Lembretes por MD ativados.",
+	"following.turned_off":       "Lembretes por MD desativados.",
+	"following.dms_error":        "Ocorreu um erro. Os lembretes por MD não foram alterados.",
+	"following.all_streams":      "Todas as transmissões de %s",
+	"following.publisher":        "Publicadora %d",
+	"following.stream":           "Transmissão %d",
+
+	// Servers
+	"server.intro":                 "🕹 Olá! Obrigado por me adicionar ao seu servidor! 🕹\n\nPara configurar o bot para anunciar quando as transmissões começam e quais plataformas você quer seguir, pressione o botão abaixo ou digite `/setup` no servidor ao qual você me adicionou.\n\nPara obter ajuda com o bot e seus comandos, digite `/help`. Os comandos só podem ser usados em servidores.",
+	"server.reminder":              "👋 Fui adicionado a **%s** há %d dias, mas ainda não fui configurado para anunciar transmissões. Pressione o botão abaixo ou digite `/setup` no servidor para escolher um canal e as plataformas a seguir.",
+	"server.leaving":               "Estou saindo do seu servidor pelo seguinte motivo: %s",
+	"server.blacklisted":           "O ID do servidor está bloqueado.\n\n**Motivo:** `%s`\n**Expira em:** `%s`",
+	"server.unusable":              "⚠️ Não consigo anunciar transmissões em **%s**.\n\n- %s\n\nUse `/settings` no seu servidor para escolher um canal em que eu possa publicar.",
+	"warning.channel_missing":      "O canal de anúncios não existe mais ou não é um canal de texto.",
+	"warning.channel_view":         "Não tenho permissão para ver o canal de anúncios.",
+	"warning.channel_send":         "Não tenho permissão para enviar mensagens no canal de anúncios.",
+	"warning.channel_embed":        "Não tenho permissão para incorporar links no canal de anúncios, então os anúncios ficarão sem os detalhes.",
+	"warning.crosspost":            "A publicação cruzada está ativada, mas o canal de anúncios não é um canal de anúncios do Discord, então os anúncios não serão publicados.",
+	"warning.role_missing":         "O cargo de anúncios não existe mais.",
+	"warning.role_no_channel":      "O cargo de anúncios não pode ser mencionado, defina um canal para que eu possa verificar se tenho permissão para mencioná-lo.",
+	"warning.role_check_error":     "Não foi possível verificar se tenho permissão para mencionar o cargo de anúncios.",
+	"warning.role_not_mentionable": "O cargo de anúncios não pode ser mencionado e não tenho permissão para mencionar todos os cargos, então ninguém será notificado.",
+
+	// Help
+	"help.general.description": "Game Streams é um bot que acompanha as transmissões de anúncios de jogos " +
+		"e pode avisar quando elas começam. \n\nUse o comando `/settings` no seu servidor " +
+		"para configurar os anúncios.",
+	"help.general.commands": "Comandos",
+	"help.general.commands_list": "`/streams` - Listar as próximas transmissões" +
+		"\n`/streaminfo` - Ver informações sobre uma transmissão" +
+		"\n`/suggest` - Sugerir uma transmissão para o banco de dados" +
+		"\n`/follow` - Ser lembrado por MD quando uma transmissão, plataforma ou publicadora começar" +
+		"\n`/following` - Listar e remover o que você segue" +
+		"\n`/help` - Obter ajuda com o bot e os comandos" +
+		"\n`/settings` [admin] - Configurar os anúncios de transmissões" +
+		"\n`/setup` [admin] - Configurar os anúncios passo a passo" +
+		"\n`/webhooks` [admin] - Enviar os anúncios para webhooks",
+	"help.general.documents":     "Documentos",
+	"help.general.privacy":       "Política de Privacidade",
+	"help.general.terms":         "Termos de Serviço",
+	"help.general.changelog":     "Registro de alterações",
+	"help.general.version":       "Versão",
+	"help.general.release_date":  "Data de lançamento",
+	"help.general.public":        "Canal de anúncios",
+	"help.general.public_follow": "Siga o canal de anúncios público para receber os anúncios sem configurar o bot: %s",
+	"help.streams.description": "Listar as próximas transmissões, ordenadas por data e horário." +
+		"\n\nTransmissões que já começaram não são listadas. " +
+		"Cada transmissão mostra seu status, ex.: rumor, anunciada ou confirmada. " +
+		"Apenas transmissões com horário confirmado são anunciadas." +
+		"\n\nA lista é limitada a %d transmissões.",
+	"help.streaminfo.description": "Ver informações sobre uma transmissão pelo título." +
+		"\n\nO campo name é obrigatório.",
+	"help.streaminfo.name": "O nome da transmissão a procurar, correspondências parciais são aceitas.",
+	"help.suggest.description": "Sugerir uma transmissão para o banco de dados. As sugestões são revisadas pelo " +
+		"dono do bot e adicionadas se forem válidas.\n\nTodos os campos são obrigatórios.",
+	"help.suggest.name": "O nome da transmissão a sugerir",
+	"help.suggest.date": "A data da transmissão no formato `AAAA-MM-DD`",
+	"help.suggest.url":  "A URL da transmissão",
+	"help.settings.description": "Configurações do bot Game Streams. Elas precisam ser definidas no seu servidor " +
+		"para que o bot anuncie transmissões.\n\nApenas administradores do servidor podem usar este " +
+		"comando.\n\nTodos os campos são opcionais.",
+	"help.settings.channel": "O canal onde anunciar quando uma transmissão começa. " +
+		"**Sem canal, o bot não anunciará transmissões.**",
+	"help.settings.role": "O cargo a mencionar quando uma transmissão começa. " +
+		"**Sem cargo, o bot continuará anunciando mas não mencionará ninguém.**",
+	"help.settings.events": "Criar um evento do Discord para cada próxima transmissão das suas plataformas. " +
+		"Os eventos são mantidos atualizados e removidos quando a transmissão termina. " +
+		"Requer a permissão Gerenciar eventos.",
+	"help.settings.lead_time": "Quantos minutos antes do início uma transmissão é anunciada.",
+	"help.settings.crosspost": "Publicar os anúncios para os servidores que seguem o canal de anúncios. O canal precisa ser um canal de anúncios.",
+	"help.settings.follow_publisher": "Anunciar todas as transmissões de uma publicadora ou showcase (ex.: " +
+		"Nintendo, Ubisoft) em qualquer plataforma. Use `unfollow_publisher` para parar.",
+	"help.settings.templates": "Personalizar o texto dos anúncios usando modelos, ex.: " +
+		"`{{.Name}} {{if .Started}}está ao vivo{{else}}começa {{.Starts}}{{end}}!`. " +
+		"Os valores disponíveis são `.Name`, `.URL`, `.Description`, `.Platforms`, " +
+		"`.Status`, `.Starts`, `.StartTime`, `.LocalDate`, `.Publishers`, `.Games`, `.Role` e " +
+		"`.Started`, com as funções `upper`, `lower`, `trim`, `join`, " +
+		"`truncate` e `default`. Use `default` para restaurar um modelo.",
+	"help.settings.display":        "Mostrar ou ocultar a miniatura e as plataformas nos anúncios.",
+	"help.settings.preview":        "Mostrar um anúncio de exemplo com seus modelos. Use `True` para pré-visualizar.",
+	"help.settings.reset_template": "Redefinir os modelos de anúncio. Use `True` para redefinir.",
+	"help.settings.reset":          "Redefinir todas as configurações. Use `True` para redefinir.",
+	"help.settings.platforms": "Ativar ou desativar os anúncios por plataforma. " +
+		"Use `True` para ativar e `False` para desativar os anúncios.",
+	"help.setup.description": "Configurar os anúncios de transmissões passo a passo. Escolha nos menus o canal " +
+		"de anúncios, o cargo a mencionar, as plataformas a seguir e com quanta antecedência as " +
+		"transmissões são anunciadas.\n\nApenas administradores do servidor podem usar este comando. " +
+		"O dono do servidor também pode começar pela mensagem enviada quando o bot entrou no servidor.",
+	"help.follow.description": "Seguir uma transmissão, uma plataforma ou uma publicadora para ser lembrado por MD " +
+		"%d minutos antes do início das transmissões." +
+		"\n\nUse `/following` para listar o que você segue, remover ou desativar os lembretes por MD.",
+	"help.follow.stream":    "O nome ou ID de uma próxima transmissão, correspondências parciais são aceitas.",
+	"help.follow.platform":  "Seguir todas as transmissões de uma plataforma.",
+	"help.follow.publisher": "Seguir todas as transmissões de uma publicadora, correspondências parciais são aceitas.",
+	"help.webhooks.description": "Enviar os anúncios de transmissões para webhooks, sem que o bot precise de permissão para " +
+		"postar em um canal. Webhooks do Discord recebem o embed do anúncio, webhooks do Slack e " +
+		"compatíveis com Slack recebem texto, e webhooks JSON recebem a transmissão assinada com " +
+		"HMAC-SHA256 no cabeçalho `X-GameStreams-Signature`.\n\nCada servidor pode registrar até %d " +
+		"webhooks. Webhooks que falham repetidamente são desativados. Use o comando sem opções para " +
+		"listar seus webhooks.",
+	"help.webhooks.add":       "A URL do webhook. Apenas URLs `https` públicas são aceitas.",
+	"help.webhooks.type":      "`Discord`, `Slack` ou `JSON`. Deduzido da URL se não definido.",
+	"help.webhooks.platform":  "Enviar apenas as transmissões de uma plataforma. Todas são enviadas se não definido.",
+	"help.webhooks.lead_time": "Quantos minutos antes do início de uma transmissão enviá-la.",
+	"help.webhooks.remove":    "O ID de um webhook para remover.",
+	"help.webhooks.enable":    "O ID de um webhook desativado para reativar.",
+}
@@ -7,23 +7,21 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
-
-	"gamestreams/config"
 )
 
-// TruncateLogs truncates the logs by rotating and vacuuming journalctl logs.
-// The number of days to keep logs is set in the config.toml file.
-func TruncateLogs() {
+// TruncateLogs truncates the logs by rotating and vacuuming journalctl logs, keeping
+// the given number of days of logs.
+func (l *Logger) TruncateLogs(daysToKeep int) {
 	if runtime.GOOS == "linux" {
 		cmd := exec.Command("sudo", "journalctl", "--rotate")
 		err := cmd.Run()
 		if err != nil {
-			LogError(" LOGS", "error rotating journalctl logs", "err", err)
+			l.LogError(" LOGS", "error rotating journalctl logs", "err", err)
 		}
-		cmd = exec.Command("sudo", "journalctl", fmt.Sprintf("--vacuum-time=%dd", config.Values.Logs.DaysToKeep))
+		cmd = exec.Command("sudo", "journalctl", fmt.Sprintf("--vacuum-time=%dd", daysToKeep))
 		err = cmd.Run()
 		if err != nil {
-			LogError(" LOGS", "error vacuuming journalctl logs", "err", err)
+			l.LogError(" LOGS", "error vacuuming journalctl logs", "err", err)
 		}
 	}
 }
@@ -1,27 +1,38 @@
 /*
-messages.go provides functions for sending messages to users and the bot owner.
+messaging.go provides functions for sending messages to users and the bot owner.
 */
 package logs
 
-import (
-	"gamestreams/config"
-)
+// Messenger sends direct messages to the bot owner. It is implemented by the messengers
+// in the messenger package.
+type Messenger interface {
+	// DM sends the text as a direct message to the user with the given ID.
+	DM(userID string, text string) error
+	// OwnerID returns the ID of the bot owner.
+	OwnerID() string
+}
+
+// RegisterMessenger sets the messenger used to DM the bot owner.
+func (l *Logger) RegisterMessenger(m Messenger) {
+	l.messenger = m
+}
 
-// DM sends a direct message to a user.
-func DM(userID string, message string) {
-	st, err := Session.UserChannelCreate(userID)
-	if err != nil {
-		LogError(" LOGS", "error creating DM channel", "err", err)
+// DM sends a direct message to a user using the registered messenger. Errors are only
+// logged, not sent to the owner, so that a failing messenger does not cause a loop.
+func (l *Logger) DM(userID string, message string) {
+	if l.messenger == nil {
 		return
 	}
-	_, err = Session.ChannelMessageSend(st.ID, message)
-	if err != nil {
-		LogError(" LOGS", "error sending DM", "err", err)
+	if dmErr := l.messenger.DM(userID, message); dmErr != nil {
+		l.ErrorWarn.WithPrefix(" LOGS").Error("error sending DM", "err", dmErr)
 	}
 }
 
-// DM sends a direct message to the bot owner. The owner's Discord ID is set in
-// config.toml.
-func DMOwner(message string) {
-	DM(config.Values.Discord.OwnerID, message)
+// DM sends a direct message to the bot owner. The owner's ID is set in config.toml for
+// the platform of the registered messenger.
+func (l *Logger) DMOwner(message string) {
+	if l.messenger == nil {
+		return
+	}
+	l.DM(l.messenger.OwnerID(), message)
 }
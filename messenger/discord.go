@@ -0,0 +1,69 @@
+/*
+discord.go contains the Discord messenger, which sends messages using the bot's Discord
+session.
+*/
+package messenger
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/config"
+	"gamestreams/utils"
+)
+
+// Discord sends messages using a Discord session.
+type Discord struct {
+	// The session used to send messages.
+	Session *discordgo.Session
+	// Returns the current configuration values.
+	config func() *config.Config
+}
+
+// NewDiscord returns a Discord messenger that sends messages using the given session
+// and reads the embed colour and owner ID with cfg.
+func NewDiscord(session *discordgo.Session, cfg func() *config.Config) Discord {
+	return Discord{Session: session, config: cfg}
+}
+
+// Name returns the name of the platform.
+func (d Discord) Name() string {
+	return "Discord"
+}
+
+// Send posts the message as an embed in the channel with the given ID.
+func (d Discord) Send(channelID string, msg Message) (string, error) {
+	embed := &discordgo.MessageEmbed{
+		Title:       utils.Truncate(msg.Title, 256),
+		URL:         msg.URL,
+		Description: utils.Truncate(msg.Text, 4096),
+		Color:       d.config().Discord.EmbedColour,
+	}
+	if msg.ImageURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: msg.ImageURL}
+	}
+	posted, sendErr := d.Session.ChannelMessageSendEmbed(channelID, embed)
+	if sendErr != nil {
+		return "", sendErr
+	}
+	return posted.ID, nil
+}
+
+// DM sends the text as a direct message to the user with the given Discord ID.
+func (d Discord) DM(userID string, text string) error {
+	channel, createErr := d.Session.UserChannelCreate(userID)
+	if createErr != nil {
+		return createErr
+	}
+	_, sendErr := d.Session.ChannelMessageSend(channel.ID, utils.Truncate(text, 2000))
+	return sendErr
+}
+
+// OwnerID returns the Discord ID of the bot owner set in config.toml.
+func (d Discord) OwnerID() string {
+	return d.config().Discord.OwnerID
+}
+
+// Channels returns no channels, as each server sets its own announce channel.
+func (d Discord) Channels() []string {
+	return nil
+}
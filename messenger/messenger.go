@@ -0,0 +1,35 @@
+/*
+messenger.go contains the Messenger interface used to send announcements and direct
+messages. Discord is the default messenger, and other chat platforms can be added so
+that streams are also announced to communities that are not on Discord.
+*/
+package messenger
+
+// Messenger sends announcements and direct messages on a single chat platform.
+type Messenger interface {
+	// Name returns the name of the platform, e.g. Discord.
+	Name() string
+	// Send posts the message in the channel with the given ID and returns the ID of the
+	// posted message.
+	Send(channelID string, msg Message) (string, error)
+	// DM sends the text as a direct message to the user with the given ID.
+	DM(userID string, text string) error
+	// OwnerID returns the ID of the bot owner on the platform, or an empty string if it
+	// is not set.
+	OwnerID() string
+	// Channels returns the IDs of the channels set in config.toml that every stream is
+	// announced in. Discord returns none, as servers choose their own channel.
+	Channels() []string
+}
+
+// Message is an announcement in a form that every platform can display.
+type Message struct {
+	// The title of the message, e.g. the name of the stream.
+	Title string
+	// The body of the message as plain text.
+	Text string
+	// The URL the title links to.
+	URL string
+	// The URL of an image shown with the message, if any.
+	ImageURL string
+}
@@ -0,0 +1,117 @@
+/*
+telegram.go contains the Telegram messenger, which sends messages using the Telegram Bot
+API. Messages are sent with the shared HTTP client, so failed requests are retried.
+*/
+package messenger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"gamestreams/config"
+	"gamestreams/utils"
+)
+
+// telegramAPI is the base URL of the Telegram Bot API.
+const telegramAPI = "https://api.telegram.org/bot"
+
+// Telegram sends messages using the Telegram Bot API.
+type Telegram struct {
+	// The token of the Telegram bot.
+	Token string
+	// The client that requests to the Bot API are made with.
+	client *utils.Client
+	// Returns the current configuration values.
+	config func() *config.Config
+}
+
+// telegramResponse is the response returned by the Telegram Bot API.
+type telegramResponse struct {
+	// True if the request succeeded.
+	OK bool `json:"ok"`
+	// The reason the request failed.
+	Description string `json:"description"`
+	// The message that was sent.
+	Result struct {
+		// The ID of the message.
+		MessageID int `json:"message_id"`
+	} `json:"result"`
+}
+
+// NewTelegram returns a Telegram messenger that sends messages as the bot with the
+// given token using the client, and reads the owner ID and chats with cfg.
+func NewTelegram(token string, client *utils.Client, cfg func() *config.Config) Telegram {
+	return Telegram{Token: token, client: client, config: cfg}
+}
+
+// Name returns the name of the platform.
+func (t Telegram) Name() string {
+	return "Telegram"
+}
+
+// Send posts the message in the chat with the given ID or @username. The title is shown
+// in bold and links to the URL of the message, which Telegram shows a preview of.
+func (t Telegram) Send(chatID string, msg Message) (string, error) {
+	var text strings.Builder
+	if msg.URL != "" {
+		fmt.Fprintf(&text, "<b><a href=\"%s\">%s</a></b>", html.EscapeString(msg.URL),
+			html.EscapeString(msg.Title))
+	} else {
+		fmt.Fprintf(&text, "<b>%s</b>", html.EscapeString(msg.Title))
+	}
+	if msg.Text != "" {
+		text.WriteString("\n\n" + html.EscapeString(msg.Text))
+	}
+	return t.sendMessage(chatID, utils.Truncate(text.String(), 4096), "HTML")
+}
+
+// DM sends the text to the user with the given Telegram ID. Telegram only allows bots
+// to message users who have started a chat with the bot.
+func (t Telegram) DM(userID string, text string) error {
+	_, sendErr := t.sendMessage(userID, utils.Truncate(text, 4096), "")
+	return sendErr
+}
+
+// OwnerID returns the Telegram ID of the bot owner set in config.toml.
+func (t Telegram) OwnerID() string {
+	return t.config().Telegram.OwnerID
+}
+
+// Channels returns the chats set in config.toml that every stream is announced in.
+func (t Telegram) Channels() []string {
+	return t.config().Telegram.Chats
+}
+
+// sendMessage calls the sendMessage method of the Bot API and returns the ID of the
+// sent message. The error returned does not include the URL, as it contains the token.
+func (t Telegram) sendMessage(chatID string, text string, parseMode string) (string, error) {
+	payload := map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if parseMode != "" {
+		payload["parse_mode"] = parseMode
+	}
+	body, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	res, postErr := t.client.Post(telegramAPI+t.Token+"/sendMessage", body,
+		map[string]string{"Content-Type": "application/json"})
+	if postErr != nil {
+		return "", postErr
+	}
+	defer res.Body.Close()
+	var response telegramResponse
+	if decodeErr := json.NewDecoder(res.Body).Decode(&response); decodeErr != nil {
+		return "", fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+	}
+	if !response.OK {
+		return "", errors.New(response.Description)
+	}
+	return strconv.Itoa(response.Result.MessageID), nil
+}
@@ -0,0 +1,108 @@
+/*
+bilibili.go contains the Bilibili provider. Live rooms are read from Bilibili's public
+room API, and videos from their OpenGraph tags.
+*/
+package providers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gamestreams/utils"
+)
+
+// bilibili resolves bilibili.com and live.bilibili.com URLs.
+type bilibili struct{}
+
+// Name returns the name of the provider.
+func (bilibili) Name() string {
+	return "Bilibili"
+}
+
+// Match returns true for Bilibili URLs.
+func (bilibili) Match(u *url.URL) bool {
+	return u.Host == "bilibili.com" ||
+		u.Host == "live.bilibili.com" ||
+		u.Host == "m.bilibili.com" ||
+		u.Host == "b23.tv"
+}
+
+// Canonical returns the URL without its query, using www.bilibili.com for videos.
+func (bilibili) Canonical(u *url.URL) string {
+	if u.Host == "live.bilibili.com" || u.Host == "b23.tv" {
+		return stripURL(u.Host, u.Path)
+	}
+	return stripURL("www.bilibili.com", u.Path)
+}
+
+// DirectURL returns the canonical URL.
+func (b bilibili) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return b.Canonical(u), nil
+}
+
+// Metadata returns the title and cover of the live room, or the OpenGraph tags of the
+// video page.
+func (b bilibili) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	if roomID := b.roomID(u); roomID != "" {
+		room, getErr := b.room(c, roomID)
+		if getErr != nil {
+			return Metadata{}, getErr
+		}
+		return parseBilibiliRoom(room), nil
+	}
+	doc, getErr := c.GetHTMLBody(b.Canonical(u))
+	if getErr != nil {
+		return Metadata{}, getErr
+	}
+	return parseOpenGraph(doc), nil
+}
+
+// IsLive returns true if the live room is broadcasting. Only live room URLs can be
+// checked.
+func (b bilibili) IsLive(c *utils.Client, u *url.URL) (bool, error) {
+	roomID := b.roomID(u)
+	if roomID == "" {
+		return false, ErrLiveUnsupported
+	}
+	room, getErr := b.room(c, roomID)
+	if getErr != nil {
+		return false, getErr
+	}
+	return parseBilibiliLive(room), nil
+}
+
+// roomID returns the ID of the live room in the URL, or an empty string if the URL is
+// not a live room.
+func (bilibili) roomID(u *url.URL) string {
+	parts := pathParts(u)
+	if u.Host != "live.bilibili.com" || len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(parts[0])
+}
+
+// room returns the JSON response of the room API for the room with the given ID.
+func (bilibili) room(c *utils.Client, roomID string) (string, error) {
+	body, getErr := c.GetBody("https://api.live.bilibili.com/room/v1/Room/get_info?room_id=" +
+		url.QueryEscape(roomID))
+	if getErr != nil {
+		return "", getErr
+	}
+	return string(body), nil
+}
+
+// parseBilibiliRoom returns the metadata from a room API response.
+func parseBilibiliRoom(room string) Metadata {
+	return Metadata{
+		Title: gjson.Get(room, "data.title").String(),
+		Thumbnail: firstSet(gjson.Get(room, "data.user_cover").String(),
+			gjson.Get(room, "data.keyframe").String()),
+	}
+}
+
+// parseBilibiliLive returns true if a room API response shows the room is live.
+func parseBilibiliLive(room string) bool {
+	return gjson.Get(room, "data.live_status").Int() == 1
+}
@@ -0,0 +1,69 @@
+/*
+cache.go contains the cache used to store the direct URLs and metadata resolved for
+stream URLs, so that announcing a stream to many servers only scrapes its page once.
+*/
+package providers
+
+import (
+	"encoding/json"
+)
+
+const (
+	// cacheDirect is the kind of cache entry holding the direct URL of a stream.
+	cacheDirect = "direct"
+	// cacheMetadata is the kind of cache entry holding the metadata of a stream.
+	cacheMetadata = "metadata"
+)
+
+// Cache stores values resolved for stream URLs. Implementations are responsible for
+// expiring entries.
+type Cache interface {
+	// Get returns the value of the given kind cached for the URL and true, or false if
+	// there is no value or it has expired.
+	Get(url string, kind string) (string, bool)
+	// Set caches the value of the given kind for the URL.
+	Set(url string, kind string, value string) error
+}
+
+// cacheGet returns the value of the given kind cached for the URL, if any.
+func (r *Resolver) cacheGet(url string, kind string) (string, bool) {
+	if r.cache == nil {
+		return "", false
+	}
+	return r.cache.Get(url, kind)
+}
+
+// cacheSet caches the value of the given kind for the URL, logging any error.
+func (r *Resolver) cacheSet(url string, kind string, value string) {
+	if r.cache == nil {
+		return
+	}
+	if setErr := r.cache.Set(url, kind, value); setErr != nil {
+		r.log.LogError("PRVDR", "error caching value",
+			"url", url,
+			"kind", kind,
+			"err", setErr)
+	}
+}
+
+// cachedMetadata returns the metadata cached for the URL, if any.
+func (r *Resolver) cachedMetadata(url string) (Metadata, bool) {
+	value, found := r.cacheGet(url, cacheMetadata)
+	if !found {
+		return Metadata{}, false
+	}
+	var metadata Metadata
+	if jsonErr := json.Unmarshal([]byte(value), &metadata); jsonErr != nil {
+		return Metadata{}, false
+	}
+	return metadata, true
+}
+
+// cacheMetadataValue caches the metadata for the URL.
+func (r *Resolver) cacheMetadataValue(url string, metadata Metadata) {
+	value, jsonErr := json.Marshal(metadata)
+	if jsonErr != nil {
+		return
+	}
+	r.cacheSet(url, cacheMetadata, string(value))
+}
@@ -0,0 +1,62 @@
+/*
+facebook.go contains the Facebook provider. Facebook pages are rarely readable without
+logging in, so the page's profile picture from the Graph API is used as the thumbnail.
+*/
+package providers
+
+import (
+	"fmt"
+	"net/url"
+
+	"gamestreams/utils"
+)
+
+// facebook resolves facebook.com and fb.watch URLs.
+type facebook struct{}
+
+// Name returns the name of the provider.
+func (facebook) Name() string {
+	return "Facebook"
+}
+
+// Match returns true for Facebook URLs.
+func (facebook) Match(u *url.URL) bool {
+	return u.Host == "facebook.com" ||
+		u.Host == "m.facebook.com" ||
+		u.Host == "fb.watch"
+}
+
+// Canonical returns the URL on www.facebook.com without its query, keeping the video ID
+// of watch links.
+func (facebook) Canonical(u *url.URL) string {
+	if u.Host == "fb.watch" {
+		return stripURL("fb.watch", u.Path)
+	}
+	if id := u.Query().Get("v"); id != "" {
+		return "https://www.facebook.com/watch/?v=" + url.QueryEscape(id)
+	}
+	return stripURL("www.facebook.com", u.Path)
+}
+
+// DirectURL returns the canonical URL.
+func (f facebook) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return f.Canonical(u), nil
+}
+
+// Metadata returns the page's profile picture and, if the page can be read, its title.
+func (f facebook) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	var metadata Metadata
+	parts := pathParts(u)
+	if u.Host != "fb.watch" && len(parts) > 0 && parts[0] != "watch" {
+		metadata.Thumbnail = fmt.Sprintf("https://graph.facebook.com/%s/picture?type=large",
+			url.PathEscape(parts[0]))
+	}
+	doc, getErr := c.GetHTMLBody(f.Canonical(u))
+	if getErr != nil {
+		return metadata, getErr
+	}
+	og := parseOpenGraph(doc)
+	metadata.Title = og.Title
+	metadata.Thumbnail = firstSet(metadata.Thumbnail, og.Thumbnail)
+	return metadata, nil
+}
@@ -0,0 +1,91 @@
+/*
+kick.go contains the Kick provider. Channel information is read from Kick's public
+channel API, which includes the current livestream when the channel is live.
+*/
+package providers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gamestreams/utils"
+)
+
+// kick resolves kick.com URLs.
+type kick struct{}
+
+// Name returns the name of the provider.
+func (kick) Name() string {
+	return "Kick"
+}
+
+// Match returns true for Kick URLs.
+func (kick) Match(u *url.URL) bool {
+	return u.Host == "kick.com"
+}
+
+// Canonical returns the channel URL with the channel name in lower case.
+func (kick) Canonical(u *url.URL) string {
+	parts := pathParts(u)
+	if len(parts) == 0 {
+		return stripURL("kick.com", "/")
+	}
+	return stripURL("kick.com", "/"+strings.ToLower(parts[0]))
+}
+
+// DirectURL returns the canonical channel URL, as Kick channels always show the current
+// stream.
+func (k kick) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return k.Canonical(u), nil
+}
+
+// Metadata returns the title and thumbnail of the current stream, or the channel name
+// and profile picture if the channel is not live.
+func (k kick) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	channel, getErr := k.channel(c, u)
+	if getErr != nil {
+		return Metadata{}, getErr
+	}
+	return parseKickChannel(channel), nil
+}
+
+// IsLive returns true if the channel has a current livestream.
+func (k kick) IsLive(c *utils.Client, u *url.URL) (bool, error) {
+	channel, getErr := k.channel(c, u)
+	if getErr != nil {
+		return false, getErr
+	}
+	return parseKickLive(channel), nil
+}
+
+// channel returns the JSON response of the channel API for the channel in the URL.
+func (kick) channel(c *utils.Client, u *url.URL) (string, error) {
+	parts := pathParts(u)
+	if len(parts) == 0 {
+		return "", ErrLiveUnsupported
+	}
+	body, getErr := c.GetBody("https://kick.com/api/v2/channels/" +
+		url.PathEscape(strings.ToLower(parts[0])))
+	if getErr != nil {
+		return "", getErr
+	}
+	return string(body), nil
+}
+
+// parseKickChannel returns the metadata from a channel API response.
+func parseKickChannel(channel string) Metadata {
+	return Metadata{
+		Title: firstSet(gjson.Get(channel, "livestream.session_title").String(),
+			gjson.Get(channel, "user.username").String()),
+		Thumbnail: firstSet(gjson.Get(channel, "livestream.thumbnail.url").String(),
+			gjson.Get(channel, "user.profile_pic").String()),
+	}
+}
+
+// parseKickLive returns true if a channel API response has a current livestream.
+func parseKickLive(channel string) bool {
+	livestream := gjson.Get(channel, "livestream")
+	return livestream.Exists() && livestream.Type != gjson.Null
+}
@@ -0,0 +1,59 @@
+/*
+opengraph.go contains the OpenGraph provider, the fallback for URLs that no other
+provider matches. It reads the title and image from the OpenGraph and Twitter card meta
+tags of the page.
+*/
+package providers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gamestreams/utils"
+)
+
+// openGraph resolves any web page using its OpenGraph meta tags.
+type openGraph struct{}
+
+// Name returns the name of the provider.
+func (openGraph) Name() string {
+	return "OpenGraph"
+}
+
+// Match returns true for every URL.
+func (openGraph) Match(u *url.URL) bool {
+	return true
+}
+
+// Canonical returns the URL without its fragment.
+func (openGraph) Canonical(u *url.URL) string {
+	canonical := *u
+	canonical.Fragment = ""
+	return canonical.String()
+}
+
+// DirectURL returns the canonical URL, as generic pages have no separate stream link.
+func (o openGraph) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return o.Canonical(u), nil
+}
+
+// Metadata returns the title and image of the page from its meta tags.
+func (o openGraph) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	doc, getErr := c.GetHTMLBody(o.Canonical(u))
+	if getErr != nil {
+		return Metadata{}, getErr
+	}
+	return parseOpenGraph(doc), nil
+}
+
+// parseOpenGraph returns the title and image of the page from its OpenGraph or Twitter
+// card meta tags, falling back to the title element.
+func parseOpenGraph(doc *goquery.Document) Metadata {
+	return Metadata{
+		Title: firstSet(metaContent(doc, "og:title", "twitter:title"),
+			strings.TrimSpace(doc.Find("title").First().Text())),
+		Thumbnail: metaContent(doc, "og:image", "og:image:url", "twitter:image"),
+	}
+}
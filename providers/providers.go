@@ -0,0 +1,248 @@
+/*
+providers.go contains the Provider interface and the registry of providers used to
+resolve metadata for stream URLs. Each provider handles the URLs of one platform, with
+the OpenGraph provider used as a fallback for any URL that no other provider matches.
+*/
+package providers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gamestreams/logs"
+	"gamestreams/utils"
+)
+
+// ErrLiveUnsupported is returned when the live status of a URL cannot be checked.
+var ErrLiveUnsupported = errors.New("live status checks are not supported for this URL")
+
+// Provider resolves information about the stream URLs of a single platform.
+type Provider interface {
+	// Name returns the name of the platform, e.g. YouTube.
+	Name() string
+	// Match returns true if the provider handles the URL.
+	Match(u *url.URL) bool
+	// Canonical returns the canonical form of the URL, removing tracking parameters
+	// and normalising the host. It does not make any requests.
+	Canonical(u *url.URL) string
+	// DirectURL returns a link to the stream itself rather than a channel or profile,
+	// so the link still points at the stream after it has ended. Pages are fetched with
+	// the client.
+	DirectURL(c *utils.Client, u *url.URL) (string, error)
+	// Metadata returns the title and thumbnail of the stream, fetching its page with
+	// the client.
+	Metadata(c *utils.Client, u *url.URL) (Metadata, error)
+}
+
+// LiveChecker is implemented by providers that can tell whether a stream is live.
+type LiveChecker interface {
+	// IsLive returns true if the stream at the URL is currently live, fetching its page
+	// with the client.
+	IsLive(c *utils.Client, u *url.URL) (bool, error)
+}
+
+// Metadata holds the information about a stream read from its page.
+type Metadata struct {
+	// The title of the stream or page.
+	Title string
+	// The URL of the thumbnail image for the stream.
+	Thumbnail string
+}
+
+// registry holds the providers in the order they are matched. The OpenGraph provider is
+// not included as it is used when no other provider matches.
+var registry = []Provider{
+	youTube{},
+	twitch{},
+	facebook{},
+	kick{},
+	x{},
+	bilibili{},
+}
+
+// Register adds a provider to the registry. Providers registered later are matched
+// before the built-in providers.
+func Register(p Provider) {
+	registry = append([]Provider{p}, registry...)
+}
+
+// For returns the provider for the URL and the parsed URL. If no provider matches, the
+// OpenGraph provider is returned.
+func For(rawURL string) (Provider, *url.URL, error) {
+	u, parseErr := url.Parse(strings.TrimSpace(rawURL))
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, nil, errors.New("URL must be absolute")
+	}
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	for _, p := range registry {
+		if p.Match(u) {
+			return p, u, nil
+		}
+	}
+	return openGraph{}, u, nil
+}
+
+// Canonicalise returns the canonical form of the URL. If the URL cannot be parsed it is
+// returned unchanged.
+func Canonicalise(rawURL string) string {
+	p, u, parseErr := For(rawURL)
+	if parseErr != nil {
+		return rawURL
+	}
+	return p.Canonical(u)
+}
+
+// Resolver resolves stream URLs with the registered providers.
+type Resolver struct {
+	// The client that pages are fetched with.
+	client *utils.Client
+	// The cache that resolved values are stored in, or nil if they are not cached.
+	cache Cache
+	// The logger that errors are logged with.
+	log *logs.Logger
+}
+
+// NewResolver returns a Resolver that fetches pages with the client and caches what it
+// finds in the cache, which may be nil.
+func NewResolver(client *utils.Client, cache Cache, log *logs.Logger) *Resolver {
+	return &Resolver{client: client, cache: cache, log: log}
+}
+
+// Direct returns a direct link to the stream at the URL. If a direct link cannot be
+// found, the canonical URL is returned. Direct links are cached by canonical URL.
+func (r *Resolver) Direct(rawURL string) string {
+	p, u, parseErr := For(rawURL)
+	if parseErr != nil {
+		return rawURL
+	}
+	canonical := p.Canonical(u)
+	if direct, found := r.cacheGet(canonical, cacheDirect); found {
+		return direct
+	}
+	direct, directErr := p.DirectURL(r.client, u)
+	if directErr != nil || direct == "" {
+		if directErr != nil {
+			r.log.LogInfo("PRVDR", "could not get direct URL", false,
+				"provider", p.Name(),
+				"url", rawURL,
+				"err", directErr)
+		}
+		return canonical
+	}
+	r.cacheSet(canonical, cacheDirect, direct)
+	return direct
+}
+
+// Resolve returns the metadata of the stream at the URL. If the provider for the URL
+// fails, the page's OpenGraph tags are used instead. Metadata is cached by canonical
+// URL unless nothing could be found.
+func (r *Resolver) Resolve(rawURL string) Metadata {
+	p, u, parseErr := For(rawURL)
+	if parseErr != nil {
+		return Metadata{}
+	}
+	canonical := p.Canonical(u)
+	if metadata, found := r.cachedMetadata(canonical); found {
+		return metadata
+	}
+	metadata, metaErr := p.Metadata(r.client, u)
+	if metaErr != nil {
+		r.log.LogInfo("PRVDR", "error getting metadata", false,
+			"provider", p.Name(),
+			"url", rawURL,
+			"err", metaErr)
+	}
+	if metadata.Thumbnail == "" || metadata.Title == "" {
+		if _, isOpenGraph := p.(openGraph); !isOpenGraph {
+			fallback, _ := openGraph{}.Metadata(r.client, u)
+			metadata.Title = firstSet(metadata.Title, fallback.Title)
+			metadata.Thumbnail = firstSet(metadata.Thumbnail, fallback.Thumbnail)
+		}
+	}
+	if metadata.Title != "" || metadata.Thumbnail != "" {
+		r.cacheMetadataValue(canonical, metadata)
+	}
+	return metadata
+}
+
+// Thumbnail returns the URL of the thumbnail image for the stream at the URL.
+func (r *Resolver) Thumbnail(rawURL string) string {
+	return r.Resolve(rawURL).Thumbnail
+}
+
+// LiveSupported returns true if the live status of the stream at the URL can be
+// checked.
+func LiveSupported(rawURL string) bool {
+	p, _, parseErr := For(rawURL)
+	if parseErr != nil {
+		return false
+	}
+	_, supported := p.(LiveChecker)
+	return supported
+}
+
+// Live returns true if the stream at the URL is currently live.
+func (r *Resolver) Live(rawURL string) (bool, error) {
+	p, u, parseErr := For(rawURL)
+	if parseErr != nil {
+		return false, parseErr
+	}
+	checker, supported := p.(LiveChecker)
+	if !supported {
+		return false, ErrLiveUnsupported
+	}
+	return checker.IsLive(r.client, u)
+}
+
+// stripURL returns the URL with the given host and path and no query or fragment.
+func stripURL(host string, path string) string {
+	return (&url.URL{Scheme: "https", Host: host, Path: path}).String()
+}
+
+// pathParts returns the non-empty parts of the path of the URL.
+func pathParts(u *url.URL) []string {
+	var parts []string
+	for _, part := range strings.Split(u.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// metaContent returns the content of the first meta tag in the document whose property
+// or name attribute is one of the given keys, in the order the keys are given.
+func metaContent(doc *goquery.Document, keys ...string) string {
+	for _, key := range keys {
+		var content string
+		doc.Find("meta").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			property, _ := s.Attr("property")
+			name, _ := s.Attr("name")
+			if property == key || name == key {
+				content, _ = s.Attr("content")
+				return false
+			}
+			return true
+		})
+		if content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// firstSet returns the first of the given values that is not empty.
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gamestreams/config"
+	"gamestreams/logs"
+	"gamestreams/utils"
+)
+
+// fixture returns the contents of the recorded page in testdata with the name.
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	contents, readErr := os.ReadFile(filepath.Join("testdata", name))
+	if readErr != nil {
+		t.Fatalf("error reading fixture: %v", readErr)
+	}
+	return string(contents)
+}
+
+// fixtureDocument returns the recorded page in testdata with the name as a document.
+func fixtureDocument(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	doc, parseErr := goquery.NewDocumentFromReader(strings.NewReader(fixture(t, name)))
+	if parseErr != nil {
+		t.Fatalf("error parsing fixture: %v", parseErr)
+	}
+	return doc
+}
+
+func TestCanonicalise(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		// YouTube
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ&si=tracking&t=30", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ?si=tracking", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ?feature=share", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/@Xbox/live?si=tracking", "https://www.youtube.com/@Xbox/live"},
+		{"https://WWW.YouTube.com/@Xbox", "https://www.youtube.com/@Xbox"},
+		// Twitch
+		{"https://twitch.tv/Nintendo?referrer=raid", "https://www.twitch.tv/nintendo"},
+		{"https://m.twitch.tv/nintendo/videos", "https://www.twitch.tv/nintendo"},
+		{"https://www.twitch.tv/videos/2012345678?t=1h", "https://www.twitch.tv/videos/2012345678"},
+		{"https://www.twitch.tv/", "https://www.twitch.tv/"},
+		// Facebook
+		{"https://m.facebook.com/PlayStation/videos/1234567890/?mibextid=abc", "https://www.facebook.com/PlayStation/videos/1234567890/"},
+		{"https://www.facebook.com/watch/?v=1234567890&ref=sharing", "https://www.facebook.com/watch/?v=1234567890"},
+		{"https://fb.watch/abcDEF123/?mibextid=abc", "https://fb.watch/abcDEF123/"},
+		// Kick
+		{"https://kick.com/Gamescom?ref=home", "https://kick.com/gamescom"},
+		{"https://www.kick.com/gamescom/videos/123", "https://kick.com/gamescom"},
+		{"https://kick.com", "https://kick.com/"},
+		// X
+		{"https://twitter.com/Xbox/status/1798765432101234567?s=20", "https://x.com/Xbox/status/1798765432101234567"},
+		{"https://mobile.twitter.com/i/broadcasts/1ypKdAbCdEfGh", "https://x.com/i/broadcasts/1ypKdAbCdEfGh"},
+		{"https://www.x.com/Xbox", "https://x.com/Xbox"},
+		// Bilibili
+		{"https://live.bilibili.com/21452505?broadcast_type=0&spm_id_from=333", "https://live.bilibili.com/21452505"},
+		{"https://m.bilibili.com/video/BV1xx411c7mD?share_source=copy", "https://www.bilibili.com/video/BV1xx411c7mD"},
+		{"https://b23.tv/AbCdEfG", "https://b23.tv/AbCdEfG"},
+		// OpenGraph
+		{"https://www.futuregamesshow.com/watch?lang=en#schedule", "https://futuregamesshow.com/watch?lang=en"},
+		// Not absolute, returned unchanged.
+		{"www.youtube.com/@Xbox", "www.youtube.com/@Xbox"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := Canonicalise(tt.url); got != tt.want {
+				t.Errorf("Canonicalise(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.youtube.com/@Xbox/live", "YouTube"},
+		{"https://youtu.be/dQw4w9WgXcQ", "YouTube"},
+		{"https://www.twitch.tv/nintendo", "Twitch"},
+		{"https://fb.watch/abcDEF123/", "Facebook"},
+		{"https://kick.com/gamescom", "Kick"},
+		{"https://twitter.com/Xbox", "X"},
+		{"https://live.bilibili.com/21452505", "Bilibili"},
+		{"https://www.futuregamesshow.com/", "OpenGraph"},
+		{"https://notyoutube.com/watch?v=dQw4w9WgXcQ", "OpenGraph"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			p, _, forErr := For(tt.url)
+			if forErr != nil {
+				t.Fatalf("For(%q) error = %v", tt.url, forErr)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("For(%q) = %s, want %s", tt.url, p.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestYouTubeVideoDirectURL(t *testing.T) {
+	// Video links are resolved without fetching the page.
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://youtu.be/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ?si=tracking", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube.com/watch?v=a%26b", "https://www.youtube.com/watch?v=a%26b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, parseErr := url.Parse(tt.url)
+			if parseErr != nil {
+				t.Fatal(parseErr)
+			}
+			direct, directErr := youTube{}.DirectURL(nil, u)
+			if directErr != nil || direct != tt.want {
+				t.Errorf("DirectURL(%q) = %q, %v, want %q", tt.url, direct, directErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYouTubeDirectURL(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{"youtube_channel.html", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"youtube_channel_offline.html", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := parseYouTubeDirectURL(fixtureDocument(t, tt.fixture)); got != tt.want {
+				t.Errorf("parseYouTubeDirectURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLive(t *testing.T) {
+	tests := []struct {
+		fixture string
+		parse   func(string) bool
+		want    bool
+	}{
+		{"youtube_watch_live.html", parseYouTubeLive, true},
+		{"youtube_watch_upcoming.html", parseYouTubeLive, false},
+		{"youtube_channel.html", parseYouTubeLive, false},
+		{"twitch_channel_live.html", parseTwitchLive, true},
+		{"twitch_channel_offline.html", parseTwitchLive, false},
+		{"kick_channel_live.json", parseKickLive, true},
+		{"kick_channel_offline.json", parseKickLive, false},
+		{"bilibili_room_live.json", parseBilibiliLive, true},
+		{"bilibili_room_offline.json", parseBilibiliLive, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := tt.parse(fixture(t, tt.fixture)); got != tt.want {
+				t.Errorf("live = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOpenGraph(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    Metadata
+	}{
+		{"youtube_channel.html", Metadata{
+			Title:     "Xbox Games Showcase 2026",
+			Thumbnail: "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault_live.jpg",
+		}},
+		{"twitch_channel_live.html", Metadata{
+			Title:     "Nintendo - Twitch",
+			Thumbnail: "https://static-cdn.jtvnw.net/jtv_user_pictures/nintendo-profile_image-300x300.png",
+		}},
+		{"facebook_video.html", Metadata{
+			Title:     "PlayStation Showcase",
+			Thumbnail: "https://scontent.xx.fbcdn.net/v/t15.5256-10/playstation-showcase.jpg",
+		}},
+		{"facebook_login.html", Metadata{Title: "Log in to Facebook"}},
+		// X only serves Twitter card tags.
+		{"x_post.html", Metadata{
+			Title:     "Xbox on X",
+			Thumbnail: "https://pbs.twimg.com/media/xbox-showcase.jpg:large",
+		}},
+		{"bilibili_video.html", Metadata{
+			Title:     "Nintendo Direct 2026.9.12_哔哩哔哩_bilibili",
+			Thumbnail: "http://i0.hdslb.com/bfs/archive/nintendo-direct.jpg",
+		}},
+		// OpenGraph tags are preferred to Twitter card tags.
+		{"opengraph.html", Metadata{
+			Title:     "Future Games Show Summer Showcase",
+			Thumbnail: "https://www.futuregamesshow.com/images/summer-showcase.jpg",
+		}},
+		{"title_only.html", Metadata{Title: "Devolver Direct 2026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := parseOpenGraph(fixtureDocument(t, tt.fixture)); got != tt.want {
+				t.Errorf("parseOpenGraph() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAPIMetadata(t *testing.T) {
+	tests := []struct {
+		fixture string
+		parse   func(string) Metadata
+		want    Metadata
+	}{
+		{"kick_channel_live.json", parseKickChannel, Metadata{
+			Title:     "gamescom Opening Night Live 2026",
+			Thumbnail: "https://images.kick.com/video_thumbnails/gamescom/opening-night-live/fullsize.webp",
+		}},
+		// Offline channels use the channel name and profile picture.
+		{"kick_channel_offline.json", parseKickChannel, Metadata{
+			Title:     "gamescom",
+			Thumbnail: "https://files.kick.com/images/user/4612345/profile_image/conversion/gamescom-fullsize.webp",
+		}},
+		{"bilibili_room_live.json", parseBilibiliRoom, Metadata{
+			Title:     "Nintendo Direct 2026.9.12 同传",
+			Thumbnail: "https://i0.hdslb.com/bfs/live/new_room_cover/nintendo-direct.jpg",
+		}},
+		// Rooms without a cover use the latest key frame.
+		{"bilibili_room_offline.json", parseBilibiliRoom, Metadata{
+			Title:     "Nintendo Direct 2026.9.12 同传",
+			Thumbnail: "https://i0.hdslb.com/bfs/live-key-frame/keyframe21452505.jpg",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := tt.parse(fixture(t, tt.fixture)); got != tt.want {
+				t.Errorf("metadata = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubProvider is a provider for the host of a test server that returns fixed metadata.
+type stubProvider struct {
+	// The host the provider matches.
+	host string
+	// The metadata returned by the provider.
+	metadata Metadata
+	// The error returned by the provider.
+	err error
+}
+
+func (stubProvider) Name() string                { return "Stub" }
+func (s stubProvider) Match(u *url.URL) bool     { return u.Host == s.host }
+func (stubProvider) Canonical(u *url.URL) string { return u.String() }
+func (stubProvider) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return u.String(), nil
+}
+func (s stubProvider) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	return s.metadata, s.err
+}
+
+func TestResolveOpenGraphFallback(t *testing.T) {
+	cfg := &config.Config{}
+	log := logs.New(cfg.Logs)
+	r := NewResolver(utils.NewClient(context.Background(), func() *config.Config { return cfg }, log), nil, log)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(fixture(t, strings.TrimPrefix(r.URL.Path, "/"))))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name     string
+		metadata Metadata
+		err      error
+		page     string
+		want     Metadata
+	}{
+		{
+			name:     "provider has everything",
+			metadata: Metadata{Title: "Provider title", Thumbnail: "https://example.com/provider.jpg"},
+			page:     "opengraph.html",
+			want:     Metadata{Title: "Provider title", Thumbnail: "https://example.com/provider.jpg"},
+		},
+		{
+			name:     "provider has no thumbnail",
+			metadata: Metadata{Title: "Provider title"},
+			page:     "opengraph.html",
+			want: Metadata{
+				Title:     "Provider title",
+				Thumbnail: "https://www.futuregamesshow.com/images/summer-showcase.jpg",
+			},
+		},
+		{
+			name: "provider failed",
+			err:  errors.New("blocked"),
+			page: "x_post.html",
+			want: Metadata{
+				Title:     "Xbox on X",
+				Thumbnail: "https://pbs.twimg.com/media/xbox-showcase.jpg:large",
+			},
+		},
+		{
+			name: "page has only a title",
+			err:  errors.New("blocked"),
+			page: "title_only.html",
+			want: Metadata{Title: "Devolver Direct 2026"},
+		},
+	}
+	builtIn := registry
+	defer func() { registry = builtIn }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry = builtIn
+			Register(stubProvider{host: host, metadata: tt.metadata, err: tt.err})
+			if got := r.Resolve(server.URL + "/" + tt.page); got != tt.want {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Pages that no provider matches are read by the OpenGraph provider itself.
+	registry = builtIn
+	want := Metadata{
+		Title:     "Future Games Show Summer Showcase",
+		Thumbnail: "https://www.futuregamesshow.com/images/summer-showcase.jpg",
+	}
+	if got := r.Resolve(server.URL + "/opengraph.html"); got != want {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+	if got := r.Thumbnail(server.URL + "/opengraph.html"); got != want.Thumbnail {
+		t.Errorf("Thumbnail() = %q, want %q", got, want.Thumbnail)
+	}
+}
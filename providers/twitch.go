@@ -0,0 +1,65 @@
+/*
+twitch.go contains the Twitch provider. Twitch channel URLs always point at the current
+stream, so the channel URL is used as the direct link.
+*/
+package providers
+
+import (
+	"net/url"
+	"strings"
+
+	"gamestreams/utils"
+)
+
+// twitch resolves twitch.tv URLs.
+type twitch struct{}
+
+// Name returns the name of the provider.
+func (twitch) Name() string {
+	return "Twitch"
+}
+
+// Match returns true for Twitch URLs.
+func (twitch) Match(u *url.URL) bool {
+	return u.Host == "twitch.tv" || u.Host == "m.twitch.tv"
+}
+
+// Canonical returns the channel URL with the channel name in lower case.
+func (twitch) Canonical(u *url.URL) string {
+	parts := pathParts(u)
+	if len(parts) == 0 {
+		return stripURL("www.twitch.tv", "/")
+	}
+	if parts[0] == "videos" && len(parts) > 1 {
+		return stripURL("www.twitch.tv", "/videos/"+parts[1])
+	}
+	return stripURL("www.twitch.tv", "/"+strings.ToLower(parts[0]))
+}
+
+// DirectURL returns the canonical channel URL.
+func (t twitch) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return t.Canonical(u), nil
+}
+
+// Metadata returns the title of the channel page and the channel's profile picture.
+func (t twitch) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	doc, getErr := c.GetHTMLBody(t.Canonical(u))
+	if getErr != nil {
+		return Metadata{}, getErr
+	}
+	return parseOpenGraph(doc), nil
+}
+
+// IsLive returns true if the channel page contains a live broadcast event.
+func (t twitch) IsLive(c *utils.Client, u *url.URL) (bool, error) {
+	source, getErr := c.GetBody(t.Canonical(u))
+	if getErr != nil {
+		return false, getErr
+	}
+	return parseTwitchLive(string(source)), nil
+}
+
+// parseTwitchLive returns true if the page source contains a live broadcast event.
+func parseTwitchLive(source string) bool {
+	return strings.Contains(strings.ReplaceAll(source, " ", ""), `"isLiveBroadcast":true`)
+}
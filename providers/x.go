@@ -0,0 +1,45 @@
+/*
+x.go contains the X (formerly Twitter) provider. X pages require JavaScript, so metadata
+is read from the OpenGraph tags served to link preview crawlers.
+*/
+package providers
+
+import (
+	"net/url"
+
+	"gamestreams/utils"
+)
+
+// x resolves x.com and twitter.com URLs.
+type x struct{}
+
+// Name returns the name of the provider.
+func (x) Name() string {
+	return "X"
+}
+
+// Match returns true for X and Twitter URLs.
+func (x) Match(u *url.URL) bool {
+	return u.Host == "x.com" ||
+		u.Host == "twitter.com" ||
+		u.Host == "mobile.twitter.com"
+}
+
+// Canonical returns the URL on x.com without its query.
+func (x) Canonical(u *url.URL) string {
+	return stripURL("x.com", u.Path)
+}
+
+// DirectURL returns the canonical URL.
+func (p x) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	return p.Canonical(u), nil
+}
+
+// Metadata returns the title and image of the post or broadcast.
+func (p x) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	doc, getErr := c.GetHTMLBody(p.Canonical(u))
+	if getErr != nil {
+		return Metadata{}, getErr
+	}
+	return parseOpenGraph(doc), nil
+}
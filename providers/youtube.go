@@ -0,0 +1,124 @@
+/*
+youtube.go contains the YouTube provider. Channel /live URLs are resolved to the video
+of the current or upcoming stream so the link still works after the stream has ended.
+*/
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gamestreams/utils"
+)
+
+// youTube resolves youtube.com and youtu.be URLs.
+type youTube struct{}
+
+// Name returns the name of the provider.
+func (youTube) Name() string {
+	return "YouTube"
+}
+
+// Match returns true for YouTube URLs.
+func (youTube) Match(u *url.URL) bool {
+	return u.Host == "youtube.com" ||
+		u.Host == "m.youtube.com" ||
+		u.Host == "youtu.be"
+}
+
+// Canonical returns a watch URL for video links and the channel URL for channel links.
+func (y youTube) Canonical(u *url.URL) string {
+	if id := y.videoID(u); id != "" {
+		return watchURL(id)
+	}
+	return stripURL("www.youtube.com", u.Path)
+}
+
+// DirectURL returns the watch URL of the video. For channel URLs the page is fetched to
+// find the video of the current or upcoming stream.
+func (y youTube) DirectURL(c *utils.Client, u *url.URL) (string, error) {
+	if id := y.videoID(u); id != "" {
+		return watchURL(id), nil
+	}
+	doc, getErr := c.GetHTMLBody(y.Canonical(u))
+	if getErr != nil {
+		return "", getErr
+	}
+	return parseYouTubeDirectURL(doc), nil
+}
+
+// Metadata returns the title of the video and its thumbnail.
+func (y youTube) Metadata(c *utils.Client, u *url.URL) (Metadata, error) {
+	direct, directErr := y.DirectURL(c, u)
+	if directErr != nil {
+		return Metadata{}, directErr
+	}
+	var metadata Metadata
+	if directURL, parseErr := url.Parse(direct); parseErr == nil {
+		if id := y.videoID(directURL); id != "" {
+			metadata.Thumbnail = fmt.Sprintf("https://img.youtube.com/vi/%s/maxresdefault.jpg", id)
+		}
+	}
+	doc, getErr := c.GetHTMLBody(firstSet(direct, y.Canonical(u)))
+	if getErr != nil {
+		return metadata, getErr
+	}
+	og := parseOpenGraph(doc)
+	metadata.Title = og.Title
+	metadata.Thumbnail = firstSet(metadata.Thumbnail, og.Thumbnail)
+	return metadata, nil
+}
+
+// IsLive returns true if the page marks the video as live now.
+func (y youTube) IsLive(c *utils.Client, u *url.URL) (bool, error) {
+	source, getErr := c.GetBody(y.Canonical(u))
+	if getErr != nil {
+		return false, getErr
+	}
+	return parseYouTubeLive(string(source)), nil
+}
+
+// videoID returns the ID of the video in the URL, or an empty string if the URL is not
+// a video link.
+func (youTube) videoID(u *url.URL) string {
+	parts := pathParts(u)
+	if u.Host == "youtu.be" && len(parts) > 0 {
+		return parts[0]
+	}
+	if id := u.Query().Get("v"); id != "" {
+		return id
+	}
+	// youtube.com/live/<id> links to a stream, youtube.com/@channel/live does not.
+	if len(parts) == 2 && (parts[0] == "live" || parts[0] == "shorts") {
+		return parts[1]
+	}
+	return ""
+}
+
+// watchURL returns the watch URL of the video with the given ID.
+func watchURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + url.QueryEscape(id)
+}
+
+// parseYouTubeDirectURL returns the first video link in the head of a YouTube channel
+// page, which is the canonical link of the current or upcoming stream.
+func parseYouTubeDirectURL(doc *goquery.Document) string {
+	var directURL string
+	doc.Find("link").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+		if strings.Contains(href, "?v=") {
+			directURL = href
+			return false
+		}
+		return true
+	})
+	return directURL
+}
+
+// parseYouTubeLive returns true if the page source marks the video as live now.
+func parseYouTubeLive(source string) bool {
+	return strings.Contains(strings.ReplaceAll(source, " ", ""), `"isLiveNow":true`)
+}
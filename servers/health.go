@@ -0,0 +1,82 @@
+/*
+health.go provides functions for checking that the announcement settings of each server
+are still usable by the bot.
+*/
+package servers
+
+import (
+	"strings"
+
+	"gamestreams/app"
+	"gamestreams/discord"
+	"gamestreams/locales"
+)
+
+// CheckAnnounceChannels checks the announce channel and role of every server that has
+// an announce channel set. If the bot is no longer able to post in the channel, because
+// it has been deleted or permissions have changed, the server owner is sent a DM in the
+// server's locale explaining the problem and how to fix it. The result of each check is recorded, so the
+// owner is only sent a DM when the channel becomes unusable rather than on every check.
+func CheckAnnounceChannels(a *app.App) {
+	session := a.Session
+	settingsList, getErr := a.DB.GetAnnounceSettings()
+	if getErr != nil {
+		a.Log.LogError("HLTH ", "error getting announce settings",
+			"err", getErr)
+		return
+	}
+	var unusable int
+	for _, settings := range settingsList {
+		check := discord.CheckChannel(session, settings.ServerID, settings.AnnounceChannel.Value)
+		if check.Usable() {
+			if setErr := a.DB.SetChannelUsable(settings.ServerID); setErr != nil {
+				a.Log.LogError("HLTH ", "error recording channel health",
+					"server", settings.ServerID,
+					"err", setErr)
+			}
+			continue
+		}
+		unusable++
+		changed, setErr := a.DB.SetChannelUnusable(settings.ServerID)
+		if setErr != nil {
+			a.Log.LogError("HLTH ", "error recording channel health",
+				"server", settings.ServerID,
+				"err", setErr)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		locale, localeErr := a.DB.GetServerLocale(settings.ServerID)
+		if localeErr != nil {
+			a.Log.LogError("HLTH ", "error getting server locale",
+				"server", settings.ServerID,
+				"err", localeErr)
+		}
+		warnings := check.Warnings(locale)
+		if settings.AnnounceRole.Value != "" {
+			if roleWarning := discord.CheckRole(session, settings.ServerID,
+				settings.AnnounceChannel.Value, settings.AnnounceRole.Value, locale); roleWarning != "" {
+				warnings = append(warnings, roleWarning)
+			}
+		}
+		a.Log.LogInfo("HLTH ", "announce channel unusable", false,
+			"server", settings.ServerID,
+			"channel", settings.AnnounceChannel.Value)
+
+		ownerID := GetServerOwner(a, session, settings.ServerID)
+		if ownerID == "" {
+			continue
+		}
+		dmErr := discord.DM(session, ownerID, locales.T(locale, "server.unusable",
+			GetServerName(a, session, settings.ServerID), strings.Join(warnings, "\n- ")))
+		if dmErr != nil {
+			a.Log.LogInfo("HLTH ", "error sending unusable channel DM", false,
+				"user", ownerID,
+				"err", dmErr)
+		}
+	}
+	a.Log.LogInfo("HLTH ", "checked announce channels", false,
+		"checked", len(settingsList),
+		"unusable", unusable)
+}
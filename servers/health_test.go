@@ -0,0 +1,115 @@
+package servers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/config"
+	"gamestreams/db"
+	"gamestreams/discordtest"
+	"gamestreams/logs"
+)
+
+const (
+	testGuildID       = "400000000000000001"
+	testOwnerID       = "400000000000000002"
+	testTextChannel   = "400000000000000010"
+	testLockedChannel = "400000000000000011"
+)
+
+func TestCheckAnnounceChannelsOnlyWarnsOnce(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Files.Database = filepath.Join(t.TempDir(), "test.db")
+	server := discordtest.New()
+	t.Cleanup(server.Close)
+	session, sessionErr := server.Session()
+	if sessionErr != nil {
+		t.Fatalf("Session() error = %v", sessionErr)
+	}
+	t.Cleanup(func() { session.Close() })
+	a := app.New(cfg, logs.New(cfg.Logs))
+	if openErr := a.OpenDB(); openErr != nil {
+		t.Fatalf("OpenDB() error = %v", openErr)
+	}
+	a.Session = session
+
+	usable := int64(discordgo.PermissionViewChannel | discordgo.PermissionSendMessages |
+		discordgo.PermissionEmbedLinks)
+	guild := &discordgo.Guild{
+		ID:      testGuildID,
+		Name:    "Test Server",
+		OwnerID: testOwnerID,
+		Roles: []*discordgo.Role{
+			{ID: testGuildID, Name: "@everyone", Permissions: usable},
+		},
+		Members: []*discordgo.Member{
+			{GuildID: testGuildID, User: server.User},
+		},
+		Channels: []*discordgo.Channel{
+			{ID: testTextChannel, Name: "streams", Type: discordgo.ChannelTypeGuildText},
+			{ID: testLockedChannel, Name: "locked", Type: discordgo.ChannelTypeGuildText,
+				PermissionOverwrites: []*discordgo.PermissionOverwrite{{
+					ID:   testGuildID,
+					Type: discordgo.PermissionOverwriteTypeRole,
+					Deny: discordgo.PermissionSendMessages,
+				}}},
+		},
+	}
+	if addErr := server.AddGuild(guild); addErr != nil {
+		t.Fatalf("AddGuild() error = %v", addErr)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, stateErr := session.State.Guild(testGuildID); stateErr == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the server to be added to the session state")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if serverErr := a.DB.NewServer(testGuildID, "Test Server", testOwnerID, time.Now().UTC(),
+		2, "en-US"); serverErr != nil {
+		t.Fatalf("a.DB.NewServer() error = %v", serverErr)
+	}
+	setChannel := func(channelID string) {
+		t.Helper()
+		settings := db.NewSettings(testGuildID)
+		settings.AnnounceChannel = db.StringSet{Value: channelID, Set: true}
+		if setErr := settings.Set(a.DB); setErr != nil {
+			t.Fatalf("Set() error = %v", setErr)
+		}
+	}
+	dmChannel := server.DMChannel(testOwnerID).ID
+	checkDMs := func(want int) {
+		t.Helper()
+		CheckAnnounceChannels(a)
+		if got := len(server.Messages(dmChannel)); got != want {
+			t.Errorf("owner was sent %d DMs, want %d", got, want)
+		}
+	}
+
+	setChannel(testTextChannel)
+	checkDMs(0)
+
+	setChannel(testLockedChannel)
+	checkDMs(1)
+	// The channel is still unusable, so the owner has already been told.
+	checkDMs(1)
+
+	// The owner is told again once the channel has been usable and breaks again.
+	setChannel(testTextChannel)
+	checkDMs(1)
+	setChannel(testLockedChannel)
+	checkDMs(2)
+
+	// Saving a usable channel with /settings resets the state.
+	if setErr := a.DB.SetChannelUsable(testGuildID); setErr != nil {
+		t.Fatalf("SetChannelUsable() error = %v", setErr)
+	}
+	checkDMs(3)
+}
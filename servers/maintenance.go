@@ -9,9 +9,8 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
+	"gamestreams/app"
 	"gamestreams/db"
-	"gamestreams/discord"
-	"gamestreams/logs"
 )
 
 // ServerMaintenance performs maintenance on the servers table.
@@ -19,32 +18,33 @@ import (
 // leaves blacklisted servers, adds servers that are in the Discord list but
 // not in the servers table, removes servers that are in the table but not in
 // the Discord list, and adds missing columns to the servers table.
-func ServerMaintenance(session *discordgo.Session) {
+func ServerMaintenance(a *app.App) {
+	session := a.Session
 	servers := session.State.Guilds
 	// add servers that are in the discord list but not in the servers table
 	// remove blacklisted servers
 	for _, server := range servers {
 		// check if server is blacklisted
-		if leaveErr := LeaveIfBlacklisted(session, server.ID, nil); leaveErr != nil {
-			logs.LogError("SERVR", "error leaving blacklisted server",
+		if leaveErr := LeaveIfBlacklisted(a, session, server.ID, nil); leaveErr != nil {
+			a.Log.LogError("SERVR", "error leaving blacklisted server",
 				"server", server.Name,
 				"err", leaveErr)
 			return
 		}
 		// check if server ID is in the servers table
-		present, checkErr := db.CheckServerID(server.ID)
+		present, checkErr := a.DB.CheckServerID(server.ID)
 		if checkErr != nil {
-			logs.LogError("SERVR", "error checking server ID",
+			a.Log.LogError("SERVR", "error checking server ID",
 				"err", checkErr)
 			return
 		}
 		if !present {
-			logs.LogInfo("SERVR", "adding server to database", false,
+			a.Log.LogInfo("SERVR", "adding server to database", false,
 				"server", server.Name)
 
-			newErr := db.NewServer(server.ID, server.Name, server.OwnerID, server.JoinedAt, server.MemberCount, server.PreferredLocale)
+			newErr := a.DB.NewServer(server.ID, server.Name, server.OwnerID, server.JoinedAt, server.MemberCount, server.PreferredLocale)
 			if newErr != nil {
-				logs.LogError("SERVR", "error adding server to database",
+				a.Log.LogError("SERVR", "error adding server to database",
 					"server", server.Name,
 					"err", newErr)
 				return
@@ -53,70 +53,70 @@ func ServerMaintenance(session *discordgo.Session) {
 	}
 
 	// remove servers that are in the table but not in the discord list
-	if removeErr := RemoveOldServerIDs(session); removeErr != nil {
-		logs.LogError("SERVR", "error removing old server IDs",
+	if removeErr := RemoveOldServerIDs(a, session); removeErr != nil {
+		a.Log.LogError("SERVR", "error removing old server IDs",
 			"err", removeErr)
 		return
 	}
 
 	// check for servers that have missing columns in the servers table
-	serverIDs, checkErr := db.CheckServerColumns()
+	serverIDs, checkErr := a.DB.CheckServerColumns()
 	if checkErr != nil {
-		logs.LogError("SERVR", "error checking server columns",
+		a.Log.LogError("SERVR", "error checking server columns",
 			"err", checkErr)
 		return
 	}
 
 	// add missing columns
 	if len(serverIDs) > 0 {
-		logs.LogInfo("SERVR", "adding missing columns and updating member counts for servers", false,
+		a.Log.LogInfo("SERVR", "adding missing columns and updating member counts for servers", false,
 			"servers", serverIDs)
 
 		for _, serverID := range serverIDs {
 			s := db.Server{
 				ID: serverID,
 			}
-			s.Get()
+			s.Get(a.DB)
 			if s.Name == "" {
-				s.Name = GetServerName(serverID)
+				s.Name = GetServerName(a, session, serverID)
 			}
 			if s.OwnerID == "" {
-				s.OwnerID = GetServerOwner(serverID)
+				s.OwnerID = GetServerOwner(a, session, serverID)
 			}
 			if s.DateJoined == "" {
-				dateJoined, dateErr := getDateJoined(serverID)
+				dateJoined, dateErr := getDateJoined(session, serverID)
 				if dateErr != nil {
-					logs.LogError("SERVR", "error getting date joined",
+					a.Log.LogError("SERVR", "error getting date joined",
 						"err", dateErr)
 				} else {
 					s.DateJoined = dateJoined
 				}
 			}
-			if !db.CheckSettings(serverID) {
+			if !a.DB.CheckSettings(serverID) {
 				s.Settings = db.NewSettings(serverID)
-				if setErr := s.Settings.Set(); setErr != nil {
-					logs.LogError("SERVR", "error setting server settings",
+				if setErr := s.Settings.Set(a.DB); setErr != nil {
+					a.Log.LogError("SERVR", "error setting server settings",
 						"err", setErr)
 				}
 			}
 			if s.Locale == "" {
-				locale, localeErr := getServerLocale(serverID)
+				locale, localeErr := getServerLocale(session, serverID)
 				if localeErr != nil {
-					logs.LogError("SERVR", "error getting server locale",
+					a.Log.LogError("SERVR", "error getting server locale",
 						"err", localeErr)
 				} else {
 					s.Locale = locale
 				}
 			}
-			memberCount, countErr := updateMemberCount(serverID)
+			memberCount, countErr := updateMemberCount(a, session, serverID)
 			if countErr != nil {
-				logs.LogError("SERVR", "error getting member count",
+				a.Log.LogError("SERVR", "error getting member count",
 					"err", countErr)
 			} else {
 				s.MemberCount = memberCount
 			}
-			if setErr := s.Set(); setErr != nil {
-				logs.LogError("SERVR", "error setting server columns",
+			if setErr := s.Set(a.DB); setErr != nil {
+				a.Log.LogError("SERVR", "error setting server columns",
 					"err", setErr)
 			}
 		}
@@ -125,10 +125,10 @@ func ServerMaintenance(session *discordgo.Session) {
 
 // updateMemberCount updates the member count of a server from its ID in the servers
 // table.
-func updateMemberCount(serverID string) (int, error) {
-	server, err := discord.Session.Guild(serverID)
+func updateMemberCount(a *app.App, session *discordgo.Session, serverID string) (int, error) {
+	server, err := session.Guild(serverID)
 	if err != nil {
-		logs.LogError("SERVR", "error getting server",
+		a.Log.LogError("SERVR", "error getting server",
 			"err", err)
 		return 0, err
 	}
@@ -136,8 +136,8 @@ func updateMemberCount(serverID string) (int, error) {
 }
 
 // getDateJoined returns the date a server was joined by the bot from its ID.
-func getDateJoined(serverID string) (string, error) {
-	server, err := discord.Session.Guild(serverID)
+func getDateJoined(session *discordgo.Session, serverID string) (string, error) {
+	server, err := session.Guild(serverID)
 	if err != nil {
 		return "", err
 	}
@@ -146,8 +146,8 @@ func getDateJoined(serverID string) (string, error) {
 }
 
 // getServerLocale returns the locale of a server from its ID.
-func getServerLocale(serverID string) (string, error) {
-	server, err := discord.Session.Guild(serverID)
+func getServerLocale(session *discordgo.Session, serverID string) (string, error) {
+	server, err := session.Guild(serverID)
 	if err != nil {
 		return "", err
 	}
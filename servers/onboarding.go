@@ -0,0 +1,57 @@
+/*
+onboarding.go provides functions for guiding servers through setting up the bot.
+*/
+package servers
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/discord"
+	"gamestreams/locales"
+)
+
+// RemindUnconfigured sends a DM to the owner of each server that has not set up the bot
+// within the number of days set in config.toml. The DM is written in the server's locale
+// and contains a button that launches the setup wizard. Each server is only reminded once.
+func RemindUnconfigured(a *app.App) {
+	days := a.Config().Onboarding.ReminderDays
+	if days <= 0 {
+		return
+	}
+	unconfigured, getErr := a.DB.GetUnconfiguredServers(days)
+	if getErr != nil {
+		a.Log.LogError("SERVR", "error getting unconfigured servers",
+			"err", getErr)
+		return
+	}
+	for _, server := range unconfigured {
+		if server.OwnerID == "" {
+			continue
+		}
+		a.Log.LogInfo("SERVR", "sending setup reminder", false,
+			"server", server.ID,
+			"owner", server.OwnerID)
+
+		locale, localeErr := a.DB.GetServerLocale(server.ID)
+		if localeErr != nil {
+			a.Log.LogError("SERVR", "error getting server locale",
+				"server", server.ID,
+				"err", localeErr)
+		}
+		if dmErr := discord.DMComplex(a.Session, server.OwnerID, &discordgo.MessageSend{
+			Content:    locales.T(locale, "server.reminder", server.Name, days),
+			Components: discord.SetupButton(server.ID, locales.T(locale, "setup.now")),
+		}); dmErr != nil {
+			// The owner may have DMs turned off, so they are not reminded again.
+			a.Log.LogInfo("SERVR", "error sending setup reminder", false,
+				"server", server.ID,
+				"err", dmErr)
+		}
+		if setErr := a.DB.SetSetupReminded(server.ID); setErr != nil {
+			a.Log.LogError("SERVR", "error recording setup reminder",
+				"server", server.ID,
+				"err", setErr)
+		}
+	}
+}
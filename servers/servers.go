@@ -4,13 +4,11 @@ servers.go provides functions for monitoring the servers the bot is in.
 package servers
 
 import (
-	"fmt"
-
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/db"
+	"gamestreams/app"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
 )
 
 // GetGuildNumber returns the number of servers the bot is in.
@@ -21,9 +19,9 @@ func GetGuildNumber(session *discordgo.Session) int {
 
 // logGuildNumber reports the number of servers the bot is in to the console and the
 // bot owner via DM.
-func logGuildNumber(session *discordgo.Session) {
+func logGuildNumber(a *app.App, session *discordgo.Session) {
 	guildNum := GetGuildNumber(session)
-	logs.LogInfo("SERVR", "connected", true,
+	a.Log.LogInfo("SERVR", "connected", true,
 		"server_count", guildNum)
 }
 
@@ -32,57 +30,64 @@ func logGuildNumber(session *discordgo.Session) {
 // already in the servers table of the database. If not, it adds the server to the
 // servers table with default options. When the bot is removed from a server, it
 // removes the server from the servers table.
-func MonitorGuilds(session *discordgo.Session) {
-	logGuildNumber(session)
-	logs.LogInfo("SERVR", "adding server join handler", false)
+func MonitorGuilds(a *app.App) {
+	session := a.Session
+	logGuildNumber(a, session)
+	a.Log.LogInfo("SERVR", "adding server join handler", false)
 
 	// join handler
 	session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildCreate) {
-		logs.LogInfo("SERVR", "joined server", false,
+		a.Log.LogInfo("SERVR", "joined server", false,
 			"server", e.Guild.Name,
 			"server_id", e.Guild.ID,
 			"owner", e.Guild.OwnerID)
-		logGuildNumber(s)
+		logGuildNumber(a, s)
 		// check if server is blacklisted
-		if leaveErr := LeaveIfBlacklisted(s, e.Guild.ID, e); leaveErr != nil {
-			logs.LogError("SERVR", "error leaving blacklisted server",
+		if leaveErr := LeaveIfBlacklisted(a, s, e.Guild.ID, e); leaveErr != nil {
+			a.Log.LogError("SERVR", "error leaving blacklisted server",
 				"server", e.Guild.Name,
 				"server_id", e.Guild.ID,
 				"err", leaveErr)
 			return
 		}
 		// check if server ID is in the servers table
-		present, checkErr := db.CheckServerID(e.Guild.ID)
+		present, checkErr := a.DB.CheckServerID(e.Guild.ID)
 		if checkErr != nil {
-			logs.LogError("SERVR", "error checking server ID",
+			a.Log.LogError("SERVR", "error checking server ID",
 				"err", checkErr)
 			return
 		}
 		if !present {
-			logs.LogInfo("SERVR", "adding server to database", false,
+			a.Log.LogInfo("SERVR", "adding server to database", false,
 				"server", e.Guild.Name)
 
-			discord.IntroDM(e.OwnerID)
+			a.Log.LogInfo("SERVR", "sending intro DM", false,
+				"user", e.OwnerID)
+			if dmErr := discord.IntroDM(s, e.OwnerID, e.Guild.ID, e.Guild.PreferredLocale); dmErr != nil {
+				a.Log.LogInfo("SERVR", "error sending intro DM", false,
+					"user", e.OwnerID,
+					"err", dmErr)
+			}
 
-			newErr := db.NewServer(e.Guild.ID, e.Guild.Name, e.Guild.OwnerID, e.Guild.JoinedAt, e.Guild.MemberCount, e.Guild.PreferredLocale)
+			newErr := a.DB.NewServer(e.Guild.ID, e.Guild.Name, e.Guild.OwnerID, e.Guild.JoinedAt, e.Guild.MemberCount, e.Guild.PreferredLocale)
 			if newErr != nil {
-				logs.LogError("SERVR", "error adding server to database",
+				a.Log.LogError("SERVR", "error adding server to database",
 					"server", e.Guild.Name,
 					"err", newErr)
 			}
 		}
 	})
-	logs.LogInfo("SERVR", "adding server leave handler", false)
+	a.Log.LogInfo("SERVR", "adding server leave handler", false)
 
 	// leave handler
 	session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildDelete) {
-		logs.LogInfo("SERVR", "left server", false,
+		a.Log.LogInfo("SERVR", "left server", false,
 			"server_id", e.Guild.ID)
 
-		logGuildNumber(s)
-		if removeErr := db.RemoveServer(e.Guild.ID); removeErr != nil {
-			logs.LogError("SERVR", "error removing server",
-				"server", GetServerName(e.Guild.ID),
+		logGuildNumber(a, s)
+		if removeErr := a.DB.RemoveServer(e.Guild.ID); removeErr != nil {
+			a.Log.LogError("SERVR", "error removing server",
+				"server", GetServerName(a, s, e.Guild.ID),
 				"server_id", e.Guild.ID,
 				"err", removeErr)
 		}
@@ -102,9 +107,9 @@ func GetAllServerIDsFromDiscord(session *discordgo.Session) []string {
 // RemoveOldServerIDs removes server IDs from the servers table that are not in the
 // Discord returned list of server IDs. This is for data cleanup in case the bot is
 // removed from a server and the server ID is not removed from the database.
-func RemoveOldServerIDs(session *discordgo.Session) error {
+func RemoveOldServerIDs(a *app.App, session *discordgo.Session) error {
 	discordServerIDs := GetAllServerIDsFromDiscord(session)
-	dbServerIDs, getErr := db.GetAllServerIDs()
+	dbServerIDs, getErr := a.DB.GetAllServerIDs()
 
 	if getErr != nil {
 		return getErr
@@ -118,9 +123,9 @@ func RemoveOldServerIDs(session *discordgo.Session) error {
 			}
 		}
 		if !found {
-			logs.LogInfo("SERVR", "removing old server ID", false,
+			a.Log.LogInfo("SERVR", "removing old server ID", false,
 				"server", dbID)
-			if removeErr := db.RemoveServer(dbID); removeErr != nil {
+			if removeErr := a.DB.RemoveServer(dbID); removeErr != nil {
 				return removeErr
 			}
 		}
@@ -129,13 +134,17 @@ func RemoveOldServerIDs(session *discordgo.Session) error {
 }
 
 // leaveServer leaves the server with the given server ID. It sends a DM to the server
-// owner with the reason the bot left the server.
-func leaveServer(session *discordgo.Session, serverID string, reason string, e *discordgo.GuildCreate) error {
-	logs.LogInfo("SERVR", "leaving server", true,
+// owner with the reason the bot left the server, in the server's preferred locale.
+func leaveServer(a *app.App, session *discordgo.Session, serverID string, reason string, e *discordgo.GuildCreate) error {
+	a.Log.LogInfo("SERVR", "leaving server", true,
 		"server", serverID,
 		"reason", reason)
 
-	discord.DM(e.OwnerID, fmt.Sprintf("I am leaving your server because: %s", reason))
+	if dmErr := discord.DM(session, e.OwnerID, locales.T(e.Guild.PreferredLocale, "server.leaving", reason)); dmErr != nil {
+		a.Log.LogInfo("SERVR", "error sending leave DM", false,
+			"user", e.OwnerID,
+			"err", dmErr)
+	}
 	if removeErr := session.GuildLeave(serverID); removeErr != nil {
 		return removeErr
 	}
@@ -145,31 +154,30 @@ func leaveServer(session *discordgo.Session, serverID string, reason string, e *
 
 // leaveIfBlacklisted checks if the server with the given server ID is blacklisted. If
 // it is, the bot leaves the server.
-func LeaveIfBlacklisted(session *discordgo.Session, serverID string, e *discordgo.GuildCreate) error {
-	blacklisted, b := db.IsBlacklisted(serverID)
+func LeaveIfBlacklisted(a *app.App, session *discordgo.Session, serverID string, e *discordgo.GuildCreate) error {
+	blacklisted, b := a.DB.IsBlacklisted(serverID)
 	if blacklisted {
-		return leaveServer(session, serverID, fmt.Sprintf("Server ID is blacklisted.\n\n"+
-			"**Reason:** `%s`\n**Expires:** `%s`",
+		return leaveServer(a, session, serverID, locales.T(e.Guild.PreferredLocale, "server.blacklisted",
 			b.Reason, b.DateExpires), e)
 	}
 	return nil
 }
 
 // GetServerName returns the name of a server from a server ID.
-func GetServerName(serverID string) string {
-	server, err := discord.Session.Guild(serverID)
+func GetServerName(a *app.App, session *discordgo.Session, serverID string) string {
+	server, err := session.Guild(serverID)
 	if err != nil {
-		logs.LogError("SERVR", "error getting server name", "err", err)
+		a.Log.LogError("SERVR", "error getting server name", "err", err)
 		return ""
 	}
 	return server.Name
 }
 
 // GetServerOwner returns the owner of a server from a server ID.
-func GetServerOwner(serverID string) string {
-	server, err := discord.Session.Guild(serverID)
+func GetServerOwner(a *app.App, session *discordgo.Session, serverID string) string {
+	server, err := session.Guild(serverID)
 	if err != nil {
-		logs.LogError("SERVR", "error getting server owner", "err", err)
+		a.Log.LogError("SERVR", "error getting server owner", "err", err)
 		return ""
 	}
 	return server.OwnerID
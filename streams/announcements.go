@@ -5,173 +5,307 @@ package streams
 
 import (
 	"fmt"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
-	"gamestreams/utils"
 )
 
 // ScheduleNotifications gets all streams for today that have not yet started from the
 // streams table of the database. It then schedules notifications for each stream by
-// creating a goroutine for each stream that sleeps until the streams start time -
-// the configured notification_t_minus in config.toml. It then posts a message to the
-// servers that are following one or more of the platforms of the stream by calling the
-// PostStreamLink function.
-func ScheduleNotifications(session *discordgo.Session) error {
+// creating a goroutine for each stream and notification lead time in use by servers.
+// Each goroutine sleeps until the streams start time - the lead time, then posts a
+// message to the servers using that lead time that are following one or more of the
+// platforms of the stream by calling the PostStreamLink function, and to the webhooks
+// using that lead time by calling PostWebhooks. At the default lead time the stream is
+// also announced with the other messengers by calling PostMessengers, and users
+// following the stream are sent a DM by calling NotifyFollowers. If the bot is not
+// connected to Discord, only webhooks and messengers are used. Only
+// streams with a confirmed time are scheduled, and the status of each stream is checked
+// again before it is announced in case it has been cancelled or postponed since.
+// Goroutines still sleeping when the bot shuts down are cancelled; today's streams are
+// scheduled again when it starts. Lead times that have already passed are skipped rather
+// than announced late, and announcements that are already scheduled are not scheduled
+// again, so it can be run more than once a day.
+func ScheduleNotifications(a *app.App) error {
 	var streamList db.Streams
-	if todayErr := streamList.GetToday(); todayErr != nil {
+	if todayErr := streamList.GetToday(a.DB); todayErr != nil {
 		return todayErr
 	}
 	if len(streamList.Streams) == 0 {
-		logs.LogInfo("STRMS", "no streams today", false)
+		a.Log.LogInfo("STRMS", "no streams today", false)
 		return nil
 	}
+	leadTimes, leadErr := a.DB.GetLeadTimes()
+	if leadErr != nil {
+		return leadErr
+	}
+	// Users are reminded of the streams they follow at the default lead time, so it is
+	// always scheduled even if no server uses it.
+	defaultLead := a.Config().Schedule.NotificationTMinus
+	if !slices.Contains(leadTimes, defaultLead) {
+		leadTimes = append(leadTimes, defaultLead)
+	}
+	now := time.Now().UTC()
 	for i, stream := range streamList.Streams {
-		go func(currentStream *db.Stream) {
-			dateTime := fmt.Sprintf("%s %s", currentStream.Date, currentStream.Time)
-			streamTime, parseErr := time.Parse("2006-01-02 15:04", dateTime)
-			if parseErr != nil {
-				logs.LogError("STRMS", "error parsing time",
-					"err", parseErr)
-				return
+		if !stream.Announceable() {
+			a.Log.LogInfo("STRMS", "skipping unconfirmed stream", false,
+				"name", stream.Name,
+				"status", stream.Status)
+			continue
+		}
+		streamTime, parseErr := streamStartTime(stream)
+		if parseErr != nil {
+			a.Log.LogError("STRMS", "error parsing time",
+				"err", parseErr)
+			continue
+		}
+		var scheduled []int
+		for _, leadTime := range leadTimes {
+			announceAt := streamTime.Add(-time.Minute * time.Duration(leadTime))
+			if announceAt.Before(now) {
+				continue
 			}
-			minsBefore := time.Minute * time.Duration(config.Values.Schedule.NotificationTMinus)
-			timeToStream := streamTime.Sub(time.Now().UTC()) - minsBefore
-			time.Sleep(timeToStream)
-			PostStreamLink(*currentStream, session)
-		}(&stream)
-		logs.LogInfo("STRMS", "scheduled stream", false,
+			currentStream := &stream
+			// The start time is part of the key, so a stream that is moved to later in
+			// the day is scheduled again for its new time.
+			key := fmt.Sprintf("announcement %d %s %d", stream.ID, streamTime.Format(time.RFC3339), leadTime)
+			started := a.GoOnce(key, fmt.Sprintf("announcement of %s at %d minutes", stream.Name, leadTime), func() {
+				if !a.Sleep(time.Until(announceAt)) {
+					a.Log.LogInfo("STRMS", "cancelled pending announcement", false,
+						"name", currentStream.Name,
+						"lead_time", leadTime)
+					return
+				}
+				latest, stillAnnounceable := refreshStream(a, *currentStream)
+				if !stillAnnounceable {
+					a.Log.LogInfo("STRMS", "stream no longer confirmed", false,
+						"name", latest.Name,
+						"status", latest.Status)
+					return
+				}
+				// Start checking whether the stream goes live so the owner is alerted
+				// if it does not, even when no servers announce it.
+				watchStream(a, latest)
+				if a.Session != nil {
+					PostStreamLink(a, latest, leadTime)
+				}
+				PostWebhooks(a, latest, leadTime)
+				if leadTime == defaultLead {
+					PostMessengers(a, latest)
+					if a.Session != nil {
+						NotifyFollowers(a, latest)
+					}
+				}
+			})
+			if started {
+				scheduled = append(scheduled, leadTime)
+			}
+		}
+		a.Log.LogInfo("STRMS", "scheduled stream", false,
 			"goroutine", i+1,
 			"name", stream.Name,
-			"time", stream.Time)
+			"time", stream.Time,
+			"lead_times", scheduled)
 	}
 	streamLen := len(streamList.Streams)
-	logs.LogInfo("STRMS", "scheduled todays streams", false,
+	a.Log.LogInfo("STRMS", "scheduled todays streams", false,
 		"count", streamLen)
 	return nil
 }
 
 // PostStreamLink posts an embed with the given streams information to the servers
-// that are following one or more of the platforms of the stream and has an announcement
-// channel set.
-func PostStreamLink(stream db.Stream, session *discordgo.Session) {
-	logs.LogInfo("STRMS", "posting stream link", false,
+// that are following one or more of the platforms or publishers of the stream, have an
+// announcement channel set, and announce streams the given number of minutes before
+// they start. The servers are found in a single query and posted to by a pool of
+// workers. Streams announced at the default lead time are also posted in the public
+// announcement channel, if one is set in config.toml.
+func PostStreamLink(a *app.App, stream db.Stream, leadTime int) {
+	a.Log.LogInfo("STRMS", "posting stream link", false,
 		"stream", stream.Name,
 		"platforms", stream.Platform)
 
-	allServerPlatforms, platErr := getAllPlatforms(stream)
-	if platErr != nil {
-		logs.LogError("STRMS", "error getting server platforms",
-			"err", platErr)
+	recipients, getErr := a.DB.GetAnnouncementRecipients(stream, leadTime)
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting announcement recipients",
+			"err", getErr)
 		return
 	}
-	// Removing duplicates is necessary because a server may follow multiple platforms
-	// and the stream may be related to multiple platforms. Therefore the same server
-	// may be added to allServerPlatforms multiple times.
-	uniqueServers := utils.RemoveSliceDuplicates(allServerPlatforms)
-	MakeStreamURLDirect(&stream)
-
-	logs.LogInfo("STRMS", "retrieved server IDs", false,
-		"count", len(uniqueServers))
+	if leadTime == a.Config().Schedule.NotificationTMinus {
+		if public, exists := publicRecipient(a); exists {
+			announced, announcedErr := a.DB.Announced(public.ServerID, stream.ID)
+			if announcedErr != nil {
+				a.Log.LogError("STRMS", "error checking public announcement",
+					"err", announcedErr)
+			} else if !announced {
+				recipients = append(recipients, public)
+			}
+		}
+	}
+	a.Log.LogInfo("STRMS", "retrieved server IDs", false,
+		"count", len(recipients))
+	if len(recipients) == 0 {
+		return
+	}
+	announcedURL := stream.URL
+	MakeStreamURLDirect(a, &stream)
 
-	for server := range uniqueServers {
-		var settings db.Settings
-		if getSetErr := settings.Get(server); getSetErr != nil {
-			logs.LogError("SCHED", "error getting settings",
-				"server", server,
-				"err", getSetErr)
-			continue
+	// The template values are created once so the stream page is only scraped once,
+	// however many servers the stream is announced in.
+	data, dataErr := newMessageData(a, stream)
+	if dataErr != nil {
+		a.Log.LogError("STRMS", "error creating message data",
+			"stream", stream.Name,
+			"err", dataErr)
+		return
+	}
+	messages := make([]*discordgo.MessageSend, len(recipients))
+	for i, r := range recipients {
+		content, embed, renderErr := renderRecipient(a, r, data)
+		if embed == nil {
+			a.Log.LogError("STRMS", "error rendering announcement",
+				"stream", stream.Name,
+				"err", renderErr)
+			return
 		}
-		if settings.AnnounceChannel.Value == "" {
-			continue
+		messages[i] = &discordgo.MessageSend{
+			Content: content,
+			Embed:   embed,
+		}
+	}
+	var posted []postedAnnouncement
+	for _, d := range deliverAnnouncement(a, stream, recipients, messages) {
+		announcement := db.Announcement{
+			ServerID:      d.recipient.ServerID,
+			StreamID:      stream.ID,
+			ChannelID:     d.recipient.ChannelID,
+			Attempts:      d.attempts,
+			StreamURL:     announcedURL,
+			PublishStatus: d.publishStatus,
 		}
-		embed, embedErr := createStreamEmbed(stream)
-		if embedErr != nil {
-			logs.LogError("STRMS", "error creating embed",
-				"server", server,
-				"err", embedErr)
+		recordDelivery(&announcement, d.msg, d.err)
+		if insertErr := announcement.Insert(a.DB); insertErr != nil {
+			a.Log.LogError("STRMS", "error recording announcement",
+				"server", d.recipient.ServerID,
+				"err", insertErr)
+		}
+		if d.err != nil {
+			a.Log.LogError("STRMS", "error posting message",
+				"server", d.recipient.ServerID,
+				"channel", d.recipient.ChannelID,
+				"role", d.recipient.RoleID,
+				"attempts", d.attempts,
+				"status", announcement.Status,
+				"err", d.err)
 			continue
 		}
-		msg, postErr := session.ChannelMessageSendComplex(settings.AnnounceChannel.Value, &discordgo.MessageSend{
-			Content: discord.DisplayRole(session, server, settings.AnnounceRole.Value),
-			Embed:   embed,
-		})
-		if postErr != nil {
-			logs.LogError("STRMS", "error posting message",
-				"server", server,
-				"channel", settings.AnnounceChannel,
-				"role", settings.AnnounceRole,
-				"err", postErr)
+		if d.publishErr != nil {
+			a.Log.LogError("STRMS", "error publishing message",
+				"server", d.recipient.ServerID,
+				"channel", d.recipient.ChannelID,
+				"status", d.publishStatus,
+				"err", d.publishErr)
 		}
-		go EditAnnouncementEmbed(msg, embed, session)
+		posted = append(posted, postedAnnouncement{msg: d.msg, recipient: d.recipient})
+	}
+	if len(posted) > 0 && !data.Started {
+		a.Go("edit announcements of "+stream.Name, func() {
+			EditAnnouncementEmbeds(a, posted, data, stream)
+		})
 	}
-	logs.LogInfo("STRMS", "finished posting stream", false,
+	a.Log.LogInfo("STRMS", "finished posting stream", false,
 		"name", stream.Name)
 }
 
-// EditAnnouncementEmbed edits the description of an announcement embed to show that
-// the stream has started. It does this by changing the "starting" to "started" in the
-// description. This is achieved by creating a new goroutine that sleeps until the
-// stream start time, then edits the message.
-func EditAnnouncementEmbed(msg *discordgo.Message, embed *discordgo.MessageEmbed, session *discordgo.Session) {
-	embed.Description = embed.Description[0:14] + "ed" + embed.Description[17:]
-	medit := discordgo.NewMessageEdit(msg.ChannelID, msg.ID).SetEmbed(embed)
-	time.Sleep(time.Duration(config.Values.Schedule.NotificationTMinus) * time.Minute)
-	_, editErr := session.ChannelMessageEditComplex(medit)
-	if editErr != nil {
-		logs.LogError("STRMS", "error editing message",
-			"channel", msg.ChannelID,
-			"message", msg.ID,
-			"err", editErr)
+// postedAnnouncement is an announcement message and the server it was posted to.
+type postedAnnouncement struct {
+	// The message that was posted.
+	msg *discordgo.Message
+	// The server the message was posted to, with its announcement templates.
+	recipient db.Recipient
+}
+
+// renderRecipient renders the announcement for the recipient using its templates and
+// locale. If the templates fail to render, the default templates are used and the error
+// is logged.
+func renderRecipient(a *app.App, r db.Recipient, data MessageData) (string, *discordgo.MessageEmbed, error) {
+	data = data.localise(r.Locale)
+	data.Role = discord.DisplayRole(a.Session, r.ServerID, r.RoleID)
+	content, embed, renderErr := renderAnnouncement(a, r.Template, data)
+	if renderErr != nil && embed != nil {
+		a.Log.LogError("STRMS", "error rendering announcement template",
+			"server", r.ServerID,
+			"err", renderErr)
 	}
+	return content, embed, renderErr
 }
 
-// createStreamEmbed returns a discordgo.MessageEmbed struct with the stream
-// information from the given stream and announcement role.
-func createStreamEmbed(stream db.Stream) (*discordgo.MessageEmbed, error) {
-	ts, tsErr := discord.CreateTimestampRelative(stream.Date, stream.Time)
-	if tsErr != nil {
-		return nil, tsErr
-	}
-	embed :=
-		&discordgo.MessageEmbed{
-			Title:       stream.Name,
-			URL:         stream.URL,
-			Type:        "video",
-			Description: fmt.Sprintf("**Stream starting %s.**\n\n%s", ts, stream.Description),
-			Thumbnail: &discordgo.MessageEmbedThumbnail{
-				URL: utils.GetVideoThumbnail(stream.URL),
-			},
-			Color: config.Values.Discord.EmbedColour,
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "\u200b\nPlatforms",
-					Value:  stream.Platform,
-					Inline: false,
-				},
-			},
+// EditAnnouncementEmbeds edits the announcement embeds of a stream to show that the
+// stream has started, by rendering each server's templates again with Started set. It
+// is run in a new goroutine that waits until the live status prober detects the stream
+// is live, then edits the messages using the worker pool. If the stream never goes live
+// the messages are left unchanged.
+func EditAnnouncementEmbeds(a *app.App, posted []postedAnnouncement, data MessageData, stream db.Stream) {
+	if !WaitForLive(a, stream) {
+		return
+	}
+	data.Started = true
+	runWorkers(a, len(posted), func(i int) {
+		msg := posted[i].msg
+		_, embed, _ := renderRecipient(a, posted[i].recipient, data)
+		if embed == nil {
+			return
 		}
-	return embed, nil
+		medit := discordgo.NewMessageEdit(msg.ChannelID, msg.ID).SetEmbed(embed)
+		_, _, editErr := withRetry(a, func() (*discordgo.Message, error) {
+			return a.Session.ChannelMessageEditComplex(medit)
+		})
+		if editErr != nil {
+			a.Log.LogError("STRMS", "error editing message",
+				"channel", msg.ChannelID,
+				"message", msg.ID,
+				"err", editErr)
+		}
+	})
 }
 
-// getAllPlatforms returns a slice of server IDs that are following one or more of the
-// platforms of the given stream.
-func getAllPlatforms(stream db.Stream) ([]string, error) {
-	platforms := strings.Split(stream.Platform, ",")
-	var allServerPlatforms []string
-	for _, platform := range platforms {
-		platform = strings.Trim(platform, " ")
-		server_list, platErr := db.GetPlatformServerIDs(platform)
-		if platErr != nil {
-			return nil, platErr
-		}
-		allServerPlatforms = append(allServerPlatforms, server_list...)
+// refreshStream gets the latest values of the stream from the database. It returns the
+// stream and true if it can still be announced.
+func refreshStream(a *app.App, stream db.Stream) (db.Stream, bool) {
+	var latest db.Streams
+	if getErr := latest.GetByID(a.DB, stream.ID); getErr != nil {
+		a.Log.LogError("STRMS", "error getting stream",
+			"stream", stream.ID,
+			"err", getErr)
+		return stream, stream.Announceable()
+	}
+	if len(latest.Streams) == 0 {
+		return stream, false
+	}
+	current := latest.Streams[0]
+	// Only announce the stream if it has not moved from the time it was scheduled for.
+	return current, current.Announceable() &&
+		current.Date == stream.Date &&
+		current.Time == stream.Time
+}
+
+// streamStartTime returns the start time of the given stream in UTC.
+func streamStartTime(stream db.Stream) (time.Time, error) {
+	dateTime := fmt.Sprintf("%s %s", stream.Date, stream.Time)
+	return time.Parse("2006-01-02 15:04", dateTime)
+}
+
+// createStreamEmbed returns a discordgo.MessageEmbed struct with the stream
+// information from the given stream, rendered with the default announcement templates.
+func createStreamEmbed(a *app.App, stream db.Stream) (*discordgo.MessageEmbed, error) {
+	data, dataErr := newMessageData(a, stream)
+	if dataErr != nil {
+		return nil, dataErr
 	}
-	return allServerPlatforms, nil
+	_, embed, renderErr := renderMessage(a, db.MessageTemplate{}, data)
+	return embed, renderErr
 }
@@ -0,0 +1,211 @@
+package streams
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/config"
+	"gamestreams/db"
+	"gamestreams/discordtest"
+	"gamestreams/logs"
+)
+
+const (
+	testGuildID     = "300000000000000001"
+	testOwnerID     = "300000000000000002"
+	testTextChannel = "300000000000000010"
+	testNewsChannel = "300000000000000011"
+	testLeadTime    = 10
+)
+
+// newTestApp returns an App with a new database that is connected to a fake Discord
+// server with one server, which announces Xbox streams in the channel.
+func newTestApp(t *testing.T, channelID string, crosspost bool) (*app.App, *discordtest.Server) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Discord.OwnerID = testOwnerID
+	cfg.Files.Database = filepath.Join(t.TempDir(), "test.db")
+	cfg.Schedule.NotificationTMinus = testLeadTime
+
+	server := discordtest.New()
+	t.Cleanup(server.Close)
+	session, sessionErr := server.Session()
+	if sessionErr != nil {
+		t.Fatalf("Session() error = %v", sessionErr)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	a := app.New(cfg, logs.New(cfg.Logs))
+	if openErr := a.OpenDB(); openErr != nil {
+		t.Fatalf("OpenDB() error = %v", openErr)
+	}
+	a.Session = session
+	// Stops the live check of the announced stream, which waits for its start time.
+	t.Cleanup(func() { a.Shutdown(5 * time.Second) })
+
+	guild := &discordgo.Guild{
+		ID:      testGuildID,
+		Name:    "Test Server",
+		OwnerID: testOwnerID,
+		Members: []*discordgo.Member{
+			{GuildID: testGuildID, User: server.User},
+		},
+		Channels: []*discordgo.Channel{
+			{ID: testTextChannel, Name: "streams", Type: discordgo.ChannelTypeGuildText},
+			{ID: testNewsChannel, Name: "news", Type: discordgo.ChannelTypeGuildNews},
+		},
+	}
+	if addErr := server.AddGuild(guild); addErr != nil {
+		t.Fatalf("AddGuild() error = %v", addErr)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, stateErr := session.State.Guild(testGuildID); stateErr == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the server to be added to the session state")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if serverErr := a.DB.NewServer(testGuildID, "Test Server", testOwnerID, time.Now().UTC(),
+		2, "en-US"); serverErr != nil {
+		t.Fatalf("a.DB.NewServer() error = %v", serverErr)
+	}
+	settings := db.NewSettings(testGuildID)
+	settings.AnnounceChannel = db.StringSet{Value: channelID, Set: true}
+	settings.Xbox = db.BoolSet{Value: true, Set: true}
+	settings.Crosspost = db.BoolSet{Value: crosspost, Set: true}
+	if setErr := settings.Set(a.DB); setErr != nil {
+		t.Fatalf("Set() error = %v", setErr)
+	}
+	return a, server
+}
+
+// insertStream adds an Xbox stream starting at the time to the database and returns
+// it.
+func insertStream(t *testing.T, a *app.App, name string, start time.Time) db.Stream {
+	t.Helper()
+	start = start.UTC()
+	streamList := db.Streams{Streams: []db.Stream{{
+		Name:     name,
+		Platform: "Xbox",
+		Date:     start.Format("2006-01-02"),
+		Time:     start.Format("15:04"),
+		Status:   db.StatusConfirmed,
+	}}}
+	streamList.InsertStreams(a.DB)
+	var inserted db.Streams
+	if getErr := inserted.GetByID(a.DB, 1); getErr != nil || len(inserted.Streams) == 0 {
+		t.Fatalf("stream was not inserted, err = %v", getErr)
+	}
+	return inserted.Streams[0]
+}
+
+// crossposts returns the paths of the crosspost requests the bot made.
+func crossposts(server *discordtest.Server) []string {
+	var paths []string
+	for _, r := range server.Requests() {
+		if r.Method == "POST" && strings.HasSuffix(r.Path, "/crosspost") {
+			paths = append(paths, r.Path)
+		}
+	}
+	return paths
+}
+
+func TestPostStreamLinkCrossposts(t *testing.T) {
+	a, server := newTestApp(t, testNewsChannel, true)
+	stream := insertStream(t, a, "Xbox Showcase", time.Now().Add(time.Hour))
+
+	PostStreamLink(a, stream, testLeadTime)
+
+	messages, waitErr := server.WaitForMessages(testNewsChannel, 1, 5*time.Second)
+	if waitErr != nil {
+		t.Fatal(waitErr)
+	}
+	msg := messages[0]
+	if msg.EditedTimestamp != nil {
+		t.Error("announcement was edited before the stream started")
+	}
+	if len(msg.Embeds) == 0 || !strings.Contains(msg.Embeds[0].Title, stream.Name) {
+		t.Errorf("announcement embeds = %+v, want the title to contain %q", msg.Embeds, stream.Name)
+	}
+	want := fmt.Sprintf("channels/%s/messages/%s/crosspost", testNewsChannel, msg.ID)
+	if paths := crossposts(server); len(paths) != 1 || paths[0] != want {
+		t.Errorf("crosspost requests = %v, want [%s]", paths, want)
+	}
+
+	announcements, getErr := a.DB.GetStreamAnnouncements(stream.ID)
+	if getErr != nil {
+		t.Fatalf("GetStreamAnnouncements() error = %v", getErr)
+	}
+	if len(announcements) != 1 || announcements[0].MessageID != msg.ID ||
+		announcements[0].PublishStatus != db.PublishPublished {
+		t.Errorf("announcements = %+v, want one published announcement of message %s",
+			announcements, msg.ID)
+	}
+}
+
+func TestPostStreamLinkEditsStartedStream(t *testing.T) {
+	a, server := newTestApp(t, testTextChannel, false)
+	// The stream cannot be checked for being live as it has no URL, so it is treated as
+	// live from its start time, which has passed.
+	stream := insertStream(t, a, "Xbox Direct", time.Now().Add(-time.Minute))
+
+	PostStreamLink(a, stream, testLeadTime)
+
+	messages, waitErr := server.WaitForMessages(testTextChannel, 2, 5*time.Second)
+	if waitErr != nil {
+		t.Fatal(waitErr)
+	}
+	posted, edited := messages[0], messages[1]
+	if edited.ID != posted.ID || edited.EditedTimestamp == nil {
+		t.Errorf("second message = %+v, want an edit of announcement %s", edited, posted.ID)
+	}
+	if len(edited.Embeds) == 0 || !strings.Contains(edited.Embeds[0].Title, stream.Name) {
+		t.Errorf("edited embeds = %+v, want the title to contain %q", edited.Embeds, stream.Name)
+	}
+	if paths := crossposts(server); len(paths) != 0 {
+		t.Errorf("crosspost requests = %v, want none for a text channel", paths)
+	}
+}
+
+func TestPostStreamLinkSkipsAnnouncedServers(t *testing.T) {
+	a, server := newTestApp(t, testTextChannel, false)
+	stream := insertStream(t, a, "Xbox Showcase", time.Now().Add(time.Hour))
+
+	PostStreamLink(a, stream, testLeadTime)
+	if _, waitErr := server.WaitForMessages(testTextChannel, 1, 5*time.Second); waitErr != nil {
+		t.Fatal(waitErr)
+	}
+	PostStreamLink(a, stream, testLeadTime)
+
+	if messages := server.Messages(testTextChannel); len(messages) != 1 {
+		t.Errorf("channel has %d messages, want the stream to be announced once", len(messages))
+	}
+}
+
+func TestScheduleNotificationsSkipsPastLeadTimes(t *testing.T) {
+	a, server := newTestApp(t, testTextChannel, false)
+	cfg := *a.Config()
+	cfg.Schedule.StreamNotifications.Cron = "0 6 * * *"
+	a.SetConfig(&cfg)
+	// The stream starts sooner than the lead time, so it is too late to announce it.
+	insertStream(t, a, "Xbox Showcase", time.Now().Add(5*time.Minute))
+
+	if scheduleErr := ScheduleNotifications(a); scheduleErr != nil {
+		t.Fatalf("ScheduleNotifications() error = %v", scheduleErr)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	if messages := server.Messages(testTextChannel); len(messages) != 0 {
+		t.Errorf("channel has %d messages, want no announcement after the lead time", len(messages))
+	}
+}
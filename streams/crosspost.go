@@ -0,0 +1,126 @@
+/*
+crosspost.go contains functions for publishing announcements posted in Discord
+announcement channels, so that they reach the servers following the channel, and for
+posting every stream in the bot's own public announcement channel.
+*/
+package streams
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+)
+
+// publish crossposts the message to the servers following its channel if the channel
+// is an announcement channel. It returns the publish status of the message, which is
+// empty if the channel is not an announcement channel, and the error if publishing
+// failed. A message that has already been crossposted is treated as published.
+func publish(a *app.App, msg *discordgo.Message) (string, error) {
+	channel, getErr := a.Session.State.Channel(msg.ChannelID)
+	if getErr != nil {
+		channel, getErr = a.Session.Channel(msg.ChannelID)
+		if getErr != nil {
+			return db.PublishFailed, getErr
+		}
+	}
+	if channel.Type != discordgo.ChannelTypeGuildNews {
+		return "", nil
+	}
+	_, _, publishErr := withRetry(a, func() (*discordgo.Message, error) {
+		return a.Session.ChannelMessageCrosspost(msg.ChannelID, msg.ID)
+	})
+	var restErr *discordgo.RESTError
+	if publishErr == nil || (errors.As(publishErr, &restErr) && restErr.Message != nil &&
+		restErr.Message.Code == discordgo.ErrCodeMessageAlreadyCrossposted) {
+		return db.PublishPublished, nil
+	}
+	if !retryable(publishErr) {
+		return db.PublishAbandoned, publishErr
+	}
+	return db.PublishFailed, publishErr
+}
+
+// publicRecipient returns the bot's public announcement channel set in config.toml as a
+// recipient that crossposts, and true if the channel is set and can be found.
+func publicRecipient(a *app.App) (db.Recipient, bool) {
+	channelID := a.Config().Announcements.PublicChannel
+	if channelID == "" {
+		return db.Recipient{}, false
+	}
+	channel, getErr := a.Session.State.Channel(channelID)
+	if getErr != nil {
+		channel, getErr = a.Session.Channel(channelID)
+		if getErr != nil {
+			a.Log.LogError("STRMS", "error getting public announcement channel",
+				"channel", channelID,
+				"err", getErr)
+			return db.Recipient{}, false
+		}
+	}
+	locale, localeErr := a.DB.GetServerLocale(channel.GuildID)
+	if localeErr != nil {
+		a.Log.LogError("STRMS", "error getting server locale",
+			"server", channel.GuildID,
+			"err", localeErr)
+	}
+	return db.Recipient{
+		ServerID:  channel.GuildID,
+		ChannelID: channelID,
+		Template:  db.MessageTemplate{ServerID: channel.GuildID},
+		Locale:    locale,
+		Crosspost: true,
+	}, true
+}
+
+// RetryFailedPublishes crossposts the announcements that were posted but failed to
+// crosspost. Announcements are no longer retried once the stream started longer ago
+// than the retry window set in config.toml.
+func RetryFailedPublishes(a *app.App) {
+	announcements, getErr := a.DB.GetUnpublishedAnnouncements()
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting unpublished announcements",
+			"err", getErr)
+		return
+	}
+	_, window := retryLimits(a)
+	for _, announcement := range announcements {
+		var streams db.Streams
+		if streamErr := streams.GetByID(a.DB, announcement.StreamID); streamErr != nil || len(streams.Streams) == 0 {
+			a.Log.LogError("STRMS", "error getting stream for publish retry",
+				"stream", announcement.StreamID,
+				"err", streamErr)
+			continue
+		}
+		stream := streams.Streams[0]
+		start, parseErr := streamStartTime(stream)
+		var publishErr error
+		if parseErr != nil || time.Since(start) > window {
+			announcement.PublishStatus = db.PublishAbandoned
+		} else {
+			msg := &discordgo.Message{ID: announcement.MessageID, ChannelID: announcement.ChannelID}
+			announcement.PublishStatus, publishErr = publish(a, msg)
+		}
+		if updateErr := announcement.Update(a.DB); updateErr != nil {
+			a.Log.LogError("STRMS", "error recording announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", updateErr)
+		}
+		if publishErr != nil {
+			a.Log.LogError("STRMS", "error publishing announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"status", announcement.PublishStatus,
+				"err", publishErr)
+			continue
+		}
+		a.Log.LogInfo("STRMS", "retried publishing announcement", false,
+			"server", announcement.ServerID,
+			"stream", stream.Name,
+			"status", announcement.PublishStatus)
+	}
+}
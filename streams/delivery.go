@@ -0,0 +1,183 @@
+/*
+delivery.go contains the worker pool used to post and edit stream announcements. Messages
+are sent by a bounded number of workers that share the session's rate limiter, so the
+per-route and global limits reported by Discord are respected. Rate limited and failed
+requests are retried with backoff, and the delivery latency of each announcement is
+logged once it has been posted everywhere.
+*/
+package streams
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/utils"
+)
+
+// delivery is the result of posting an announcement to a single server.
+type delivery struct {
+	// The server the announcement was posted to.
+	recipient db.Recipient
+	// The message that was posted, or nil if posting failed.
+	msg *discordgo.Message
+	// The error returned by the final attempt, if posting failed.
+	err error
+	// The number of attempts made.
+	attempts int
+	// The time from the start of the announcement until the message was posted.
+	latency time.Duration
+	// The publish status of the message, if it was crossposted.
+	publishStatus string
+	// The error returned when crossposting the message, if it failed.
+	publishErr error
+}
+
+// deliverAnnouncement posts each message to the recipient at the same index using the
+// worker pool and returns the result for each recipient, in the same order. Messages
+// are crossposted once posted if the recipient has crossposting enabled.
+func deliverAnnouncement(a *app.App, stream db.Stream, recipients []db.Recipient, messages []*discordgo.MessageSend) []delivery {
+	start := time.Now()
+	deliveries := make([]delivery, len(recipients))
+	runWorkers(a, len(recipients), func(i int) {
+		r := recipients[i]
+		msg, attempts, sendErr := withRetry(a, func() (*discordgo.Message, error) {
+			return a.Session.ChannelMessageSendComplex(r.ChannelID, messages[i])
+		})
+		deliveries[i] = delivery{
+			recipient: r,
+			msg:       msg,
+			err:       sendErr,
+			attempts:  attempts,
+			latency:   time.Since(start),
+		}
+		if sendErr == nil && r.Crosspost {
+			deliveries[i].publishStatus, deliveries[i].publishErr = publish(a, msg)
+		}
+	})
+	logDeliveryStats(a, stream, deliveries, time.Since(start))
+	return deliveries
+}
+
+// runWorkers calls work for each job from 0 to jobs-1 using the number of workers set
+// in config.toml, and returns once every job has finished.
+func runWorkers(a *app.App, jobs int, work func(i int)) {
+	workers := a.Config().Announcements.Workers
+	if workers <= 0 {
+		workers = 5
+	}
+	if workers > jobs {
+		workers = jobs
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				work(i)
+			}
+		}()
+	}
+	for i := 0; i < jobs; i++ {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// withRetry calls send until it succeeds, returns an error that should not be retried,
+// or the number of retries set in config.toml is reached. It returns the message, the
+// number of attempts made, and the error from the final attempt.
+func withRetry(a *app.App, send func() (*discordgo.Message, error)) (*discordgo.Message, int, error) {
+	retries := a.Config().Announcements.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	for attempt := 0; ; attempt++ {
+		msg, sendErr := send()
+		if sendErr == nil || attempt >= retries || !retryable(sendErr) {
+			return msg, attempt + 1, sendErr
+		}
+		if !a.Sleep(retryWait(sendErr, attempt)) {
+			return msg, attempt + 1, sendErr
+		}
+	}
+}
+
+// retryable returns true if the request that returned the error should be retried.
+// Rate limits, server errors and errors without a response, such as timeouts, are
+// retried. Other errors, such as missing permissions, are not.
+func retryable(err error) bool {
+	var rateErr *discordgo.RateLimitError
+	if errors.As(err, &rateErr) {
+		return true
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) {
+		if restErr.Response == nil {
+			return true
+		}
+		status := restErr.Response.StatusCode
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// retryWait returns how long to wait before retrying the request that returned the
+// error. Rate limit errors wait for the time given by Discord.
+func retryWait(err error, attempt int) time.Duration {
+	var rateErr *discordgo.RateLimitError
+	if errors.As(err, &rateErr) && rateErr.RateLimit != nil && rateErr.TooManyRequests != nil {
+		return rateErr.RetryAfter
+	}
+	return utils.Backoff(attempt)
+}
+
+// logDeliveryStats logs the number of servers an announcement was posted to, the
+// number of failures and retries, and the median, 95th percentile and maximum time
+// taken for the announcement to reach a server.
+func logDeliveryStats(a *app.App, stream db.Stream, deliveries []delivery, total time.Duration) {
+	var latencies []time.Duration
+	var failed, retried int
+	for _, d := range deliveries {
+		if d.attempts > 1 {
+			retried++
+		}
+		if d.err != nil {
+			failed++
+			continue
+		}
+		latencies = append(latencies, d.latency)
+	}
+	slices.Sort(latencies)
+	a.Log.LogInfo("STRMS", "announcement delivered", false,
+		"stream", stream.Name,
+		"sent", len(latencies),
+		"failed", failed,
+		"retried", retried,
+		"p50", percentile(latencies, 50).Round(time.Millisecond).String(),
+		"p95", percentile(latencies, 95).Round(time.Millisecond).String(),
+		"max", percentile(latencies, 100).Round(time.Millisecond).String(),
+		"total", total.Round(time.Millisecond).String())
+}
+
+// percentile returns the given percentile of the sorted durations, or 0 if there are
+// none.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -0,0 +1,258 @@
+/*
+events.go contains functions for posting streams as Discord scheduled events in servers
+that have enabled them, and keeping those events in sync with the streams table.
+*/
+package streams
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/utils"
+)
+
+// SyncScheduledEvents creates, updates and deletes Discord scheduled events so that each
+// server that has enabled scheduled events has one for every upcoming stream on the
+// platforms it follows. Events for streams that have ended, been deleted or moved out
+// of the configured window are removed, as are all events for servers that have since
+// disabled scheduled events.
+func SyncScheduledEvents(a *app.App) {
+	settingsList, getErr := a.DB.GetEventSettings()
+	if getErr != nil {
+		a.Log.LogError("EVNTS", "error getting event settings",
+			"err", getErr)
+		return
+	}
+	var window db.Streams
+	if windowErr := window.GetEventWindow(a.DB); windowErr != nil {
+		a.Log.LogError("EVNTS", "error getting streams",
+			"err", windowErr)
+		return
+	}
+	enabled := make(map[string]bool)
+	for _, settings := range settingsList {
+		enabled[settings.ServerID] = true
+		syncServerEvents(a, settings, window.Streams)
+	}
+
+	existing, existErr := a.DB.GetScheduledEvents("")
+	if existErr != nil {
+		a.Log.LogError("EVNTS", "error getting scheduled events",
+			"err", existErr)
+		return
+	}
+	for _, event := range existing {
+		if !enabled[event.ServerID] {
+			deleteScheduledEvent(a, a.Session, event)
+		}
+	}
+	a.Log.LogInfo("EVNTS", "synced scheduled events", false,
+		"servers", len(settingsList))
+}
+
+// SyncServerEvents syncs the scheduled events of a single server. If the server has
+// disabled scheduled events, all of its events are deleted.
+func SyncServerEvents(a *app.App, settings db.Settings) {
+	if !settings.ScheduledEvents.Value {
+		existing, existErr := a.DB.GetScheduledEvents(settings.ServerID)
+		if existErr != nil {
+			a.Log.LogError("EVNTS", "error getting scheduled events",
+				"server", settings.ServerID,
+				"err", existErr)
+			return
+		}
+		for _, event := range existing {
+			deleteScheduledEvent(a, a.Session, event)
+		}
+		return
+	}
+	var window db.Streams
+	if windowErr := window.GetEventWindow(a.DB); windowErr != nil {
+		a.Log.LogError("EVNTS", "error getting streams",
+			"err", windowErr)
+		return
+	}
+	syncServerEvents(a, settings, window.Streams)
+}
+
+// syncServerEvents creates or updates a scheduled event in the server for each of the
+// given streams that the server follows and that are upcoming and have not yet ended,
+// then deletes the server's events for any other streams, including rumoured, cancelled
+// and postponed streams.
+func syncServerEvents(a *app.App, settings db.Settings, streamList []db.Stream) {
+	existing, existErr := a.DB.GetScheduledEvents(settings.ServerID)
+	if existErr != nil {
+		a.Log.LogError("EVNTS", "error getting scheduled events",
+			"server", settings.ServerID,
+			"err", existErr)
+		return
+	}
+	events := make(map[int]db.ScheduledEvent)
+	for _, event := range existing {
+		events[event.StreamID] = event
+	}
+	duration := eventDuration(a)
+
+	wanted := make(map[int]bool)
+	for _, stream := range streamList {
+		if !settings.FollowsStream(stream) || !stream.Upcoming() {
+			continue
+		}
+		start, parseErr := streamStartTime(stream)
+		if parseErr != nil {
+			a.Log.LogError("EVNTS", "error parsing time",
+				"stream", stream.ID,
+				"err", parseErr)
+			continue
+		}
+		if start.Add(duration).Before(time.Now().UTC()) {
+			continue
+		}
+		wanted[stream.ID] = true
+
+		event, exists := events[stream.ID]
+		if exists {
+			// Discord does not allow the start time of an active event to be changed.
+			if !start.After(time.Now().UTC()) || !eventChanged(event, stream, start) {
+				continue
+			}
+			updateScheduledEvent(a, a.Session, event, stream, start, duration)
+		} else if start.After(time.Now().UTC()) {
+			createScheduledEvent(a, a.Session, settings.ServerID, stream, start, duration)
+		}
+	}
+	for streamID, event := range events {
+		if !wanted[streamID] {
+			deleteScheduledEvent(a, a.Session, event)
+		}
+	}
+}
+
+// createScheduledEvent creates an external Discord scheduled event for the stream in
+// the server and records it in the database.
+func createScheduledEvent(a *app.App, session *discordgo.Session, serverID string, stream db.Stream, start time.Time, duration time.Duration) {
+	created, createErr := session.GuildScheduledEventCreate(serverID, eventParams(stream, start, duration))
+	if createErr != nil {
+		a.Log.LogError("EVNTS", "error creating scheduled event",
+			"server", serverID,
+			"stream", stream.Name,
+			"err", createErr)
+		return
+	}
+	event := db.ScheduledEvent{
+		ServerID:  serverID,
+		StreamID:  stream.ID,
+		EventID:   created.ID,
+		Name:      stream.Name,
+		StartTime: start.Format(time.RFC3339),
+		URL:       stream.URL,
+	}
+	if setErr := event.Set(a.DB); setErr != nil {
+		a.Log.LogError("EVNTS", "error saving scheduled event",
+			"server", serverID,
+			"stream", stream.Name,
+			"err", setErr)
+	}
+	a.Log.LogInfo("EVNTS", "created scheduled event", false,
+		"server", serverID,
+		"stream", stream.Name)
+}
+
+// updateScheduledEvent edits the Discord scheduled event to match the stream and
+// records the new values in the database.
+func updateScheduledEvent(a *app.App, session *discordgo.Session, event db.ScheduledEvent, stream db.Stream, start time.Time, duration time.Duration) {
+	_, editErr := session.GuildScheduledEventEdit(event.ServerID, event.EventID, eventParams(stream, start, duration))
+	if editErr != nil {
+		if isNotFound(editErr) {
+			// The event was deleted in Discord, create it again.
+			event.Delete(a.DB)
+			createScheduledEvent(a, session, event.ServerID, stream, start, duration)
+			return
+		}
+		a.Log.LogError("EVNTS", "error editing scheduled event",
+			"server", event.ServerID,
+			"stream", stream.Name,
+			"err", editErr)
+		return
+	}
+	event.Name = stream.Name
+	event.StartTime = start.Format(time.RFC3339)
+	event.URL = stream.URL
+	if setErr := event.Set(a.DB); setErr != nil {
+		a.Log.LogError("EVNTS", "error saving scheduled event",
+			"server", event.ServerID,
+			"stream", stream.Name,
+			"err", setErr)
+	}
+}
+
+// deleteScheduledEvent deletes the Discord scheduled event and removes it from the
+// database. Events that have already been deleted in Discord are only removed from the
+// database.
+func deleteScheduledEvent(a *app.App, session *discordgo.Session, event db.ScheduledEvent) {
+	delErr := session.GuildScheduledEventDelete(event.ServerID, event.EventID)
+	if delErr != nil && !isNotFound(delErr) {
+		a.Log.LogError("EVNTS", "error deleting scheduled event",
+			"server", event.ServerID,
+			"event", event.EventID,
+			"err", delErr)
+		return
+	}
+	if rmErr := event.Delete(a.DB); rmErr != nil {
+		a.Log.LogError("EVNTS", "error removing scheduled event",
+			"server", event.ServerID,
+			"event", event.EventID,
+			"err", rmErr)
+	}
+}
+
+// eventParams returns the parameters for an external Discord scheduled event for the
+// stream, using the stream URL as the location.
+func eventParams(stream db.Stream, start time.Time, duration time.Duration) *discordgo.GuildScheduledEventParams {
+	end := start.Add(duration)
+	location := stream.URL
+	if len(location) > 100 || location == "" {
+		location = "Online"
+	}
+	return &discordgo.GuildScheduledEventParams{
+		Name:               utils.Truncate(stream.Name, 100),
+		Description:        utils.Truncate(stream.Description, 1000),
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeExternal,
+		EntityMetadata: &discordgo.GuildScheduledEventEntityMetadata{
+			Location: location,
+		},
+	}
+}
+
+// eventDuration returns the expected length of a stream as set in config.toml. If it
+// is not set, streams are expected to last two hours.
+func eventDuration(a *app.App) time.Duration {
+	if a.Config().Events.DurationMinutes <= 0 {
+		return 2 * time.Hour
+	}
+	return time.Duration(a.Config().Events.DurationMinutes) * time.Minute
+}
+
+// eventChanged returns true if the name, start time or URL of the stream differ from
+// the values recorded when the event was last synced.
+func eventChanged(event db.ScheduledEvent, stream db.Stream, start time.Time) bool {
+	return event.Name != stream.Name ||
+		event.StartTime != start.Format(time.RFC3339) ||
+		event.URL != stream.URL
+}
+
+// isNotFound returns true if the error is a Discord API 404 response.
+func isNotFound(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) &&
+		restErr.Response != nil &&
+		restErr.Response.StatusCode == http.StatusNotFound
+}
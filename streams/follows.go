@@ -0,0 +1,79 @@
+/*
+follows.go contains functions for reminding users by DM when a stream or platform they
+follow is about to start.
+*/
+package streams
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/discord"
+	"gamestreams/locales"
+)
+
+// NotifyFollowers sends a DM containing the stream embed to each user that follows the
+// stream or one of its platforms, in the locale the user last used the bot in. Users that have opted out of DM notifications, have
+// already been reminded about the stream, or have reached the daily DM limit set in
+// config.toml are skipped.
+func NotifyFollowers(a *app.App, stream db.Stream) {
+	followers, getErr := a.DB.GetStreamFollowers(stream)
+	if getErr != nil {
+		a.Log.LogError("FOLLW", "error getting stream followers",
+			"stream", stream.Name,
+			"err", getErr)
+		return
+	}
+	if len(followers) == 0 {
+		return
+	}
+	embed, embedErr := createStreamEmbed(a, stream)
+	if embedErr != nil {
+		a.Log.LogError("FOLLW", "error creating embed",
+			"stream", stream.Name,
+			"err", embedErr)
+		return
+	}
+	var sent int
+	for _, userID := range followers {
+		count, countErr := a.DB.CountUserNotifications(userID)
+		if countErr != nil {
+			a.Log.LogError("FOLLW", "error counting notifications",
+				"user", userID,
+				"err", countErr)
+			continue
+		}
+		if a.Config().Follows.DailyDMLimit > 0 && count >= a.Config().Follows.DailyDMLimit {
+			a.Log.LogInfo("FOLLW", "daily DM limit reached", false,
+				"user", userID)
+			continue
+		}
+		locale, localeErr := a.DB.GetUserLocale(userID)
+		if localeErr != nil {
+			a.Log.LogError("FOLLW", "error getting user locale",
+				"user", userID,
+				"err", localeErr)
+		}
+		dmErr := discord.DMComplex(a.Session, userID, &discordgo.MessageSend{
+			Content: locales.T(locale, "follow.dm"),
+			Embeds:  []*discordgo.MessageEmbed{embed},
+		})
+		if dmErr != nil {
+			// The user may have DMs disabled, so nothing is recorded against their limit.
+			a.Log.LogInfo("FOLLW", "error sending reminder", false,
+				"user", userID,
+				"err", dmErr)
+			continue
+		}
+		if addErr := a.DB.AddUserNotification(userID, stream.ID); addErr != nil {
+			a.Log.LogError("FOLLW", "error recording notification",
+				"user", userID,
+				"err", addErr)
+		}
+		sent++
+	}
+	a.Log.LogInfo("FOLLW", "notified followers", false,
+		"stream", stream.Name,
+		"count", sent)
+}
@@ -0,0 +1,174 @@
+/*
+live.go contains the live status prober. Around the scheduled start time of a stream it
+polls the stream URL until the stream goes live, records the actual start time, and
+alerts the owner if the stream has not started after a grace period. Announcements wait
+on the prober before being edited to show that the stream has started.
+*/
+package streams
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/providers"
+)
+
+// liveWatch tracks the live status of a single stream. The done channel is closed once
+// the stream has gone live or the prober has given up.
+type liveWatch struct {
+	// Closed when the prober has finished.
+	done chan struct{}
+	// True if the stream was detected as live, or if its URL cannot be checked and the
+	// scheduled start time has passed.
+	live bool
+}
+
+var (
+	// liveWatches holds the watch for each stream that is being probed.
+	liveWatches = make(map[int]*liveWatch)
+	// liveWatchesMu guards liveWatches.
+	liveWatchesMu sync.Mutex
+)
+
+// watchStream returns the watch for the stream, starting the prober if it is not
+// already running.
+func watchStream(a *app.App, stream db.Stream) *liveWatch {
+	liveWatchesMu.Lock()
+	defer liveWatchesMu.Unlock()
+
+	if watch, exists := liveWatches[stream.ID]; exists {
+		return watch
+	}
+	watch := &liveWatch{done: make(chan struct{})}
+	liveWatches[stream.ID] = watch
+	a.Go("live check of "+stream.Name, func() {
+		watch.probe(a, stream)
+	})
+	return watch
+}
+
+// WaitForLive blocks until the stream has gone live or the prober has given up. It
+// returns true if the stream went live. Streams whose URL cannot be checked are
+// treated as live at their scheduled start time.
+func WaitForLive(a *app.App, stream db.Stream) bool {
+	watch := watchStream(a, stream)
+	<-watch.done
+	return watch.live
+}
+
+// finish records the result of the prober and wakes any announcements waiting on it.
+func (w *liveWatch) finish(streamID int, live bool) {
+	liveWatchesMu.Lock()
+	delete(liveWatches, streamID)
+	liveWatchesMu.Unlock()
+
+	w.live = live
+	close(w.done)
+}
+
+// probe polls the stream URL from shortly before the scheduled start time until the
+// stream goes live or the configured give up time is reached.
+func (w *liveWatch) probe(a *app.App, stream db.Stream) {
+	start, parseErr := streamStartTime(stream)
+	if parseErr != nil {
+		a.Log.LogError(" LIVE", "error parsing time",
+			"stream", stream.Name,
+			"err", parseErr)
+		w.finish(stream.ID, false)
+		return
+	}
+	if !providers.LiveSupported(stream.URL) {
+		w.finish(stream.ID, probeSleep(a, stream, time.Until(start)))
+		return
+	}
+	poll, early, grace, giveUp := liveTimings(a)
+	if !probeSleep(a, stream, time.Until(start.Add(-early))) {
+		w.finish(stream.ID, false)
+		return
+	}
+
+	a.Log.LogInfo(" LIVE", "checking if stream is live", false,
+		"stream", stream.Name,
+		"url", stream.URL)
+	var alerted bool
+	for {
+		live, checkErr := a.Providers.Live(stream.URL)
+		if errors.Is(checkErr, providers.ErrLiveUnsupported) {
+			// The provider can only check some of its URLs, e.g. live rooms but not
+			// videos, so fall back to the scheduled start time.
+			w.finish(stream.ID, probeSleep(a, stream, time.Until(start)))
+			return
+		} else if checkErr != nil {
+			a.Log.LogInfo(" LIVE", "error checking live status", false,
+				"stream", stream.Name,
+				"err", checkErr)
+		}
+		now := time.Now().UTC()
+		if live {
+			if setErr := a.DB.SetLive(stream.ID, now); setErr != nil {
+				a.Log.LogError(" LIVE", "error setting stream live",
+					"stream", stream.Name,
+					"err", setErr)
+			}
+			a.Log.LogInfo(" LIVE", "stream is live", false,
+				"stream", stream.Name,
+				"delay", now.Sub(start).Round(time.Second).String())
+			w.finish(stream.ID, true)
+			return
+		}
+		if !alerted && now.After(start.Add(grace)) {
+			a.Log.LogInfo(" LIVE", "stream has not started", true,
+				"stream", stream.Name,
+				"id", stream.ID,
+				"url", stream.URL,
+				"scheduled", start.Format(time.RFC3339))
+			alerted = true
+		}
+		if now.After(start.Add(giveUp)) {
+			a.Log.LogInfo(" LIVE", "stopped checking stream", false,
+				"stream", stream.Name)
+			w.finish(stream.ID, false)
+			return
+		}
+		if !probeSleep(a, stream, poll) {
+			w.finish(stream.ID, false)
+			return
+		}
+	}
+}
+
+// probeSleep pauses the prober for the duration. It returns false if the bot started
+// shutting down first, in which case the stream is not checked again.
+func probeSleep(a *app.App, stream db.Stream, d time.Duration) bool {
+	if a.Sleep(d) {
+		return true
+	}
+	a.Log.LogInfo(" LIVE", "stopped checking stream, shutting down", false,
+		"stream", stream.Name)
+	return false
+}
+
+// liveTimings returns the poll interval, early start, grace period and give up time
+// set in config.toml, using defaults for any that are not set.
+func liveTimings(a *app.App) (time.Duration, time.Duration, time.Duration, time.Duration) {
+	poll := time.Duration(a.Config().Live.PollSeconds) * time.Second
+	if poll <= 0 {
+		poll = time.Minute
+	}
+	early := time.Duration(a.Config().Live.EarlyMinutes) * time.Minute
+	if early < 0 {
+		early = 0
+	}
+	grace := time.Duration(a.Config().Live.GraceMinutes) * time.Minute
+	if grace <= 0 {
+		grace = 15 * time.Minute
+	}
+	giveUp := time.Duration(a.Config().Live.GiveUpMinutes) * time.Minute
+	if giveUp <= grace {
+		giveUp = grace + time.Hour
+	}
+	return poll, early, grace, giveUp
+}
@@ -4,15 +4,23 @@ maintenance.go contains functions that are used to maintain the streams table in
 package streams
 
 import (
-	"gamestreams/db"
-	"gamestreams/logs"
+	"gamestreams/app"
 )
 
 // StreamMaintenance checks for streams in the streams table of the database that are
-// over the limit specified in config.toml and removes them.
-func StreamMaintenance() {
-	if err := db.RemoveOldStreams(); err != nil {
-		logs.LogError("STRMS", "error removing old streams",
+// over the limit specified in config.toml and removes them. Confirmed and live streams
+// from previous days are marked as ended, and expired URL metadata is removed.
+func StreamMaintenance(a *app.App) {
+	if err := a.DB.RemoveOldStreams(); err != nil {
+		a.Log.LogError("STRMS", "error removing old streams",
+			"err", err)
+	}
+	if err := a.DB.MarkEndedStreams(); err != nil {
+		a.Log.LogError("STRMS", "error marking ended streams",
+			"err", err)
+	}
+	if err := a.DB.RemoveExpiredMetadata(); err != nil {
+		a.Log.LogError("STRMS", "error removing expired metadata",
 			"err", err)
 	}
 }
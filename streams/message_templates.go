@@ -0,0 +1,411 @@
+/*
+message_templates.go contains functions for rendering stream announcements from the
+templates set by each server. Templates use Go text/template syntax with a small set of
+safe functions, and are given the stream's values along with whether it has started, so
+that the same template produces both the "starting" and "started" versions of a message.
+*/
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/discord"
+	"gamestreams/locales"
+	"gamestreams/utils"
+)
+
+const (
+	// defaultContentTemplate is the template used for the message content when a
+	// server has not set one.
+	defaultContentTemplate = `{{.Role}}`
+	// defaultTitleTemplate is the template used for the embed title when a server has
+	// not set one.
+	defaultTitleTemplate = `{{.Name}}`
+	// maxTemplateLength is the maximum length of a single template.
+	maxTemplateLength = 1000
+	// maxTemplateOutput is the maximum number of bytes a template can produce before
+	// rendering is stopped.
+	maxTemplateOutput = 8192
+	// templateTimeout is the longest a single template can take to render.
+	templateTimeout = time.Second
+)
+
+// rangeFields are the fields of MessageData that templates can range over. Ranging over
+// anything else, such as a number held in a variable, could loop for an unbounded time.
+var rangeFields = []string{"Publishers", "Games"}
+
+// MessageData holds the values of a stream that are available to announcement
+// templates.
+type MessageData struct {
+	// The name of the stream.
+	Name string
+	// The URL of the stream.
+	URL string
+	// The description of the stream.
+	Description string
+	// The platforms of the stream, separated by commas.
+	Platforms string
+	// The status of the stream.
+	Status string
+	// The date of the stream, in YYYY-MM-DD format.
+	Date string
+	// The date of the stream, in the form used by the server's locale.
+	LocalDate string
+	// The time of the stream in UTC, in HH:MM format.
+	Time string
+	// A relative Discord timestamp for the start of the stream, e.g. "in 10 minutes".
+	Starts string
+	// A full Discord timestamp for the start of the stream, shown in the reader's time
+	// zone.
+	StartTime string
+	// The names of the publishers presenting the stream.
+	Publishers []string
+	// The names of the games featured in the stream.
+	Games []string
+	// The mention of the server's announcement role, if any.
+	Role string
+	// True once the stream has started.
+	Started bool
+	// The URL of the stream's thumbnail.
+	Thumbnail string
+	// The locale the announcement is rendered in.
+	Locale string
+}
+
+// defaultDescriptionTemplate returns the template used for the embed description in
+// the given locale when a server has not set one.
+func defaultDescriptionTemplate(locale string) string {
+	return "**{{if .Started}}" + locales.T(locale, "announcement.started", "{{.Starts}}") +
+		"{{else}}" + locales.T(locale, "announcement.starting", "{{.Starts}}") +
+		"{{end}}.**\n\n{{.Description}}"
+}
+
+// localise returns the values with the locale and the date in the form used by it.
+func (d MessageData) localise(locale string) MessageData {
+	if locale == "" {
+		locale = locales.Default
+	}
+	d.Locale = locale
+	d.LocalDate = locales.FormatDateString(locale, d.Date)
+	return d
+}
+
+// templateFuncs are the functions available to announcement templates.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"join": func(sep string, values []string) string {
+		return strings.Join(values, sep)
+	},
+	"truncate": func(n int, s string) string {
+		return utils.Truncate(s, n)
+	},
+	"default": func(fallback string, value string) string {
+		if strings.TrimSpace(value) == "" {
+			return fallback
+		}
+		return value
+	},
+	"call": func(...any) (string, error) {
+		return "", errors.New("call is not allowed")
+	},
+}
+
+// newMessageData returns the template values for the stream. The thumbnail is resolved
+// here so that it is only fetched once, however many servers the stream is rendered for.
+func newMessageData(a *app.App, stream db.Stream) (MessageData, error) {
+	starts, tsErr := discord.CreateTimestampRelative(stream.Date, stream.Time)
+	if tsErr != nil {
+		return MessageData{}, tsErr
+	}
+	start, parseErr := streamStartTime(stream)
+	if parseErr != nil {
+		return MessageData{}, parseErr
+	}
+	if stream.Publishers == nil && stream.Games == nil && stream.ID != 0 {
+		// The publishers and games are only used by templates, so the stream is still
+		// announced without them.
+		if loadErr := stream.LoadEntities(a.DB); loadErr != nil {
+			a.Log.LogError("STRMS", "error loading publishers and games",
+				"stream", stream.Name,
+				"err", loadErr)
+		}
+	}
+	return MessageData{
+		Name:        stream.Name,
+		URL:         stream.URL,
+		Description: stream.Description,
+		Platforms:   stream.Platform,
+		Status:      stream.Status,
+		Date:        stream.Date,
+		Time:        stream.Time,
+		Starts:      starts,
+		StartTime:   fmt.Sprintf("<t:%d:f>", start.Unix()),
+		Publishers:  stream.Publishers,
+		Games:       stream.Games,
+		Started:     stream.Status == db.StatusLive || stream.ActualStart != "",
+		Thumbnail:   a.Providers.Thumbnail(stream.URL),
+	}.localise(locales.Default), nil
+}
+
+// sampleMessageData returns template values for an example stream, used to check and
+// preview templates when there are no upcoming streams.
+func sampleMessageData() MessageData {
+	start := time.Now().UTC().Add(10 * time.Minute)
+	return MessageData{
+		Name:        "Example Showcase",
+		URL:         "https://www.youtube.com/@example",
+		Description: "A look at upcoming games.",
+		Platforms:   "PlayStation, Xbox, PC",
+		Status:      db.StatusConfirmed,
+		Date:        start.Format("2006-01-02"),
+		Time:        start.Format("15:04"),
+		Starts:      fmt.Sprintf("<t:%d:R>", start.Unix()),
+		StartTime:   fmt.Sprintf("<t:%d:f>", start.Unix()),
+		Publishers:  []string{"Example Publisher"},
+		Games:       []string{"Example Game"},
+	}.localise(locales.Default)
+}
+
+// renderMessage renders the server's templates with the given values, returning the
+// message content and embed.
+func renderMessage(a *app.App, t db.MessageTemplate, data MessageData) (string, *discordgo.MessageEmbed, error) {
+	content, contentErr := renderTemplate("content", t.Content, defaultContentTemplate, data)
+	if contentErr != nil {
+		return "", nil, contentErr
+	}
+	title, titleErr := renderTemplate("title", t.Title, defaultTitleTemplate, data)
+	if titleErr != nil {
+		return "", nil, titleErr
+	}
+	description, descErr := renderTemplate("description", t.Description,
+		defaultDescriptionTemplate(data.Locale), data)
+	if descErr != nil {
+		return "", nil, descErr
+	}
+	footer, footerErr := renderTemplate("footer", t.Footer, "", data)
+	if footerErr != nil {
+		return "", nil, footerErr
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       utils.Truncate(title, 256),
+		URL:         data.URL,
+		Type:        "video",
+		Description: utils.Truncate(description, 4096),
+		Color:       a.Config().Discord.EmbedColour,
+	}
+	if !t.HideThumbnail && data.Thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: data.Thumbnail}
+	}
+	if !t.HidePlatforms {
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "\u200b\n" + locales.T(data.Locale, "field.platforms"),
+				Value:  utils.PlaceholderText(data.Platforms, data.Locale),
+				Inline: false,
+			},
+		}
+	}
+	if footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: utils.Truncate(footer, 2048)}
+	}
+	return utils.Truncate(content, 2000), embed, nil
+}
+
+// renderAnnouncement renders the server's templates with the given values. If the
+// server's templates fail to render, the default templates are used instead.
+func renderAnnouncement(a *app.App, t db.MessageTemplate, data MessageData) (string, *discordgo.MessageEmbed, error) {
+	content, embed, renderErr := renderMessage(a, t, data)
+	if renderErr == nil {
+		return content, embed, nil
+	}
+	content, embed, defaultErr := renderMessage(a, db.MessageTemplate{ServerID: t.ServerID}, data)
+	if defaultErr != nil {
+		return "", nil, defaultErr
+	}
+	return content, embed, renderErr
+}
+
+// renderTemplate parses and executes a single template, using the fallback if the
+// template is empty. Rendering is abandoned if it takes longer than templateTimeout.
+func renderTemplate(name string, text string, fallback string, data MessageData) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	if text == "" {
+		return "", nil
+	}
+	tmpl, parseErr := parseTemplate(name, text)
+	if parseErr != nil {
+		return "", parseErr
+	}
+	out := &limitedBuilder{limit: maxTemplateOutput, deadline: time.Now().Add(templateTimeout)}
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(out, data)
+	}()
+	timer := time.NewTimer(templateTimeout)
+	defer timer.Stop()
+	select {
+	case execErr := <-done:
+		if execErr != nil {
+			return "", fmt.Errorf("%s template: %w", name, execErr)
+		}
+		return strings.TrimSpace(out.String()), nil
+	case <-timer.C:
+		return "", fmt.Errorf("%s template took longer than %s to render", name, templateTimeout)
+	}
+}
+
+// parseTemplate parses the template and checks that it is safe to run.
+func parseTemplate(name string, text string) (*template.Template, error) {
+	if len(text) > maxTemplateLength {
+		return nil, fmt.Errorf("%s template is longer than %d characters", name, maxTemplateLength)
+	}
+	tmpl, parseErr := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if parseErr != nil {
+		return nil, fmt.Errorf("%s template: %w", name, parseErr)
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if checkErr := checkTemplateNode(t.Tree.Root); checkErr != nil {
+			return nil, fmt.Errorf("%s template: %w", name, checkErr)
+		}
+	}
+	return tmpl, nil
+}
+
+// checkTemplateNode returns an error if the node or any of its children ranges over
+// anything but one of the rangeFields, which would let a template loop for an unbounded
+// time, or calls another template.
+func checkTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.RangeNode:
+		if !rangesOverField(n.Pipe) {
+			return fmt.Errorf("range is only allowed over .%s",
+				strings.Join(rangeFields, " or ."))
+		}
+		return checkBranch(n.List, n.ElseList)
+	case *parse.IfNode:
+		return checkBranch(n.List, n.ElseList)
+	case *parse.WithNode:
+		return checkBranch(n.List, n.ElseList)
+	case *parse.TemplateNode:
+		return errors.New("calling other templates is not allowed")
+	}
+	return nil
+}
+
+// rangesOverField reports whether the pipeline of a range node is just one of the
+// rangeFields of the template values, e.g. .Publishers or $.Games.
+func rangesOverField(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	var ident []string
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		ident = arg.Ident
+	case *parse.VariableNode:
+		if len(arg.Ident) == 0 || arg.Ident[0] != "$" {
+			return false
+		}
+		ident = arg.Ident[1:]
+	default:
+		return false
+	}
+	return len(ident) == 1 && slices.Contains(rangeFields, ident[0])
+}
+
+// checkBranch checks the lists of a range, if or with node.
+func checkBranch(list *parse.ListNode, elseList *parse.ListNode) error {
+	if err := checkTemplateNode(list); err != nil {
+		return err
+	}
+	return checkTemplateNode(elseList)
+}
+
+// limitedBuilder is a strings.Builder that returns an error once more than limit bytes
+// have been written to it, or once its deadline has passed.
+type limitedBuilder struct {
+	strings.Builder
+	// The maximum number of bytes that can be written.
+	limit int
+	// The time after which writes fail, so a template that has timed out stops.
+	deadline time.Time
+}
+
+// Write writes p to the builder, or returns an error if it would exceed the limit or
+// the deadline has passed.
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("output is longer than %d characters", b.limit)
+	}
+	if time.Now().After(b.deadline) {
+		return 0, fmt.Errorf("took longer than %s to render", templateTimeout)
+	}
+	return b.Builder.Write(p)
+}
+
+// CheckMessageTemplate renders the templates with example values and returns an error
+// describing the first template that cannot be rendered.
+func CheckMessageTemplate(a *app.App, t db.MessageTemplate) error {
+	data := sampleMessageData()
+	if _, _, renderErr := renderMessage(a, t, data); renderErr != nil {
+		return renderErr
+	}
+	data.Started = true
+	_, _, renderErr := renderMessage(a, t, data)
+	return renderErr
+}
+
+// PreviewMessage renders the server's templates for the next upcoming stream, or an
+// example stream if there are none, in both the starting and started states and in the
+// given locale. It returns the message content and the two embeds.
+func PreviewMessage(a *app.App, t db.MessageTemplate, roleID string, locale string) (string, []*discordgo.MessageEmbed, error) {
+	data := sampleMessageData()
+	var upcoming db.Streams
+	if getErr := upcoming.GetUpcoming(a.DB, 1); getErr == nil && len(upcoming.Streams) > 0 &&
+		upcoming.Streams[0].Time != "" {
+		stream := upcoming.Streams[0]
+		MakeStreamURLDirect(a, &stream)
+		if streamData, dataErr := newMessageData(a, stream); dataErr == nil {
+			data = streamData
+		}
+	}
+	data = data.localise(locale)
+	data.Role = discord.DisplayRole(a.Session, t.ServerID, roleID)
+	data.Started = false
+	content, starting, startingErr := renderMessage(a, t, data)
+	if startingErr != nil {
+		return "", nil, startingErr
+	}
+	data.Started = true
+	_, started, startedErr := renderMessage(a, t, data)
+	if startedErr != nil {
+		return "", nil, startedErr
+	}
+	return content, []*discordgo.MessageEmbed{starting, started}, nil
+}
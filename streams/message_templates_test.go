@@ -0,0 +1,80 @@
+package streams
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTemplateRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		allowed bool
+	}{
+		{"field", `{{range .Publishers}}{{.}} {{end}}`, true},
+		{"field with else", `{{range .Games}}{{.}}{{else}}none{{end}}`, true},
+		{"declared field", `{{range $i, $game := .Games}}{{$i}}{{$game}}{{end}}`, true},
+		{"root variable field", `{{range .Publishers}}{{range $.Games}}{{.}}{{end}}{{end}}`, true},
+		{"nested in if", `{{if .Started}}{{range .Publishers}}{{.}}{{end}}{{end}}`, true},
+		{"number", `{{range 300000000}}{{end}}`, false},
+		{"variable", `{{$n := 300000000}}{{range $n}}{{end}}`, false},
+		{"max int variable", `{{$n := 9223372036854775807}}{{range $n}}{{end}}`, false},
+		{"declared variable", `{{$n := 5}}{{range $i := $n}}{{end}}`, false},
+		{"pipeline", `{{range .Publishers | len}}{{end}}`, false},
+		{"len", `{{range len .Publishers}}{{end}}`, false},
+		{"parenthesised len", `{{range (len .Games)}}{{end}}`, false},
+		{"other field", `{{range .Name}}{{end}}`, false},
+		{"dot", `{{with .Publishers}}{{range .}}{{end}}{{end}}`, false},
+		{"nested in else", `{{if .Started}}{{else}}{{$n := 9}}{{range $n}}{{end}}{{end}}`, false},
+		{"template call", `{{define "x"}}{{end}}{{template "x"}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, parseErr := parseTemplate("description", tt.text)
+			if tt.allowed && parseErr != nil {
+				t.Errorf("parseTemplate(%q) = %v, want no error", tt.text, parseErr)
+			}
+			if !tt.allowed && parseErr == nil {
+				t.Errorf("parseTemplate(%q) succeeded, want an error", tt.text)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := MessageData{
+		Name:       "Showcase",
+		Publishers: []string{"Xbox", "Bethesda"},
+		Games:      []string{"Starfield"},
+	}
+	out, renderErr := renderTemplate("description",
+		`{{range $i, $p := .Publishers}}{{if $i}}, {{end}}{{$p}}{{end}}: {{range .Games}}{{.}}{{end}}`,
+		"", data)
+	if renderErr != nil {
+		t.Fatalf("renderTemplate() error = %v", renderErr)
+	}
+	if want := "Xbox, Bethesda: Starfield"; out != want {
+		t.Errorf("renderTemplate() = %q, want %q", out, want)
+	}
+
+	if _, renderErr := renderTemplate("description", `{{$n := 300000000}}{{range $n}}{{end}}`,
+		"", data); renderErr == nil {
+		t.Error("renderTemplate() of a range over a variable succeeded, want an error")
+	}
+}
+
+func TestRenderTemplateOutputLimit(t *testing.T) {
+	data := MessageData{Publishers: make([]string, 100)}
+	text := `{{range .Publishers}}` + strings.Repeat("x", 100) + `{{end}}`
+	if _, renderErr := renderTemplate("description", text, "", data); renderErr == nil {
+		t.Error("renderTemplate() of a template longer than the limit succeeded, want an error")
+	}
+}
+
+func TestLimitedBuilderDeadline(t *testing.T) {
+	b := &limitedBuilder{limit: maxTemplateOutput, deadline: time.Now().Add(-time.Second)}
+	if _, writeErr := b.Write([]byte("x")); writeErr == nil {
+		t.Error("Write() after the deadline succeeded, want an error")
+	}
+}
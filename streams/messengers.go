@@ -0,0 +1,76 @@
+/*
+messengers.go contains functions for announcing streams with the messengers added to
+the app, such as in Telegram chats, so that streams can be announced to communities
+that are not on Discord.
+*/
+package streams
+
+import (
+	"fmt"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/locales"
+	"gamestreams/messenger"
+)
+
+// PostMessengers announces the stream in the channels set in config.toml for each
+// messenger of the app. Discord servers are not announced to here, as each server sets
+// its own announce channel and is announced to by PostStreamLink.
+func PostMessengers(a *app.App, stream db.Stream) {
+	var channels int
+	for _, m := range a.Messengers {
+		channels += len(m.Channels())
+	}
+	if channels == 0 {
+		return
+	}
+	MakeStreamURLDirect(a, &stream)
+	data, dataErr := newMessageData(a, stream)
+	if dataErr != nil {
+		a.Log.LogError("STRMS", "error creating message data",
+			"stream", stream.Name,
+			"err", dataErr)
+		return
+	}
+	msg := messengerMessage(data)
+	for _, m := range a.Messengers {
+		for _, channelID := range m.Channels() {
+			if _, sendErr := m.Send(channelID, msg); sendErr != nil {
+				a.Log.LogError("STRMS", "error posting announcement",
+					"messenger", m.Name(),
+					"channel", channelID,
+					"stream", stream.Name,
+					"err", sendErr)
+				continue
+			}
+			a.Log.LogInfo("STRMS", "posted announcement", false,
+				"messenger", m.Name(),
+				"channel", channelID,
+				"stream", stream.Name)
+		}
+	}
+}
+
+// messengerMessage returns the announcement for the stream as a message that every
+// messenger can display.
+func messengerMessage(data MessageData) messenger.Message {
+	text := plainAnnouncement(data)
+	if data.Description != "" {
+		text += "\n\n" + data.Description
+	}
+	return messenger.Message{
+		Title:    data.Name,
+		Text:     text,
+		URL:      data.URL,
+		ImageURL: data.Thumbnail,
+	}
+}
+
+// plainAnnouncement returns a line of plain text saying when the stream starts and its
+// platforms, in the locale of the data.
+func plainAnnouncement(data MessageData) string {
+	starts := fmt.Sprintf("%s %s UTC", data.LocalDate, data.Time)
+	return fmt.Sprintf("%s (%s)", locales.T(data.Locale, "announcement.starting", starts),
+		data.Platforms)
+}
@@ -0,0 +1,104 @@
+/*
+status.go contains functions for displaying the status of streams and for telling
+servers when a stream they have already announced is cancelled or postponed.
+*/
+package streams
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/locales"
+)
+
+// statusEmoji are the emoji shown next to streams of each status. The name of each
+// status is translated using the status.<status> message.
+var statusEmoji = map[string]string{
+	db.StatusRumoured:  "❔",
+	db.StatusAnnounced: "📅",
+	db.StatusConfirmed: "✅",
+	db.StatusLive:      "🔴",
+	db.StatusEnded:     "⏹️",
+	db.StatusCancelled: "❌",
+	db.StatusPostponed: "⏸️",
+}
+
+// StatusBadge returns the badge for the given stream status in the given locale, or an
+// empty string if the status is not known.
+func StatusBadge(status string, locale string) string {
+	emoji, exists := statusEmoji[status]
+	if !exists {
+		return ""
+	}
+	return emoji + " " + locales.T(locale, "status."+status)
+}
+
+// SendStatusFollowups replies to the announcements of streams that have since been
+// cancelled or postponed, telling the servers that announced them about the change.
+// Each server is only told once for each status.
+func SendStatusFollowups(a *app.App) {
+	announcements, getErr := a.DB.GetPendingFollowups()
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting pending follow-ups",
+			"err", getErr)
+		return
+	}
+	for _, announcement := range announcements {
+		var streams db.Streams
+		if streamErr := streams.GetByID(a.DB, announcement.StreamID); streamErr != nil || len(streams.Streams) == 0 {
+			a.Log.LogError("STRMS", "error getting stream for follow-up",
+				"stream", announcement.StreamID,
+				"err", streamErr)
+			continue
+		}
+		stream := streams.Streams[0]
+		locale, localeErr := a.DB.GetServerLocale(announcement.ServerID)
+		if localeErr != nil {
+			a.Log.LogError("STRMS", "error getting server locale",
+				"server", announcement.ServerID,
+				"err", localeErr)
+		}
+		_, sendErr := a.Session.ChannelMessageSendComplex(announcement.ChannelID, &discordgo.MessageSend{
+			Embeds: []*discordgo.MessageEmbed{followupEmbed(a, stream, locale)},
+			Reference: &discordgo.MessageReference{
+				MessageID: announcement.MessageID,
+				ChannelID: announcement.ChannelID,
+				GuildID:   announcement.ServerID,
+			},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+		if sendErr != nil {
+			a.Log.LogError("STRMS", "error posting follow-up",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", sendErr)
+			continue
+		}
+		if setErr := announcement.SetFollowupStatus(a.DB, stream.Status); setErr != nil {
+			a.Log.LogError("STRMS", "error recording follow-up",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", setErr)
+		}
+		a.Log.LogInfo("STRMS", "posted follow-up", false,
+			"server", announcement.ServerID,
+			"stream", stream.Name,
+			"status", stream.Status)
+	}
+}
+
+// followupEmbed returns an embed telling a server that the stream has been cancelled
+// or postponed, in the server's locale.
+func followupEmbed(a *app.App, stream db.Stream, locale string) *discordgo.MessageEmbed {
+	description := locales.T(locale, "followup.cancelled", stream.Name)
+	if stream.Status == db.StatusPostponed {
+		description = locales.T(locale, "followup.postponed", stream.Name)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       StatusBadge(stream.Status, locale),
+		URL:         stream.URL,
+		Description: description,
+		Color:       a.Config().Discord.EmbedColour,
+	}
+}
@@ -9,26 +9,28 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
-	"gamestreams/config"
+	"gamestreams/app"
 	"gamestreams/db"
 	"gamestreams/discord"
-	"gamestreams/logs"
+	"gamestreams/locales"
 	"gamestreams/utils"
 )
 
 // StreamList populates a Streams struct with upcoming streams from the streams table
 // of the database. It then creates a discordgo.MessageEmbed struct with the date, time
-// and title of the next [limit] streams. The limit is set in the config.toml file.
-func StreamList() (*discordgo.MessageEmbed, error) {
+// and title of the next [limit] streams in the given locale. The limit is set in the
+// config.toml file.
+func StreamList(a *app.App, locale string) (*discordgo.MessageEmbed, error) {
 	embed := &discordgo.MessageEmbed{
-		Title: "Upcoming Streams",
-		Color: config.Values.Discord.EmbedColour,
+		Title: locales.T(locale, "streams.title"),
+		Color: a.Config().Discord.EmbedColour,
 	}
 	var streamList db.Streams
-	if upcomErr := streamList.GetUpcoming(); upcomErr != nil {
+	if upcomErr := streamList.GetUpcoming(a.DB); upcomErr != nil {
 		return nil, upcomErr
 	}
 
@@ -36,11 +38,11 @@ func StreamList() (*discordgo.MessageEmbed, error) {
 		return embed, errors.New("no streams found")
 	}
 	for i, stream := range streamList.Streams {
-		logs.LogInfo("STRMS", "creating embed field", false,
+		a.Log.LogInfo("STRMS", "creating embed field", false,
 			"name", stream.Name,
 			"time", stream.Time)
 
-		embedField, embedErr := streamEmbedField(stream)
+		embedField, embedErr := streamEmbedField(stream, locale)
 		if embedErr != nil {
 			return nil, embedErr
 		}
@@ -55,69 +57,95 @@ func StreamList() (*discordgo.MessageEmbed, error) {
 
 // StreamInfo gets a stream from the streams table of the database by name. It then
 // returns a discordgo.MessageEmbed struct with the date, time, platforms, URL, and
-// description of the stream.
-func StreamInfo(streamName string) (*discordgo.MessageEmbed, error) {
+// description of the stream, with the field names in the given locale.
+func StreamInfo(a *app.App, streamName string, locale string) (*discordgo.MessageEmbed, error) {
 	var streams db.Streams
-	if err := streams.GetInfo(streamName); err != nil {
+	if err := streams.GetInfo(a.DB, streamName); err != nil {
 		return nil, err
 	}
 	if len(streams.Streams) == 0 {
 		return nil, errors.New("no streams found")
 	}
 	stream := streams.Streams[0]
-	date, time, dtErr := discord.CreateTimestamp(stream.Date, stream.Time)
+	date, startTime, dtErr := discord.CreateTimestamp(stream.Date, stream.Time)
 	if dtErr != nil {
 		return nil, dtErr
 	}
 	embed := &discordgo.MessageEmbed{
 		Title: stream.Name,
-		Color: config.Values.Discord.EmbedColour,
+		Color: a.Config().Discord.EmbedColour,
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:   "Platforms",
+				Name:   locales.T(locale, "field.status"),
+				Value:  utils.PlaceholderText(StatusBadge(stream.Status, locale), locale),
+				Inline: false,
+			},
+			{
+				Name:   "\u200b\n" + locales.T(locale, "field.platforms"),
 				Value:  stream.Platform,
 				Inline: false,
 			},
 			{
-				Name:   "\u200b\nDate",
+				Name:   "\u200b\n" + locales.T(locale, "field.date"),
 				Value:  date,
 				Inline: true,
 			},
 			{
-				Name:   "\u200b\nTime",
-				Value:  time,
+				Name:   "\u200b\n" + locales.T(locale, "field.time"),
+				Value:  startTime,
 				Inline: true,
 			},
 			{
-				Name:   "\u200b\nURL",
+				Name:   "\u200b\n" + locales.T(locale, "field.url"),
 				Value:  stream.URL,
 				Inline: false,
 			},
 			{
-				Name:   "\u200b\nDescription",
+				Name:   "\u200b\n" + locales.T(locale, "field.description"),
 				Value:  stream.Description,
 				Inline: false,
 			},
 		},
 	}
+	if actualStart, parseErr := time.Parse(time.RFC3339, stream.ActualStart); parseErr == nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "\u200b\n" + locales.T(locale, "field.went_live"),
+			Value:  fmt.Sprintf("<t:%d:t>", actualStart.Unix()),
+			Inline: false,
+		})
+	}
+	if loadErr := stream.LoadEntities(a.DB); loadErr != nil {
+		a.Log.LogError("STRMS", "error getting publishers and games",
+			"stream", stream.ID,
+			"err", loadErr)
+	}
+	if len(stream.Publishers) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "\u200b\n" + locales.T(locale, "field.publishers"),
+			Value:  strings.Join(stream.Publishers, ", "),
+			Inline: false,
+		})
+	}
+	if len(stream.Games) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "\u200b\n" + locales.T(locale, "field.games"),
+			Value:  utils.Truncate(strings.Join(stream.Games, ", "), 1024),
+			Inline: false,
+		})
+	}
 	return embed, nil
 }
 
-// MakeStreamURLDirect checks if the stream URL is a Youtube link and if so, gets the
-// direct URL to the stream. This is done as streams could be linked to as a profile's
+// MakeStreamURLDirect replaces the stream URL with a direct link to the stream using
+// the provider for the URL. This is done as streams could be linked to as a profile's
 // /live URL which would no longer link to the correct video after the stream has ended.
-func MakeStreamURLDirect(stream *db.Stream) {
-	if strings.Contains(stream.URL, "youtube") {
-		directUrl, success := utils.GetYoutubeDirectURL(stream.URL)
-		if success {
-			stream.URL = directUrl
-		}
-	}
+func MakeStreamURLDirect(a *app.App, stream *db.Stream) {
+	stream.URL = a.Providers.Direct(stream.URL)
 }
 
 // streamEmbedField returns a discordgo.MessageEmbedField struct with the date, time,
-// and name of the given stream.
-func streamEmbedField(stream db.Stream) (*discordgo.MessageEmbedField, error) {
+// name and status badge of the given stream, in the given locale.
+func streamEmbedField(stream db.Stream, locale string) (*discordgo.MessageEmbedField, error) {
 	ds, ts, tsErr := discord.CreateTimestamp(stream.Date, stream.Time)
 	if tsErr != nil {
 		return nil, tsErr
@@ -127,5 +155,8 @@ func streamEmbedField(stream db.Stream) (*discordgo.MessageEmbedField, error) {
 		Value:  stream.Name,
 		Inline: false,
 	}
+	if badge := StatusBadge(stream.Status, locale); badge != "" {
+		field.Value = fmt.Sprintf("%s\n*%s*", stream.Name, badge)
+	}
 	return field, nil
 }
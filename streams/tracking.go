@@ -0,0 +1,352 @@
+/*
+tracking.go contains functions that act on the announcements recorded in the database.
+Announcements that failed to post are retried, and the messages posted for a stream can
+be edited or deleted, e.g. when its URL changes or it is cancelled.
+*/
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+)
+
+// recordDelivery sets the message, delivery status and error of the announcement from
+// the result of posting it. Failures that can be retried are marked as failed, and
+// permanent failures are marked as abandoned.
+func recordDelivery(a *db.Announcement, msg *discordgo.Message, postErr error) {
+	if postErr == nil {
+		a.ChannelID = msg.ChannelID
+		a.MessageID = msg.ID
+		a.SentAt = time.Now().UTC().Format(time.RFC3339)
+		a.Status = db.AnnouncementSent
+		a.Error = ""
+		return
+	}
+	a.Error = postErr.Error()
+	a.Status = db.AnnouncementAbandoned
+	if retryable(postErr) {
+		a.Status = db.AnnouncementFailed
+	}
+}
+
+// serverRecipient returns the channel, role, locale and announcement templates of the
+// server for the announcement.
+func serverRecipient(a *app.App, announcement db.Announcement) (db.Recipient, error) {
+	var settings db.Settings
+	if getSetErr := settings.Get(a.DB, announcement.ServerID); getSetErr != nil {
+		return db.Recipient{}, getSetErr
+	}
+	messageTemplate, templateErr := a.DB.GetMessageTemplate(announcement.ServerID)
+	if templateErr != nil {
+		return db.Recipient{}, templateErr
+	}
+	locale, localeErr := a.DB.GetServerLocale(announcement.ServerID)
+	if localeErr != nil {
+		return db.Recipient{}, localeErr
+	}
+	recipient := db.Recipient{
+		ServerID:  announcement.ServerID,
+		ChannelID: announcement.ChannelID,
+		RoleID:    settings.AnnounceRole.Value,
+		Template:  messageTemplate,
+		Locale:    locale,
+		Crosspost: settings.Crosspost.Value,
+	}
+	// The public announcement channel does not use the settings of its server.
+	if announcement.ChannelID == a.Config().Announcements.PublicChannel {
+		recipient.RoleID = ""
+		recipient.Template = db.MessageTemplate{ServerID: announcement.ServerID}
+		recipient.Crosspost = true
+	}
+	return recipient, nil
+}
+
+// streamMessageData returns the template values for the stream, using its direct URL.
+func streamMessageData(a *app.App, stream db.Stream) (MessageData, error) {
+	MakeStreamURLDirect(a, &stream)
+	return newMessageData(a, stream)
+}
+
+// RetryFailedAnnouncements reposts the announcements that failed to post with an error
+// that can be retried. Announcements are abandoned once the retry limit set in
+// config.toml is reached, the stream can no longer be announced, or the stream started
+// longer ago than the retry window.
+func RetryFailedAnnouncements(a *app.App) {
+	announcements, getErr := a.DB.GetFailedAnnouncements()
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting failed announcements",
+			"err", getErr)
+		return
+	}
+	limit, window := retryLimits(a)
+	for _, announcement := range announcements {
+		var streams db.Streams
+		if streamErr := streams.GetByID(a.DB, announcement.StreamID); streamErr != nil || len(streams.Streams) == 0 {
+			a.Log.LogError("STRMS", "error getting stream for retry",
+				"stream", announcement.StreamID,
+				"err", streamErr)
+			continue
+		}
+		stream := streams.Streams[0]
+		start, parseErr := streamStartTime(stream)
+		if announcement.Attempts >= limit || !stream.Announceable() || parseErr != nil ||
+			time.Since(start) > window {
+			announcement.Status = db.AnnouncementAbandoned
+			if updateErr := announcement.Update(a.DB); updateErr != nil {
+				a.Log.LogError("STRMS", "error abandoning announcement",
+					"server", announcement.ServerID,
+					"stream", stream.Name,
+					"err", updateErr)
+			}
+			a.Log.LogInfo("STRMS", "abandoned failed announcement", false,
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"attempts", announcement.Attempts,
+				"err", announcement.Error)
+			continue
+		}
+		data, dataErr := streamMessageData(a, stream)
+		if dataErr != nil {
+			a.Log.LogError("STRMS", "error creating message data",
+				"stream", stream.Name,
+				"err", dataErr)
+			continue
+		}
+		recipient, recipientErr := serverRecipient(a, announcement)
+		if recipientErr != nil {
+			a.Log.LogError("STRMS", "error getting settings",
+				"server", announcement.ServerID,
+				"err", recipientErr)
+			continue
+		}
+		content, embed, _ := renderRecipient(a, recipient, data)
+		if embed == nil {
+			continue
+		}
+		msg, attempts, postErr := withRetry(a, func() (*discordgo.Message, error) {
+			return a.Session.ChannelMessageSendComplex(announcement.ChannelID, &discordgo.MessageSend{
+				Content: content,
+				Embed:   embed,
+			})
+		})
+		announcement.Attempts += attempts
+		recordDelivery(&announcement, msg, postErr)
+		var publishErr error
+		if postErr == nil && recipient.Crosspost {
+			announcement.PublishStatus, publishErr = publish(a, msg)
+		}
+		if updateErr := announcement.Update(a.DB); updateErr != nil {
+			a.Log.LogError("STRMS", "error recording announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", updateErr)
+		}
+		if postErr != nil {
+			a.Log.LogError("STRMS", "error retrying announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"attempts", announcement.Attempts,
+				"err", postErr)
+			continue
+		}
+		if publishErr != nil {
+			a.Log.LogError("STRMS", "error publishing announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"status", announcement.PublishStatus,
+				"err", publishErr)
+		}
+		a.Log.LogInfo("STRMS", "retried announcement", false,
+			"server", announcement.ServerID,
+			"stream", stream.Name,
+			"attempts", announcement.Attempts)
+		if !data.Started {
+			posted := []postedAnnouncement{{msg: msg, recipient: recipient}}
+			a.Go("edit announcements of "+stream.Name, func() {
+				EditAnnouncementEmbeds(a, posted, data, stream)
+			})
+		}
+	}
+}
+
+// retryLimits returns the retry limit and retry window set in config.toml, using
+// defaults for any that are not set.
+func retryLimits(a *app.App) (int, time.Duration) {
+	limit := a.Config().Announcements.RetryLimit
+	if limit <= 0 {
+		limit = 10
+	}
+	window := time.Duration(a.Config().Announcements.RetryWindowMinutes) * time.Minute
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	return limit, window
+}
+
+// EditStreamAnnouncements edits every posted announcement of the stream to match the
+// stream's current values. It returns the number of messages edited.
+func EditStreamAnnouncements(a *app.App, stream db.Stream) (int, error) {
+	announcements, getErr := a.DB.GetStreamAnnouncements(stream.ID)
+	if getErr != nil {
+		return 0, getErr
+	}
+	if len(announcements) == 0 {
+		return 0, nil
+	}
+	data, dataErr := streamMessageData(a, stream)
+	if dataErr != nil {
+		return 0, dataErr
+	}
+	edited := make([]bool, len(announcements))
+	runWorkers(a, len(announcements), func(i int) {
+		announcement := &announcements[i]
+		recipient, recipientErr := serverRecipient(a, *announcement)
+		if recipientErr != nil {
+			a.Log.LogError("STRMS", "error getting settings",
+				"server", announcement.ServerID,
+				"err", recipientErr)
+			return
+		}
+		_, embed, _ := renderRecipient(a, recipient, data)
+		if embed == nil {
+			return
+		}
+		medit := discordgo.NewMessageEdit(announcement.ChannelID, announcement.MessageID).SetEmbed(embed)
+		_, _, editErr := withRetry(a, func() (*discordgo.Message, error) {
+			return a.Session.ChannelMessageEditComplex(medit)
+		})
+		if editErr != nil {
+			a.Log.LogError("STRMS", "error editing announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", editErr)
+			return
+		}
+		announcement.StreamURL = stream.URL
+		if updateErr := announcement.Update(a.DB); updateErr != nil {
+			a.Log.LogError("STRMS", "error recording announcement",
+				"server", announcement.ServerID,
+				"stream", stream.Name,
+				"err", updateErr)
+		}
+		edited[i] = true
+	})
+	return countTrue(edited), nil
+}
+
+// DeleteStreamAnnouncements deletes every posted announcement of the stream with the
+// given ID. Messages that have already been deleted are treated as deleted. It returns
+// the number of messages deleted.
+func DeleteStreamAnnouncements(a *app.App, streamID int) (int, error) {
+	announcements, getErr := a.DB.GetStreamAnnouncements(streamID)
+	if getErr != nil {
+		return 0, getErr
+	}
+	deleted := make([]bool, len(announcements))
+	runWorkers(a, len(announcements), func(i int) {
+		announcement := &announcements[i]
+		_, _, deleteErr := withRetry(a, func() (*discordgo.Message, error) {
+			return nil, a.Session.ChannelMessageDelete(announcement.ChannelID, announcement.MessageID)
+		})
+		var restErr *discordgo.RESTError
+		if deleteErr != nil && !(errors.As(deleteErr, &restErr) && restErr.Response != nil &&
+			restErr.Response.StatusCode == http.StatusNotFound) {
+			a.Log.LogError("STRMS", "error deleting announcement",
+				"server", announcement.ServerID,
+				"stream", streamID,
+				"err", deleteErr)
+			return
+		}
+		announcement.Status = db.AnnouncementDeleted
+		if updateErr := announcement.Update(a.DB); updateErr != nil {
+			a.Log.LogError("STRMS", "error recording announcement",
+				"server", announcement.ServerID,
+				"stream", streamID,
+				"err", updateErr)
+		}
+		deleted[i] = true
+	})
+	return countTrue(deleted), nil
+}
+
+// SyncAnnouncements edits the posted announcements of streams whose URL has changed
+// since they were announced, so the messages link to the new URL.
+func SyncAnnouncements(a *app.App) {
+	announcements, getErr := a.DB.GetChangedAnnouncements()
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting changed announcements",
+			"err", getErr)
+		return
+	}
+	synced := make(map[int]bool)
+	for _, announcement := range announcements {
+		if synced[announcement.StreamID] {
+			continue
+		}
+		synced[announcement.StreamID] = true
+		var streams db.Streams
+		if streamErr := streams.GetByID(a.DB, announcement.StreamID); streamErr != nil || len(streams.Streams) == 0 {
+			a.Log.LogError("STRMS", "error getting stream for announcement edit",
+				"stream", announcement.StreamID,
+				"err", streamErr)
+			continue
+		}
+		edited, editErr := EditStreamAnnouncements(a, streams.Streams[0])
+		if editErr != nil {
+			a.Log.LogError("STRMS", "error editing announcements",
+				"stream", announcement.StreamID,
+				"err", editErr)
+			continue
+		}
+		a.Log.LogInfo("STRMS", "edited announcements for changed URL", false,
+			"stream", streams.Streams[0].Name,
+			"count", edited)
+	}
+}
+
+// countTrue returns the number of values that are true.
+func countTrue(values []bool) int {
+	var count int
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
+
+// AnnouncementSummary returns a description of the delivery and publish status of each
+// announcement of the stream with the given ID, for use in owner commands.
+func AnnouncementSummary(a *app.App, streamID int) (string, error) {
+	counts, countErr := a.DB.CountStreamAnnouncements(streamID)
+	if countErr != nil {
+		return "", countErr
+	}
+	if len(counts) == 0 {
+		return "no announcements", nil
+	}
+	var summary string
+	for _, status := range []string{db.AnnouncementSent, db.AnnouncementFailed,
+		db.AnnouncementAbandoned, db.AnnouncementDeleted} {
+		summary += fmt.Sprintf("%s: `%d`\n", status, counts[status])
+	}
+	publishes, publishErr := a.DB.CountStreamPublishes(streamID)
+	if publishErr != nil {
+		return "", publishErr
+	}
+	if len(publishes) > 0 {
+		summary += "\n**Crossposts**\n"
+		for _, status := range []string{db.PublishPublished, db.PublishFailed,
+			db.PublishAbandoned} {
+			summary += fmt.Sprintf("%s: `%d`\n", status, publishes[status])
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,397 @@
+/*
+webhooks.go contains functions for delivering stream announcements to outbound webhooks.
+Webhooks are delivered to by the same scheduler as channel announcements, using the
+worker pool and the shared HTTP client, which retries failed requests. Each webhook
+counts the deliveries that fail in a row and is disabled once the limit set in
+config.toml is reached.
+*/
+package streams
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gamestreams/app"
+	"gamestreams/db"
+	"gamestreams/locales"
+	"gamestreams/utils"
+)
+
+// webhookEvent is the payload sent to generic JSON webhooks.
+type webhookEvent struct {
+	// The name of the event, always stream.starting.
+	Event string `json:"event"`
+	// The number of minutes before the stream starts that it was delivered.
+	LeadTime int `json:"lead_time"`
+	// The stream that is starting.
+	Stream webhookStream `json:"stream"`
+}
+
+// webhookStream holds the values of a stream sent to generic JSON webhooks.
+type webhookStream struct {
+	// The ID of the stream.
+	ID int `json:"id"`
+	// The name of the stream.
+	Name string `json:"name"`
+	// The URL of the stream.
+	URL string `json:"url"`
+	// The description of the stream.
+	Description string `json:"description"`
+	// The platforms of the stream.
+	Platforms []string `json:"platforms"`
+	// The status of the stream.
+	Status string `json:"status"`
+	// The start time of the stream, in RFC3339 format.
+	Start string `json:"start"`
+	// The names of the publishers presenting the stream.
+	Publishers []string `json:"publishers"`
+	// The names of the games featured in the stream.
+	Games []string `json:"games"`
+	// The URL of the stream's thumbnail.
+	Thumbnail string `json:"thumbnail,omitempty"`
+}
+
+// discordWebhookMessage is the payload sent to Discord webhooks.
+type discordWebhookMessage struct {
+	// The message content.
+	Content string `json:"content,omitempty"`
+	// The announcement embed.
+	Embeds []*discordgo.MessageEmbed `json:"embeds"`
+	// The mentions that are allowed, which is none.
+	AllowedMentions *discordgo.MessageAllowedMentions `json:"allowed_mentions"`
+}
+
+// slackWebhookMessage is the payload sent to Slack and Slack-compatible webhooks.
+type slackWebhookMessage struct {
+	// The text of the message.
+	Text string `json:"text"`
+}
+
+// discordWebhookHosts are the hosts that Discord webhook URLs can use.
+var discordWebhookHosts = []string{"discord.com", "discordapp.com", "ptb.discord.com",
+	"canary.discord.com"}
+
+// PostWebhooks delivers the stream to the enabled webhooks that use the given lead time
+// and follow one or more of the platforms of the stream. The webhooks are delivered to
+// by the worker pool, and the outcome of each delivery is recorded against the webhook.
+func PostWebhooks(a *app.App, stream db.Stream, leadTime int) {
+	webhooks, getErr := a.DB.GetStreamWebhooks(stream, leadTime)
+	if getErr != nil {
+		a.Log.LogError("STRMS", "error getting webhooks",
+			"err", getErr)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	MakeStreamURLDirect(a, &stream)
+	data, dataErr := newMessageData(a, stream)
+	if dataErr != nil {
+		a.Log.LogError("STRMS", "error creating message data",
+			"stream", stream.Name,
+			"err", dataErr)
+		return
+	}
+	failed := make([]bool, len(webhooks))
+	runWorkers(a, len(webhooks), func(i int) {
+		w := &webhooks[i]
+		deliverErr := deliverWebhook(a, *w, stream, data, leadTime)
+		if deliverErr == nil {
+			if recordErr := w.RecordSuccess(a.DB); recordErr != nil {
+				a.Log.LogError("STRMS", "error recording webhook delivery",
+					"webhook", w.ID,
+					"err", recordErr)
+			}
+			return
+		}
+		failed[i] = true
+		disabled, recordErr := w.RecordFailure(a.DB, deliverErr, webhookDisableAfter(a))
+		if recordErr != nil {
+			a.Log.LogError("STRMS", "error recording webhook failure",
+				"webhook", w.ID,
+				"err", recordErr)
+		}
+		// The endpoint belongs to the server, so the bot owner is only told when the
+		// webhook is disabled.
+		a.Log.LogInfo("STRMS", "error delivering webhook", false,
+			"webhook", w.ID,
+			"server", w.ServerID,
+			"failures", w.Failures,
+			"err", deliverErr)
+		if disabled {
+			a.Log.LogInfo("STRMS", "disabled failing webhook", true,
+				"webhook", w.ID,
+				"server", w.ServerID,
+				"failures", w.Failures,
+				"err", deliverErr)
+		}
+	})
+	a.Log.LogInfo("STRMS", "delivered webhooks", false,
+		"stream", stream.Name,
+		"sent", len(webhooks)-countTrue(failed),
+		"failed", countTrue(failed))
+}
+
+// deliverWebhook posts the payload for the kind of the webhook to its URL. It returns
+// an error if the request fails after retrying or the response is not successful.
+func deliverWebhook(a *app.App, w db.Webhook, stream db.Stream, data MessageData, leadTime int) error {
+	var payload any
+	switch w.Kind {
+	case db.WebhookDiscord:
+		discordPayload, payloadErr := discordWebhookPayload(a, w, data)
+		if payloadErr != nil {
+			return payloadErr
+		}
+		payload = discordPayload
+	case db.WebhookSlack:
+		payload = slackWebhookPayload(a, w, data)
+	case db.WebhookJSON:
+		payload = jsonWebhookPayload(stream, data, leadTime)
+	default:
+		return fmt.Errorf("unknown webhook kind %q", w.Kind)
+	}
+	body, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	res, postErr := a.HTTP.WebhookPost(w.URL, body, func() map[string]string {
+		return webhookHeaders(w, body, time.Now())
+	})
+	if postErr != nil {
+		return postErr
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+	}
+	return nil
+}
+
+// webhookHeaders returns the headers of a delivery to the webhook made at the time.
+// Deliveries to JSON webhooks with a secret are signed with the time, so the headers
+// are made again for each attempt.
+func webhookHeaders(w db.Webhook, body []byte, now time.Time) map[string]string {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if w.Kind == db.WebhookJSON && w.Secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		headers["X-GameStreams-Event"] = "stream.starting"
+		headers["X-GameStreams-Timestamp"] = timestamp
+		headers["X-GameStreams-Signature"] = "sha256=" + signWebhook(w.Secret, timestamp, body)
+	}
+	return headers
+}
+
+// discordWebhookPayload returns the announcement rendered with the templates and locale
+// of the server that registered the webhook, or the default templates for webhooks
+// registered by the bot owner.
+func discordWebhookPayload(a *app.App, w db.Webhook, data MessageData) (discordWebhookMessage, error) {
+	t := db.MessageTemplate{ServerID: w.ServerID}
+	if w.ServerID != "" {
+		serverTemplate, templateErr := a.DB.GetMessageTemplate(w.ServerID)
+		if templateErr != nil {
+			a.Log.LogError("STRMS", "error getting announcement template",
+				"server", w.ServerID,
+				"err", templateErr)
+		} else {
+			t = serverTemplate
+		}
+	}
+	content, embed, renderErr := renderAnnouncement(a, t, data.localise(webhookLocale(a, w)))
+	if embed == nil {
+		return discordWebhookMessage{}, renderErr
+	}
+	return discordWebhookMessage{
+		Content: content,
+		Embeds:  []*discordgo.MessageEmbed{embed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	}, nil
+}
+
+// slackWebhookPayload returns the announcement as plain text, which Slack and Matrix
+// bridges that accept Slack-compatible webhooks can both display.
+func slackWebhookPayload(a *app.App, w db.Webhook, data MessageData) slackWebhookMessage {
+	data = data.localise(webhookLocale(a, w))
+	text := fmt.Sprintf("%s\n%s\n%s", data.Name, plainAnnouncement(data), data.URL)
+	return slackWebhookMessage{Text: text}
+}
+
+// jsonWebhookPayload returns the stream as a webhook event.
+func jsonWebhookPayload(stream db.Stream, data MessageData, leadTime int) webhookEvent {
+	var start string
+	if startTime, parseErr := streamStartTime(stream); parseErr == nil {
+		start = startTime.Format(time.RFC3339)
+	}
+	var platforms []string
+	for _, platform := range strings.Split(stream.Platform, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			platforms = append(platforms, platform)
+		}
+	}
+	return webhookEvent{
+		Event:    "stream.starting",
+		LeadTime: leadTime,
+		Stream: webhookStream{
+			ID:          stream.ID,
+			Name:        data.Name,
+			URL:         data.URL,
+			Description: data.Description,
+			Platforms:   platforms,
+			Status:      data.Status,
+			Start:       start,
+			Publishers:  data.Publishers,
+			Games:       data.Games,
+			Thumbnail:   data.Thumbnail,
+		},
+	}
+}
+
+// webhookLocale returns the locale of the server that registered the webhook, or the
+// default locale.
+func webhookLocale(a *app.App, w db.Webhook) string {
+	if w.ServerID == "" {
+		return locales.Default
+	}
+	locale, localeErr := a.DB.GetServerLocale(w.ServerID)
+	if localeErr != nil {
+		a.Log.LogError("STRMS", "error getting server locale",
+			"server", w.ServerID,
+			"err", localeErr)
+	}
+	return locale
+}
+
+// signWebhook returns the hex encoded HMAC-SHA256 of the timestamp and body, joined by
+// a full stop, using the secret as the key. Receivers can compute the same signature to
+// check that the request came from the bot and was not replayed.
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookDisableAfter returns the number of deliveries in a row that can fail before a
+// webhook is disabled, as set in config.toml.
+func webhookDisableAfter(a *app.App) int {
+	if a.Config().Webhooks.DisableAfter <= 0 {
+		return 5
+	}
+	return a.Config().Webhooks.DisableAfter
+}
+
+// WebhookServerLimit returns the maximum number of webhooks each server can register, as
+// set in config.toml.
+func WebhookServerLimit(a *app.App) int {
+	if a.Config().Webhooks.ServerLimit <= 0 {
+		return 3
+	}
+	return a.Config().Webhooks.ServerLimit
+}
+
+// NewWebhook returns a webhook for the URL, checking that the URL is a public HTTPS URL
+// that can be used for the kind of webhook. If the kind is empty it is worked out from
+// the URL. JSON webhooks are given a random secret to sign payloads with.
+func NewWebhook(serverID string, kind string, rawURL string, platforms string, leadTime int) (db.Webhook, error) {
+	u, parseErr := url.Parse(strings.TrimSpace(rawURL))
+	if parseErr != nil || u.Host == "" {
+		return db.Webhook{}, errors.New("the URL is not valid")
+	}
+	if u.Scheme != "https" {
+		return db.Webhook{}, errors.New("the URL must use https")
+	}
+	if !publicHost(u.Hostname()) {
+		return db.Webhook{}, errors.New("the URL must be a public address")
+	}
+	discordURL := slices.Contains(discordWebhookHosts, strings.ToLower(u.Hostname())) &&
+		strings.HasPrefix(u.Path, "/api/webhooks/")
+	if kind == "" {
+		switch {
+		case discordURL:
+			kind = db.WebhookDiscord
+		case strings.EqualFold(u.Hostname(), "hooks.slack.com"):
+			kind = db.WebhookSlack
+		default:
+			kind = db.WebhookJSON
+		}
+	}
+	w := db.Webhook{
+		ServerID:  serverID,
+		Kind:      kind,
+		URL:       u.String(),
+		Platforms: platforms,
+		LeadTime:  leadTime,
+	}
+	switch kind {
+	case db.WebhookDiscord:
+		if !discordURL {
+			return db.Webhook{}, errors.New("the URL is not a Discord webhook")
+		}
+	case db.WebhookSlack:
+	case db.WebhookJSON:
+		secret := make([]byte, 32)
+		if _, randErr := rand.Read(secret); randErr != nil {
+			return db.Webhook{}, randErr
+		}
+		w.Secret = hex.EncodeToString(secret)
+	default:
+		return db.Webhook{}, fmt.Errorf("unknown webhook kind %q", kind)
+	}
+	return w, nil
+}
+
+// publicHost returns true if the host is not localhost or a private, loopback,
+// link-local or unspecified IP address. This only rejects obviously private URLs when
+// a webhook is added; the address a host name resolves to is checked each time the
+// webhook is delivered to.
+func publicHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return true
+	}
+	return utils.PublicIP(ip)
+}
+
+// DescribeWebhook returns a short description of the webhook for listing, without the
+// path of its URL as it may contain a token.
+func DescribeWebhook(a *app.App, w db.Webhook, locale string) string {
+	host := w.URL
+	if u, parseErr := url.Parse(w.URL); parseErr == nil {
+		host = u.Host
+	}
+	platforms := locales.T(locale, "webhooks.all_platforms")
+	if w.Platforms != "" {
+		platforms = w.Platforms
+	}
+	leadTime := w.LeadTime
+	if leadTime <= 0 {
+		leadTime = a.Config().Schedule.NotificationTMinus
+	}
+	status := locales.T(locale, "webhooks.enabled_status")
+	if !w.Enabled {
+		status = locales.T(locale, "webhooks.disabled_status", w.Failures, w.LastError)
+	} else if w.Failures > 0 {
+		status = locales.T(locale, "webhooks.failing_status", w.Failures, w.LastError)
+	}
+	return fmt.Sprintf("`#%d` %s · %s · %s · %s\n%s", w.ID, w.Kind, host, platforms,
+		locales.T(locale, "common.minutes", leadTime), status)
+}
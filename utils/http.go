@@ -0,0 +1,306 @@
+/*
+http.go contains the HTTP client used for all outbound requests. Requests time
+out after the timeout set in config.toml when they are made, are retried with
+exponential backoff on network errors, 429 and 5xx responses, and are rate limited per
+host so that pages are not scraped too quickly. Requests and the waits between them are
+cancelled when the bot shuts down. Webhooks are posted with a separate
+client that only connects to public addresses and does not follow redirects, so user
+supplied URLs cannot be used to reach the network the bot runs on.
+*/
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"gamestreams/config"
+	"gamestreams/logs"
+)
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address that is not
+// public.
+var ErrPrivateAddress = errors.New("address is not public")
+
+// errRedirect is returned when a webhook responds with a redirect.
+var errRedirect = errors.New("webhook redirects are not followed")
+
+// maxRetryAfter is the longest a Retry-After header can make a request wait before its
+// next attempt, so a host cannot hold up the job that made the request.
+const maxRetryAfter = 60 * time.Second
+
+// Client makes the outbound HTTP requests of the bot. It reads the timeout, retries,
+// rate limit and User-Agent from the configuration each time a request is made, so they
+// can be changed by reloading config.toml.
+type Client struct {
+	// Cancelled when the bot starts shutting down, which cancels the requests in progress
+	// and their waits.
+	ctx context.Context
+	// Returns the current configuration values.
+	config func() *config.Config
+	// The logger that retries are logged with.
+	log *logs.Logger
+	// The client used for all outbound requests except webhooks.
+	http *http.Client
+	// The client used to post to webhooks.
+	public *http.Client
+	// Holds the earliest time the next request can be made to each host.
+	hostNext map[string]time.Time
+	// Guards hostNext.
+	hostNextMu sync.Mutex
+}
+
+// NewClient returns a Client that reads its configuration values with cfg and logs
+// with log. Requests are cancelled once ctx is cancelled. The clients it makes requests with have no timeout of their own, as the
+// timeout set in config.toml is applied to each request. The webhook client checks
+// each address when it is dialled rather than when the URL is added, so a host that
+// resolves to a private address later is still refused, and it does not follow
+// redirects to other hosts.
+func NewClient(ctx context.Context, cfg func() *config.Config, log *logs.Logger) *Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: dialPublic,
+	}
+	return &Client{
+		ctx:    ctx,
+		config: cfg,
+		log:    log,
+		http:   &http.Client{},
+		public: &http.Client{
+			Transport: &http.Transport{
+				// Proxies are not used, as the address of the proxy would be checked
+				// rather than the address of the webhook.
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				TLSHandshakeTimeout: 10 * time.Second,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return errRedirect
+			},
+		},
+		hostNext: make(map[string]time.Time),
+	}
+}
+
+// dialPublic is the Control function of the webhook client's dialer. It refuses to
+// connect to any address that is not public.
+func dialPublic(network string, address string, c syscall.RawConn) error {
+	host, _, splitErr := net.SplitHostPort(address)
+	if splitErr != nil {
+		return splitErr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// PublicIP returns false if the IP address is private, loopback, link-local, multicast
+// or unspecified.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
+
+// Get makes a GET request to the URL. Network errors, 429 and 5xx responses are retried
+// with exponential backoff, honouring any Retry-After header. The caller must close the
+// body of the returned response.
+func (c *Client) Get(URL string) (*http.Response, error) {
+	return c.request(c.http, http.MethodGet, URL, nil, nil)
+}
+
+// Post makes a POST request to the URL with the body and headers. As the request may
+// have been received even if no response was, it is only retried if the connection
+// could not be made or the response is a 429 or 5xx. The caller must close the body of
+// the returned response.
+func (c *Client) Post(URL string, body []byte, headers map[string]string) (*http.Response, error) {
+	return c.request(c.http, http.MethodPost, URL, body, func() map[string]string {
+		return headers
+	})
+}
+
+// WebhookPost makes a POST request to the webhook URL with the body, retrying in the
+// same way as Post. headers is called before each attempt, so that signatures that
+// include the time are made again for each attempt. The request is refused with
+// ErrPrivateAddress if the host resolves to an address that is not public, and
+// redirects are not followed. The caller must close the body of the returned response.
+func (c *Client) WebhookPost(URL string, body []byte, headers func() map[string]string) (*http.Response, error) {
+	return c.request(c.public, http.MethodPost, URL, body, headers)
+}
+
+// requestTimeout returns the timeout for each attempt of a request, as set in
+// config.toml when the attempt is made.
+func (c *Client) requestTimeout() time.Duration {
+	timeout := time.Duration(c.config().HTTP.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return timeout
+}
+
+// cancelBody is the body of a response that cancels the context of its request when it
+// is closed, so the timeout also covers reading the body.
+type cancelBody struct {
+	io.ReadCloser
+	// The function that cancels the context of the request.
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the request.
+func (b *cancelBody) Close() error {
+	closeErr := b.ReadCloser.Close()
+	b.cancel()
+	return closeErr
+}
+
+// request makes a request to the URL with the HTTP client and the given method and body,
+// setting the headers returned by headers on each attempt if it is not nil. Each
+// attempt times out after the number of seconds set in config.toml. 429 and 5xx
+// responses are retried up to the number of retries set in config.toml, as are
+// network errors for GET requests and connection errors for other requests. If the bot
+// starts shutting down, the request is cancelled and the context's error is returned.
+func (c *Client) request(hc *http.Client, method string, URL string, body []byte, headers func() map[string]string) (*http.Response, error) {
+	u, parseErr := url.Parse(URL)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	retries := c.config().HTTP.Retries
+	if retries < 0 {
+		retries = 0
+	}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			c.log.LogInfo("UTILS", "retrying request", false,
+				"host", u.Host,
+				"attempt", attempt,
+				"err", lastErr)
+		}
+		if waitErr := c.waitForHost(u.Host); waitErr != nil {
+			return nil, errors.Join(lastErr, waitErr)
+		}
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		ctx, cancel := context.WithTimeout(c.ctx, c.requestTimeout())
+		req, reqErr := http.NewRequestWithContext(ctx, method, URL, reader)
+		if reqErr != nil {
+			cancel()
+			return nil, reqErr
+		}
+		if c.config().HTTP.UserAgent != "" {
+			req.Header.Set("User-Agent", c.config().HTTP.UserAgent)
+		}
+		if headers != nil {
+			for key, value := range headers() {
+				req.Header.Set(key, value)
+			}
+		}
+		res, doErr := hc.Do(req)
+		if doErr != nil {
+			cancel()
+			// Keep only the host in the error, as the path of a webhook URL is a secret.
+			var urlErr *url.Error
+			if errors.As(doErr, &urlErr) {
+				doErr = fmt.Errorf("%s %s: %w", method, u.Host, urlErr.Err)
+			}
+			if errors.Is(doErr, ErrPrivateAddress) || errors.Is(doErr, errRedirect) ||
+				(method != http.MethodGet && !connectError(doErr)) {
+				return nil, doErr
+			}
+			lastErr = doErr
+			if attempt < retries {
+				if sleepErr := c.sleep(backoff(attempt, "")); sleepErr != nil {
+					return nil, errors.Join(lastErr, sleepErr)
+				}
+			}
+			continue
+		}
+		if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+			lastErr = fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+			retryAfter := res.Header.Get("Retry-After")
+			res.Body.Close()
+			cancel()
+			if attempt < retries {
+				if sleepErr := c.sleep(backoff(attempt, retryAfter)); sleepErr != nil {
+					return nil, errors.Join(lastErr, sleepErr)
+				}
+			}
+			continue
+		}
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		return res, nil
+	}
+	return nil, lastErr
+}
+
+// connectError returns true if the error is from connecting to the host, in which case
+// the request was not sent.
+func connectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// waitForHost blocks until a request can be made to the host without exceeding the
+// rate limit set in config.toml, then reserves the next slot. It returns the context's
+// error if the bot starts shutting down while it waits.
+func (c *Client) waitForHost(host string) error {
+	interval := time.Duration(c.config().HTTP.HostIntervalMillis) * time.Millisecond
+	if interval <= 0 {
+		return c.ctx.Err()
+	}
+	c.hostNextMu.Lock()
+	now := time.Now()
+	next := c.hostNext[host]
+	if next.Before(now) {
+		next = now
+	}
+	c.hostNext[host] = next.Add(interval)
+	c.hostNextMu.Unlock()
+
+	return c.sleep(time.Until(next))
+}
+
+// sleep waits for the duration, or until the bot starts shutting down, in which case
+// it returns the context's error.
+func (c *Client) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+// backoff returns how long to wait before the next attempt. The Retry-After header is
+// used if it is set, up to maxRetryAfter, otherwise the wait is given by Backoff.
+func backoff(attempt int, retryAfter string) time.Duration {
+	if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxRetryAfter)
+	}
+	return Backoff(attempt)
+}
+
+// Backoff returns how long to wait before retrying after the given number of failed
+// attempts, starting at half a second and doubling with each attempt with some jitter.
+func Backoff(attempt int) time.Duration {
+	wait := time.Duration(1<<attempt) * 500 * time.Millisecond
+	return wait + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
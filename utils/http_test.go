@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gamestreams/config"
+	"gamestreams/logs"
+)
+
+func TestDialPublic(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:443", false},
+		{"[::1]:443", false},
+		{"10.0.0.5:443", false},
+		{"172.16.3.4:443", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:443", false},
+		{"[fc00::1]:443", false},
+		{"0.0.0.0:443", false},
+		{"[::ffff:127.0.0.1]:443", false},
+		{"224.0.0.1:443", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			dialErr := dialPublic("tcp", tt.address, nil)
+			if tt.allowed && dialErr != nil {
+				t.Errorf("dialPublic(%q) = %v, want no error", tt.address, dialErr)
+			}
+			if !tt.allowed && !errors.Is(dialErr, ErrPrivateAddress) {
+				t.Errorf("dialPublic(%q) = %v, want ErrPrivateAddress", tt.address, dialErr)
+			}
+		})
+	}
+}
+
+// newTestClient returns a Client that uses the configuration values.
+func newTestClient(cfg *config.Config) *Client {
+	return NewClient(context.Background(), func() *config.Config { return cfg }, logs.New(cfg.Logs))
+}
+
+func TestWebhookPostRefusesLoopback(t *testing.T) {
+	c := newTestClient(&config.Config{HTTP: config.HTTP{Retries: 2}})
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	if _, postErr := c.WebhookPost(server.URL, []byte("{}"), nil); !errors.Is(postErr, ErrPrivateAddress) {
+		t.Errorf("WebhookPost() error = %v, want ErrPrivateAddress", postErr)
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests, want 0", requests)
+	}
+}
+
+// dropConnection closes the connection of the request without responding.
+func dropConnection(t *testing.T, w http.ResponseWriter) {
+	conn, _, hijackErr := w.(http.Hijacker).Hijack()
+	if hijackErr != nil {
+		t.Error(hijackErr)
+		return
+	}
+	conn.Close()
+}
+
+func TestRequestRetries(t *testing.T) {
+	c := newTestClient(&config.Config{HTTP: config.HTTP{Retries: 1}})
+	tests := []struct {
+		name     string
+		method   string
+		respond  func(w http.ResponseWriter, attempt int32)
+		wantErr  bool
+		attempts int32
+	}{
+		{
+			name:   "POST retried after 503",
+			method: http.MethodPost,
+			respond: func(w http.ResponseWriter, attempt int32) {
+				if attempt == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			},
+			attempts: 2,
+		},
+		{
+			name:   "POST not retried after the connection is dropped",
+			method: http.MethodPost,
+			respond: func(w http.ResponseWriter, attempt int32) {
+				dropConnection(t, w)
+			},
+			wantErr:  true,
+			attempts: 1,
+		},
+		{
+			name:   "GET retried after the connection is dropped",
+			method: http.MethodGet,
+			respond: func(w http.ResponseWriter, attempt int32) {
+				dropConnection(t, w)
+			},
+			wantErr:  true,
+			attempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			var seen []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = append(seen, r.Header.Get("X-Attempt"))
+				tt.respond(w, attempts.Add(1))
+			}))
+
+			var calls int
+			start := time.Now()
+			res, requestErr := c.request(c.http, tt.method, server.URL, []byte("{}"),
+				func() map[string]string {
+					calls++
+					return map[string]string{"X-Attempt": strconv.Itoa(calls)}
+				})
+			elapsed := time.Since(start)
+			if res != nil {
+				res.Body.Close()
+			}
+			server.Close()
+			if (requestErr != nil) != tt.wantErr {
+				t.Errorf("request() error = %v, want error %t", requestErr, tt.wantErr)
+			}
+			if attempts.Load() != tt.attempts {
+				t.Errorf("server received %d requests, want %d", attempts.Load(), tt.attempts)
+			}
+			for i, header := range seen {
+				if header != strconv.Itoa(i+1) {
+					t.Errorf("attempt %d sent headers of attempt %s, want headers made for each attempt",
+						i+1, header)
+				}
+			}
+			// The wait before the second attempt is at most 750ms, and a wait after the
+			// last attempt would be at least a second.
+			if elapsed >= 1500*time.Millisecond {
+				t.Errorf("request() took %s, want no wait after the last attempt", elapsed)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutReadOnEachRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{HTTP: config.HTTP{TimeoutSeconds: 1}}
+	c := NewClient(context.Background(), func() *config.Config { return cfg }, logs.New(cfg.Logs))
+	if res, getErr := c.Get(server.URL); getErr == nil {
+		res.Body.Close()
+		t.Error("Get() with a 1 second timeout succeeded, want a timeout")
+	}
+
+	// The config is reloaded after the client has been created, and the new timeout is
+	// used.
+	cfg = &config.Config{HTTP: config.HTTP{TimeoutSeconds: 3}}
+	res, getErr := c.Get(server.URL)
+	if getErr != nil {
+		t.Fatalf("Get() with a 3 second timeout error = %v", getErr)
+	}
+	defer res.Body.Close()
+	body, readErr := io.ReadAll(res.Body)
+	if readErr != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v, want ok", body, readErr)
+	}
+}
+
+func TestBackoffCapsRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter string
+		want       time.Duration
+	}{
+		{"0", 0},
+		{"30", 30 * time.Second},
+		{"60", time.Minute},
+		{"86400", time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(0, tt.retryAfter); got != tt.want {
+			t.Errorf("backoff(0, %q) = %s, want %s", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestRequestCancelledDuringWaits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		cfg  config.HTTP
+		// True if a request is made first, so the request that is cancelled waits for
+		// the host interval.
+		reserveHost bool
+	}{
+		{"backoff", config.HTTP{Retries: 1}, false},
+		{"host interval", config.HTTP{HostIntervalMillis: 30000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{HTTP: tt.cfg}
+			ctx, cancel := context.WithCancel(context.Background())
+			c := NewClient(ctx, func() *config.Config { return cfg }, logs.New(cfg.Logs))
+			if tt.reserveHost {
+				if res, getErr := c.Get(server.URL); getErr == nil {
+					res.Body.Close()
+				}
+			}
+			time.AfterFunc(100*time.Millisecond, cancel)
+
+			start := time.Now()
+			res, getErr := c.Get(server.URL)
+			if res != nil {
+				res.Body.Close()
+			}
+			if !errors.Is(getErr, context.Canceled) {
+				t.Errorf("Get() error = %v, want context.Canceled", getErr)
+			}
+			if elapsed := time.Since(start); elapsed >= 5*time.Second {
+				t.Errorf("Get() took %s after the context was cancelled", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+/*
+recurrence.go contains a parser for RRULE-style recurrence rules and functions to expand
+them into concrete dates. Only the parts of RFC 5545 needed for recurring streams are
+supported: weekly and monthly frequencies with INTERVAL, BYDAY, BYMONTHDAY, COUNT and
+UNTIL.
+*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is a parsed recurrence rule, e.g. FREQ=MONTHLY;BYDAY=2TH for the second
+// Thursday of every month.
+type Rule struct {
+	// The frequency of the rule, WEEKLY or MONTHLY.
+	Freq string
+	// The number of weeks or months between each occurrence. Defaults to 1.
+	Interval int
+	// The days of the week the rule occurs on. For monthly rules each day may have an
+	// ordinal, e.g. 2TH for the second Thursday or -1FR for the last Friday.
+	ByDay []RuleDay
+	// The days of the month the rule occurs on. Negative values count from the end of
+	// the month. Only used by monthly rules.
+	ByMonthDay []int
+	// The maximum number of occurrences, counted from the start date. Zero means no
+	// limit.
+	Count int
+	// The last date the rule can occur on. The zero time means no limit.
+	Until time.Time
+}
+
+// RuleDay is a day of the week in a recurrence rule with an optional ordinal.
+type RuleDay struct {
+	// The day of the week.
+	Weekday time.Weekday
+	// The occurrence of the weekday within the month, e.g. 2 for the second or -1 for
+	// the last. Zero means every occurrence.
+	N int
+}
+
+// ruleWeekdays maps the two letter RRULE day codes to weekdays.
+var ruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRule parses an RRULE-style string such as "FREQ=WEEKLY;BYDAY=TU" or
+// "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR". An optional "RRULE:" prefix is ignored.
+func ParseRule(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(s)), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("empty recurrence rule")
+	}
+	for _, part := range strings.Split(s, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			if value != "WEEKLY" && value != "MONTHLY" {
+				return Rule{}, fmt.Errorf("unsupported frequency %q", value)
+			}
+			r.Freq = value
+		case "INTERVAL":
+			interval, convErr := strconv.Atoi(value)
+			if convErr != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("invalid interval %q", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				ruleDay, dayErr := parseRuleDay(day)
+				if dayErr != nil {
+					return Rule{}, dayErr
+				}
+				r.ByDay = append(r.ByDay, ruleDay)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, convErr := strconv.Atoi(day)
+				if convErr != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return Rule{}, fmt.Errorf("invalid month day %q", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, monthDay)
+			}
+		case "COUNT":
+			count, convErr := strconv.Atoi(value)
+			if convErr != nil || count < 1 {
+				return Rule{}, fmt.Errorf("invalid count %q", value)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, parseErr := time.Parse("20060102", value[:min(len(value), 8)])
+			if parseErr != nil {
+				return Rule{}, fmt.Errorf("invalid until date %q", value)
+			}
+			r.Until = until
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+	if r.Freq == "" {
+		return Rule{}, errors.New("recurrence rule has no FREQ")
+	}
+	if len(r.ByDay) > 0 && len(r.ByMonthDay) > 0 {
+		return Rule{}, errors.New("BYDAY and BYMONTHDAY cannot be used together")
+	}
+	if r.Freq == "WEEKLY" {
+		if len(r.ByMonthDay) > 0 {
+			return Rule{}, errors.New("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+		}
+		for _, day := range r.ByDay {
+			if day.N != 0 {
+				return Rule{}, errors.New("BYDAY ordinals cannot be used with FREQ=WEEKLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseRuleDay parses a BYDAY value such as "TU", "2TH" or "-1FR".
+func parseRuleDay(s string) (RuleDay, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return RuleDay{}, fmt.Errorf("invalid day %q", s)
+	}
+	weekday, found := ruleWeekdays[s[len(s)-2:]]
+	if !found {
+		return RuleDay{}, fmt.Errorf("invalid day %q", s)
+	}
+	day := RuleDay{Weekday: weekday}
+	if ordinal := s[:len(s)-2]; ordinal != "" {
+		n, convErr := strconv.Atoi(ordinal)
+		if convErr != nil || n == 0 || n < -5 || n > 5 {
+			return RuleDay{}, fmt.Errorf("invalid day ordinal %q", s)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+// Between returns the dates the rule occurs on between from and to inclusive, for a
+// rule that starts on the given date. The start date anchors the interval and is the
+// default day when the rule has no BYDAY or BYMONTHDAY. Times are ignored and the
+// returned dates are at midnight UTC.
+func (r Rule) Between(start time.Time, from time.Time, to time.Time) []time.Time {
+	start = truncateDay(start)
+	from = truncateDay(from)
+	to = truncateDay(to)
+	if !r.Until.IsZero() && r.Until.Before(to) {
+		to = truncateDay(r.Until)
+	}
+	interval := max(r.Interval, 1)
+
+	var dates []time.Time
+	count := 0
+	// Rules with a count have to be expanded from the start to know which occurrences
+	// are within the count. Other rules start at the period containing from, so rules
+	// that started long ago are not expanded from the beginning.
+	first := 0
+	if r.Count == 0 {
+		first = r.periodsBefore(start, from) / interval * interval
+	}
+	empty := 0
+	for period := first; ; period += interval {
+		var candidates []time.Time
+		if r.Freq == "WEEKLY" {
+			candidates = r.weekDates(start, period)
+		} else {
+			candidates = r.monthDates(start, period)
+		}
+		if len(candidates) > 0 && candidates[0].After(to) {
+			break
+		}
+		// Guard against rules that never produce a date, e.g. BYMONTHDAY=31 with an
+		// interval that only lands on shorter months.
+		if len(candidates) == 0 {
+			empty++
+			if empty > maxEmptyPeriods {
+				break
+			}
+			continue
+		}
+		empty = 0
+		for _, date := range candidates {
+			if date.Before(start) || date.After(to) {
+				continue
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return dates
+			}
+			if !date.Before(from) {
+				dates = append(dates, date)
+			}
+		}
+	}
+	return dates
+}
+
+// maxEmptyPeriods is the number of periods in a row without a date after which a rule
+// is treated as never occurring again. A monthly rule for the 5th weekday or the 29th
+// of a month with a yearly interval can go several years without a date, but not this
+// many.
+const maxEmptyPeriods = 100
+
+// periodsBefore returns the number of whole weeks or months, depending on the
+// frequency of the rule, from the period of the start date to the period of the given
+// date, or 0 if the date is before the start.
+func (r Rule) periodsBefore(start time.Time, date time.Time) int {
+	if !date.After(start) {
+		return 0
+	}
+	if r.Freq == "WEEKLY" {
+		startMonday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		dateMonday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return int(dateMonday.Sub(startMonday).Hours()/24) / 7
+	}
+	return (date.Year()-start.Year())*12 + int(date.Month()-start.Month())
+}
+
+// weekDates returns the dates of the rule in the week that is the given number of
+// weeks after the week of the start date. Weeks start on Monday.
+func (r Rule) weekDates(start time.Time, weeks int) []time.Time {
+	monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+weeks*7)
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []RuleDay{{Weekday: start.Weekday()}}
+	}
+	var dates []time.Time
+	for _, day := range days {
+		dates = append(dates, monday.AddDate(0, 0, (int(day.Weekday)+6)%7))
+	}
+	sortDates(dates)
+	return dates
+}
+
+// monthDates returns the dates of the rule in the month that is the given number of
+// months after the month of the start date.
+func (r Rule) monthDates(start time.Time, months int) []time.Time {
+	first := time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	var dates []time.Time
+	for _, day := range r.ByDay {
+		offset := (int(day.Weekday) - int(first.Weekday()) + 7) % 7
+		var monthDays []int
+		for d := 1 + offset; d <= daysInMonth; d += 7 {
+			monthDays = append(monthDays, d)
+		}
+		switch {
+		case day.N == 0:
+			for _, d := range monthDays {
+				dates = append(dates, first.AddDate(0, 0, d-1))
+			}
+		case day.N > 0 && day.N <= len(monthDays):
+			dates = append(dates, first.AddDate(0, 0, monthDays[day.N-1]-1))
+		case day.N < 0 && -day.N <= len(monthDays):
+			dates = append(dates, first.AddDate(0, 0, monthDays[len(monthDays)+day.N]-1))
+		}
+	}
+	monthDays := r.ByMonthDay
+	if len(r.ByDay) == 0 && len(monthDays) == 0 {
+		monthDays = []int{start.Day()}
+	}
+	for _, d := range monthDays {
+		if d < 0 {
+			d = daysInMonth + d + 1
+		}
+		if d >= 1 && d <= daysInMonth {
+			dates = append(dates, first.AddDate(0, 0, d-1))
+		}
+	}
+	sortDates(dates)
+	return dates
+}
+
+// truncateDay returns midnight UTC of the day of the given time.
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sortDates sorts the dates in ascending order.
+func sortDates(dates []time.Time) {
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRuleBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		from  string
+		to    string
+		want  []string
+	}{
+		{
+			name:  "weekly",
+			rule:  "FREQ=WEEKLY;BYDAY=TU",
+			start: "2026-01-06", from: "2026-01-01", to: "2026-01-31",
+			want: []string{"2026-01-06", "2026-01-13", "2026-01-20", "2026-01-27"},
+		},
+		{
+			name:  "weekly on the start day",
+			rule:  "FREQ=WEEKLY",
+			start: "2026-01-06", from: "2026-01-10", to: "2026-01-25",
+			want: []string{"2026-01-13", "2026-01-20"},
+		},
+		{
+			name:  "weekly from before the start",
+			rule:  "FREQ=WEEKLY;BYDAY=TU",
+			start: "2026-01-13", from: "2026-01-01", to: "2026-01-20",
+			want: []string{"2026-01-13", "2026-01-20"},
+		},
+		{
+			name:  "fortnightly on two days starting decades ago",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: "1990-01-01", from: "2026-10-01", to: "2026-10-31",
+			want: []string{"2026-10-05", "2026-10-08", "2026-10-19", "2026-10-22"},
+		},
+		{
+			name:  "monthly 2nd weekday",
+			rule:  "FREQ=MONTHLY;BYDAY=2TH",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-04-30",
+			want: []string{"2026-01-08", "2026-02-12", "2026-03-12", "2026-04-09"},
+		},
+		{
+			name:  "monthly 5th weekday only in months that have one",
+			rule:  "FREQ=MONTHLY;BYDAY=5FR",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-06-30",
+			want: []string{"2026-01-30", "2026-05-29"},
+		},
+		{
+			name:  "monthly last weekday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-03-31",
+			want: []string{"2026-01-30", "2026-02-27", "2026-03-27"},
+		},
+		{
+			name:  "monthly 2nd weekday starting over a century ago",
+			rule:  "FREQ=MONTHLY;BYDAY=2TH",
+			start: "1900-01-11", from: "2026-10-01", to: "2026-12-31",
+			want: []string{"2026-10-08", "2026-11-12", "2026-12-10"},
+		},
+		{
+			name:  "month day skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-06-30",
+			want: []string{"2026-01-31", "2026-03-31", "2026-05-31"},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-03-31",
+			want: []string{"2026-01-31", "2026-02-28", "2026-03-31"},
+		},
+		{
+			name:  "until",
+			rule:  "FREQ=MONTHLY;BYDAY=2TH;UNTIL=20260215",
+			start: "2026-01-01", from: "2026-01-01", to: "2026-04-30",
+			want: []string{"2026-01-08", "2026-02-12"},
+		},
+		{
+			name:  "count is from the start",
+			rule:  "FREQ=MONTHLY;BYDAY=2TH;COUNT=3",
+			start: "2026-01-01", from: "2026-02-01", to: "2026-12-31",
+			want: []string{"2026-02-12", "2026-03-12"},
+		},
+		{
+			name:  "count reached before from",
+			rule:  "FREQ=WEEKLY;BYDAY=TU;COUNT=2",
+			start: "2026-01-06", from: "2026-02-01", to: "2026-02-28",
+			want: nil,
+		},
+		{
+			name:  "never occurs",
+			rule:  "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30",
+			start: "2026-02-01", from: "2026-01-01", to: "2200-01-01",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, parseErr := ParseRule(tt.rule)
+			if parseErr != nil {
+				t.Fatalf("ParseRule(%q) error = %v", tt.rule, parseErr)
+			}
+			var got []string
+			for _, date := range rule.Between(parseDate(t, tt.start), parseDate(t, tt.from),
+				parseDate(t, tt.to)) {
+				got = append(got, date.Format(time.DateOnly))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"FREQ=DAILY",
+		"BYDAY=TU",
+		"FREQ=WEEKLY;BYDAY=2TU",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=6TU",
+		"FREQ=MONTHLY;BYDAY=TU;BYMONTHDAY=1",
+		"FREQ=MONTHLY;INTERVAL=0",
+		"FREQ=MONTHLY;COUNT=-1",
+		"FREQ=MONTHLY;UNTIL=2026",
+	} {
+		if _, parseErr := ParseRule(rule); parseErr == nil {
+			t.Errorf("ParseRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
+
+// parseDate returns the date in YYYY-MM-DD format as a time.
+func parseDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	date, parseErr := time.Parse(time.DateOnly, s)
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+	return date
+}
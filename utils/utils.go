@@ -6,20 +6,16 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"net/url"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 
-	"gamestreams/logs"
+	"gamestreams/locales"
 )
 
-// StartTime is the time the bot started.
-var StartTime time.Time
-
 // ParseTomlDate converts a date string from DD/MM/YYYY to YYYY-MM-DD.
 func ParseTomlDate(d string) (string, error) {
 	splitStr := strings.Split(d, "/")
@@ -29,6 +25,19 @@ func ParseTomlDate(d string) (string, error) {
 	return fmt.Sprintf("%s-%s-%s", splitStr[2], splitStr[1], splitStr[0]), nil
 }
 
+// FormatTomlDate converts a date string from YYYY-MM-DD to DD/MM/YYYY, the reverse of
+// ParseTomlDate. Empty strings are returned unchanged.
+func FormatTomlDate(d string) (string, error) {
+	if d == "" {
+		return "", nil
+	}
+	splitStr := strings.Split(d, "-")
+	if len(splitStr) != 3 {
+		return "", errors.New("invalid date format")
+	}
+	return fmt.Sprintf("%s/%s/%s", splitStr[2], splitStr[1], splitStr[0]), nil
+}
+
 // Pluralise returns an "s" if n is not 1. Used for pluralising words.
 func Pluralise(n int) string {
 	if n == 1 {
@@ -47,96 +56,34 @@ func RemoveSliceDuplicates(s []string) map[string]bool {
 	return m
 }
 
-// PlaceholderText returns "not set" if the given string is empty.
-func PlaceholderText(s string) string {
+// PlaceholderText returns "not set", in the given locale, if the given string is empty.
+func PlaceholderText(s string, locale string) string {
 	s = strings.TrimSpace(s)
 	if len(s) == 0 ||
 		s == "<#>" ||
 		s == "<@>" ||
 		s == "<@&>" {
-		return "not set"
+		return locales.T(locale, "common.not_set")
 	}
 	return s
 }
 
-// GetVideoThumbnail returns the thumbnail of a video stream from a given URL.
-func GetVideoThumbnail(streamURL string) string {
-	if strings.Contains(streamURL, "twitch") {
-		return GetTwitchProfilePicture(streamURL)
-	} else if strings.Contains(streamURL, "youtube") {
-		return GetYoutubeLiveThumbnail(streamURL)
-	} else if strings.Contains(streamURL, "facebook") {
-		name := strings.Split(streamURL, "/")[3]
-		return fmt.Sprintf("https://graph.facebook.com/%s/picture?type=large", name)
-	}
-	return ""
-}
-
-// GetTwitchProfilePicture returns the profile picture of a Twitch stream from a given
-// URL.
-func GetTwitchProfilePicture(streamURL string) string {
-	doc, err := GetHTMLBody(streamURL)
-	if err != nil {
-		logs.LogError("UTILS", "error getting twitch html", "err", err)
-		return ""
-	}
-	var pictureURL string
-	doc.Find("meta").Each(func(i int, s *goquery.Selection) {
-		val, exists := s.Attr("property")
-		if exists && val == "og:image" {
-			pictureURL, _ = s.Attr("content")
-		}
-	})
-	return pictureURL
-}
-
-// GetYoutubeLiveThumbnail returns the thumbnail of a YouTube stream from a given URL.
-// If the URL is a direct link to the stream, it extract the video ID from the URL.
-// If the URL is a channel link, it will call GetYoutubeDirectUrl to get the direct
-// link and extract the video ID from that.
-func GetYoutubeLiveThumbnail(streamURL string) string {
-	var ID string = ""
-
-	if strings.Contains(streamURL, "=") {
-		// thumbnail from direct link
-		ID = strings.Split(streamURL, "=")[1]
-	} else {
-		// thumbnail from channel link
-		directURL, success := GetYoutubeDirectURL(streamURL)
-		if success {
-			ID = strings.Split(directURL, "=")[1]
-		}
-	}
-	if ID != "" {
-		return fmt.Sprintf("https://img.youtube.com/vi/%s/maxresdefault.jpg", ID)
+// Truncate shortens a string to at most n characters, replacing the end with an
+// ellipsis if it was shortened.
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
 	}
-	return ""
-}
-
-// GetYoutubeDirectURL returns the direct URL of a YouTube stream from a profiles
-// /live URL.
-func GetYoutubeDirectURL(streamURL string) (string, bool) {
-	var directURL string = ""
-	var success bool = false
-
-	doc, err := GetHTMLBody(streamURL)
-	if err != nil {
-		logs.LogError("UTILS", "error getting youtube html", "err", err)
-		return "", false
+	if n <= 3 {
+		return string(runes[:n])
 	}
-	doc.Find("link").Each(func(i int, s *goquery.Selection) {
-		URL, _ := s.Attr("href")
-		if strings.Contains(URL, "?v=") {
-			directURL = URL
-			success = true
-		}
-	})
-	return directURL, success
+	return string(runes[:n-3]) + "..."
 }
 
 // GetHTMLBody returns the HTML body of a given URL as a goquery.Document struct.
-func GetHTMLBody(URL string) (*goquery.Document, error) {
-	res, err := http.Get(URL)
+func (c *Client) GetHTMLBody(URL string) (*goquery.Document, error) {
+	res, err := c.Get(URL)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +98,19 @@ func GetHTMLBody(URL string) (*goquery.Document, error) {
 	return doc, err
 }
 
+// GetBody returns the body of the response from a given URL.
+func (c *Client) GetBody(URL string) ([]byte, error) {
+	res, err := c.Get(URL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
 // PatternValidator checks if a string matches a given regex pattern.
 func PatternValidator(s string, pattern string) (bool, error) {
 	match, err := regexp.MatchString(pattern, s)